@@ -14,6 +14,7 @@ func BenchmarkBFV(b *testing.B) {
 	b.Run("Encrypt", benchEncrypt)
 	b.Run("Decrypt", benchDecrypt)
 	b.Run("Evaluator", benchEvaluator)
+	b.Run("LinearTransform", benchLinearTransform)
 }
 
 func benchEncoder(b *testing.B) {
@@ -118,6 +119,12 @@ func benchEvaluator(b *testing.B) {
 		params.kgen.GenRot(RotationLeft, params.sk, 1, rotkey)
 		params.kgen.GenRot(RotationRow, params.sk, 0, rotkey)
 
+		// Rotations shared by the RotateHoisted benchmark
+		rotations := []uint64{1, 2, 3, 4, 5, 6, 7, 8}
+		for _, k := range rotations[1:] {
+			params.kgen.GenRot(RotationLeft, params.sk, k, rotkey)
+		}
+
 		b.Run(testString("Add/", parameters), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				evaluator.Add(ciphertext1, ciphertext2, ciphertext1)
@@ -160,5 +167,48 @@ func benchEvaluator(b *testing.B) {
 			}
 		})
 
+		b.Run(testString("RotateHoisted/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				evaluator.RotateHoisted(ciphertext1, rotations, rotkey)
+			}
+		})
+
+	}
+}
+
+func benchLinearTransform(b *testing.B) {
+
+	for _, parameters := range testParams.bfvParameters {
+
+		params := genBfvParams(parameters)
+
+		// Banded matrix with 32 non-zero diagonals
+		diagMatrix := make(map[uint64][]uint64)
+		for k := uint64(0); k < 32; k++ {
+			diagMatrix[k] = params.bfvContext.contextT.NewUniformPoly().Coeffs[0]
+		}
+
+		ltNaive := NewLinearTransform(parameters, params.encoder, diagMatrix)
+		ltBSGS := NewLinearTransformBSGS(parameters, params.encoder, diagMatrix)
+
+		rotkey := NewRotationKeys()
+		for _, k := range append(ltNaive.Rotations(), ltBSGS.Rotations()...) {
+			params.kgen.GenRot(RotationLeft, params.sk, k, rotkey)
+		}
+
+		ciphertext := NewCiphertextRandom(parameters, 1)
+		ctOut := NewCiphertext(parameters, 1)
+
+		b.Run(testString("Naive/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.LinearTransform(ciphertext, ltNaive, rotkey, ctOut)
+			}
+		})
+
+		b.Run(testString("BSGS/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.LinearTransform(ciphertext, ltBSGS, rotkey, ctOut)
+			}
+		})
 	}
 }
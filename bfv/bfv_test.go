@@ -1,9 +1,11 @@
 package bfv
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -59,9 +61,15 @@ func TestBFV(t *testing.T) {
 	t.Run("Evaluator/Add", testEvaluatorAdd)
 	t.Run("Evaluator/Sub", testEvaluatorSub)
 	t.Run("Evaluator/Mul", testEvaluatorMul)
+	t.Run("Evaluator/Levels", testEvaluatorLevels)
+	t.Run("NoiseBudget", testNoiseBudget)
+	t.Run("Parameters/Security", testSecurity)
+	t.Run("Errors", testErrors)
 	t.Run("Evaluator/KeySwitch", testKeySwitch)
 	t.Run("Evaluator/RotateRows", testRotateRows)
 	t.Run("Evaluator/RotateCols", testRotateCols)
+	t.Run("Evaluator/LinearTransform", testLinearTransform)
+	t.Run("Evaluator/Gadget", testGadget)
 	t.Run("Marshalling", testMarshaller)
 }
 
@@ -238,6 +246,153 @@ func testMarshaller(t *testing.T) {
 				}
 			}
 		})
+
+		equalSwitchingKeys := func(swkWant, swkTest *SwitchingKey) bool {
+			if swkTest == nil || len(swkWant.evakey) != len(swkTest.evakey) || swkWant.logBase != swkTest.logBase {
+				return false
+			}
+			// The keys of the gadget decomposition are in basis Q only
+			contextKeys := contextQP
+			if swkWant.logBase != 0 {
+				contextKeys = params.bfvContext.contextQ
+			}
+			for j := range swkWant.evakey {
+				for k := range swkWant.evakey[j] {
+					if !contextKeys.Equal(swkWant.evakey[j][k], swkTest.evakey[j][k]) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		t.Run(testString("Compressed/Ciphertext/", parameters), func(t *testing.T) {
+
+			for _, fast := range []bool{false, true} {
+
+				coeffs, plaintext, _ := newTestVectors(params, nil, t)
+
+				var ciphertextWant *Ciphertext
+				if fast {
+					ciphertextWant = params.encryptorSk.EncryptFastNew(plaintext)
+				} else {
+					ciphertextWant = params.encryptorSk.EncryptNew(plaintext)
+				}
+
+				data, err := ciphertextWant.MarshalBinaryCompressed()
+				check(t, err)
+
+				if uint64(len(data)) != ciphertextWant.GetDataLenCompressed(true) || 2*uint64(len(data)) > ciphertextWant.GetDataLen(true)+2*64 {
+					t.Errorf("invalid compressed Ciphertext size: have %d, uncompressed %d", len(data), ciphertextWant.GetDataLen(true))
+				}
+
+				ciphertextTest := new(Ciphertext)
+				check(t, ciphertextTest.UnmarshalBinaryCompressed(parameters, data))
+
+				for i := range ciphertextWant.value {
+					if !params.bfvContext.contextQ.Equal(ciphertextWant.value[i], ciphertextTest.value[i]) {
+						t.Errorf("marshal compressed Ciphertext element [%d]", i)
+					}
+				}
+
+				verifyTestVectors(params, params.decryptor, coeffs, ciphertextTest, t)
+			}
+
+			if _, err := params.encryptorPk.EncryptNew(NewPlaintext(parameters)).MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a Ciphertext encrypted with the public-key should not be compressible")
+			}
+
+			_, plaintext, _ := newTestVectors(params, nil, t)
+
+			ciphertext := params.encryptorSk.EncryptNew(plaintext)
+			params.evaluator.Add(ciphertext, ciphertext, ciphertext)
+			if _, err := ciphertext.MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a Ciphertext modified by the evaluator should not be compressible")
+			}
+
+			if parameters.MaxLevel() > 0 {
+				ciphertext = params.encryptorSk.EncryptNew(plaintext)
+				check(t, params.evaluator.DropLevel(ciphertext, 1))
+				if _, err := ciphertext.MarshalBinaryCompressed(); err == nil {
+					t.Errorf("a Ciphertext dropped to a lower level should not be compressible")
+				}
+			}
+		})
+
+		t.Run(testString("Compressed/Keys/", parameters), func(t *testing.T) {
+
+			for _, logBase := range []uint64{0, 30} {
+
+				skOut := params.kgen.GenSecretKey()
+
+				var switchingKey *SwitchingKey
+				var evalkey *EvaluationKey
+				rotationKey := NewRotationKeys()
+
+				if logBase == 0 {
+					switchingKey = params.kgen.GenSwitchingKey(params.sk, skOut)
+					evalkey = params.kgen.GenRelinKey(params.sk, 2)
+					params.kgen.GenRot(RotationLeft, params.sk, 1, rotationKey)
+					params.kgen.GenRot(RotationRow, params.sk, 0, rotationKey)
+				} else {
+					switchingKey = params.kgen.GenSwitchingKeyGadget(params.sk, skOut, logBase)
+					evalkey = params.kgen.GenRelinKeyGadget(params.sk, 1, logBase)
+					params.kgen.GenRotGadget(RotationRight, params.sk, 1, logBase, rotationKey)
+				}
+
+				data, err := switchingKey.MarshalBinaryCompressed()
+				check(t, err)
+
+				if uint64(len(data)) != switchingKey.GetDataLenCompressed(true) {
+					t.Errorf("invalid compressed SwitchingKey size: have %d, want %d", len(data), switchingKey.GetDataLenCompressed(true))
+				}
+
+				switchingKeyTest := new(SwitchingKey)
+				check(t, switchingKeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				if !equalSwitchingKeys(switchingKey, switchingKeyTest) {
+					t.Errorf("marshal compressed SwitchingKey (logBase=%d)", logBase)
+				}
+
+				data, err = evalkey.MarshalBinaryCompressed()
+				check(t, err)
+
+				evalkeyTest := new(EvaluationKey)
+				check(t, evalkeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				for deg := range evalkey.evakey {
+					if !equalSwitchingKeys(evalkey.evakey[deg], evalkeyTest.evakey[deg]) {
+						t.Errorf("marshal compressed EvaluationKey deg %d (logBase=%d)", deg, logBase)
+					}
+				}
+
+				data, err = rotationKey.MarshalBinaryCompressed()
+				check(t, err)
+
+				rotationKeyTest := new(RotationKeys)
+				check(t, rotationKeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				for i, swk := range rotationKey.evakeyRotColLeft {
+					if !equalSwitchingKeys(swk, rotationKeyTest.evakeyRotColLeft[i]) {
+						t.Errorf("marshal compressed RotationKey RotateLeft %d (logBase=%d)", i, logBase)
+					}
+				}
+
+				for i, swk := range rotationKey.evakeyRotColRight {
+					if !equalSwitchingKeys(swk, rotationKeyTest.evakeyRotColRight[i]) {
+						t.Errorf("marshal compressed RotationKey RotateRight %d (logBase=%d)", i, logBase)
+					}
+				}
+
+				if rotationKey.evakeyRotRow != nil && !equalSwitchingKeys(rotationKey.evakeyRotRow, rotationKeyTest.evakeyRotRow) {
+					t.Errorf("marshal compressed RotationKey RotateRow (logBase=%d)", logBase)
+				}
+			}
+
+			if _, err := NewSwitchingKey(parameters).MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a SwitchingKey without seed should not be compressible")
+			}
+		})
 	}
 }
 
@@ -312,6 +467,33 @@ func testEncoder(t *testing.T) {
 
 			verifyTestVectors(params, params.decryptor, values, plaintext, t)
 		})
+
+		t.Run(testString("EncodeCoeffs&DecodeCoeffs/", parameters), func(t *testing.T) {
+
+			contextT := params.bfvContext.contextT
+
+			coeffs0 := contextT.NewUniformPoly()
+			coeffs1 := contextT.NewUniformPoly()
+
+			plaintext0 := NewPlaintext(params.params)
+			plaintext1 := NewPlaintext(params.params)
+
+			params.encoder.EncodeCoeffs(coeffs0.Coeffs[0], plaintext0)
+			params.encoder.EncodeCoeffs(coeffs1.Coeffs[0], plaintext1)
+
+			if utils.EqualSliceUint64(coeffs0.Coeffs[0], params.encoder.DecodeCoeffs(plaintext0)) != true {
+				t.Errorf("decoding error")
+			}
+
+			// The product of two plaintexts encoded on the coefficients is the negacyclic product of their polynomials
+			ciphertext := params.encryptorSk.EncryptNew(plaintext0)
+			params.evaluator.Mul(ciphertext, plaintext1, ciphertext)
+			contextT.MulPoly(coeffs0, coeffs1, coeffs0)
+
+			if utils.EqualSliceUint64(coeffs0.Coeffs[0], params.encoder.DecodeCoeffs(params.decryptor.DecryptNew(ciphertext))) != true {
+				t.Errorf("decryption error")
+			}
+		})
 	}
 }
 
@@ -336,7 +518,13 @@ func testEncryptor(t *testing.T) {
 
 			params.encoder.EncodeUint(coeffs.Coeffs[0], plaintext)
 
-			verifyTestVectors(params, params.decryptor, coeffs, params.encryptorPk.EncryptFastNew(plaintext), t)
+			ciphertext := params.encryptorPk.EncryptFastNew(plaintext)
+
+			if params.bfvContext.contextQ.Equal(ciphertext.value[1], params.bfvContext.contextQ.NewPoly()) {
+				t.Errorf("the uniform element of the Ciphertext is zero")
+			}
+
+			verifyTestVectors(params, params.decryptor, coeffs, ciphertext, t)
 		})
 
 		t.Run(testString("EncryptFromSk/", parameters), func(t *testing.T) {
@@ -496,9 +684,485 @@ func testEvaluatorMul(t *testing.T) {
 			params.evaluator.Relinearize(receiver, rlk, receiver)
 			verifyTestVectors(params, params.decryptor, values1, receiver, t)
 		})
+
+		t.Run(testString("ShallowCopy/", parameters), func(t *testing.T) {
+
+			evaluator := params.evaluator.WithKey(rlk, nil)
+
+			values := make([]*ring.Poly, 4)
+			ciphertexts := make([]*Ciphertext, 4)
+			for i := range ciphertexts {
+				values[i], _, ciphertexts[i] = newTestVectors(params, params.encryptorPk, t)
+				params.bfvContext.contextT.MulCoeffs(values[i], values[i], values[i])
+			}
+
+			// Each goroutine squares its ciphertext with its own shallow copy of the evaluator
+			var wg sync.WaitGroup
+			wg.Add(len(ciphertexts))
+			for i := range ciphertexts {
+				go func(evaluator Evaluator, ciphertext *Ciphertext) {
+					evaluator.Relinearize(evaluator.MulNew(ciphertext, ciphertext), nil, ciphertext)
+					wg.Done()
+				}(evaluator.ShallowCopy(), ciphertexts[i])
+			}
+			wg.Wait()
+
+			for i := range ciphertexts {
+				verifyTestVectors(params, params.decryptor, values[i], ciphertexts[i], t)
+			}
+		})
+	}
+}
+
+func testEvaluatorLevels(t *testing.T) {
+
+	for _, parameters := range testParams.bfvParameters {
+
+		params := genBfvParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk, 1)
+
+		rotkey := params.kgen.GenRotationKeysPow2(params.sk)
+		params.kgen.GenRot(RotationRow, params.sk, 0, rotkey)
+
+		// Bit-size of the modulus at the given level
+		logQLvl := func(level uint64) (logQ uint64) {
+			for _, logqi := range parameters.LogQi[:level+1] {
+				logQ += logqi
+			}
+			return
+		}
+
+		t.Run(testString("ModSwitch/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			for ciphertext.Level() != 0 {
+
+				level := ciphertext.Level()
+
+				ciphertext, err = params.evaluator.ModSwitchNew(ciphertext)
+				check(t, err)
+
+				if ciphertext.Level() != level-1 {
+					t.Errorf("invalid level after ModSwitch: have %d, want %d", ciphertext.Level(), level-1)
+				}
+
+				verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+			}
+
+			if err := params.evaluator.ModSwitch(ciphertext, ciphertext); err == nil {
+				t.Errorf("ModSwitch at level 0 should return an error")
+			}
+		})
+
+		t.Run(testString("DropLevel/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertextLvl0, err := params.evaluator.DropLevelNew(ciphertext, parameters.MaxLevel())
+			check(t, err)
+
+			if ciphertextLvl0.Level() != 0 || ciphertext.Level() != parameters.MaxLevel() {
+				t.Errorf("invalid levels after DropLevelNew")
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertextLvl0, t)
+
+			// Decryption on a receiver of higher level
+			plaintext := NewPlaintext(parameters)
+			params.decryptor.Decrypt(ciphertextLvl0, plaintext)
+
+			if utils.EqualSliceUint64(values.Coeffs[0], params.encoder.DecodeUint(plaintext)) != true {
+				t.Errorf("decryption error")
+			}
+
+			if err := params.evaluator.DropLevel(ciphertextLvl0, 1); err == nil {
+				t.Errorf("DropLevel below level 0 should return an error")
+			}
+		})
+
+		t.Run(testString("AddDifferentLevels/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			check(t, params.evaluator.DropLevel(ciphertext1, 1))
+
+			receiver := params.evaluator.AddNew(ciphertext1, ciphertext2)
+			params.bfvContext.contextT.Add(values1, values2, values1)
+
+			if receiver.Level() != ciphertext1.Level() {
+				t.Errorf("invalid level after Add: have %d, want %d", receiver.Level(), ciphertext1.Level())
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+
+			// In place on the operand of highest level
+			params.evaluator.Sub(ciphertext2, receiver, ciphertext2)
+			params.bfvContext.contextT.Sub(values2, values1, values2)
+
+			verifyTestVectors(params, params.decryptor, values2, ciphertext2, t)
+
+			// With a plaintext at the maximum level
+			params.evaluator.Add(ciphertext1, plaintext2, receiver)
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+		})
+
+		t.Run(testString("MulRelin/", parameters), func(t *testing.T) {
+
+			level := parameters.MaxLevel() - 1
+
+			if parameters.MaxLevel() == 0 || logQLvl(level) < 100 {
+				t.Skip("not enough noise budget at level", level)
+			}
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			check(t, params.evaluator.DropLevel(ciphertext1, 1))
+
+			receiver := params.evaluator.MulNew(ciphertext1, ciphertext2)
+			params.bfvContext.contextT.MulCoeffs(values1, values2, values1)
+
+			if receiver.Level() != level {
+				t.Errorf("invalid level after Mul: have %d, want %d", receiver.Level(), level)
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+
+			receiver = params.evaluator.RelinearizeNew(receiver, rlk)
+
+			if receiver.Level() != level {
+				t.Errorf("invalid level after Relinearize: have %d, want %d", receiver.Level(), level)
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+
+			params.evaluator.Mul(receiver, plaintext2, receiver)
+			params.bfvContext.contextT.MulCoeffs(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+		})
+
+		t.Run(testString("Rotate/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			check(t, params.evaluator.DropLevel(ciphertext, parameters.MaxLevel()))
+
+			slots := params.bfvContext.n >> 1
+			mask := slots - 1
+
+			valuesWant := params.bfvContext.contextT.NewPoly()
+
+			receiver := params.evaluator.RotateColumnsNew(ciphertext, 5, rotkey)
+
+			for i := uint64(0); i < slots; i++ {
+				valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+5)&mask]
+				valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+5)&mask)+slots]
+			}
+
+			verifyTestVectors(params, params.decryptor, valuesWant, receiver, t)
+
+			params.evaluator.RotateRows(receiver, rotkey, receiver)
+
+			valuesWant.Coeffs[0] = append(valuesWant.Coeffs[0][slots:], valuesWant.Coeffs[0][:slots]...)
+
+			verifyTestVectors(params, params.decryptor, valuesWant, receiver, t)
+
+			hoistedKey := NewRotationKeys()
+			params.kgen.GenRot(RotationLeft, params.sk, 3, hoistedKey)
+
+			receivers := params.evaluator.RotateHoisted(ciphertext, []uint64{3}, hoistedKey)
+
+			for i := uint64(0); i < slots; i++ {
+				valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+3)&mask]
+				valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+3)&mask)+slots]
+			}
+
+			verifyTestVectors(params, params.decryptor, valuesWant, receivers[3], t)
+		})
+	}
+}
+
+func testNoiseBudget(t *testing.T) {
+
+	for _, parameters := range testParams.bfvParameters {
+
+		// Same moduli without the special primes Pi, whose key-switching keys use the gadget decomposition
+		moduli := parameters.Moduli.Copy()
+		moduli.Pi = nil
+
+		for _, parameters := range []*Parameters{parameters, NewParametersFromModuli(parameters.LogN, parameters.T, moduli, parameters.Sigma)} {
+
+			params := genBfvParams(parameters)
+
+			estimator := NewNoiseEstimator(parameters)
+
+			var logBase uint64
+			var rlk *EvaluationKey
+			rotkey := NewRotationKeys()
+
+			if len(parameters.Pi) == 0 {
+				logBase = 30
+				rlk = params.kgen.GenRelinKeyGadget(params.sk, 1, logBase)
+				params.kgen.GenRotGadget(RotationLeft, params.sk, 1, logBase, rotkey)
+			} else {
+				rlk = params.kgen.GenRelinKey(params.sk, 1)
+				params.kgen.GenRot(RotationLeft, params.sk, 1, rotkey)
+			}
+
+			// The encryption without P (EncryptFast) is used since Pi might be empty.
+			newTestVectorsFast := func() (plaintext *Plaintext, ciphertext *Ciphertext) {
+				_, plaintext, _ = newTestVectors(params, nil, t)
+				return plaintext, params.encryptorPk.EncryptFastNew(plaintext)
+			}
+
+			// The estimated budget must stay within a few bits of the measured one
+			verifyBudget := func(ciphertext *Ciphertext, estimate *NoiseEstimate, t *testing.T) {
+
+				if ciphertext.Level() != estimate.Level() || ciphertext.Degree() != estimate.Degree() {
+					t.Errorf("estimate level/degree %d/%d does not match ciphertext level/degree %d/%d", estimate.Level(), estimate.Degree(), ciphertext.Level(), ciphertext.Degree())
+				}
+
+				measured := params.decryptor.InvariantNoiseBudget(ciphertext)
+				if diff := measured - estimate.Budget(); diff <= -10 || diff >= 10 {
+					t.Errorf("measured budget %d bits, estimated budget %d bits", measured, estimate.Budget())
+				}
+			}
+
+			t.Run(testString(fmt.Sprintf("Fresh/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				_, ciphertext := newTestVectorsFast()
+
+				if params.decryptor.InvariantNoiseBudget(ciphertext) == 0 {
+					t.Errorf("fresh ciphertext has no noise budget")
+				}
+
+				verifyBudget(ciphertext, estimator.Fresh(), t)
+			})
+
+			t.Run(testString(fmt.Sprintf("Circuit/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				_, ciphertext1 := newTestVectorsFast()
+				plaintext2, ciphertext2 := newTestVectorsFast()
+
+				fresh := params.decryptor.InvariantNoiseBudget(ciphertext1)
+
+				estimate1, estimate2 := estimator.Fresh(), estimator.Fresh()
+
+				params.evaluator.Add(ciphertext1, ciphertext2, ciphertext1)
+				estimate1 = estimator.Add(estimate1, estimate2)
+				verifyBudget(ciphertext1, estimate1, t)
+
+				params.evaluator.Mul(ciphertext1, plaintext2, ciphertext1)
+				estimate1 = estimator.MulPlain(estimate1)
+				verifyBudget(ciphertext1, estimate1, t)
+
+				if params.decryptor.InvariantNoiseBudget(ciphertext1) >= fresh {
+					t.Errorf("noise budget did not decrease after a multiplication")
+				}
+
+				receiver := NewCiphertext(parameters, 2)
+				params.evaluator.Mul(ciphertext1, ciphertext2, receiver)
+				estimate1 = estimator.Mul(estimate1, estimate2)
+				verifyBudget(receiver, estimate1, t)
+
+				var err error
+
+				params.evaluator.Relinearize(receiver, rlk, ciphertext1)
+				estimate1, err = estimator.Relinearize(estimate1, logBase)
+				check(t, err)
+				verifyBudget(ciphertext1, estimate1, t)
+
+				params.evaluator.RotateColumns(ciphertext1, 1, rotkey, ciphertext1)
+				estimate1, err = estimator.SwitchKeys(estimate1, logBase)
+				check(t, err)
+				verifyBudget(ciphertext1, estimate1, t)
+
+				if ciphertext1.Level() > 0 {
+					params.evaluator.DropLevel(ciphertext1, 1)
+					estimate1, _ = estimator.DropLevel(estimate1, 1)
+					verifyBudget(ciphertext1, estimate1, t)
+				}
+
+				if len(parameters.Pi) == 0 {
+					if _, err := estimator.SwitchKeys(estimate1, 0); !errors.Is(err, ErrInvalidParameters) {
+						t.Errorf("invalid error: have %v, want %v", err, ErrInvalidParameters)
+					}
+				}
+			})
+		}
+	}
+}
+
+func testSecurity(t *testing.T) {
+
+	t.Run("CheckSecurity", func(t *testing.T) {
+
+		for _, parameters := range DefaultParams {
+			if err := parameters.CheckSecurity(128, ring.SecretTernary); err != nil {
+				t.Error(err)
+			}
+		}
+
+		if DefaultParams[PN13QP218].CheckSecurity(192, ring.SecretTernary) == nil {
+			t.Errorf("PN13QP218 should not ensure 192-bit security")
+		}
+	})
+
+	for _, depth := range []uint64{1, 3} {
+
+		t.Run(fmt.Sprintf("GenParameters/depth=%d", depth), func(t *testing.T) {
+
+			parameters, err := GenParameters(65537, depth, 128)
+			check(t, err)
+
+			if err = parameters.CheckSecurity(128, ring.SecretTernary); err != nil {
+				t.Error(err)
+			}
+
+			params := genBfvParams(parameters)
+
+			rlk := params.kgen.GenRelinKey(params.sk, 1)
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			for i := uint64(0); i < depth; i++ {
+				ciphertext = params.evaluator.RelinearizeNew(params.evaluator.MulNew(ciphertext, ciphertext), rlk)
+				params.bfvContext.contextT.MulCoeffs(values, values, values)
+			}
+
+			if params.decryptor.InvariantNoiseBudget(ciphertext) == 0 {
+				t.Errorf("noise budget exhausted after depth %d", depth)
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
 	}
 }
 
+func testErrors(t *testing.T) {
+
+	parameters := testParams.bfvParameters[0]
+
+	params := genBfvParams(parameters)
+
+	// Parameters and keys of another ring degree
+	otherParams := genBfvParams(testParams.bfvParameters[1])
+
+	checkError := func(t *testing.T, err, target error) {
+		var bfvErr *Error
+		if !errors.As(err, &bfvErr) || !errors.Is(err, target) {
+			t.Errorf("invalid error: have %v, want %v", err, target)
+		}
+	}
+
+	t.Run(testString("Constructors/", parameters), func(t *testing.T) {
+
+		_, err := TryNewEncoder(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewKeyGenerator(nil)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewEvaluator(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewEncryptorFromPk(parameters, otherParams.pk)
+		checkError(t, err, ErrRingDegreeMismatch)
+
+		_, err = TryNewEncryptorFromSk(parameters, nil)
+		checkError(t, err, ErrMissingKey)
+
+		_, err = TryNewDecryptor(parameters, otherParams.sk)
+		checkError(t, err, ErrRingDegreeMismatch)
+
+		if _, err = TryNewDecryptor(parameters, params.sk); err != nil {
+			t.Error(err)
+		}
+
+		_, err = TryNewCiphertextLvl(parameters, 1, parameters.MaxLevel()+1)
+		checkError(t, err, ErrLevelMismatch)
+
+		_, err = TryNewPlaintext(nil)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewSecretKey(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewNoiseEstimator(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		noSpecialPrimes := parameters.Copy()
+		noSpecialPrimes.Pi = nil
+
+		_, err = TryNewRelinKey(noSpecialPrimes, 2)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewSwitchingKey(noSpecialPrimes)
+		checkError(t, err, ErrInvalidParameters)
+
+		slots := uint64(1) << (parameters.LogN - 1)
+
+		_, err = TryNewLinearTransform(parameters, params.encoder, map[uint64][]uint64{})
+		checkError(t, err, ErrInvalidMatrix)
+
+		_, err = TryNewLinearTransformBSGS(parameters, params.encoder, map[uint64][]uint64{0: make([]uint64, slots), 1: make([]uint64, slots<<1)})
+		checkError(t, err, ErrInvalidMatrix)
+
+		_, err = TryNewLinearTransform(parameters, params.encoder, map[uint64][]uint64{1: make([]uint64, slots), slots + 1: make([]uint64, slots)})
+		checkError(t, err, ErrInvalidMatrix)
+
+		if _, err = TryNewLinearTransformBSGS(parameters, params.encoder, map[uint64][]uint64{0: make([]uint64, slots)}); err != nil {
+			t.Error(err)
+		}
+
+		if _, err = TryNewCiphertext(parameters, 1); err != nil {
+			t.Error(err)
+		}
+
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || !errors.Is(err, ErrInvalidParameters) {
+					t.Errorf("NewEncoder should panic with an Error wrapping ErrInvalidParameters")
+				}
+			}()
+			NewEncoder(new(Parameters))
+		}()
+	})
+
+	t.Run(testString("Evaluator/", parameters), func(t *testing.T) {
+
+		values, plaintext, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+		ciphertextOut := NewCiphertext(parameters, 1)
+
+		checkError(t, params.evaluator.TryAdd(plaintext, plaintext, ciphertextOut), ErrInvalidOperand)
+		checkError(t, params.evaluator.TrySub(ciphertext, nil, ciphertextOut), ErrNilOperand)
+		checkError(t, params.evaluator.TryAdd(ciphertext, NewCiphertext(otherParams.params, 1), ciphertextOut), ErrRingDegreeMismatch)
+		checkError(t, params.evaluator.TryMul(ciphertext, ciphertext, ciphertextOut), ErrDegreeMismatch)
+		checkError(t, params.evaluator.TryRelinearize(NewCiphertext(parameters, 2), nil, ciphertextOut), ErrMissingKey)
+		checkError(t, params.evaluator.TrySwitchKeys(NewCiphertext(parameters, 2), params.kgen.GenSwitchingKey(params.sk, params.sk), ciphertextOut), ErrDegreeMismatch)
+		checkError(t, params.evaluator.TryRotateColumns(ciphertext, 1, new(RotationKeys), ciphertextOut), ErrMissingKey)
+		checkError(t, params.evaluator.TryRotateRows(ciphertext, new(RotationKeys), ciphertextOut), ErrMissingKey)
+
+		ciphertextLvl0, err := params.evaluator.DropLevelNew(ciphertext, parameters.MaxLevel())
+		check(t, err)
+		checkError(t, params.evaluator.ModSwitch(ciphertextLvl0, ciphertextLvl0), ErrLevelMismatch)
+
+		// A successful operation
+		if err := params.evaluator.TryAdd(ciphertext, plaintext, ciphertextOut); err != nil {
+			t.Error(err)
+		}
+
+		params.bfvContext.contextT.Add(values, values, values)
+		verifyTestVectors(params, params.decryptor, values, ciphertextOut, t)
+	})
+}
+
 func testKeySwitch(t *testing.T) {
 
 	for _, parameters := range testParams.bfvParameters {
@@ -608,6 +1272,30 @@ func testRotateCols(t *testing.T) {
 			}
 		})
 
+		t.Run(testString("Hoisted/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			rotations := []uint64{0, 1, 2, 3, 4, 5, slots - 1}
+
+			hoistedKey := NewRotationKeys()
+			for _, n := range rotations[1:] {
+				params.kgen.GenRot(RotationLeft, params.sk, n, hoistedKey)
+			}
+
+			receivers := params.evaluator.RotateHoisted(ciphertext, rotations, hoistedKey)
+
+			for _, n := range rotations {
+
+				for i := uint64(0); i < slots; i++ {
+					valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+n)&mask]
+					valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+n)&mask)+slots]
+				}
+
+				verifyTestVectors(params, params.decryptor, valuesWant, receivers[n], t)
+			}
+		})
+
 		t.Run(testString("Random/", parameters), func(t *testing.T) {
 
 			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
@@ -629,3 +1317,209 @@ func testRotateCols(t *testing.T) {
 		})
 	}
 }
+
+func testGadget(t *testing.T) {
+
+	for _, parameters := range testParams.bfvParameters {
+
+		logBase := uint64(30)
+
+		// Same moduli without the special primes Pi, which are not needed by the gadget decomposition.
+		moduli := parameters.Moduli.Copy()
+		moduli.Pi = nil
+
+		for _, parameters := range []*Parameters{parameters, NewParametersFromModuli(parameters.LogN, parameters.T, moduli, parameters.Sigma)} {
+
+			params := genBfvParams(parameters)
+
+			// The encryption without P (EncryptFast) is used since Pi might be empty.
+			newTestVectorsFast := func(params *bfvParams) (coeffs *ring.Poly, plaintext *Plaintext, ciphertext *Ciphertext) {
+				coeffs, plaintext, _ = newTestVectors(params, nil, t)
+				return coeffs, plaintext, params.encryptorPk.EncryptFastNew(plaintext)
+			}
+
+			slots := params.bfvContext.n >> 1
+			mask := slots - 1
+
+			t.Run(testString(fmt.Sprintf("Relinearize/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				rlk := params.kgen.GenRelinKeyGadget(params.sk, 1, logBase)
+
+				values1, _, ciphertext1 := newTestVectorsFast(params)
+				values2, _, ciphertext2 := newTestVectorsFast(params)
+
+				receiver := params.evaluator.MulNew(ciphertext1, ciphertext2)
+				params.bfvContext.contextT.MulCoeffs(values1, values2, values1)
+
+				params.evaluator.Relinearize(receiver, rlk, receiver)
+
+				if receiver.Degree() != 1 {
+					t.Errorf("invalid degree after Relinearize: have %d, want 1", receiver.Degree())
+				}
+
+				verifyTestVectors(params, params.decryptor, values1, receiver, t)
+			})
+
+			t.Run(testString(fmt.Sprintf("KeySwitch/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				sk2 := params.kgen.GenSecretKey()
+				decryptorSk2 := NewDecryptor(parameters, sk2)
+				switchKey := params.kgen.GenSwitchingKeyGadget(params.sk, sk2, logBase)
+
+				if switchKey.LogBase() != logBase {
+					t.Errorf("invalid SwitchingKey logBase: have %d, want %d", switchKey.LogBase(), logBase)
+				}
+
+				values, _, ciphertext := newTestVectorsFast(params)
+
+				params.evaluator.SwitchKeys(ciphertext, switchKey, ciphertext)
+
+				verifyTestVectors(params, decryptorSk2, values, ciphertext, t)
+			})
+
+			t.Run(testString(fmt.Sprintf("Rotate/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				rotations := []uint64{0, 1, 5}
+
+				rotkey := NewRotationKeys()
+				params.kgen.GenRotGadget(RotationRow, params.sk, 0, logBase, rotkey)
+				for _, n := range rotations[1:] {
+					params.kgen.GenRotGadget(RotationLeft, params.sk, n, logBase, rotkey)
+				}
+
+				values, _, ciphertext := newTestVectorsFast(params)
+
+				valuesWant := params.bfvContext.contextT.NewPoly()
+
+				receivers := params.evaluator.RotateHoisted(ciphertext, rotations, rotkey)
+
+				for _, n := range rotations {
+
+					for i := uint64(0); i < slots; i++ {
+						valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+n)&mask]
+						valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+n)&mask)+slots]
+					}
+
+					verifyTestVectors(params, params.decryptor, valuesWant, receivers[n], t)
+
+					if n != 0 {
+						verifyTestVectors(params, params.decryptor, valuesWant, params.evaluator.RotateColumnsNew(ciphertext, n, rotkey), t)
+					}
+				}
+
+				valuesWant.Coeffs[0] = append(values.Coeffs[0][slots:], values.Coeffs[0][:slots]...)
+
+				verifyTestVectors(params, params.decryptor, valuesWant, params.evaluator.RotateRowsNew(ciphertext, rotkey), t)
+			})
+
+			t.Run(testString(fmt.Sprintf("Marshalling/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				contextQ := params.bfvContext.contextQ
+
+				rlk := params.kgen.GenRelinKeyGadget(params.sk, 2, logBase)
+
+				data, err := rlk.MarshalBinary()
+				check(t, err)
+
+				resRlk := new(EvaluationKey)
+				err = resRlk.UnmarshalBinary(data)
+				check(t, err)
+
+				for deg := range rlk.evakey {
+
+					if resRlk.evakey[deg].LogBase() != logBase {
+						t.Errorf("marshal EvaluationKey deg %d logBase: have %d, want %d", deg, resRlk.evakey[deg].LogBase(), logBase)
+					}
+
+					evakeyWant := rlk.evakey[deg].evakey
+					evakeyTest := resRlk.evakey[deg].evakey
+
+					if len(evakeyWant) != len(evakeyTest) {
+						t.Errorf("marshal EvaluationKey deg %d: have %d elements, want %d", deg, len(evakeyTest), len(evakeyWant))
+						continue
+					}
+
+					for j := range evakeyWant {
+						for k := range evakeyWant[j] {
+							if !contextQ.Equal(evakeyWant[j][k], evakeyTest[j][k]) {
+								t.Errorf("marshal EvaluationKey deg %d element [%d][%d]", deg, j, k)
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func testLinearTransform(t *testing.T) {
+
+	for _, parameters := range testParams.bfvParameters {
+
+		params := genBfvParams(parameters)
+
+		slots := params.bfvContext.n >> 1
+		mask := slots - 1
+		T := params.bfvContext.contextT.Modulus[0]
+
+		diagMatrix := make(map[uint64][]uint64)
+		for _, k := range []uint64{0, 1, 2, 3, 16, 17, slots - 16, slots - 1} {
+			diagMatrix[k] = params.bfvContext.contextT.NewUniformPoly().Coeffs[0]
+		}
+
+		valuesWant := params.bfvContext.contextT.NewPoly()
+
+		verify := func(values *ring.Poly, ciphertext *Ciphertext) {
+
+			valuesWant.Zero()
+
+			for k, diag := range diagMatrix {
+				for i := uint64(0); i < slots; i++ {
+					valuesWant.Coeffs[0][i] += ring.BRed(diag[i], values.Coeffs[0][(i+k)&mask], T, params.bfvContext.contextT.GetBredParams()[0])
+					valuesWant.Coeffs[0][i+slots] += ring.BRed(diag[i+slots], values.Coeffs[0][((i+k)&mask)+slots], T, params.bfvContext.contextT.GetBredParams()[0])
+				}
+			}
+
+			params.bfvContext.contextT.Reduce(valuesWant, valuesWant)
+
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+		}
+
+		t.Run(testString("Naive/", parameters), func(t *testing.T) {
+
+			lt := NewLinearTransform(parameters, params.encoder, diagMatrix)
+
+			rotkeys := NewRotationKeys()
+			for _, k := range lt.Rotations() {
+				params.kgen.GenRot(RotationLeft, params.sk, k, rotkeys)
+			}
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			verify(values, params.evaluator.LinearTransformNew(ciphertext, lt, rotkeys))
+		})
+
+		t.Run(testString("BSGS/", parameters), func(t *testing.T) {
+
+			lt := NewLinearTransformBSGS(parameters, params.encoder, diagMatrix)
+
+			rotkeys := NewRotationKeys()
+			for _, k := range lt.Rotations() {
+				params.kgen.GenRot(RotationLeft, params.sk, k, rotkeys)
+			}
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			verify(values, params.evaluator.LinearTransformNew(ciphertext, lt, rotkeys))
+		})
+
+		t.Run(testString("Collision/", parameters), func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewLinearTransform should panic on diagonals with the same index modulo N/2")
+				}
+			}()
+			NewLinearTransform(parameters, params.encoder, map[uint64][]uint64{1: diagMatrix[1], slots + 1: diagMatrix[1]})
+		})
+	}
+}
@@ -5,22 +5,53 @@ type Ciphertext struct {
 	*bfvElement
 }
 
-// NewCiphertext creates a new ciphertext parameterized by degree, level and scale.
+// TryNewCiphertext is the same as NewCiphertext, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewCiphertext(params *Parameters, degree uint64) (*Ciphertext, error) {
+
+	if err := checkParameters("NewCiphertext", params); err != nil {
+		return nil, err
+	}
+
+	return NewCiphertext(params, degree), nil
+}
+
+// NewCiphertext creates a new ciphertext of the given degree at the maximum level.
 func NewCiphertext(params *Parameters, degree uint64) (ciphertext *Ciphertext) {
+	return NewCiphertextLvl(params, degree, params.MaxLevel())
+}
 
-	if !params.isValid {
-		panic("cannot NewCiphertext: params not valid (check if they were generated properly)")
+// TryNewCiphertextLvl is the same as NewCiphertextLvl, but returns an Error instead of panicking if the parameters or
+// the level are invalid.
+func TryNewCiphertextLvl(params *Parameters, degree, level uint64) (*Ciphertext, error) {
+
+	if err := checkLevel("NewCiphertext", params, level); err != nil {
+		return nil, err
 	}
 
-	return &Ciphertext{newBfvElement(params, degree)}
+	return NewCiphertextLvl(params, degree, level), nil
 }
 
-// NewCiphertextRandom generates a new uniformly distributed ciphertext of degree, level and scale.
+// NewCiphertextLvl creates a new ciphertext of the given degree and level.
+func NewCiphertextLvl(params *Parameters, degree, level uint64) (ciphertext *Ciphertext) {
+
+	if err := checkLevel("NewCiphertext", params, level); err != nil {
+		panic(err)
+	}
+
+	return &Ciphertext{bfvElement: newBfvElement(params, degree, level)}
+}
+
+// NewCiphertextRandom generates a new uniformly distributed ciphertext of the given degree at the maximum level.
 func NewCiphertextRandom(params *Parameters, degree uint64) (ciphertext *Ciphertext) {
+	return NewCiphertextRandomLvl(params, degree, params.MaxLevel())
+}
+
+// NewCiphertextRandomLvl generates a new uniformly distributed ciphertext of the given degree and level.
+func NewCiphertextRandomLvl(params *Parameters, degree, level uint64) (ciphertext *Ciphertext) {
 
 	if !params.isValid {
 		panic("cannot NewCiphertextRandom: params not valid (check if they were generated properly)")
 	}
 
-	return &Ciphertext{newBfvElementRandom(params, degree)}
+	return &Ciphertext{bfvElement: newBfvElementRandom(params, degree, level)}
 }
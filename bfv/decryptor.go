@@ -2,6 +2,7 @@ package bfv
 
 import (
 	"github.com/ldsec/lattigo/ring"
+	"math/big"
 )
 
 // Decryptor is an interface for decryptors
@@ -13,6 +14,10 @@ type Decryptor interface {
 	// Decrypt decrypts the input ciphertext and returns the result on the
 	// provided receiver plaintext.
 	Decrypt(ciphertext *Ciphertext, plaintext *Plaintext)
+
+	// InvariantNoiseBudget measures, using the secret-key, the remaining
+	// invariant noise budget of the input ciphertext in bits.
+	InvariantNoiseBudget(ciphertext *Ciphertext) int
 }
 
 // decryptor is a structure used to decrypt ciphertexts. It stores the secret-key.
@@ -23,15 +28,36 @@ type decryptor struct {
 	polypool   *ring.Poly
 }
 
+// TryNewDecryptor is the same as NewDecryptor, but returns an Error instead of panicking if the parameters are invalid
+// or if the secret-key does not match them.
+func TryNewDecryptor(params *Parameters, sk *SecretKey) (Decryptor, error) {
+
+	if err := checkDecryptor(params, sk); err != nil {
+		return nil, err
+	}
+
+	return NewDecryptor(params, sk), nil
+}
+
+func checkDecryptor(params *Parameters, sk *SecretKey) error {
+
+	if err := checkParameters("NewDecryptor", params); err != nil {
+		return err
+	}
+
+	if sk == nil {
+		return newError("NewDecryptor", ErrMissingKey, "secret-key is nil")
+	}
+
+	return checkPoly("NewDecryptor", params, sk.sk, len(params.Qi)+len(params.Pi), "secret-key")
+}
+
 // NewDecryptor creates a new Decryptor from the parameters with the secret-key
 // given as input.
 func NewDecryptor(params *Parameters, sk *SecretKey) Decryptor {
-	if !params.isValid {
-		panic("cannot NewDecryptor: params not valid (check if they were generated properly)")
-	}
 
-	if sk.sk.GetDegree() != int(1<<params.LogN) {
-		panic("cannot NewDecryptor: secret_key degree must match context degree")
+	if err := checkDecryptor(params, sk); err != nil {
+		panic(err)
 	}
 
 	ctx := newBFVContext(params)
@@ -44,32 +70,92 @@ func NewDecryptor(params *Parameters, sk *SecretKey) Decryptor {
 	}
 }
 
+// DecryptNew decrypts the input ciphertext and returns the result on a new plaintext at the level of the ciphertext.
 func (decryptor *decryptor) DecryptNew(ciphertext *Ciphertext) *Plaintext {
-	plaintext := NewPlaintext(decryptor.params)
+	plaintext := NewPlaintextLvl(decryptor.params, ciphertext.Level())
 
 	decryptor.Decrypt(ciphertext, plaintext)
 
 	return plaintext
 }
 
+// Decrypt decrypts the input ciphertext and returns the result on the provided receiver plaintext, which is
+// set to the level of the ciphertext. The level of the receiver must be at least the level of the ciphertext.
 func (decryptor *decryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) {
+
+	if plaintext.Level() < ciphertext.Level() {
+		panic("cannot Decrypt: plaintext level is smaller than the ciphertext level")
+	}
+
 	ringContext := decryptor.bfvContext.contextQ
 
-	ringContext.NTT(ciphertext.value[ciphertext.Degree()], plaintext.value)
+	level := ciphertext.Level()
+
+	plaintext.value.Coeffs = plaintext.value.Coeffs[:level+1]
+
+	ringContext.NTTLvl(level, ciphertext.value[ciphertext.Degree()], plaintext.value)
 
 	for i := uint64(ciphertext.Degree()); i > 0; i-- {
-		ringContext.MulCoeffsMontgomery(plaintext.value, decryptor.sk.sk, plaintext.value)
-		ringContext.NTT(ciphertext.value[i-1], decryptor.polypool)
-		ringContext.Add(plaintext.value, decryptor.polypool, plaintext.value)
+		ringContext.MulCoeffsMontgomeryLvl(level, plaintext.value, decryptor.sk.sk, plaintext.value)
+		ringContext.NTTLvl(level, ciphertext.value[i-1], decryptor.polypool)
+		ringContext.AddLvl(level, plaintext.value, decryptor.polypool, plaintext.value)
 
 		if i&7 == 7 {
-			ringContext.Reduce(plaintext.value, plaintext.value)
+			ringContext.ReduceLvl(level, plaintext.value, plaintext.value)
 		}
 	}
 
 	if (ciphertext.Degree())&7 != 7 {
-		ringContext.Reduce(plaintext.value, plaintext.value)
+		ringContext.ReduceLvl(level, plaintext.value, plaintext.value)
+	}
+
+	ringContext.InvNTTLvl(level, plaintext.value, plaintext.value)
+}
+
+// InvariantNoiseBudget returns the invariant noise budget of the input ciphertext in bits, that is, -log2(2*||v||), where v is
+// the invariant noise of the ciphertext, defined by (T/Q_l) * (c0 + c1*s + ...) = m + v mod T, and ||.|| is the infinity norm.
+// The decryption is correct as long as the budget is positive. Returns 0 if the noise budget is exhausted.
+func (decryptor *decryptor) InvariantNoiseBudget(ciphertext *Ciphertext) int {
+
+	ringContext := decryptor.bfvContext.contextQ
+
+	level := ciphertext.Level()
+
+	plaintext := NewPlaintextLvl(decryptor.params, level)
+
+	decryptor.Decrypt(ciphertext, plaintext)
+
+	// T * (c0 + c1*s + ...) = T*e - (Q_l mod T)*m mod Q_l
+	ringContext.MulScalarLvl(level, plaintext.value, decryptor.params.T, plaintext.value)
+
+	coeffsBigint := make([]*big.Int, ringContext.N)
+	ringContext.PolyToBigint(plaintext.value, coeffsBigint)
+
+	Q := ring.NewUint(1)
+	for _, qi := range ringContext.Modulus[:level+1] {
+		Q.Mul(Q, ring.NewUint(qi))
+	}
+
+	QHalf := new(big.Int).Rsh(Q, 1)
+
+	// Infinity norm of the centered coefficients
+	norm := new(big.Int)
+	for _, c := range coeffsBigint {
+
+		if c.Cmp(QHalf) == 1 {
+			c.Sub(Q, c)
+		}
+
+		if c.Cmp(norm) == 1 {
+			norm.Set(c)
+		}
+	}
+
+	budget := Q.BitLen() - norm.BitLen() - 1
+
+	if budget < 0 {
+		return 0
 	}
 
-	ringContext.InvNTT(plaintext.value, plaintext.value)
+	return budget
 }
@@ -12,6 +12,8 @@ type Encoder interface {
 	EncodeInt(coeffs []int64, plaintext *Plaintext)
 	DecodeUint(plaintext *Plaintext) (coeffs []uint64)
 	DecodeInt(plaintext *Plaintext) (coeffs []int64)
+	EncodeCoeffs(coeffs []uint64, plaintext *Plaintext)
+	DecodeCoeffs(plaintext *Plaintext) (coeffs []uint64)
 }
 
 // Encoder is a structure that stores the parameters to encode values on a plaintext in a SIMD (Single-Instruction Multiple-Data) fashion.
@@ -19,16 +21,26 @@ type encoder struct {
 	params       *Parameters
 	bfvContext   *bfvContext
 	indexMatrix  []uint64
-	simplescaler *ring.SimpleScaler
+	simplescaler []*ring.SimpleScaler
 	polypool     *ring.Poly
-	deltaMont    []uint64
+	deltaMont    [][]uint64
+}
+
+// TryNewEncoder is the same as NewEncoder, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewEncoder(params *Parameters) (Encoder, error) {
+
+	if err := checkParameters("NewEncoder", params); err != nil {
+		return nil, err
+	}
+
+	return NewEncoder(params), nil
 }
 
 // NewEncoder creates a new encoder from the provided parameters.
 func NewEncoder(params *Parameters) Encoder {
 
-	if !params.isValid {
-		panic("cannot NewEncoder: params not valid (check if they were generated properly)")
+	if err := checkParameters("NewEncoder", params); err != nil {
+		panic(err)
 	}
 
 	bfvContext := newBFVContext(params)
@@ -57,12 +69,23 @@ func NewEncoder(params *Parameters) Encoder {
 		pos &= (m - 1)
 	}
 
+	// Delta = Q_l/T and the scaling by T/Q_l both depend on the level of the plaintext
+	deltaMont := make([][]uint64, params.MaxLevel()+1)
+	simplescaler := make([]*ring.SimpleScaler, params.MaxLevel()+1)
+
+	for level := range params.Qi {
+		contextQl := ring.NewContext()
+		contextQl.SetParameters(bfvContext.n, params.Qi[:level+1])
+		deltaMont[level] = GenLiftParams(contextQl, params.T)
+		simplescaler[level] = ring.NewSimpleScaler(params.T, contextQl)
+	}
+
 	return &encoder{
 		params:       params.Copy(),
 		bfvContext:   bfvContext,
 		indexMatrix:  indexMatrix,
-		deltaMont:    GenLiftParams(bfvContext.contextQ, params.T),
-		simplescaler: ring.NewSimpleScaler(params.T, bfvContext.contextQ),
+		deltaMont:    deltaMont,
+		simplescaler: simplescaler,
 		polypool:     bfvContext.contextT.NewPoly(),
 	}
 }
@@ -118,16 +141,59 @@ func (encoder *encoder) EncodeInt(coeffs []int64, plaintext *Plaintext) {
 	encoder.encodePlaintext(plaintext)
 }
 
+// EncodeCoeffs encodes an uint64 slice of size at most N directly on the coefficients of the plaintext polynomial, without batching
+// (the remaining coefficients are set to zero), and scales them by Delta = Q_l/T, where Q_l is the modulus at the level of the plaintext. The coefficients are reduced modulo the plaintext modulus.
+func (encoder *encoder) EncodeCoeffs(coeffs []uint64, plaintext *Plaintext) {
+
+	if len(coeffs) > len(encoder.indexMatrix) {
+		panic("cannot EncodeCoeffs: invalid input to encode (number of coefficients must be smaller or equal to the context)")
+	}
+
+	if len(plaintext.value.Coeffs[0]) != len(encoder.indexMatrix) {
+		panic("cannot EncodeCoeffs: invalid plaintext to receive encoding (number of coefficients does not match the context of the encoder)")
+	}
+
+	for i := 0; i < len(coeffs); i++ {
+		plaintext.value.Coeffs[0][i] = coeffs[i] % encoder.params.T
+	}
+
+	for i := len(coeffs); i < len(encoder.indexMatrix); i++ {
+		plaintext.value.Coeffs[0][i] = 0
+	}
+
+	encoder.scaleUp(plaintext)
+}
+
+// DecodeCoeffs decodes a plaintext encoded with EncodeCoeffs and returns its coefficients (scaled down by Delta) in a uint64 slice of size N.
+func (encoder *encoder) DecodeCoeffs(plaintext *Plaintext) (coeffs []uint64) {
+
+	encoder.simplescaler[plaintext.Level()].Scale(plaintext.value, encoder.polypool)
+
+	coeffs = make([]uint64, encoder.bfvContext.n)
+
+	copy(coeffs, encoder.polypool.Coeffs[0])
+
+	return
+}
+
 func (encoder *encoder) encodePlaintext(p *Plaintext) {
 
 	encoder.bfvContext.contextT.InvNTT(p.value, p.value)
 
+	encoder.scaleUp(p)
+}
+
+// scaleUp multiplies the coefficients modulo T of the plaintext, stored on its first modulus, by Delta = Q_l/T.
+func (encoder *encoder) scaleUp(p *Plaintext) {
+
 	ringContext := encoder.bfvContext.contextQ
 
-	for i := len(ringContext.Modulus) - 1; i >= 0; i-- {
+	level := p.Level()
+
+	for i := int(level); i >= 0; i-- {
 		tmp1 := p.value.Coeffs[i]
 		tmp2 := p.value.Coeffs[0]
-		deltaMont := encoder.deltaMont[i]
+		deltaMont := encoder.deltaMont[level][i]
 		qi := ringContext.Modulus[i]
 		bredParams := ringContext.GetMredParams()[i]
 		for j := uint64(0); j < ringContext.N; j++ {
@@ -139,7 +205,7 @@ func (encoder *encoder) encodePlaintext(p *Plaintext) {
 // DecodeUint decodes a batched plaintext and returns the coefficients in a uint64 slice.
 func (encoder *encoder) DecodeUint(plaintext *Plaintext) (coeffs []uint64) {
 
-	encoder.simplescaler.Scale(plaintext.value, encoder.polypool)
+	encoder.simplescaler[plaintext.Level()].Scale(plaintext.value, encoder.polypool)
 
 	encoder.bfvContext.contextT.NTT(encoder.polypool, encoder.polypool)
 
@@ -159,7 +225,7 @@ func (encoder *encoder) DecodeInt(plaintext *Plaintext) (coeffs []int64) {
 
 	var value int64
 
-	encoder.simplescaler.Scale(plaintext.value, encoder.polypool)
+	encoder.simplescaler[plaintext.Level()].Scale(plaintext.value, encoder.polypool)
 
 	encoder.bfvContext.contextT.NTT(encoder.polypool, encoder.polypool)
 
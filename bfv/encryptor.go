@@ -2,12 +2,17 @@ package bfv
 
 import (
 	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
 )
 
-// Encryptor in an interface for encryptors
+// Encryptor in an interface for encryptors. The plaintexts and ciphertexts are always encrypted
+// at the maximum level; Evaluator.DropLevel can be used to obtain ciphertexts at a lower level.
 //
 // encrypt with pk : ciphertext = [pk[0]*u + m + e_0, pk[1]*u + e_1]
 // encrypt with sk : ciphertext = [-a*sk + m + e, a]
+//
+// When encrypting with the secret-key (without CRP), a is generated from a random seed which is stored in the
+// ciphertext, so that the fresh ciphertext can be compressed (see Ciphertext.MarshalBinaryCompressed).
 type Encryptor interface {
 	// EncryptNew encrypts the input plaintext using the stored key and returns
 	// the result on a newly created ciphertext. The encryption is done by first
@@ -70,38 +75,90 @@ type pkEncryptor struct {
 
 type skEncryptor struct {
 	encryptor
-	sk *SecretKey
+	sk           *SecretKey
+	crpGenerator *ring.CRPGenerator
 }
 
 // NewEncryptorFromPk creates a new Encryptor with the provided public-key.
 // This encryptor can be used to encrypt plaintexts, using the stored key.
 func NewEncryptorFromPk(params *Parameters, pk *PublicKey) Encryptor {
-	enc := newEncryptor(params)
 
-	if uint64(pk.pk[0].GetDegree()) != uint64(1<<params.LogN) || uint64(pk.pk[1].GetDegree()) != uint64(1<<params.LogN) {
-		panic("error: pk ring degree doesn't match bfvcontext ring degree")
+	if err := checkEncryptorFromPk(params, pk); err != nil {
+		panic(err)
+	}
+
+	return &pkEncryptor{newEncryptor(params), pk}
+}
+
+// TryNewEncryptorFromPk is the same as NewEncryptorFromPk, but returns an Error instead of panicking if the parameters are invalid
+// or if the public-key does not match them.
+func TryNewEncryptorFromPk(params *Parameters, pk *PublicKey) (Encryptor, error) {
+
+	if err := checkEncryptorFromPk(params, pk); err != nil {
+		return nil, err
 	}
 
-	return &pkEncryptor{enc, pk}
+	return NewEncryptorFromPk(params, pk), nil
+}
+
+func checkEncryptorFromPk(params *Parameters, pk *PublicKey) error {
+
+	if err := checkParameters("NewEncryptorFromPk", params); err != nil {
+		return err
+	}
+
+	if pk == nil {
+		return newError("NewEncryptorFromPk", ErrMissingKey, "public-key is nil")
+	}
+
+	for i := range pk.pk {
+		if err := checkPoly("NewEncryptorFromPk", params, pk.pk[i], len(params.Qi)+len(params.Pi), "public-key"); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // NewEncryptorFromSk creates a new Encryptor with the provided secret-key.
 // This encryptor can be used to encrypt plaintexts, using the stored key.
 func NewEncryptorFromSk(params *Parameters, sk *SecretKey) Encryptor {
+
+	if err := checkEncryptorFromSk(params, sk); err != nil {
+		panic(err)
+	}
+
 	enc := newEncryptor(params)
 
-	if uint64(sk.sk.GetDegree()) != uint64(1<<params.LogN) {
-		panic("error: sk ring degree doesn't match bfvcontext ring degree")
+	return &skEncryptor{enc, sk, ring.NewCRPGenerator(nil, enc.bfvContext.contextQ)}
+}
+
+// TryNewEncryptorFromSk is the same as NewEncryptorFromSk, but returns an Error instead of panicking if the parameters are invalid
+// or if the secret-key does not match them.
+func TryNewEncryptorFromSk(params *Parameters, sk *SecretKey) (Encryptor, error) {
+
+	if err := checkEncryptorFromSk(params, sk); err != nil {
+		return nil, err
 	}
 
-	return &skEncryptor{enc, sk}
+	return NewEncryptorFromSk(params, sk), nil
 }
 
-func newEncryptor(params *Parameters) encryptor {
-	if !params.isValid {
-		panic("cannot NewEncryptor: params not valid (check if they were generated properly)")
+func checkEncryptorFromSk(params *Parameters, sk *SecretKey) error {
+
+	if err := checkParameters("NewEncryptorFromSk", params); err != nil {
+		return err
 	}
 
+	if sk == nil {
+		return newError("NewEncryptorFromSk", ErrMissingKey, "secret-key is nil")
+	}
+
+	return checkPoly("NewEncryptorFromSk", params, sk.sk, len(params.Qi)+len(params.Pi), "secret-key")
+}
+
+func newEncryptor(params *Parameters) encryptor {
+
 	ctx := newBFVContext(params)
 	qp := ctx.contextQP
 
@@ -168,6 +225,12 @@ func (encryptor *pkEncryptor) EncryptFromCRPFastNew(plaintext *Plaintext, crp *r
 
 func (encryptor *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext, fast bool) {
 
+	if plaintext.Level() != encryptor.params.MaxLevel() || ciphertext.Level() != encryptor.params.MaxLevel() {
+		panic("cannot Encrypt: plaintext and ciphertext must be at the maximum level (use DropLevel to obtain ciphertexts at a lower level)")
+	}
+
+	ciphertext.seed = nil
+
 	var ringContext *ring.Context
 
 	if fast {
@@ -175,19 +238,21 @@ func (encryptor *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Cipherte
 
 		ringContext.SampleTernaryMontgomeryNTT(encryptor.polypool[2], 0.5)
 
-		ringContext.MulCoeffsMontgomery(encryptor.polypool[2], encryptor.pk.pk[0], encryptor.polypool[0])
-		ringContext.MulCoeffsMontgomery(encryptor.polypool[2], encryptor.pk.pk[1], encryptor.polypool[1])
+		// ct[0] = pk[0]*u
+		// ct[1] = pk[1]*u
+		ringContext.MulCoeffsMontgomery(encryptor.polypool[2], encryptor.pk.pk[0], ciphertext.value[0])
+		ringContext.MulCoeffsMontgomery(encryptor.polypool[2], encryptor.pk.pk[1], ciphertext.value[1])
 
-		ringContext.InvNTT(encryptor.polypool[0], encryptor.polypool[0])
-		ringContext.InvNTT(encryptor.polypool[1], encryptor.polypool[1])
+		ringContext.InvNTT(ciphertext.value[0], ciphertext.value[0])
+		ringContext.InvNTT(ciphertext.value[1], ciphertext.value[1])
 
 		// ct[0] = pk[0]*u + e0
 		encryptor.bfvContext.gaussianSampler.Sample(encryptor.polypool[2])
-		ringContext.Add(encryptor.polypool[0], encryptor.polypool[2], encryptor.polypool[0])
+		ringContext.Add(ciphertext.value[0], encryptor.polypool[2], ciphertext.value[0])
 
 		// ct[1] = pk[1]*u + e1
 		encryptor.bfvContext.gaussianSampler.Sample(encryptor.polypool[2])
-		ringContext.Add(encryptor.polypool[1], encryptor.polypool[2], encryptor.polypool[1])
+		ringContext.Add(ciphertext.value[1], encryptor.polypool[2], ciphertext.value[1])
 
 	} else {
 
@@ -284,13 +349,27 @@ func (encryptor *skEncryptor) EncryptFromCRPFast(plaintext *Plaintext, ciphertex
 }
 
 func (encryptor *skEncryptor) encryptSample(plaintext *Plaintext, ciphertext *Ciphertext, fast bool) {
-	if fast {
-		encryptor.bfvContext.contextQ.UniformPoly(encryptor.polypool[1])
-	} else {
-		encryptor.bfvContext.contextQP.UniformPoly(encryptor.polypool[1])
+
+	// a is generated in basis Q from a new seed, so that it can be re-generated from the seed only
+	seed := utils.NewSeed()
+	encryptor.crpGenerator.Seed(seed)
+	encryptor.crpGenerator.Clock(encryptor.polypool[1])
+
+	if !fast {
+		// a*P in basis QP (which is zero modulo each Pi), so that the division by P returns a
+		encryptor.bfvContext.contextQ.MulScalarBigint(encryptor.polypool[1], encryptor.bfvContext.contextP.ModulusBigint, encryptor.polypool[1])
+		for i := len(encryptor.params.Qi); i < len(encryptor.polypool[1].Coeffs); i++ {
+			for j := range encryptor.polypool[1].Coeffs[i] {
+				encryptor.polypool[1].Coeffs[i][j] = 0
+			}
+		}
 	}
 
 	encryptor.encrypt(plaintext, ciphertext, encryptor.polypool[1], fast)
+
+	ciphertext.seed = seed
+	ciphertext.seedLevel = encryptor.params.MaxLevel()
+	ciphertext.seedDigest = ciphertext.value[1].Digest(ciphertext.seedLevel)
 }
 
 func (encryptor *skEncryptor) encryptFromCRP(plaintext *Plaintext, ciphertext *Ciphertext, crp *ring.Poly, fast bool) {
@@ -305,6 +384,12 @@ func (encryptor *skEncryptor) encryptFromCRP(plaintext *Plaintext, ciphertext *C
 
 func (encryptor *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext, crp *ring.Poly, fast bool) {
 
+	if plaintext.Level() != encryptor.params.MaxLevel() || ciphertext.Level() != encryptor.params.MaxLevel() {
+		panic("cannot Encrypt: plaintext and ciphertext must be at the maximum level (use DropLevel to obtain ciphertexts at a lower level)")
+	}
+
+	ciphertext.seed = nil
+
 	var ringContext *ring.Context
 
 	if fast {
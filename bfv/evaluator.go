@@ -3,6 +3,7 @@ package bfv
 import (
 	"github.com/ldsec/lattigo/ring"
 	"github.com/ldsec/lattigo/utils"
+	"math"
 	"math/big"
 )
 
@@ -30,9 +31,25 @@ type Evaluator interface {
 	SwitchKeysNew(ct0 *Ciphertext, switchkey *SwitchingKey) (ctOut *Ciphertext)
 	RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext)
 	RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext)
+	RotateHoisted(ct0 *Ciphertext, rotations []uint64, rotkeys *RotationKeys) (cOut map[uint64]*Ciphertext)
 	RotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext)
 	RotateRowsNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOut *Ciphertext)
 	InnerSum(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext)
+	LinearTransformNew(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys) (ctOut *Ciphertext)
+	LinearTransform(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys, ctOut *Ciphertext)
+	ModSwitch(ct0, ctOut *Ciphertext) (err error)
+	ModSwitchNew(ct0 *Ciphertext) (ctOut *Ciphertext, err error)
+	DropLevel(ct0 *Ciphertext, levels uint64) (err error)
+	DropLevelNew(ct0 *Ciphertext, levels uint64) (ctOut *Ciphertext, err error)
+	TryAdd(op0, op1 Operand, ctOut *Ciphertext) (err error)
+	TrySub(op0, op1 Operand, ctOut *Ciphertext) (err error)
+	TryMul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error)
+	TryRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) (err error)
+	TrySwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext) (err error)
+	TryRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) (err error)
+	TryRotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) (err error)
+	ShallowCopy() Evaluator
+	WithKey(evakey *EvaluationKey, rotkeys *RotationKeys) Evaluator
 }
 
 // evaluator is a struct that holds the necessary elements to perform the homomorphic operations between ciphertexts and/or plaintexts.
@@ -49,11 +66,63 @@ type evaluator struct {
 
 	pHalf *big.Int
 
+	evakey  *EvaluationKey
+	rotkeys *RotationKeys
+
+	*evaluatorBuffers
+}
+
+// evaluatorBuffers is a struct that holds the memory pool of an evaluator. Unlike the other fields of the evaluator,
+// it is written by the homomorphic operations, and is therefore never shared between evaluators.
+type evaluatorBuffers struct {
 	poolQ [][]*ring.Poly
 	poolP [][]*ring.Poly
 
-	polypool      [2]*ring.Poly
-	keyswitchpool [5]*ring.Poly
+	polypool       [2]*ring.Poly
+	keyswitchpoolQ [5]*ring.Poly
+	keyswitchpoolP [4]*ring.Poly
+
+	ctxpool [2]*bfvElement
+}
+
+func newEvaluatorBuffers(params *Parameters, bfvContext *bfvContext) *evaluatorBuffers {
+
+	q := bfvContext.contextQ
+	qm := bfvContext.contextQMul
+	p := bfvContext.contextP
+
+	buffers := new(evaluatorBuffers)
+
+	buffers.poolQ = make([][]*ring.Poly, 4)
+	buffers.poolP = make([][]*ring.Poly, 4)
+	for i := 0; i < 4; i++ {
+		buffers.poolQ[i] = make([]*ring.Poly, 6)
+		buffers.poolP[i] = make([]*ring.Poly, 6)
+		for j := 0; j < 6; j++ {
+			buffers.poolQ[i][j] = q.NewPoly()
+			buffers.poolP[i][j] = qm.NewPoly()
+		}
+	}
+
+	buffers.keyswitchpoolQ = [5]*ring.Poly{q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly()}
+	if len(params.Pi) != 0 {
+		buffers.keyswitchpoolP = [4]*ring.Poly{p.NewPoly(), p.NewPoly(), p.NewPoly(), p.NewPoly()}
+	}
+
+	buffers.polypool = [2]*ring.Poly{q.NewPoly(), q.NewPoly()}
+	buffers.ctxpool = [2]*bfvElement{newBfvElement(params, 2, params.MaxLevel()), newBfvElement(params, 2, params.MaxLevel())}
+
+	return buffers
+}
+
+// TryNewEvaluator is the same as NewEvaluator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewEvaluator(params *Parameters) (Evaluator, error) {
+
+	if err := checkParameters("NewEvaluator", params); err != nil {
+		return nil, err
+	}
+
+	return NewEvaluator(params), nil
 }
 
 // NewEvaluator creates a new Evaluator, that can be used to do homomorphic
@@ -61,34 +130,20 @@ type evaluator struct {
 // and ciphertexts that will be used for intermediate values.
 func NewEvaluator(params *Parameters) Evaluator {
 
-	if !params.isValid {
-		panic("cannot NewEvaluator: params not valid (check if they were generated properly)")
+	if err := checkParameters("NewEvaluator", params); err != nil {
+		panic(err)
 	}
 
 	bfvContext := newBFVContext(params)
 	q := bfvContext.contextQ
 	qm := bfvContext.contextQMul
 	p := bfvContext.contextP
-	qp := bfvContext.contextQP
-
-	poolQ := make([][]*ring.Poly, 4)
-	poolP := make([][]*ring.Poly, 4)
-	for i := 0; i < 4; i++ {
-		poolQ[i] = make([]*ring.Poly, 6)
-		poolP[i] = make([]*ring.Poly, 6)
-		for j := 0; j < 6; j++ {
-			poolQ[i][j] = q.NewPoly()
-			poolP[i][j] = qm.NewPoly()
-		}
-	}
 
 	var baseconverter *ring.FastBasisExtender
 	var decomposer *ring.Decomposer
-	var keyswitchpool [5]*ring.Poly
 	if len(params.Pi) != 0 {
 		baseconverter = ring.NewFastBasisExtender(q, p)
 		decomposer = ring.NewDecomposer(q.Modulus, p.Modulus)
-		keyswitchpool = [5]*ring.Poly{qp.NewPoly(), qp.NewPoly(), qp.NewPoly(), qp.NewPoly(), qp.NewPoly()}
 	}
 
 	return &evaluator{
@@ -98,54 +153,250 @@ func NewEvaluator(params *Parameters) Evaluator {
 		baseconverterQ1P:  baseconverter,
 		decomposer:        decomposer,
 		pHalf:             new(big.Int).Rsh(qm.ModulusBigint, 1),
-		polypool:          [2]*ring.Poly{q.NewPoly(), q.NewPoly()},
-		keyswitchpool:     keyswitchpool,
-		poolQ:             poolQ,
-		poolP:             poolP,
+		evaluatorBuffers:  newEvaluatorBuffers(params, bfvContext),
+	}
+}
+
+// ShallowCopy creates a shallow copy of the target evaluator, which shares its read-only precomputations (the contexts,
+// the basis extension and decomposition parameters) and its keys, but has its own memory pool. This is much cheaper than
+// NewEvaluator, and the copy and the target can be used concurrently, e.g., by one evaluator per goroutine.
+func (evaluator *evaluator) ShallowCopy() Evaluator {
+	return shallowCopy(evaluator)
+}
+
+// WithKey creates a shallow copy of the target evaluator (see ShallowCopy) that stores the given evaluation and rotation
+// keys (either of which can be nil). The returned evaluator uses them in Relinearize, RotateColumns, RotateRows, InnerSum,
+// RotateHoisted and LinearTransform when they are given a nil key.
+func (evaluator *evaluator) WithKey(evakey *EvaluationKey, rotkeys *RotationKeys) Evaluator {
+	eval := shallowCopy(evaluator)
+	eval.evakey = evakey
+	eval.rotkeys = rotkeys
+	return eval
+}
+
+func shallowCopy(eval *evaluator) *evaluator {
+
+	var baseconverter *ring.FastBasisExtender
+	if eval.baseconverterQ1P != nil {
+		baseconverter = eval.baseconverterQ1P.ShallowCopy()
+	}
+
+	return &evaluator{
+		params:            eval.params,
+		bfvContext:        eval.bfvContext,
+		baseconverterQ1Q2: eval.baseconverterQ1Q2.ShallowCopy(),
+		baseconverterQ1P:  baseconverter,
+		decomposer:        eval.decomposer,
+		pHalf:             eval.pHalf,
+		evakey:            eval.evakey,
+		rotkeys:           eval.rotkeys,
+		evaluatorBuffers:  newEvaluatorBuffers(eval.params, eval.bfvContext),
+	}
+}
+
+// getEvaluationKey returns evakey, or the evaluation key stored by the evaluator if evakey is nil.
+func (evaluator *evaluator) getEvaluationKey(evakey *EvaluationKey) *EvaluationKey {
+	if evakey == nil {
+		return evaluator.evakey
 	}
+	return evakey
+}
+
+// getRotationKeys returns rotkeys, or the rotation keys stored by the evaluator if rotkeys is nil.
+func (evaluator *evaluator) getRotationKeys(rotkeys *RotationKeys) *RotationKeys {
+	if rotkeys == nil {
+		return evaluator.rotkeys
+	}
+	return rotkeys
 }
 
 func (evaluator *evaluator) getElemAndCheckBinary(op0, op1, opOut Operand, opOutMinDegree uint64) (el0, el1, elOut *bfvElement) {
-	if op0 == nil || op1 == nil || opOut == nil {
-		panic("cannot getElemAndCheckBinary: operands cannot be nil")
+
+	if err := evaluator.checkBinary("getElemAndCheckBinary", op0, op1, opOut, opOutMinDegree); err != nil {
+		panic(err)
+	}
+
+	level := utils.MinUint64(utils.MinUint64(op0.Level(), op1.Level()), opOut.Level())
+
+	el0 = evaluator.modSwitchOnPool(0, op0.Element(), level)
+
+	if op0.Element() == op1.Element() {
+		el1 = el0
+	} else {
+		el1 = evaluator.modSwitchOnPool(1, op1.Element(), level)
+	}
+
+	elOut = opOut.Element()
+	setLevel(elOut, level)
+
+	return // TODO: more checks on elements
+}
+
+func (evaluator *evaluator) getElemAndCheckUnary(op0, opOut Operand, opOutMinDegree uint64) (el0, elOut *bfvElement) {
+
+	if err := evaluator.checkUnary("getElemAndCheckUnary", op0, opOut, opOutMinDegree); err != nil {
+		panic(err)
+	}
+
+	level := utils.MinUint64(op0.Level(), opOut.Level())
+
+	el0 = evaluator.modSwitchOnPool(0, op0.Element(), level)
+
+	elOut = opOut.Element()
+	setLevel(elOut, level)
+
+	return // TODO: more checks on elements
+}
+
+// checkOperands returns an Error if one of the operands is nil or if their ring degree does not match the parameters.
+func (evaluator *evaluator) checkOperands(op string, operands ...Operand) error {
+
+	for _, operand := range operands {
+
+		if isNilOperand(operand) {
+			return newError(op, ErrNilOperand, "operands cannot be nil")
+		}
+
+		for _, pol := range operand.Element().value {
+			if pol.GetDegree() != int(evaluator.bfvContext.n) {
+				return newError(op, ErrRingDegreeMismatch, "operand ring degree does not match the parameters")
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkBinary returns an Error if the operands of a binary operation are not consistent.
+func (evaluator *evaluator) checkBinary(op string, op0, op1, opOut Operand, opOutMinDegree uint64) error {
+
+	if err := evaluator.checkOperands(op, op0, op1, opOut); err != nil {
+		return err
 	}
 
 	if op0.Degree()+op1.Degree() == 0 {
-		panic("cannot getElemAndCheckBinary: operands cannot be both plaintexts")
+		return newError(op, ErrInvalidOperand, "operands cannot be both plaintexts")
 	}
 
 	if opOut.Degree() < opOutMinDegree {
-		panic("cannot getElemAndCheckBinary: receiver operand degree is too small")
+		return newError(op, ErrDegreeMismatch, "receiver operand degree is too small")
 	}
 
-	el0, el1, elOut = op0.Element(), op1.Element(), opOut.Element()
-	return // TODO: more checks on elements
+	return nil
 }
 
-func (evaluator *evaluator) getElemAndCheckUnary(op0, opOut Operand, opOutMinDegree uint64) (el0, elOut *bfvElement) {
-	if op0 == nil || opOut == nil {
-		panic("cannot getElemAndCheckUnary: operand cannot be nil")
+// checkUnary returns an Error if the operands of a unary operation are not consistent.
+func (evaluator *evaluator) checkUnary(op string, op0, opOut Operand, opOutMinDegree uint64) error {
+
+	if err := evaluator.checkOperands(op, op0, opOut); err != nil {
+		return err
 	}
 
 	if op0.Degree() == 0 {
-		panic("cannot getElemAndCheckUnary: operand cannot be plaintext")
+		return newError(op, ErrInvalidOperand, "operand cannot be plaintext")
 	}
 
 	if opOut.Degree() < opOutMinDegree {
-		panic("cannot getElemAndCheckUnary: receiver operand degree is too small")
+		return newError(op, ErrDegreeMismatch, "receiver operand degree is too small")
 	}
-	el0, elOut = op0.Element(), opOut.Element()
-	return // TODO: more checks on elements
+
+	return nil
+}
+
+// checkKeySwitch returns an Error if the input and output ciphertexts of a key-switching operation are not of degree 1.
+func (evaluator *evaluator) checkKeySwitch(op string, ct0, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkOperands(op, ct0, ctOut); err != nil {
+		return err
+	}
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		return newError(op, ErrDegreeMismatch, "input and output must be of degree 1")
+	}
+
+	return nil
+}
+
+// isNilOperand returns true if the operand, or the element it wraps, is nil.
+func isNilOperand(op Operand) bool {
+	switch el := op.(type) {
+	case nil:
+		return true
+	case *Ciphertext:
+		return el == nil || el.bfvElement == nil
+	case *Plaintext:
+		return el == nil || el.bfvElement == nil
+	case *bfvElement:
+		return el == nil
+	}
+	return false
+}
+
+// getCiphertextAtLevel returns ct0 at the level of the receiver ctOut if it is smaller, and sets ctOut
+// to the level of ct0 otherwise. The input ciphertext is never modified.
+func (evaluator *evaluator) getCiphertextAtLevel(ct0, ctOut *Ciphertext) *Ciphertext {
+
+	level := utils.MinUint64(ct0.Level(), ctOut.Level())
+
+	el0 := evaluator.modSwitchOnPool(0, ct0.Element(), level)
+
+	setLevel(ctOut.Element(), level)
+
+	if el0 == ct0.Element() {
+		return ct0
+	}
+
+	return el0.Ciphertext()
+}
+
+// modSwitchOnPool returns el at the target level. If the level of el is higher, it is copied on the i-th
+// element of the memory pool and modulus-switched down to the target level, so that el is not modified.
+func (evaluator *evaluator) modSwitchOnPool(i int, el *bfvElement, level uint64) *bfvElement {
+
+	if el.Level() == level {
+		return el
+	}
+
+	context := evaluator.bfvContext.contextQ
+
+	pool := evaluator.ctxpool[i]
+	for pool.Degree() < el.Degree() {
+		pool.value = append(pool.value, context.NewPoly())
+	}
+
+	elTmp := &bfvElement{value: pool.value[:el.Degree()+1], isNTT: el.isNTT}
+
+	for j := range el.value {
+		elTmp.value[j].Coeffs = elTmp.value[j].Coeffs[:el.Level()+1]
+		elTmp.value[j].Copy(el.value[j])
+
+		for uint64(len(elTmp.value[j].Coeffs)-1) > level {
+			context.DivRoundByLastModulus(elTmp.value[j])
+		}
+	}
+
+	return elTmp
+}
+
+// setLevel sets the level of the target element, which must have been allocated at a level greater or equal to the target level.
+func setLevel(el *bfvElement, level uint64) {
+	for i := range el.value {
+		el.value[i].Coeffs = el.value[i].Coeffs[:level+1]
+	}
+	el.seed = nil
 }
 
 // evaluateInPlaceBinary applies the provided function in place on el0 and el1 and returns the result in elOut.
-func evaluateInPlaceBinary(el0, el1, elOut *bfvElement, evaluate func(*ring.Poly, *ring.Poly, *ring.Poly)) {
+// The three elements must be at the same level.
+func (evaluator *evaluator) evaluateInPlaceBinary(el0, el1, elOut *bfvElement, evaluate func(uint64, *ring.Poly, *ring.Poly, *ring.Poly)) {
+
+	level := elOut.Level()
 
 	maxDegree := utils.MaxUint64(el0.Degree(), el1.Degree())
 	minDegree := utils.MinUint64(el0.Degree(), el1.Degree())
 
 	for i := uint64(0); i < minDegree+1; i++ {
-		evaluate(el0.value[i], el1.value[i], elOut.value[i])
+		evaluate(level, el0.value[i], el1.value[i], elOut.value[i])
 	}
 
 	// If the inputs degrees differ, it copies the remaining degree on the receiver.
@@ -157,27 +408,47 @@ func evaluateInPlaceBinary(el0, el1, elOut *bfvElement, evaluate func(*ring.Poly
 	}
 	if largest != nil && largest != elOut { // checks to avoid unnecessary work.
 		for i := minDegree + 1; i < maxDegree+1; i++ {
-			elOut.value[i].Copy(largest.value[i])
+			evaluator.bfvContext.contextQ.CopyLvl(level, largest.value[i], elOut.value[i])
 		}
 	}
 }
 
 // evaluateInPlaceUnary applies the provided function in place on el0 and returns the result in elOut.
-func evaluateInPlaceUnary(el0, elOut *bfvElement, evaluate func(*ring.Poly, *ring.Poly)) {
+// The two elements must be at the same level.
+func (evaluator *evaluator) evaluateInPlaceUnary(el0, elOut *bfvElement, evaluate func(uint64, *ring.Poly, *ring.Poly)) {
+
+	level := elOut.Level()
+
 	for i := range el0.value {
-		evaluate(el0.value[i], elOut.value[i])
+		evaluate(level, el0.value[i], elOut.value[i])
 	}
 }
 
 // Add adds op0 to op1 and returns the result in ctOut.
 func (evaluator *evaluator) Add(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
-	evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.Add)
+	evaluator.evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.AddLvl)
+}
+
+// TryAdd is the same as Add, but returns an Error instead of panicking if the operands are inconsistent.
+func (evaluator *evaluator) TryAdd(op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkOperands("Add", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	if err := evaluator.checkBinary("Add", op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree())); err != nil {
+		return err
+	}
+
+	evaluator.Add(op0, op1, ctOut)
+
+	return nil
 }
 
 // AddNew adds op0 to op1 and creates a new element ctOut to store the result.
 func (evaluator *evaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	ctOut = NewCiphertextLvl(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()), utils.MinUint64(op0.Level(), op1.Level()))
 	evaluator.Add(op0, op1, ctOut)
 	return
 }
@@ -185,12 +456,12 @@ func (evaluator *evaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
 // AddNoMod adds op0 to op1 without modular reduction, and returns the result in cOut.
 func (evaluator *evaluator) AddNoMod(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
-	evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.AddNoMod)
+	evaluator.evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.AddNoModLvl)
 }
 
 // AddNoModNew adds op0 to op1 without modular reduction and creates a new element ctOut to store the result.
 func (evaluator *evaluator) AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	ctOut = NewCiphertextLvl(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()), utils.MinUint64(op0.Level(), op1.Level()))
 	evaluator.AddNoMod(op0, op1, ctOut)
 	return
 }
@@ -198,18 +469,34 @@ func (evaluator *evaluator) AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
 // Sub subtracts op1 from op0 and returns the result in cOut.
 func (evaluator *evaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
-	evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.Sub)
+	evaluator.evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.SubLvl)
 
 	if el0.Degree() < el1.Degree() {
 		for i := el0.Degree() + 1; i < el1.Degree()+1; i++ {
-			evaluator.bfvContext.contextQ.Neg(ctOut.Value()[i], ctOut.Value()[i])
+			evaluator.bfvContext.contextQ.NegLvl(elOut.Level(), elOut.value[i], elOut.value[i])
 		}
 	}
 }
 
+// TrySub is the same as Sub, but returns an Error instead of panicking if the operands are inconsistent.
+func (evaluator *evaluator) TrySub(op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkOperands("Sub", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	if err := evaluator.checkBinary("Sub", op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree())); err != nil {
+		return err
+	}
+
+	evaluator.Sub(op0, op1, ctOut)
+
+	return nil
+}
+
 // SubNew subtracts op1 from op0 and creates a new element ctOut to store the result.
 func (evaluator *evaluator) SubNew(op0, op1 Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	ctOut = NewCiphertextLvl(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()), utils.MinUint64(op0.Level(), op1.Level()))
 	evaluator.Sub(op0, op1, ctOut)
 	return
 }
@@ -218,18 +505,18 @@ func (evaluator *evaluator) SubNew(op0, op1 Operand) (ctOut *Ciphertext) {
 func (evaluator *evaluator) SubNoMod(op0, op1 Operand, ctOut *Ciphertext) {
 	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
 
-	evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.SubNoMod)
+	evaluator.evaluateInPlaceBinary(el0, el1, elOut, evaluator.bfvContext.contextQ.SubNoModLvl)
 
 	if el0.Degree() < el1.Degree() {
 		for i := el0.Degree() + 1; i < el1.Degree()+1; i++ {
-			evaluator.bfvContext.contextQ.Neg(ctOut.Value()[i], ctOut.Value()[i])
+			evaluator.bfvContext.contextQ.NegLvl(elOut.Level(), elOut.value[i], elOut.value[i])
 		}
 	}
 }
 
 // SubNoModNew subtracts op1 from op0 without modular reduction and creates a new element ctOut to store the result.
 func (evaluator *evaluator) SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	ctOut = NewCiphertextLvl(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()), utils.MinUint64(op0.Level(), op1.Level()))
 	evaluator.SubNoMod(op0, op1, ctOut)
 	return
 }
@@ -237,12 +524,12 @@ func (evaluator *evaluator) SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
 // Neg negates op and returns the result in ctOut.
 func (evaluator *evaluator) Neg(op Operand, ctOut *Ciphertext) {
 	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
-	evaluateInPlaceUnary(el0, elOut, evaluator.bfvContext.contextQ.Neg)
+	evaluator.evaluateInPlaceUnary(el0, elOut, evaluator.bfvContext.contextQ.NegLvl)
 }
 
 // NegNew negates op and creates a new element to store the result.
 func (evaluator *evaluator) NegNew(op Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, op.Degree())
+	ctOut = NewCiphertextLvl(evaluator.params, op.Degree(), op.Level())
 	evaluator.Neg(op, ctOut)
 	return ctOut
 }
@@ -250,12 +537,12 @@ func (evaluator *evaluator) NegNew(op Operand) (ctOut *Ciphertext) {
 // Reduce applies a modular reduction to op and returns the result in ctOut.
 func (evaluator *evaluator) Reduce(op Operand, ctOut *Ciphertext) {
 	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
-	evaluateInPlaceUnary(el0, elOut, evaluator.bfvContext.contextQ.Reduce)
+	evaluator.evaluateInPlaceUnary(el0, elOut, evaluator.bfvContext.contextQ.ReduceLvl)
 }
 
 // ReduceNew applies a modular reduction to op and creates a new element ctOut to store the result.
 func (evaluator *evaluator) ReduceNew(op Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, op.Degree())
+	ctOut = NewCiphertextLvl(evaluator.params, op.Degree(), op.Level())
 	evaluator.Reduce(op, ctOut)
 	return ctOut
 }
@@ -263,24 +550,27 @@ func (evaluator *evaluator) ReduceNew(op Operand) (ctOut *Ciphertext) {
 // MulScalar multiplies op by a uint64 scalar and returns the result in ctOut.
 func (evaluator *evaluator) MulScalar(op Operand, scalar uint64, ctOut *Ciphertext) {
 	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
-	fun := func(el, elOut *ring.Poly) { evaluator.bfvContext.contextQ.MulScalar(el, scalar, elOut) }
-	evaluateInPlaceUnary(el0, elOut, fun)
+	fun := func(level uint64, el, elOut *ring.Poly) {
+		evaluator.bfvContext.contextQ.MulScalarLvl(level, el, scalar, elOut)
+	}
+	evaluator.evaluateInPlaceUnary(el0, elOut, fun)
 }
 
 // MulScalarNew multiplies op by a uint64 scalar and creates a new element ctOut to store the result.
 func (evaluator *evaluator) MulScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, op.Degree())
+	ctOut = NewCiphertextLvl(evaluator.params, op.Degree(), op.Level())
 	evaluator.MulScalar(op, scalar, ctOut)
 	return
 }
 
-// tensorAndRescale computes (ct0 x ct1) * (t/Q) and stores the result in ctOut.
+// tensorAndRescale computes (ct0 x ct1) * (t/Q_l) and stores the result in ctOut, where Q_l is the modulus
+// at the level of the elements, which must all be at the same level.
 func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 
 	contextQ := evaluator.bfvContext.contextQ
 	contextQMul := evaluator.bfvContext.contextQMul
 
-	levelQ := uint64(len(contextQ.Modulus) - 1)
+	levelQ := ctOut.Level()
 	levelQMul := uint64(len(contextQMul.Modulus) - 1)
 
 	// Prepares the ciphertexts for the Tensoring by extending their
@@ -298,7 +588,7 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 	for i := range ct0.value {
 		evaluator.baseconverterQ1Q2.ModUpSplitQP(levelQ, ct0.value[i], c0Q2[i])
 
-		contextQ.NTT(ct0.value[i], c0Q1[i])
+		contextQ.NTTLvl(levelQ, ct0.value[i], c0Q1[i])
 		contextQMul.NTT(c0Q2[i], c0Q2[i])
 	}
 
@@ -307,7 +597,7 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 		for i := range ct1.value {
 			evaluator.baseconverterQ1Q2.ModUpSplitQP(levelQ, ct1.value[i], c1Q2[i])
 
-			contextQ.NTT(ct1.value[i], c1Q1[i])
+			contextQ.NTTLvl(levelQ, ct1.value[i], c1Q1[i])
 			contextQMul.NTT(c1Q2[i], c1Q2[i])
 		}
 	}
@@ -324,46 +614,46 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 		c01Q := evaluator.poolQ[3][1]
 		c01P := evaluator.poolP[3][1]
 
-		contextQ.MForm(c0Q1[0], c00Q)
+		contextQ.MFormLvl(levelQ, c0Q1[0], c00Q)
 		contextQMul.MForm(c0Q2[0], c00Q2)
 
-		contextQ.MForm(c0Q1[1], c01Q)
+		contextQ.MFormLvl(levelQ, c0Q1[1], c01Q)
 		contextQMul.MForm(c0Q2[1], c01P)
 
 		// Squaring case
 		if ct0 == ct1 {
 
 			// c0 = c0[0]*c0[0]
-			contextQ.MulCoeffsMontgomery(c00Q, c0Q1[0], c2Q1[0])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c00Q, c0Q1[0], c2Q1[0])
 			contextQMul.MulCoeffsMontgomery(c00Q2, c0Q2[0], c2Q2[0])
 
 			// c1 = 2*c0[0]*c0[1]
-			contextQ.MulCoeffsMontgomery(c00Q, c0Q1[1], c2Q1[1])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c00Q, c0Q1[1], c2Q1[1])
 			contextQMul.MulCoeffsMontgomery(c00Q2, c0Q2[1], c2Q2[1])
 
-			contextQ.AddNoMod(c2Q1[1], c2Q1[1], c2Q1[1])
+			contextQ.AddNoModLvl(levelQ, c2Q1[1], c2Q1[1], c2Q1[1])
 			contextQMul.AddNoMod(c2Q2[1], c2Q2[1], c2Q2[1])
 
 			// c2 = c0[1]*c0[1]
-			contextQ.MulCoeffsMontgomery(c01Q, c0Q1[1], c2Q1[2])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c01Q, c0Q1[1], c2Q1[2])
 			contextQMul.MulCoeffsMontgomery(c01P, c0Q2[1], c2Q2[2])
 
 			// Normal case
 		} else {
 
 			// c0 = c0[0]*c1[0]
-			contextQ.MulCoeffsMontgomery(c00Q, c1Q1[0], c2Q1[0])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c00Q, c1Q1[0], c2Q1[0])
 			contextQMul.MulCoeffsMontgomery(c00Q2, c1Q2[0], c2Q2[0])
 
 			// c1 = c0[0]*c1[1] + c0[1]*c1[0]
-			contextQ.MulCoeffsMontgomery(c00Q, c1Q1[1], c2Q1[1])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c00Q, c1Q1[1], c2Q1[1])
 			contextQMul.MulCoeffsMontgomery(c00Q2, c1Q2[1], c2Q2[1])
 
-			contextQ.MulCoeffsMontgomeryAndAddNoMod(c01Q, c1Q1[0], c2Q1[1])
+			contextQ.MulCoeffsMontgomeryAndAddNoModLvl(levelQ, c01Q, c1Q1[0], c2Q1[1])
 			contextQMul.MulCoeffsMontgomeryAndAddNoMod(c01P, c1Q2[0], c2Q2[1])
 
 			// c2 = c0[1]*c1[1]
-			contextQ.MulCoeffsMontgomery(c01Q, c1Q1[1], c2Q1[2])
+			contextQ.MulCoeffsMontgomeryLvl(levelQ, c01Q, c1Q1[1], c2Q1[2])
 			contextQMul.MulCoeffsMontgomery(c01P, c1Q2[1], c2Q2[2])
 		}
 
@@ -382,32 +672,32 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 			c00Q2 := evaluator.poolP[3]
 
 			for i := range ct0.value {
-				contextQ.MForm(c0Q1[i], c00Q1[i])
+				contextQ.MFormLvl(levelQ, c0Q1[i], c00Q1[i])
 				contextQMul.MForm(c0Q2[i], c00Q2[i])
 			}
 
 			for i := uint64(0); i < ct0.Degree()+1; i++ {
 				for j := i + 1; j < ct0.Degree()+1; j++ {
-					contextQ.MulCoeffsMontgomery(c00Q1[i], c0Q1[j], c2Q1[i+j])
+					contextQ.MulCoeffsMontgomeryLvl(levelQ, c00Q1[i], c0Q1[j], c2Q1[i+j])
 					contextQMul.MulCoeffsMontgomery(c00Q2[i], c0Q2[j], c2Q2[i+j])
 
-					contextQ.Add(c2Q1[i+j], c2Q1[i+j], c2Q1[i+j])
+					contextQ.AddLvl(levelQ, c2Q1[i+j], c2Q1[i+j], c2Q1[i+j])
 					contextQMul.Add(c2Q2[i+j], c2Q2[i+j], c2Q2[i+j])
 				}
 			}
 
 			for i := uint64(0); i < ct0.Degree()+1; i++ {
-				contextQ.MulCoeffsMontgomeryAndAdd(c00Q1[i], c0Q1[i], c2Q1[i<<1])
+				contextQ.MulCoeffsMontgomeryAndAddLvl(levelQ, c00Q1[i], c0Q1[i], c2Q1[i<<1])
 				contextQMul.MulCoeffsMontgomeryAndAdd(c00Q2[i], c0Q2[i], c2Q2[i<<1])
 			}
 
 			// Normal case
 		} else {
 			for i := range ct0.value {
-				contextQ.MForm(c0Q1[i], c0Q1[i])
+				contextQ.MFormLvl(levelQ, c0Q1[i], c0Q1[i])
 				contextQMul.MForm(c0Q2[i], c0Q2[i])
 				for j := range ct1.value {
-					contextQ.MulCoeffsMontgomeryAndAdd(c0Q1[i], c1Q1[j], c2Q1[i+j])
+					contextQ.MulCoeffsMontgomeryAndAddLvl(levelQ, c0Q1[i], c1Q1[j], c2Q1[i+j])
 					contextQMul.MulCoeffsMontgomeryAndAdd(c0Q2[i], c1Q2[j], c2Q2[i+j])
 				}
 			}
@@ -421,7 +711,7 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 	// Applies the inverse NTT to the ciphertext, scales down the ciphertext
 	// by t/q and reduces its basis from QP to Q
 	for i := range ctOut.value {
-		contextQ.InvNTT(c2Q1[i], c2Q1[i])
+		contextQ.InvNTTLvl(levelQ, c2Q1[i], c2Q1[i])
 		contextQMul.InvNTT(c2Q2[i], c2Q2[i])
 
 		/*
@@ -454,12 +744,13 @@ func (evaluator *evaluator) tensorAndRescale(ct0, ct1, ctOut *bfvElement) {
 		//fmt.Println()
 
 		// Centers (ct(x)Q -> P)/Q by (P-1)/2 and extends ((ct(x)Q -> P)/Q) to the basis Q
+		// (the extension is done on the full basis Q and only the moduli up to levelQ are kept)
 		contextQMul.AddScalarBigint(c2Q2[i], evaluator.pHalf, c2Q2[i])
-		evaluator.baseconverterQ1Q2.ModUpSplitPQ(levelQMul, c2Q2[i], ctOut.value[i])
-		contextQ.SubScalarBigint(ctOut.value[i], evaluator.pHalf, ctOut.value[i])
+		evaluator.baseconverterQ1Q2.ModUpSplitPQ(levelQMul, c2Q2[i], evaluator.polypool[0])
+		contextQ.SubScalarBigint(evaluator.polypool[0], evaluator.pHalf, evaluator.polypool[0])
 
 		// Option (2) (ct(x)/Q)*T, doing so only requires that Q*P > Q*Q, faster but adds error ~|T|
-		contextQ.MulScalar(ctOut.value[i], evaluator.bfvContext.contextT.Modulus[0], ctOut.value[i])
+		contextQ.MulScalarLvl(levelQ, evaluator.polypool[0], evaluator.bfvContext.contextT.Modulus[0], ctOut.value[i])
 	}
 }
 
@@ -469,9 +760,25 @@ func (evaluator *evaluator) Mul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext)
 	evaluator.tensorAndRescale(el0, el1, elOut)
 }
 
+// TryMul is the same as Mul, but returns an Error instead of panicking if the operands are inconsistent.
+func (evaluator *evaluator) TryMul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkOperands("Mul", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	if err := evaluator.checkBinary("Mul", op0, op1, ctOut, op0.Degree()+op1.Degree()); err != nil {
+		return err
+	}
+
+	evaluator.Mul(op0, op1, ctOut)
+
+	return nil
+}
+
 // MulNew multiplies op0 by op1 and creates a new element ctOut to store the result.
 func (evaluator *evaluator) MulNew(op0 *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, op0.Degree()+op1.Degree())
+	ctOut = NewCiphertextLvl(evaluator.params, op0.Degree()+op1.Degree(), utils.MinUint64(op0.Level(), op1.Level()))
 	evaluator.Mul(op0, op1, ctOut)
 	return
 }
@@ -481,18 +788,20 @@ func (evaluator *evaluator) relinearize(ct0 *Ciphertext, evakey *EvaluationKey,
 
 	context := evaluator.bfvContext.contextQ
 
+	level := ctOut.Level()
+
 	if ctOut != ct0 {
-		evaluator.bfvContext.contextQ.Copy(ct0.value[0], ctOut.value[0])
-		evaluator.bfvContext.contextQ.Copy(ct0.value[1], ctOut.value[1])
+		context.CopyLvl(level, ct0.value[0], ctOut.value[0])
+		context.CopyLvl(level, ct0.value[1], ctOut.value[1])
 	}
 
-	p0 := evaluator.keyswitchpool[2]
-	p1 := evaluator.keyswitchpool[3]
+	p0 := evaluator.keyswitchpoolQ[2]
+	p1 := evaluator.keyswitchpoolQ[3]
 
 	for deg := uint64(ct0.Degree()); deg > 1; deg-- {
-		evaluator.switchKeys(ct0.value[deg], evakey.evakey[deg-2], p0, p1)
-		context.Add(ctOut.value[0], p0, ctOut.value[0])
-		context.Add(ctOut.value[1], p1, ctOut.value[1])
+		evaluator.switchKeys(level, ct0.value[deg], evakey.evakey[deg-2], p0, p1)
+		context.AddLvl(level, ctOut.value[0], p0, ctOut.value[0])
+		context.AddLvl(level, ctOut.value[1], p1, ctOut.value[1])
 	}
 
 	ctOut.SetValue(ctOut.value[:2])
@@ -508,19 +817,56 @@ func (evaluator *evaluator) relinearize(ct0 *Ciphertext, evakey *EvaluationKey,
 // of degree 3 will require that the evaluation key stores the keys for both degree 3 and degree 2 ciphertexts).
 func (evaluator *evaluator) Relinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) {
 
-	if int(ct0.Degree()-1) > len(evakey.evakey) {
-		panic("cannot Relinearize: input ciphertext degree too large to allow relinearization")
+	evakey = evaluator.getEvaluationKey(evakey)
+
+	if err := evaluator.checkRelinearize(ct0, evakey, ctOut); err != nil {
+		panic(err)
 	}
 
 	if ct0.Degree() < 2 {
 		if ct0 != ctOut {
-			ctOut.Copy(ct0.Element())
+			ctOut.Copy(evaluator.getCiphertextAtLevel(ct0, ctOut).Element())
 		}
 	} else {
-		evaluator.relinearize(ct0, evakey, ctOut)
+		evaluator.relinearize(evaluator.getCiphertextAtLevel(ct0, ctOut), evakey, ctOut)
 	}
 }
 
+// TryRelinearize is the same as Relinearize, but returns an Error instead of panicking if the inputs are inconsistent.
+func (evaluator *evaluator) TryRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) error {
+
+	evakey = evaluator.getEvaluationKey(evakey)
+
+	if err := evaluator.checkRelinearize(ct0, evakey, ctOut); err != nil {
+		return err
+	}
+
+	evaluator.Relinearize(ct0, evakey, ctOut)
+
+	return nil
+}
+
+func (evaluator *evaluator) checkRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkOperands("Relinearize", ct0, ctOut); err != nil {
+		return err
+	}
+
+	if evakey == nil {
+		return newError("Relinearize", ErrMissingKey, "evaluation key is nil")
+	}
+
+	if int(ct0.Degree()-1) > len(evakey.evakey) {
+		return newError("Relinearize", ErrMissingKey, "input ciphertext degree too large to allow relinearization")
+	}
+
+	if ctOut.Degree() < 1 {
+		return newError("Relinearize", ErrDegreeMismatch, "receiver operand degree is too small")
+	}
+
+	return nil
+}
+
 // RelinearizeNew relinearizes the ciphertext ct0 of degree > 1 until it is of degree 1, and creates a new ciphertext to store the result.
 //
 // Requires a correct evaluation key as additional input:
@@ -530,7 +876,7 @@ func (evaluator *evaluator) Relinearize(ct0 *Ciphertext, evakey *EvaluationKey,
 // - it must be of degree high enough to relinearize the input ciphertext to degree 1 (e.g., a ciphertext
 // of degree 3 will require that the evaluation key stores the keys for both degree 3 and degree 2 ciphertexts).
 func (evaluator *evaluator) RelinearizeNew(ct0 *Ciphertext, evakey *EvaluationKey) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, 1)
+	ctOut = NewCiphertextLvl(evaluator.params, 1, ct0.Level())
 	evaluator.Relinearize(ct0, evakey, ctOut)
 	return
 }
@@ -539,32 +885,61 @@ func (evaluator *evaluator) RelinearizeNew(ct0 *Ciphertext, evakey *EvaluationKe
 // it must encrypt the target key under the public key under which ct0 is currently encrypted.
 func (evaluator *evaluator) SwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext) {
 
+	if err := evaluator.checkSwitchKeys(ct0, switchKey, ctOut); err != nil {
+		panic(err)
+	}
+
 	context := evaluator.bfvContext.contextQ
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot SwitchKeys: input and output must be of degree 1 to allow key switching")
+	ct0 = evaluator.getCiphertextAtLevel(ct0, ctOut)
+
+	level := ctOut.Level()
+
+	p0 := evaluator.keyswitchpoolQ[2]
+	p1 := evaluator.keyswitchpoolQ[3]
+
+	evaluator.switchKeys(level, ct0.value[1], switchKey, p0, p1)
+
+	context.AddLvl(level, ct0.value[0], p0, ctOut.value[0])
+	context.CopyLvl(level, p1, ctOut.value[1])
+}
+
+// TrySwitchKeys is the same as SwitchKeys, but returns an Error instead of panicking if the inputs are inconsistent.
+func (evaluator *evaluator) TrySwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkSwitchKeys(ct0, switchKey, ctOut); err != nil {
+		return err
 	}
 
-	p0 := evaluator.keyswitchpool[2]
-	p1 := evaluator.keyswitchpool[3]
+	evaluator.SwitchKeys(ct0, switchKey, ctOut)
 
-	evaluator.switchKeys(ct0.value[1], switchKey, p0, p1)
+	return nil
+}
+
+func (evaluator *evaluator) checkSwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkKeySwitch("SwitchKeys", ct0, ctOut); err != nil {
+		return err
+	}
+
+	if switchKey == nil {
+		return newError("SwitchKeys", ErrMissingKey, "switching key is nil")
+	}
 
-	context.Add(ct0.value[0], p0, ctOut.value[0])
-	context.Copy(p1, ctOut.value[1])
+	return nil
 }
 
 // SwitchKeysNew applies the key-switching procedure to the ciphertext ct0 and creates a new ciphertext to store the result. It requires as an additional input a valid switching-key:
 // it must encrypt the target key under the public key under which ct0 is currently encrypted.
 func (evaluator *evaluator) SwitchKeysNew(ct0 *Ciphertext, switchkey *SwitchingKey) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, 1)
+	ctOut = NewCiphertextLvl(evaluator.params, 1, ct0.Level())
 	evaluator.SwitchKeys(ct0, switchkey, ctOut)
 	return
 }
 
 // RotateColumnsNew applies RotateColumns and returns the result in a new Ciphertext.
 func (evaluator *evaluator) RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, 1)
+	ctOut = NewCiphertextLvl(evaluator.params, 1, ct0.Level())
 	evaluator.RotateColumns(ct0, k, evakey, ctOut)
 	return
 }
@@ -577,10 +952,14 @@ func (evaluator *evaluator) RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *
 // hamming weight will be chosen; then the specific rotation will be computed as a sum of powers of two rotations.
 func (evaluator *evaluator) RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot RotateColumns: input and or output must be of degree 1")
+	evakey = evaluator.getRotationKeys(evakey)
+
+	if err := evaluator.checkRotateColumns(ct0, k, evakey, ctOut); err != nil {
+		panic(err)
 	}
 
+	ct0 = evaluator.getCiphertextAtLevel(ct0, ctOut)
+
 	k &= ((evaluator.bfvContext.n >> 1) - 1)
 
 	if k == 0 {
@@ -594,32 +973,60 @@ func (evaluator *evaluator) RotateColumns(ct0 *Ciphertext, k uint64, evakey *Rot
 
 			evaluator.permute(ct0, evaluator.bfvContext.galElRotColLeft[k], evakey.evakeyRotColLeft[k], ctOut)
 
+			// Otherwise, it computes the least amount of rotation between k to the left and n/2-k to the right required
+			// to apply the requested rotation from the left and right pow2 rotations
+		} else if utils.HammingWeight64(k) <= utils.HammingWeight64((evaluator.bfvContext.n>>1)-k) {
+			evaluator.rotateColumnsLPow2(ct0, k, evakey, ctOut)
 		} else {
+			evaluator.rotateColumnsRPow2(ct0, (evaluator.bfvContext.n>>1)-k, evakey, ctOut)
+		}
+	}
+}
 
-			// If the needed rotation key has not been generated, it looks if the left and right pow2 rotations have been generated
-			hasPow2Rotations := true
-			for i := uint64(1); i < evaluator.bfvContext.n>>1; i <<= 1 {
-				if evakey.evakeyRotColLeft[i] == nil || evakey.evakeyRotColRight[i] == nil {
-					hasPow2Rotations = false
-					break
-				}
-			}
+// TryRotateColumns is the same as RotateColumns, but returns an Error instead of panicking if the inputs are inconsistent
+// or if the required rotation keys have not been generated.
+func (evaluator *evaluator) TryRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) error {
 
-			// If they have been generated, it computes the least amount of rotation between k to the left and n/2-k to the right required to apply the requested rotation
-			if hasPow2Rotations {
+	evakey = evaluator.getRotationKeys(evakey)
 
-				if utils.HammingWeight64(k) <= utils.HammingWeight64((evaluator.bfvContext.n>>1)-k) {
-					evaluator.rotateColumnsLPow2(ct0, k, evakey, ctOut)
-				} else {
-					evaluator.rotateColumnsRPow2(ct0, (evaluator.bfvContext.n>>1)-k, evakey, ctOut)
-				}
+	if err := evaluator.checkRotateColumns(ct0, k, evakey, ctOut); err != nil {
+		return err
+	}
 
-				// Otherwise, it returns an error indicating that the keys have not been generated
-			} else {
-				panic("cannot RotateColumns: specific rotation and pow2 rotations have not been generated")
-			}
+	evaluator.RotateColumns(ct0, k, evakey, ctOut)
+
+	return nil
+}
+
+func (evaluator *evaluator) checkRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkKeySwitch("RotateColumns", ct0, ctOut); err != nil {
+		return err
+	}
+
+	k &= ((evaluator.bfvContext.n >> 1) - 1)
+
+	if k == 0 {
+		return nil
+	}
+
+	if evakey == nil {
+		return newError("RotateColumns", ErrMissingKey, "rotation keys are nil")
+	}
+
+	// Looks in the rotation key if the corresponding rotation has been generated
+	if evakey.evakeyRotColLeft[k] != nil {
+		return nil
+	}
+
+	// If the needed rotation key has not been generated, it looks if the left and right pow2 rotations have been generated
+	for i := uint64(1); i < evaluator.bfvContext.n>>1; i <<= 1 {
+		if evakey.evakeyRotColLeft[i] == nil || evakey.evakeyRotColRight[i] == nil {
+			return newError("RotateColumns", ErrMissingKey, "specific rotation and pow2 rotations have not been generated")
 		}
 	}
+
+	return nil
 }
 
 // rotateColumnsLPow2 applies the Galois Automorphism on an element, rotating the element by k positions to the left, and returns the result in ctOut.
@@ -640,13 +1047,15 @@ func (evaluator *evaluator) rotateColumnsPow2(ct0 *Ciphertext, generator, k uint
 
 	context := evaluator.bfvContext.contextQ
 
+	level := ctOut.Level()
+
 	mask = (evaluator.bfvContext.n << 1) - 1
 
 	evakeyIndex = 1
 
 	if ct0 != ctOut {
-		context.Copy(ct0.value[0], ctOut.value[0])
-		context.Copy(ct0.value[1], ctOut.value[1])
+		context.CopyLvl(level, ct0.value[0], ctOut.value[0])
+		context.CopyLvl(level, ct0.value[1], ctOut.value[1])
 	}
 
 	// Applies the Galois automorphism and the key-switching process
@@ -668,20 +1077,46 @@ func (evaluator *evaluator) rotateColumnsPow2(ct0 *Ciphertext, generator, k uint
 // RotateRows rotates the rows of ct0 and returns the result in ctOut.
 func (evaluator *evaluator) RotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot RotateRows: input and/or output must be of degree 1")
+	evakey = evaluator.getRotationKeys(evakey)
+
+	if err := evaluator.checkRotateRows(ct0, evakey, ctOut); err != nil {
+		panic(err)
+	}
+
+	evaluator.permute(evaluator.getCiphertextAtLevel(ct0, ctOut), evaluator.bfvContext.galElRotRow, evakey.evakeyRotRow, ctOut)
+}
+
+// TryRotateRows is the same as RotateRows, but returns an Error instead of panicking if the inputs are inconsistent
+// or if the rotation key has not been generated.
+func (evaluator *evaluator) TryRotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	evakey = evaluator.getRotationKeys(evakey)
+
+	if err := evaluator.checkRotateRows(ct0, evakey, ctOut); err != nil {
+		return err
+	}
+
+	evaluator.RotateRows(ct0, evakey, ctOut)
+
+	return nil
+}
+
+func (evaluator *evaluator) checkRotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	if err := evaluator.checkKeySwitch("RotateRows", ct0, ctOut); err != nil {
+		return err
 	}
 
-	if evakey.evakeyRotRow == nil {
-		panic("cannot RotateRows: rotation key not generated")
+	if evakey == nil || evakey.evakeyRotRow == nil {
+		return newError("RotateRows", ErrMissingKey, "rotation key not generated")
 	}
 
-	evaluator.permute(ct0, evaluator.bfvContext.galElRotRow, evakey.evakeyRotRow, ctOut)
+	return nil
 }
 
 // RotateRowsNew rotates the rows of ct0 and returns the result a new Ciphertext.
 func (evaluator *evaluator) RotateRowsNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOut *Ciphertext) {
-	ctOut = NewCiphertext(evaluator.params, 1)
+	ctOut = NewCiphertextLvl(evaluator.params, 1, ct0.Level())
 	evaluator.RotateRows(ct0, evakey, ctOut)
 	return
 }
@@ -690,13 +1125,15 @@ func (evaluator *evaluator) RotateRowsNew(ct0 *Ciphertext, evakey *RotationKeys)
 // The resulting vector will be of the form [sum, sum, .., sum, sum].
 func (evaluator *evaluator) InnerSum(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot InnerSum: input and output must be of degree 1")
+	evakey = evaluator.getRotationKeys(evakey)
+
+	if err := evaluator.checkKeySwitch("InnerSum", ct0, ctOut); err != nil {
+		panic(err)
 	}
 
-	cTmp := NewCiphertext(evaluator.params, 1)
+	ctOut.Copy(evaluator.getCiphertextAtLevel(ct0, ctOut).Element())
 
-	ctOut.Copy(ct0.Element())
+	cTmp := NewCiphertextLvl(evaluator.params, 1, ctOut.Level())
 
 	for i := uint64(1); i < evaluator.bfvContext.n>>1; i <<= 1 {
 		evaluator.RotateColumns(ctOut, i, evakey, cTmp)
@@ -707,11 +1144,13 @@ func (evaluator *evaluator) InnerSum(ct0 *Ciphertext, evakey *RotationKeys, ctOu
 	evaluator.Add(ctOut, cTmp.bfvElement, ctOut)
 }
 
-// permute performs a column rotation on ct0 and returns the result in ctOut
+// permute performs a column rotation on ct0 and returns the result in ctOut. Both ciphertexts must be at the same level.
 func (evaluator *evaluator) permute(ct0 *Ciphertext, generator uint64, switchKey *SwitchingKey, ctOut *Ciphertext) {
 
 	context := evaluator.bfvContext.contextQ
 
+	level := ctOut.Level()
+
 	var el0, el1 *ring.Poly
 
 	if ct0 != ctOut {
@@ -720,94 +1159,378 @@ func (evaluator *evaluator) permute(ct0 *Ciphertext, generator uint64, switchKey
 		el0, el1 = evaluator.polypool[0], evaluator.polypool[1]
 	}
 
-	context.Permute(ct0.value[0], generator, el0)
-	context.Permute(ct0.value[1], generator, el1)
+	context.PermuteLvl(level, ct0.value[0], generator, el0)
+	context.PermuteLvl(level, ct0.value[1], generator, el1)
 
-	p0 := evaluator.keyswitchpool[2]
-	p1 := evaluator.keyswitchpool[3]
+	p0 := evaluator.keyswitchpoolQ[2]
+	p1 := evaluator.keyswitchpoolQ[3]
 
-	evaluator.switchKeys(el1, switchKey, p0, p1)
+	evaluator.switchKeys(level, el1, switchKey, p0, p1)
 
-	context.Add(el0, p0, ctOut.value[0])
-	context.Copy(p1, ctOut.value[1])
+	context.AddLvl(level, el0, p0, ctOut.value[0])
+	context.CopyLvl(level, p1, ctOut.value[1])
 }
 
-// switchKeys applies the general key-switching procedure of the form [c0 + cx*evakey[0], c1 + cx*evakey[1]]
-func (evaluator *evaluator) switchKeys(cx *ring.Poly, evakey *SwitchingKey, p0, p1 *ring.Poly) {
+// switchKeys applies the general key-switching procedure of the form [c0 + cx*evakey[0], c1 + cx*evakey[1]] at the given level,
+// and returns the result in p0 and p1 (outside of the NTT domain).
+func (evaluator *evaluator) switchKeys(level uint64, cx *ring.Poly, evakey *SwitchingKey, p0, p1 *ring.Poly) {
 
-	var level, reduce uint64
+	if evakey.logBase != 0 {
+		evaluator.switchKeysGadget(level, cx, evakey, p0, p1)
+		return
+	}
 
-	context := evaluator.bfvContext.contextQ
-	contextKeys := evaluator.bfvContext.contextQP
+	if evaluator.decomposer == nil {
+		panic("cannot switchKeys: modulus P is empty")
+	}
 
-	level = uint64(len(context.Modulus)) - 1
+	var reduce uint64
 
-	for i := range evaluator.keyswitchpool {
-		evaluator.keyswitchpool[i].Zero()
-	}
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	c2QiQ := evaluator.keyswitchpoolQ[0]
+	c2QiP := evaluator.keyswitchpoolP[0]
+
+	c2 := evaluator.keyswitchpoolQ[1]
 
-	c2Qi := evaluator.keyswitchpool[0]
-	c2 := evaluator.keyswitchpool[1]
+	p0P := evaluator.keyswitchpoolP[1]
+	p1P := evaluator.keyswitchpoolP[2]
 
-	// We switch the element on which the key-switching operation will be conducted out of the NTT domain
-	context.NTT(cx, c2)
+	p0.Zero()
+	p1.Zero()
+	p0P.Zero()
+	p1P.Zero()
+
+	// We switch the element on which the key-switching operation will be conducted in the NTT domain
+	contextQ.NTTLvl(level, cx, c2)
 
 	reduce = 0
 
-	N := contextKeys.N
-	c2QiNtt := make([]uint64, N)
+	beta := uint64(math.Ceil(float64(level+1) / float64(evaluator.params.alpha)))
 
 	// Key switching with CRT decomposition for the Qi
-	for i := uint64(0); i < evaluator.params.beta; i++ {
+	for i := uint64(0); i < beta; i++ {
+
+		evaluator.decomposeAndSplitNTT(level, i, c2, cx, c2QiQ, c2QiP)
 
-		p0idxst := i * evaluator.params.alpha
-		p0idxed := p0idxst + evaluator.decomposer.Xalpha()[i]
+		evaluator.mulKeyAndAdd(level, evakey.evakey[i], c2QiQ, c2QiP, p0, p1, p0P, p1P)
 
-		// c2Qi = cx mod qi
-		evaluator.decomposer.Decompose(level, i, cx, c2Qi)
+		if reduce&7 == 7 {
+			contextQ.ReduceLvl(level, p0, p0)
+			contextQ.ReduceLvl(level, p1, p1)
+			contextP.Reduce(p0P, p0P)
+			contextP.Reduce(p1P, p1P)
+		}
 
-		for x, qi := range contextKeys.Modulus {
+		reduce++
+	}
 
-			nttPsi := contextKeys.GetNttPsi()[x]
-			bredParams := contextKeys.GetBredParams()[x]
-			mredParams := contextKeys.GetMredParams()[x]
+	if (reduce-1)&7 != 7 {
+		contextQ.ReduceLvl(level, p0, p0)
+		contextQ.ReduceLvl(level, p1, p1)
+		contextP.Reduce(p0P, p0P)
+		contextP.Reduce(p1P, p1P)
+	}
 
-			if p0idxst <= uint64(x) && uint64(x) < p0idxed {
-				p2tmp := c2.Coeffs[x]
-				for j := uint64(0); j < N; j++ {
-					c2QiNtt[j] = p2tmp[j]
-				}
-			} else {
-				ring.NTT(c2Qi.Coeffs[x], c2QiNtt, N, nttPsi, qi, mredParams, bredParams)
+	evaluator.modDown(level, p0, p0P, p1, p1P)
+}
+
+// switchKeysGadget is the same as switchKeys for a SwitchingKey using the gadget decomposition of base 2^logBase: each Qi
+// residue of cx is decomposed in base 2^logBase, and each digit is multiplied by the corresponding element of the key in basis Q.
+func (evaluator *evaluator) switchKeysGadget(level uint64, cx *ring.Poly, evakey *SwitchingKey, p0, p1 *ring.Poly) {
+
+	var reduce uint64
+
+	contextQ := evaluator.bfvContext.contextQ
+
+	c2 := evaluator.keyswitchpoolQ[1]
+
+	digits := uint64(len(evakey.evakey)) / uint64(len(contextQ.Modulus))
+
+	p0.Zero()
+	p1.Zero()
+
+	for i := uint64(0); i < level+1; i++ {
+
+		for j := uint64(0); j < digits; j++ {
+
+			contextQ.DecomposeDigitLvl(level, i, j, evakey.logBase, cx, c2)
+			contextQ.NTTLvl(level, c2, c2)
+
+			contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey.evakey[i*digits+j][0], c2, p0)
+			contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey.evakey[i*digits+j][1], c2, p1)
+
+			if reduce&7 == 7 {
+				contextQ.ReduceLvl(level, p0, p0)
+				contextQ.ReduceLvl(level, p1, p1)
 			}
 
-			key0 := evakey.evakey[i][0].Coeffs[x]
-			key1 := evakey.evakey[i][1].Coeffs[x]
-			p2tmp := p0.Coeffs[x]
-			p3tmp := p1.Coeffs[x]
+			reduce++
+		}
+	}
+
+	if (reduce-1)&7 != 7 {
+		contextQ.ReduceLvl(level, p0, p0)
+		contextQ.ReduceLvl(level, p1, p1)
+	}
+
+	contextQ.InvNTTLvl(level, p0, p0)
+	contextQ.InvNTTLvl(level, p1, p1)
+}
+
+// decomposeAndSplitNTT decomposes cx into the beta-th element of its CRT decomposition, and returns it split
+// in the moduli Q (up to the given level) and P, in the NTT domain. c2NTT must be the NTT of cx.
+func (evaluator *evaluator) decomposeAndSplitNTT(level, beta uint64, c2NTT, cx, c2QiQ, c2QiP *ring.Poly) {
+
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	evaluator.decomposer.DecomposeAndSplit(level, beta, cx, c2QiQ, c2QiP)
+
+	p0idxst := beta * evaluator.params.alpha
+	p0idxed := p0idxst + evaluator.decomposer.Xalpha()[beta]
+
+	// c2QiQ = cx mod qi mod qj
+	for x := uint64(0); x < level+1; x++ {
+
+		if p0idxst <= x && x < p0idxed {
+			copy(c2QiQ.Coeffs[x], c2NTT.Coeffs[x])
+		} else {
+			ring.NTT(c2QiQ.Coeffs[x], c2QiQ.Coeffs[x], contextQ.N, contextQ.GetNttPsi()[x], contextQ.Modulus[x], contextQ.GetMredParams()[x], contextQ.GetBredParams()[x])
+		}
+	}
+
+	// c2QiP = cx mod qi mod pj
+	contextP.NTT(c2QiP, c2QiP)
+}
+
+// mulKeyAndAdd multiplies the decomposed polynomial (c2QiQ, c2QiP) by the switching key and adds the result
+// on (p0, p0P) and (p1, p1P) without modular reduction.
+func (evaluator *evaluator) mulKeyAndAdd(level uint64, evakey [2]*ring.Poly, c2QiQ, c2QiP, p0, p1, p0P, p1P *ring.Poly) {
+
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey[0], c2QiQ, p0)
+	contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey[1], c2QiQ, p1)
+
+	// The residues of the keys modulo the special primes are stored after the residues modulo the Qi
+	for j, keysindex := 0, len(contextQ.Modulus); j < len(contextP.Modulus); j, keysindex = j+1, keysindex+1 {
+
+		pj := contextP.Modulus[j]
+		mredParams := contextP.GetMredParams()[j]
+
+		key0 := evakey[0].Coeffs[keysindex]
+		key1 := evakey[1].Coeffs[keysindex]
+		c2tmp := c2QiP.Coeffs[j]
+		p0tmp := p0P.Coeffs[j]
+		p1tmp := p1P.Coeffs[j]
+
+		for y := uint64(0); y < contextP.N; y++ {
+			p0tmp[y] += ring.MRed(key0[y], c2tmp[y], pj, mredParams)
+			p1tmp[y] += ring.MRed(key1[y], c2tmp[y], pj, mredParams)
+		}
+	}
+}
+
+// modDown switches (p0, p0P) and (p1, p1P) outside of the NTT domain and divides them by P,
+// returning the result in p0 and p1.
+func (evaluator *evaluator) modDown(level uint64, p0, p0P, p1, p1P *ring.Poly) {
+
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	contextQ.InvNTTLvl(level, p0, p0)
+	contextQ.InvNTTLvl(level, p1, p1)
+	contextP.InvNTT(p0P, p0P)
+	contextP.InvNTT(p1P, p1P)
+
+	evaluator.baseconverterQ1P.ModDownSplitedPQ(level, p0, p0P, p0)
+	evaluator.baseconverterQ1P.ModDownSplitedPQ(level, p1, p1P, p1)
+}
+
+// RotateHoisted takes an input Ciphertext and a list of rotations and returns a map of Ciphertext, where each element of the map is the input Ciphertext
+// with its columns rotated to the left by one element of the list. The decomposition of the input Ciphertext is computed only once and
+// shared across all the rotations, which makes it much faster than sequential calls to RotateColumns.
+// The rotation keys of all the specific (non-zero) rotations must be provided. The output Ciphertexts are at the level of ct0.
+func (evaluator *evaluator) RotateHoisted(ct0 *Ciphertext, rotations []uint64, rotkeys *RotationKeys) (cOut map[uint64]*Ciphertext) {
+
+	rotkeys = evaluator.getRotationKeys(rotkeys)
+
+	if ct0.Degree() != 1 {
+		panic("cannot RotateHoisted: input must be of degree 1")
+	}
+
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	level := ct0.Level()
+
+	cOut = make(map[uint64]*Ciphertext)
+
+	var c2QiQNTT, c2QiPNTT []*ring.Poly
+
+	for _, n := range rotations {
+
+		if _, ok := cOut[n]; ok {
+			continue
+		}
+
+		k := n & ((evaluator.bfvContext.n >> 1) - 1)
+
+		cOut[n] = NewCiphertextLvl(evaluator.params, 1, level)
+
+		if k == 0 {
+			cOut[n].Copy(ct0.Element())
+			continue
+		}
+
+		if rotkeys.evakeyRotColLeft[k] == nil {
+			panic("cannot RotateHoisted: specific rotation has not been generated")
+		}
+
+		// The decomposition with the special primes cannot be shared with the keys using a gadget decomposition
+		if rotkeys.evakeyRotColLeft[k].logBase != 0 {
+			evaluator.permute(ct0, evaluator.bfvContext.galElRotColLeft[k], rotkeys.evakeyRotColLeft[k], cOut[n])
+			continue
+		}
+
+		// The decomposition is only computed if at least one non-trivial rotation is requested
+		if c2QiQNTT == nil {
 
-			for y := uint64(0); y < context.N; y++ {
-				p2tmp[y] += ring.MRed(key0[y], c2QiNtt[y], qi, mredParams)
-				p3tmp[y] += ring.MRed(key1[y], c2QiNtt[y], qi, mredParams)
+			beta := uint64(math.Ceil(float64(level+1) / float64(evaluator.params.alpha)))
+
+			c2QiQNTT = make([]*ring.Poly, beta)
+			c2QiPNTT = make([]*ring.Poly, beta)
+
+			c2 := evaluator.keyswitchpoolQ[1]
+			contextQ.NTTLvl(level, ct0.value[1], c2)
+
+			for i := range c2QiQNTT {
+				c2QiQNTT[i] = contextQ.NewPoly()
+				c2QiPNTT[i] = contextP.NewPoly()
+				evaluator.decomposeAndSplitNTT(level, uint64(i), c2, ct0.value[1], c2QiQNTT[i], c2QiPNTT[i])
 			}
 		}
 
+		galEl := evaluator.bfvContext.galElRotColLeft[k]
+
+		p0 := evaluator.keyswitchpoolQ[2]
+		p1 := evaluator.keyswitchpoolQ[3]
+
+		evaluator.keySwitchHoisted(level, c2QiQNTT, c2QiPNTT, ring.PermuteNTTIndex(galEl, 1, contextQ.N), rotkeys.evakeyRotColLeft[k], p0, p1)
+
+		contextQ.PermuteLvl(level, ct0.value[0], galEl, cOut[n].value[0])
+		contextQ.AddLvl(level, cOut[n].value[0], p0, cOut[n].value[0])
+		contextQ.CopyLvl(level, p1, cOut[n].value[1])
+	}
+
+	return
+}
+
+// keySwitchHoisted applies the key-switching procedure on the automorphism of cx given by the NTT permutation index, taking as input the
+// decomposition of cx computed by decomposeAndSplitNTT, and returns the result in p0 and p1 (outside of the NTT domain).
+func (evaluator *evaluator) keySwitchHoisted(level uint64, c2QiQNTT, c2QiPNTT []*ring.Poly, index []uint64, evakey *SwitchingKey, p0, p1 *ring.Poly) {
+
+	var reduce uint64
+
+	contextQ := evaluator.bfvContext.contextQ
+	contextP := evaluator.bfvContext.contextP
+
+	c2QiQPermuted := evaluator.keyswitchpoolQ[4]
+	c2QiPPermuted := evaluator.keyswitchpoolP[3]
+
+	p0P := evaluator.keyswitchpoolP[1]
+	p1P := evaluator.keyswitchpoolP[2]
+
+	p0.Zero()
+	p1.Zero()
+	p0P.Zero()
+	p1P.Zero()
+
+	for i := range c2QiQNTT {
+
+		ring.PermuteNTTWithIndex(c2QiQNTT[i], index, c2QiQPermuted)
+		ring.PermuteNTTWithIndex(c2QiPNTT[i], index, c2QiPPermuted)
+
+		evaluator.mulKeyAndAdd(level, evakey.evakey[i], c2QiQPermuted, c2QiPPermuted, p0, p1, p0P, p1P)
+
 		if reduce&7 == 7 {
-			contextKeys.Reduce(p0, p0)
-			contextKeys.Reduce(p1, p1)
+			contextQ.ReduceLvl(level, p0, p0)
+			contextQ.ReduceLvl(level, p1, p1)
+			contextP.Reduce(p0P, p0P)
+			contextP.Reduce(p1P, p1P)
 		}
 
 		reduce++
 	}
 
 	if (reduce-1)&7 != 7 {
-		contextKeys.Reduce(p0, p0)
-		contextKeys.Reduce(p1, p1)
+		contextQ.ReduceLvl(level, p0, p0)
+		contextQ.ReduceLvl(level, p1, p1)
+		contextP.Reduce(p0P, p0P)
+		contextP.Reduce(p1P, p1P)
 	}
 
-	contextKeys.InvNTT(p0, p0)
-	contextKeys.InvNTT(p1, p1)
+	evaluator.modDown(level, p0, p0P, p1, p1P)
+}
+
+// ModSwitch divides ct0 by the last modulus of its moduli chain (with rounding), and returns the result in ctOut, whose level
+// is set to the level of ct0 minus one. This scales the message from Q_l/T to Q_{l-1}/T and reduces the noise accordingly.
+// Returns an error if ct0 is already at level 0. The level of ctOut must be at least the level of ct0.
+func (evaluator *evaluator) ModSwitch(ct0, ctOut *Ciphertext) (err error) {
+
+	if ct0.Level() == 0 {
+		return newError("ModSwitch", ErrLevelMismatch, "input Ciphertext already at level 0")
+	}
+
+	if ct0.Level() > ctOut.Level() {
+		return newError("ModSwitch", ErrLevelMismatch, "receiver Ciphertext level is smaller than the input Ciphertext level")
+	}
+
+	if ct0.Degree() != ctOut.Degree() {
+		return newError("ModSwitch", ErrDegreeMismatch, "degrees of receiver Ciphertext and input Ciphertext do not match")
+	}
+
+	ctOut.Copy(ct0.Element())
+	ctOut.seed = nil
+
+	for i := range ctOut.value {
+		evaluator.bfvContext.contextQ.DivRoundByLastModulus(ctOut.value[i])
+	}
+
+	return nil
+}
+
+// ModSwitchNew divides ct0 by the last modulus of its moduli chain (with rounding), and returns the result in a newly created element.
+// Returns an error if ct0 is already at level 0.
+func (evaluator *evaluator) ModSwitchNew(ct0 *Ciphertext) (ctOut *Ciphertext, err error) {
+	ctOut = NewCiphertextLvl(evaluator.params, ct0.Degree(), ct0.Level())
+	return ctOut, evaluator.ModSwitch(ct0, ctOut)
+}
+
+// DropLevelNew reduces the level of ct0 by levels, applying the corresponding modulus switchings, and returns the result in a newly
+// created element.
+func (evaluator *evaluator) DropLevelNew(ct0 *Ciphertext, levels uint64) (ctOut *Ciphertext, err error) {
+	ctOut = NewCiphertextLvl(evaluator.params, ct0.Degree(), ct0.Level())
+	ctOut.Copy(ct0.Element())
+	return ctOut, evaluator.DropLevel(ctOut, levels)
+}
+
+// DropLevel reduces the level of ct0 by levels, applying the corresponding modulus switchings, and returns the result in ct0.
+// Returns an error if the level of ct0 is smaller than levels.
+func (evaluator *evaluator) DropLevel(ct0 *Ciphertext, levels uint64) (err error) {
+
+	if ct0.Level() < levels {
+		return newError("DropLevel", ErrLevelMismatch, "Ciphertext level is smaller than the number of levels to drop")
+	}
+
+	for i := uint64(0); i < levels; i++ {
+		if err = evaluator.ModSwitch(ct0, ct0); err != nil {
+			return
+		}
+	}
 
-	evaluator.baseconverterQ1P.ModDownPQ(level, p0, p0)
-	evaluator.baseconverterQ1P.ModDownPQ(level, p1, p1)
+	return nil
 }
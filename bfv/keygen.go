@@ -2,6 +2,7 @@ package bfv
 
 import (
 	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
 )
 
 // KeyGenerator is an interface implementing the methods of the keyGenerator.
@@ -14,14 +15,21 @@ type KeyGenerator interface {
 	GenSwitchingKey(skIn, skOut *SecretKey) (evk *SwitchingKey)
 	GenRot(rotType Rotation, sk *SecretKey, k uint64, rotKey *RotationKeys)
 	GenRotationKeysPow2(sk *SecretKey) (rotKey *RotationKeys)
+	GenRelinKeyGadget(sk *SecretKey, maxDegree, logBase uint64) (evk *EvaluationKey)
+	GenSwitchingKeyGadget(skIn, skOut *SecretKey, logBase uint64) (evk *SwitchingKey)
+	GenRotGadget(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys)
+	GenRotationKeysPow2Gadget(sk *SecretKey, logBase uint64) (rotKey *RotationKeys)
 }
 
 // keyGenerator is a structure that stores the elements required to create new keys,
 // as well as a small memory pool for intermediate values.
 type keyGenerator struct {
-	params     *Parameters
-	bfvContext *bfvContext
-	polypool   *ring.Poly
+	params           *Parameters
+	bfvContext       *bfvContext
+	gaussianSamplerQ *ring.KYSampler
+	crpGeneratorQ    *ring.CRPGenerator
+	crpGeneratorQP   *ring.CRPGenerator
+	polypool         *ring.Poly
 }
 
 // SecretKey is a structure that stores the SecretKey.
@@ -57,8 +65,14 @@ type EvaluationKey struct {
 }
 
 // SwitchingKey is a structure that stores the switching-keys required during the key-switching.
+// It either uses the RNS decomposition with the special primes Pi (logBase = 0), or the RNS decomposition combined with
+// a digit decomposition of each Qi in base 2^logBase, in which case its elements are in basis Qi only.
+// The SwitchingKeys generated by a KeyGenerator store the seed from which their uniform elements were generated, so
+// that they can be compressed (see SwitchingKey.MarshalBinaryCompressed).
 type SwitchingKey struct {
-	evakey [][2]*ring.Poly
+	evakey  [][2]*ring.Poly
+	logBase uint64
+	seed    []byte
 }
 
 // Get returns the switching key backing slice.
@@ -66,20 +80,39 @@ func (swk *SwitchingKey) Get() [][2]*ring.Poly {
 	return swk.evakey
 }
 
+// LogBase returns the logarithm of the base of the gadget decomposition of the target SwitchingKey, or 0 if it
+// uses the RNS decomposition with the special primes Pi.
+func (swk *SwitchingKey) LogBase() uint64 {
+	return swk.logBase
+}
+
+// TryNewKeyGenerator is the same as NewKeyGenerator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewKeyGenerator(params *Parameters) (KeyGenerator, error) {
+
+	if err := checkParameters("NewKeyGenerator", params); err != nil {
+		return nil, err
+	}
+
+	return NewKeyGenerator(params), nil
+}
+
 // NewKeyGenerator creates a new KeyGenerator, from which the secret and public keys, as well as the evaluation,
 // rotation and switching keys can be generated.
 func NewKeyGenerator(params *Parameters) KeyGenerator {
 
-	if !params.isValid {
-		panic("cannot NewKeyGenerator: params not valid (check if they were generated properly)")
+	if err := checkParameters("NewKeyGenerator", params); err != nil {
+		panic(err)
 	}
 
 	bfvContext := newBFVContext(params)
 
 	return &keyGenerator{
-		params:     params.Copy(),
-		bfvContext: bfvContext,
-		polypool:   bfvContext.contextQP.NewPoly(),
+		params:           params.Copy(),
+		bfvContext:       bfvContext,
+		gaussianSamplerQ: bfvContext.contextQ.NewKYSampler(params.Sigma, int(6*params.Sigma)),
+		crpGeneratorQ:    ring.NewCRPGenerator(nil, bfvContext.contextQ),
+		crpGeneratorQP:   ring.NewCRPGenerator(nil, bfvContext.contextQP),
+		polypool:         bfvContext.contextQP.NewPoly(),
 	}
 }
 
@@ -95,11 +128,21 @@ func (keygen *keyGenerator) GenSecretkeyWithDistrib(p float64) (sk *SecretKey) {
 	return sk
 }
 
+// TryNewSecretKey is the same as NewSecretKey, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewSecretKey(params *Parameters) (*SecretKey, error) {
+
+	if err := checkParameters("NewSecretKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewSecretKey(params), nil
+}
+
 // NewSecretKey generates a new SecretKey with zero values.
 func NewSecretKey(params *Parameters) *SecretKey {
 
-	if !params.isValid {
-		panic("cannot NewSecretKey: params not valid (check if they were generated properly)")
+	if err := checkParameters("NewSecretKey", params); err != nil {
+		panic(err)
 	}
 
 	sk := new(SecretKey)
@@ -135,11 +178,21 @@ func (keygen *keyGenerator) GenPublicKey(sk *SecretKey) (pk *PublicKey) {
 	return pk
 }
 
+// TryNewPublicKey is the same as NewPublicKey, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewPublicKey(params *Parameters) (*PublicKey, error) {
+
+	if err := checkParameters("NewPublicKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewPublicKey(params), nil
+}
+
 // NewPublicKey returns a new PublicKey with zero values.
 func NewPublicKey(params *Parameters) (pk *PublicKey) {
 
-	if !params.isValid {
-		panic("cannot NewPublicKey: params not valid (check if they were generated properly)")
+	if err := checkParameters("NewPublicKey", params); err != nil {
+		panic(err)
 	}
 
 	pk = new(PublicKey)
@@ -195,11 +248,22 @@ func (keygen *keyGenerator) GenRelinKey(sk *SecretKey, maxDegree uint64) (evk *E
 	return
 }
 
+// TryNewRelinKey is the same as NewRelinKey, but returns an Error instead of panicking if the parameters are invalid or
+// do not have special primes Pi.
+func TryNewRelinKey(params *Parameters, maxDegree uint64) (*EvaluationKey, error) {
+
+	if err := checkSpecialPrimes("NewRelinKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewRelinKey(params, maxDegree), nil
+}
+
 // NewRelinKey creates a new EvaluationKey with zero values.
 func NewRelinKey(params *Parameters, maxDegree uint64) (evakey *EvaluationKey) {
 
-	if !params.isValid {
-		panic("cannot NewRelinKey: params not valid (check if they were generated properly)")
+	if err := checkSpecialPrimes("NewRelinKey", params); err != nil {
+		panic(err)
 	}
 
 	evakey = new(EvaluationKey)
@@ -224,6 +288,44 @@ func NewRelinKey(params *Parameters, maxDegree uint64) (evakey *EvaluationKey) {
 	return
 }
 
+// GenRelinKeyGadget is the same as GenRelinKey, but generates an evaluation key using the gadget decomposition of
+// base 2^logBase (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRelinKeyGadget(sk *SecretKey, maxDegree, logBase uint64) (evk *EvaluationKey) {
+
+	evk = new(EvaluationKey)
+
+	evk.evakey = make([]*SwitchingKey, maxDegree)
+
+	keygen.polypool.Copy(sk.Get())
+
+	for i := uint64(0); i < maxDegree; i++ {
+		keygen.bfvContext.contextQ.MulCoeffsMontgomery(keygen.polypool, sk.Get(), keygen.polypool)
+		evk.evakey[i] = keygen.newSwitchingKeyGadget(keygen.polypool, sk.Get(), logBase)
+	}
+
+	keygen.polypool.Zero()
+
+	return
+}
+
+// NewRelinKeyGadget creates a new EvaluationKey with zero values, using the gadget decomposition of base 2^logBase.
+func NewRelinKeyGadget(params *Parameters, maxDegree, logBase uint64) (evakey *EvaluationKey) {
+
+	if !params.isValid {
+		panic("cannot NewRelinKeyGadget: params not valid (check if they were generated properly)")
+	}
+
+	evakey = new(EvaluationKey)
+
+	evakey.evakey = make([]*SwitchingKey, maxDegree)
+
+	for w := uint64(0); w < maxDegree; w++ {
+		evakey.evakey[w] = NewSwitchingKeyGadget(params, logBase)
+	}
+
+	return
+}
+
 // Get returns the slice of SwitchingKeys of the target EvaluationKey.
 func (evk *EvaluationKey) Get() []*SwitchingKey {
 	return evk.evakey
@@ -231,16 +333,31 @@ func (evk *EvaluationKey) Get() []*SwitchingKey {
 
 // SetRelinKeys sets the polynomial of the target EvaluationKey as the input polynomials.
 func (evk *EvaluationKey) SetRelinKeys(rlk [][][2]*ring.Poly) {
+	evk.SetRelinKeysGadget(rlk, 0)
+}
+
+// SetRelinKeysGadget sets the polynomial of the target EvaluationKey as the input polynomials, which are the elements of
+// a gadget decomposition of base 2^logBase (see GenRelinKeyGadget).
+func (evk *EvaluationKey) SetRelinKeysGadget(rlk [][][2]*ring.Poly, logBase uint64) {
 
 	evk.evakey = make([]*SwitchingKey, len(rlk))
 	for i := range rlk {
-		evk.evakey[i] = new(SwitchingKey)
-		evk.evakey[i].evakey = make([][2]*ring.Poly, len(rlk[i]))
-		for j := range rlk[i] {
-			evk.evakey[i].evakey[j][0] = rlk[i][j][0].CopyNew()
-			evk.evakey[i].evakey[j][1] = rlk[i][j][1].CopyNew()
-		}
+		evk.evakey[i] = newSwitchingKeyFromPolys(rlk[i], logBase)
+	}
+}
+
+// newSwitchingKeyFromPolys creates a new SwitchingKey storing a copy of the input polynomials.
+func newSwitchingKeyFromPolys(evakey [][2]*ring.Poly, logBase uint64) (swk *SwitchingKey) {
+
+	swk = new(SwitchingKey)
+	swk.logBase = logBase
+	swk.evakey = make([][2]*ring.Poly, len(evakey))
+	for j := range evakey {
+		swk.evakey[j][0] = evakey[j][0].CopyNew()
+		swk.evakey[j][1] = evakey[j][1].CopyNew()
 	}
+
+	return
 }
 
 // GenSwitchingKey generates a new key-switching key, that will allow to re-encrypt under the output-key a ciphertext encrypted under the input-key.
@@ -260,11 +377,22 @@ func (keygen *keyGenerator) GenSwitchingKey(skIn, skOut *SecretKey) (evk *Switch
 	return
 }
 
+// TryNewSwitchingKey is the same as NewSwitchingKey, but returns an Error instead of panicking if the parameters are
+// invalid or do not have special primes Pi.
+func TryNewSwitchingKey(params *Parameters) (*SwitchingKey, error) {
+
+	if err := checkSpecialPrimes("NewSwitchingKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewSwitchingKey(params), nil
+}
+
 // NewSwitchingKey returns a new SwitchingKey with zero values.
 func NewSwitchingKey(params *Parameters) (evakey *SwitchingKey) {
 
-	if !params.isValid {
-		panic("cannot NewSwitchingKey: params not valid (check if they were generated properly)")
+	if err := checkSpecialPrimes("NewSwitchingKey", params); err != nil {
+		panic(err)
 	}
 
 	evakey = new(SwitchingKey)
@@ -282,6 +410,81 @@ func NewSwitchingKey(params *Parameters) (evakey *SwitchingKey) {
 	return
 }
 
+// GenSwitchingKeyGadget generates a new key-switching key, that will allow to re-encrypt under the output-key a ciphertext
+// encrypted under the input-key, using the RNS decomposition combined with a digit decomposition in base 2^logBase of each Qi
+// instead of the special primes Pi. The key has GadgetDigits(logBase) elements per Qi, and the noise added by the key-switching
+// grows with 2^logBase.
+func (keygen *keyGenerator) GenSwitchingKeyGadget(skIn, skOut *SecretKey, logBase uint64) (evk *SwitchingKey) {
+	return keygen.newSwitchingKeyGadget(skIn.Get(), skOut.Get(), logBase)
+}
+
+// NewSwitchingKeyGadget returns a new SwitchingKey with zero values, using the gadget decomposition of base 2^logBase.
+func NewSwitchingKeyGadget(params *Parameters, logBase uint64) (evakey *SwitchingKey) {
+
+	if !params.isValid {
+		panic("cannot NewSwitchingKeyGadget: params not valid (check if they were generated properly)")
+	}
+
+	evakey = new(SwitchingKey)
+	evakey.logBase = logBase
+
+	evakey.evakey = make([][2]*ring.Poly, uint64(len(params.Qi))*params.GadgetDigits(logBase))
+
+	for i := range evakey.evakey {
+		evakey.evakey[i][0] = params.NewPolyQ()
+		evakey.evakey[i][1] = params.NewPolyQ()
+	}
+
+	return
+}
+
+// newSwitchingKeyGadget generates the elements [-a*skOut + skIn * (qiBarre*qiStar) * 2^(logBase*j) + e, a] in basis Q,
+// for each Qi and each digit j.
+func (keygen *keyGenerator) newSwitchingKeyGadget(skIn, skOut *ring.Poly, logBase uint64) (switchkey *SwitchingKey) {
+
+	ringContext := keygen.bfvContext.contextQ
+
+	digits := keygen.params.GadgetDigits(logBase)
+
+	switchkey = new(SwitchingKey)
+	switchkey.logBase = logBase
+	switchkey.evakey = make([][2]*ring.Poly, uint64(len(ringContext.Modulus))*digits)
+	switchkey.seed = utils.NewSeed()
+
+	keygen.crpGeneratorQ.Seed(switchkey.seed)
+
+	for i, qi := range ringContext.Modulus {
+
+		bredParams := ringContext.GetBredParams()[i]
+
+		for j := uint64(0); j < digits; j++ {
+
+			index := uint64(i)*digits + j
+
+			// e
+			switchkey.evakey[index][0] = keygen.gaussianSamplerQ.SampleNTTNew()
+			ringContext.MForm(switchkey.evakey[index][0], switchkey.evakey[index][0])
+			// a
+			switchkey.evakey[index][1] = keygen.crpGeneratorQ.ClockNew()
+
+			// e + skIn * (qiBarre*qiStar) * 2^(logBase*j)
+			// (qiBarre*qiStar)%qi = 1, else 0
+			pow2 := ring.ModExp(2, logBase*j, qi)
+			p0tmp := skIn.Coeffs[i]
+			p1tmp := switchkey.evakey[index][0].Coeffs[i]
+
+			for w := uint64(0); w < ringContext.N; w++ {
+				p1tmp[w] = ring.CRed(p1tmp[w]+ring.BRed(p0tmp[w], pow2, qi, bredParams), qi)
+			}
+
+			// skIn * (qiBarre*qiStar) * 2^(logBase*j) - a*sk + e
+			ringContext.MulCoeffsMontgomeryAndSub(switchkey.evakey[index][1], skOut, switchkey.evakey[index][0])
+		}
+	}
+
+	return
+}
+
 func (keygen *keyGenerator) newswitchingkey(skIn, skOut *ring.Poly) (switchkey *SwitchingKey) {
 
 	switchkey = new(SwitchingKey)
@@ -294,6 +497,9 @@ func (keygen *keyGenerator) newswitchingkey(skIn, skOut *ring.Poly) (switchkey *
 	// delta_sk = skIn - skOut = GaloisEnd(skOut, rotation) - skOut
 
 	switchkey.evakey = make([][2]*ring.Poly, keygen.params.beta)
+	switchkey.seed = utils.NewSeed()
+
+	keygen.crpGeneratorQP.Seed(switchkey.seed)
 
 	for i := uint64(0); i < keygen.params.beta; i++ {
 
@@ -301,7 +507,7 @@ func (keygen *keyGenerator) newswitchingkey(skIn, skOut *ring.Poly) (switchkey *
 		switchkey.evakey[i][0] = bfvContext.gaussianSampler.SampleNTTNew()
 		ringContext.MForm(switchkey.evakey[i][0], switchkey.evakey[i][0])
 		// a
-		switchkey.evakey[i][1] = ringContext.NewUniformPoly()
+		switchkey.evakey[i][1] = keygen.crpGeneratorQP.ClockNew()
 
 		// e + skIn * (qiBarre*qiStar) * 2^w
 		// (qiBarre*qiStar)%qi = 1, else 0
@@ -345,6 +551,17 @@ func (keygen *keyGenerator) GenRot(rotType Rotation, sk *SecretKey, k uint64, ro
 		panic("Cannot GenRelinKey: modulus P is empty")
 	}
 
+	keygen.genRot(rotType, sk, k, 0, rotKey)
+}
+
+// GenRotGadget is the same as GenRot, but generates the SwitchingKey using the gadget decomposition of base 2^logBase
+// (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRotGadget(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys) {
+	keygen.genRot(rotType, sk, k, logBase, rotKey)
+}
+
+func (keygen *keyGenerator) genRot(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys) {
+
 	k &= ((keygen.bfvContext.n >> 1) - 1)
 
 	switch rotType {
@@ -353,23 +570,33 @@ func (keygen *keyGenerator) GenRot(rotType Rotation, sk *SecretKey, k uint64, ro
 			rotKey.evakeyRotColLeft = make(map[uint64]*SwitchingKey)
 		}
 		if rotKey.evakeyRotColLeft[k] == nil && k != 0 {
-			rotKey.evakeyRotColLeft[k] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColLeft[k])
+			rotKey.evakeyRotColLeft[k] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColLeft[k], logBase)
 		}
 	case RotationRight:
 		if rotKey.evakeyRotColRight == nil {
 			rotKey.evakeyRotColRight = make(map[uint64]*SwitchingKey)
 		}
 		if rotKey.evakeyRotColRight[k] == nil && k != 0 {
-			rotKey.evakeyRotColRight[k] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColRight[k])
+			rotKey.evakeyRotColRight[k] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColRight[k], logBase)
 		}
 	case RotationRow:
-		rotKey.evakeyRotRow = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotRow)
+		rotKey.evakeyRotRow = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotRow, logBase)
 	}
 }
 
 // GenRotationKeysPow2 generates a new struct of RotationKeys that stores the keys of all the left and right powers of two rotations. The provided SecretKey must be the SecretKey used to generate the PublicKey under
 // which the ciphertexts to rotate are encrypted under. rows is a boolean value that indicates if the keys for the row rotation have to be generated.
 func (keygen *keyGenerator) GenRotationKeysPow2(sk *SecretKey) (rotKey *RotationKeys) {
+	return keygen.genRotationKeysPow2(sk, 0)
+}
+
+// GenRotationKeysPow2Gadget is the same as GenRotationKeysPow2, but generates the SwitchingKeys using the gadget decomposition
+// of base 2^logBase (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRotationKeysPow2Gadget(sk *SecretKey, logBase uint64) (rotKey *RotationKeys) {
+	return keygen.genRotationKeysPow2(sk, logBase)
+}
+
+func (keygen *keyGenerator) genRotationKeysPow2(sk *SecretKey, logBase uint64) (rotKey *RotationKeys) {
 
 	rotKey = new(RotationKeys)
 
@@ -378,63 +605,58 @@ func (keygen *keyGenerator) GenRotationKeysPow2(sk *SecretKey) (rotKey *Rotation
 
 	for n := uint64(1); n < keygen.bfvContext.n>>1; n <<= 1 {
 
-		rotKey.evakeyRotColLeft[n] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColLeft[n])
-		rotKey.evakeyRotColRight[n] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColRight[n])
+		rotKey.evakeyRotColLeft[n] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColLeft[n], logBase)
+		rotKey.evakeyRotColRight[n] = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotColRight[n], logBase)
 	}
 
-	rotKey.evakeyRotRow = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotRow)
+	rotKey.evakeyRotRow = genrotkey(keygen, sk.Get(), keygen.bfvContext.galElRotRow, logBase)
 
 	return
 }
 
 // SetRotKey populates the target RotationKeys with a new SwitchingKey using the input polynomials.
 func (rotKey *RotationKeys) SetRotKey(rotType Rotation, k uint64, evakey [][2]*ring.Poly) {
+	rotKey.SetRotKeyGadget(rotType, k, 0, evakey)
+}
+
+// SetRotKeyGadget populates the target RotationKeys with a new SwitchingKey using the input polynomials, which are the
+// elements of a gadget decomposition of base 2^logBase (see GenRotGadget).
+func (rotKey *RotationKeys) SetRotKeyGadget(rotType Rotation, k, logBase uint64, evakey [][2]*ring.Poly) {
 	switch rotType {
 	case RotationLeft:
 		if rotKey.evakeyRotColLeft == nil {
 			rotKey.evakeyRotColLeft = make(map[uint64]*SwitchingKey)
 		}
 		if rotKey.evakeyRotColLeft[k] == nil && k != 0 {
-			rotKey.evakeyRotColLeft[k] = new(SwitchingKey)
-			rotKey.evakeyRotColLeft[k].evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyRotColLeft[k].evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyRotColLeft[k].evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyRotColLeft[k] = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 	case RotationRight:
 		if rotKey.evakeyRotColRight == nil {
 			rotKey.evakeyRotColRight = make(map[uint64]*SwitchingKey)
 		}
 		if rotKey.evakeyRotColRight[k] == nil && k != 0 {
-			rotKey.evakeyRotColRight[k] = new(SwitchingKey)
-			rotKey.evakeyRotColRight[k].evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyRotColRight[k].evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyRotColRight[k].evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyRotColRight[k] = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 	case RotationRow:
 		if rotKey.evakeyRotRow == nil {
-			rotKey.evakeyRotRow = new(SwitchingKey)
-			rotKey.evakeyRotRow.evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyRotRow.evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyRotRow.evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyRotRow = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 	}
 }
 
-func genrotkey(keygen *keyGenerator, sk *ring.Poly, gen uint64) (switchkey *SwitchingKey) {
+func genrotkey(keygen *keyGenerator, sk *ring.Poly, gen, logBase uint64) (switchkey *SwitchingKey) {
 
 	ringContext := keygen.bfvContext.contextQP
 
 	ring.PermuteNTT(sk, gen, keygen.polypool)
 
-	ringContext.MulScalarBigint(keygen.polypool, keygen.bfvContext.contextP.ModulusBigint, keygen.polypool)
+	if logBase != 0 {
+		switchkey = keygen.newSwitchingKeyGadget(keygen.polypool, sk, logBase)
+	} else {
+		ringContext.MulScalarBigint(keygen.polypool, keygen.bfvContext.contextP.ModulusBigint, keygen.polypool)
+		switchkey = keygen.newswitchingkey(keygen.polypool, sk)
+	}
 
-	switchkey = keygen.newswitchingkey(keygen.polypool, sk)
 	keygen.polypool.Zero()
 
 	return
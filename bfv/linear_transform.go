@@ -0,0 +1,248 @@
+package bfv
+
+import (
+	"github.com/ldsec/lattigo/utils"
+)
+
+// LinearTransform is a struct storing a plaintext matrix given by its non-zero diagonals, pre-encoded on Plaintexts,
+// which can be homomorphically multiplied with an encrypted vector. The slots are seen as a matrix of 2 rows of N/2
+// columns, and the matrix is applied independently on each row. The diagonal of index k is the vector
+// (M[0][k], M[1][k+1], ..., M[N/2-1][k+N/2-1]), where the indexes are taken modulo N/2.
+type LinearTransform struct {
+	n1  uint64 // Size of the baby-step (0 if the baby-step giant-step algorithm is not used)
+	vec map[uint64]*Plaintext
+}
+
+// TryNewLinearTransform is the same as NewLinearTransform, but returns an Error instead of panicking if the parameters
+// or the diagonals are invalid.
+func TryNewLinearTransform(params *Parameters, encoder Encoder, diagMatrix map[uint64][]uint64) (*LinearTransform, error) {
+
+	if _, err := checkDiagMatrix("NewLinearTransform", params, diagMatrix); err != nil {
+		return nil, err
+	}
+
+	return NewLinearTransform(params, encoder, diagMatrix), nil
+}
+
+// NewLinearTransform encodes the non-zero diagonals of a matrix, indexed by their rotation to the left, on Plaintexts.
+// The resulting LinearTransform is evaluated with one hoisted rotation per diagonal.
+// A diagonal of size N/2 is applied on both rows of the slots, and a diagonal of size N gives a distinct
+// diagonal for each row (the first N/2 values for the first row and the last N/2 values for the second row).
+func NewLinearTransform(params *Parameters, encoder Encoder, diagMatrix map[uint64][]uint64) (lt *LinearTransform) {
+
+	slots, err := checkDiagMatrix("NewLinearTransform", params, diagMatrix)
+	if err != nil {
+		panic(err)
+	}
+
+	lt = &LinearTransform{
+		vec: make(map[uint64]*Plaintext),
+	}
+
+	values := make([]uint64, slots<<1)
+
+	for k, diag := range diagMatrix {
+		k &= slots - 1
+		for i := uint64(0); i < slots; i++ {
+			values[i] = diag[i]
+			values[i+slots] = diag[i+uint64(len(diag))-slots]
+		}
+		lt.vec[k] = NewPlaintext(params)
+		encoder.EncodeUint(values, lt.vec[k])
+	}
+
+	return
+}
+
+// TryNewLinearTransformBSGS is the same as NewLinearTransformBSGS, but returns an Error instead of panicking if the
+// parameters or the diagonals are invalid.
+func TryNewLinearTransformBSGS(params *Parameters, encoder Encoder, diagMatrix map[uint64][]uint64) (*LinearTransform, error) {
+
+	if _, err := checkDiagMatrix("NewLinearTransformBSGS", params, diagMatrix); err != nil {
+		return nil, err
+	}
+
+	return NewLinearTransformBSGS(params, encoder, diagMatrix), nil
+}
+
+// NewLinearTransformBSGS encodes the non-zero diagonals of a matrix, indexed by their rotation to the left, on Plaintexts.
+// The resulting LinearTransform is evaluated with the baby-step giant-step algorithm, which requires much fewer
+// rotations (and rotation keys) than NewLinearTransform when the matrix has many non-zero diagonals.
+// The diagonals follow the same layout as for NewLinearTransform.
+func NewLinearTransformBSGS(params *Parameters, encoder Encoder, diagMatrix map[uint64][]uint64) (lt *LinearTransform) {
+
+	slots, err := checkDiagMatrix("NewLinearTransformBSGS", params, diagMatrix)
+	if err != nil {
+		panic(err)
+	}
+
+	lt = &LinearTransform{
+		vec: make(map[uint64]*Plaintext),
+	}
+
+	diags := make([]uint64, 0, len(diagMatrix))
+	for k := range diagMatrix {
+		diags = append(diags, k&(slots-1))
+	}
+
+	lt.n1 = utils.FindBestBSGSSplit(diags, slots)
+
+	values := make([]uint64, slots<<1)
+
+	for k, diag := range diagMatrix {
+
+		k &= slots - 1
+
+		// The diagonals are pre-rotated to the right by the giant-step, which can then be factored out
+		giant := k - (k % lt.n1)
+
+		for i := uint64(0); i < slots; i++ {
+			values[i] = diag[(i+slots-giant)&(slots-1)]
+			values[i+slots] = diag[((i+slots-giant)&(slots-1))+uint64(len(diag))-slots]
+		}
+
+		lt.vec[k] = NewPlaintext(params)
+		encoder.EncodeUint(values, lt.vec[k])
+	}
+
+	return
+}
+
+// Rotations returns the list of column rotations to the left for which a rotation key must be generated
+// (with KeyGenerator.GenRot and RotationLeft) to evaluate the LinearTransform.
+func (lt *LinearTransform) Rotations() (rotations []uint64) {
+
+	if lt.n1 == 0 {
+		return utils.NaiveRotations(lt.diags())
+	}
+
+	return utils.BSGSRotations(lt.diags(), lt.n1)
+}
+
+// diags returns the indexes of the non-zero diagonals of the LinearTransform.
+func (lt *LinearTransform) diags() (diags []uint64) {
+
+	diags = make([]uint64, 0, len(lt.vec))
+	for k := range lt.vec {
+		diags = append(diags, k)
+	}
+
+	return
+}
+
+// LinearTransformNew homomorphically multiplies the plaintext matrix of the LinearTransform with the vector encrypted in ct0,
+// and returns the result in a newly created Ciphertext.
+func (evaluator *evaluator) LinearTransformNew(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys) (ctOut *Ciphertext) {
+	ctOut = NewCiphertextLvl(evaluator.params, 1, ct0.Level())
+	evaluator.LinearTransform(ct0, lt, rotkeys, ctOut)
+	return
+}
+
+// LinearTransform homomorphically multiplies the plaintext matrix of the LinearTransform with the vector encrypted in ct0,
+// and returns the result in ctOut. The rotation keys for all the rotations returned by lt.Rotations() must be provided.
+func (evaluator *evaluator) LinearTransform(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys, ctOut *Ciphertext) {
+
+	rotkeys = evaluator.getRotationKeys(rotkeys)
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot LinearTransform: input and output Ciphertext must be of degree 1")
+	}
+
+	if ct0 == ctOut {
+		panic("cannot LinearTransform: input and output Ciphertext must be different")
+	}
+
+	for i := range ctOut.value {
+		ctOut.value[i].Zero()
+	}
+	ctOut.seed = nil
+
+	tmp := NewCiphertextLvl(evaluator.params, 1, ct0.Level())
+
+	if lt.n1 == 0 {
+
+		rotations := make([]uint64, 0, len(lt.vec))
+		for k := range lt.vec {
+			rotations = append(rotations, k)
+		}
+
+		ctRot := evaluator.RotateHoisted(ct0, rotations, rotkeys)
+
+		for k, pt := range lt.vec {
+			evaluator.Mul(ctRot[k], pt, tmp)
+			evaluator.Add(ctOut, tmp, ctOut)
+		}
+
+		return
+	}
+
+	// Baby-step giant-step : M*v = sum_{g} rot_{g}(sum_{b} rot_{-g}(diag_{g+b}) * rot_{b}(v))
+	babySteps, giantSteps := utils.BSGSIndex(lt.diags(), lt.n1)
+
+	ctRot := evaluator.RotateHoisted(ct0, babySteps, rotkeys)
+
+	inner := NewCiphertextLvl(evaluator.params, 1, ct0.Level())
+
+	for _, giant := range giantSteps {
+
+		for i := range inner.value {
+			inner.value[i].Zero()
+		}
+
+		for _, baby := range babySteps {
+			if pt, ok := lt.vec[giant+baby]; ok {
+				evaluator.Mul(ctRot[baby], pt, tmp)
+				evaluator.Add(inner, tmp, inner)
+			}
+		}
+
+		if giant != 0 {
+			evaluator.RotateColumns(inner, giant, rotkeys, tmp)
+			evaluator.Add(ctOut, tmp, ctOut)
+		} else {
+			evaluator.Add(ctOut, inner, ctOut)
+		}
+	}
+}
+
+// checkDiagMatrix checks the parameters, and that all the diagonals have the same size, which must be either N/2 or N,
+// and distinct indexes modulo N/2, and returns N/2.
+func checkDiagMatrix(op string, params *Parameters, diagMatrix map[uint64][]uint64) (slots uint64, err error) {
+
+	if err = checkParameters(op, params); err != nil {
+		return 0, err
+	}
+
+	slots = uint64(1) << (params.LogN - 1)
+
+	if len(diagMatrix) == 0 {
+		return 0, newError(op, ErrInvalidMatrix, "the matrix must have at least one non-zero diagonal")
+	}
+
+	var size uint64
+	for _, diag := range diagMatrix {
+
+		if size == 0 {
+			size = uint64(len(diag))
+		}
+
+		if uint64(len(diag)) != size {
+			return 0, newError(op, ErrInvalidMatrix, "all the diagonals must have the same size")
+		}
+	}
+
+	if size != slots && size != slots<<1 {
+		return 0, newError(op, ErrInvalidMatrix, "the size of the diagonals must be N/2 or N")
+	}
+
+	// The diagonals are indexed modulo N/2, so two distinct keys must not give the same diagonal
+	indexes := make(map[uint64]bool)
+	for k := range diagMatrix {
+		if indexes[k&(slots-1)] {
+			return 0, newError(op, ErrInvalidMatrix, "two diagonals have the same index modulo N/2")
+		}
+		indexes[k&(slots-1)] = true
+	}
+
+	return slots, nil
+}
@@ -1,7 +1,9 @@
 package bfv
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"github.com/ldsec/lattigo/ring"
 )
 
@@ -72,6 +74,99 @@ func (ciphertext *Ciphertext) GetDataLen(WithMetaData bool) (dataLen uint64) {
 	return dataLen
 }
 
+// GetDataLenCompressed returns the length in bytes of the target Ciphertext once marshaled with MarshalBinaryCompressed.
+func (ciphertext *Ciphertext) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	if WithMetaData {
+		dataLen += 2
+	}
+
+	return dataLen + uint64(len(ciphertext.seed)) + ciphertext.value[0].GetDataLen(WithMetaData)
+}
+
+// MarshalBinaryCompressed encodes a fresh Ciphertext encrypted with the secret-key in a byte slice, storing the seed of its
+// uniform element instead of the element itself, which halves its size. Returns an error if the Ciphertext has no seed,
+// which is the case if it was encrypted with the public-key or from a CRP, or if it was modified after its encryption
+// (e.g. as the receiver of an evaluation, or with DropLevel), since the seed is then cleared, or if its uniform element
+// was overwritten through Value() (e.g. as the output of a multiparty protocol).
+func (ciphertext *Ciphertext) MarshalBinaryCompressed() (data []byte, err error) {
+
+	if ciphertext.seed == nil {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the ciphertext has no seed (only the fresh encryptions with the secret-key can be compressed)")
+	}
+
+	if ciphertext.Degree() != 1 || ciphertext.Level() != ciphertext.seedLevel {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the ciphertext must be of degree 1 and at the level of its encryption")
+	}
+
+	if !bytes.Equal(ciphertext.value[1].Digest(ciphertext.seedLevel), ciphertext.seedDigest) {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the uniform element of the ciphertext was modified after its encryption")
+	}
+
+	data = make([]byte, ciphertext.GetDataLenCompressed(true))
+
+	if ciphertext.isNTT {
+		data[0] = 1
+	}
+
+	data[1] = uint8(len(ciphertext.seed))
+
+	pointer := 2 + uint64(copy(data[2:], ciphertext.seed))
+
+	if _, err = ciphertext.value[0].WriteTo(data[pointer:]); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinaryCompressed decodes a Ciphertext marshaled with MarshalBinaryCompressed in the target Ciphertext,
+// re-generating its uniform element from the seed.
+func (ciphertext *Ciphertext) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return errors.New("cannot UnmarshalBinaryCompressed: data is too short")
+	}
+
+	ciphertext.bfvElement = new(bfvElement)
+
+	ciphertext.value = make([]*ring.Poly, 2)
+
+	if uint8(data[0]) == 1 {
+		ciphertext.isNTT = true
+	}
+
+	pointer := 2 + uint64(data[1])
+
+	ciphertext.seed = make([]byte, data[1])
+	copy(ciphertext.seed, data[2:pointer])
+
+	ciphertext.value[0] = new(ring.Poly)
+
+	if _, err = ciphertext.value[0].DecodePolyNew(data[pointer:]); err != nil {
+		return err
+	}
+
+	var crpGenerator *ring.CRPGenerator
+	if crpGenerator, err = expander.newCRPGenerator(expander.contextQ, ciphertext.seed, ciphertext.value[0]); err != nil {
+		return err
+	}
+
+	ciphertext.seedLevel = uint64(len(ciphertext.value[0].Coeffs) - 1)
+
+	// a is generated in the NTT domain, whereas the encryptions are in the coefficient domain
+	ciphertext.value[1] = crpGenerator.ClockNew()
+	expander.contextQ.InvNTT(ciphertext.value[1], ciphertext.value[1])
+
+	ciphertext.seedDigest = ciphertext.value[1].Digest(ciphertext.seedLevel)
+
+	return nil
+}
+
 // GetDataLen returns the length in bytes of the target SecretKey.
 func (sk *SecretKey) GetDataLen(WithMetadata bool) (dataLen uint64) {
 	return sk.sk.GetDataLen(WithMetadata)
@@ -153,13 +248,22 @@ func (pk *PublicKey) UnmarshalBinary(data []byte) (err error) {
 
 // GetDataLen returns the length in bytes of the target EvaluationKey.
 func (evaluationkey *EvaluationKey) GetDataLen(WithMetadata bool) (dataLen uint64) {
+	return evaluationkey.getDataLen(WithMetadata, false)
+}
+
+// GetDataLenCompressed returns the length in bytes of the target EvaluationKey once marshaled with MarshalBinaryCompressed.
+func (evaluationkey *EvaluationKey) GetDataLenCompressed(WithMetadata bool) (dataLen uint64) {
+	return evaluationkey.getDataLen(WithMetadata, true)
+}
+
+func (evaluationkey *EvaluationKey) getDataLen(WithMetadata, compressed bool) (dataLen uint64) {
 
 	if WithMetadata {
 		dataLen++
 	}
 
 	for _, evakey := range evaluationkey.evakey {
-		dataLen += evakey.GetDataLen(WithMetadata)
+		dataLen += evakey.getDataLen(WithMetadata, compressed)
 	}
 
 	return
@@ -167,10 +271,20 @@ func (evaluationkey *EvaluationKey) GetDataLen(WithMetadata bool) (dataLen uint6
 
 // MarshalBinary encodes an EvaluationKey key in a byte slice.
 func (evaluationkey *EvaluationKey) MarshalBinary() (data []byte, err error) {
+	return evaluationkey.marshal(false)
+}
+
+// MarshalBinaryCompressed encodes an EvaluationKey generated by a KeyGenerator in a byte slice, storing the seed of the
+// uniform elements of its SwitchingKeys instead of the elements themselves (see SwitchingKey.MarshalBinaryCompressed).
+func (evaluationkey *EvaluationKey) MarshalBinaryCompressed() (data []byte, err error) {
+	return evaluationkey.marshal(true)
+}
+
+func (evaluationkey *EvaluationKey) marshal(compressed bool) (data []byte, err error) {
 
 	var pointer uint64
 
-	dataLen := evaluationkey.GetDataLen(true)
+	dataLen := evaluationkey.getDataLen(true, compressed)
 
 	data = make([]byte, dataLen)
 
@@ -180,7 +294,7 @@ func (evaluationkey *EvaluationKey) MarshalBinary() (data []byte, err error) {
 
 	for _, evakey := range evaluationkey.evakey {
 
-		if pointer, err = evakey.encode(pointer, data); err != nil {
+		if pointer, err = evakey.encode(pointer, data, compressed); err != nil {
 			return nil, err
 		}
 	}
@@ -190,6 +304,22 @@ func (evaluationkey *EvaluationKey) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a previously marshaled EvaluationKey in the target EvaluationKey.
 func (evaluationkey *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
+	return evaluationkey.unmarshal(data, nil)
+}
+
+// UnmarshalBinaryCompressed decodes an EvaluationKey marshaled with MarshalBinaryCompressed in the target EvaluationKey,
+// re-generating the uniform elements of its SwitchingKeys from their seed.
+func (evaluationkey *EvaluationKey) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	return evaluationkey.unmarshal(data, expander)
+}
+
+func (evaluationkey *EvaluationKey) unmarshal(data []byte, expander *seedExpander) (err error) {
 
 	deg := uint64(data[0])
 
@@ -199,7 +329,7 @@ func (evaluationkey *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
 	var inc uint64
 	for i := uint64(0); i < deg; i++ {
 		evaluationkey.evakey[i] = new(SwitchingKey)
-		if inc, err = evaluationkey.evakey[i].decode(data[pointer:]); err != nil {
+		if inc, err = evaluationkey.evakey[i].decode(data[pointer:], expander); err != nil {
 			return err
 		}
 		pointer += inc
@@ -210,14 +340,32 @@ func (evaluationkey *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
 
 // GetDataLen returns the length in bytes of the target SwitchingKey.
 func (switchkey *SwitchingKey) GetDataLen(WithMetadata bool) (dataLen uint64) {
+	return switchkey.getDataLen(WithMetadata, false)
+}
+
+// GetDataLenCompressed returns the length in bytes of the target SwitchingKey once marshaled with MarshalBinaryCompressed.
+func (switchkey *SwitchingKey) GetDataLenCompressed(WithMetadata bool) (dataLen uint64) {
+	return switchkey.getDataLen(WithMetadata, true)
+}
+
+func (switchkey *SwitchingKey) getDataLen(WithMetadata, compressed bool) (dataLen uint64) {
 
 	if WithMetadata {
-		dataLen++
+		dataLen += 2
+	}
+
+	if compressed {
+		if WithMetadata {
+			dataLen++
+		}
+		dataLen += uint64(len(switchkey.seed))
 	}
 
 	for j := uint64(0); j < uint64(len(switchkey.evakey)); j++ {
 		dataLen += switchkey.evakey[j][0].GetDataLen(WithMetadata)
-		dataLen += switchkey.evakey[j][1].GetDataLen(WithMetadata)
+		if !compressed {
+			dataLen += switchkey.evakey[j][1].GetDataLen(WithMetadata)
+		}
 	}
 
 	return
@@ -228,7 +376,21 @@ func (switchkey *SwitchingKey) MarshalBinary() (data []byte, err error) {
 
 	data = make([]byte, switchkey.GetDataLen(true))
 
-	if _, err = switchkey.encode(0, data); err != nil {
+	if _, err = switchkey.encode(0, data, false); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MarshalBinaryCompressed encodes a SwitchingKey generated by a KeyGenerator in a byte slice, storing the seed of its
+// uniform elements instead of the elements themselves, which halves its size. Returns an error if the SwitchingKey
+// has no seed, which is the case if it was not generated by a KeyGenerator (e.g. if it was set from polynomials).
+func (switchkey *SwitchingKey) MarshalBinaryCompressed() (data []byte, err error) {
+
+	data = make([]byte, switchkey.GetDataLenCompressed(true))
+
+	if _, err = switchkey.encode(0, data, true); err != nil {
 		return nil, err
 	}
 
@@ -238,22 +400,53 @@ func (switchkey *SwitchingKey) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decode a previously marshaled SwitchingKey in the target SwitchingKey.
 func (switchkey *SwitchingKey) UnmarshalBinary(data []byte) (err error) {
 
-	if _, err = switchkey.decode(data); err != nil {
+	if _, err = switchkey.decode(data, nil); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, error) {
+// UnmarshalBinaryCompressed decodes a SwitchingKey marshaled with MarshalBinaryCompressed in the target SwitchingKey,
+// re-generating its uniform elements from the seed.
+func (switchkey *SwitchingKey) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	if _, err = switchkey.decode(data, expander); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (switchkey *SwitchingKey) encode(pointer uint64, data []byte, compressed bool) (uint64, error) {
 
 	var err error
 
 	var inc uint64
 
+	if len(switchkey.evakey) > 0xFF {
+		return pointer, errors.New("cannot encode SwitchingKey: more than 255 decomposition elements")
+	}
+
+	if compressed && switchkey.seed == nil {
+		return pointer, errors.New("cannot encode SwitchingKey: no seed (only the keys generated by a KeyGenerator can be compressed)")
+	}
+
 	data[pointer] = uint8(len(switchkey.evakey))
+	data[pointer+1] = uint8(switchkey.logBase)
 
-	pointer++
+	pointer += 2
+
+	if compressed {
+		data[pointer] = uint8(len(switchkey.seed))
+		pointer++
+		pointer += uint64(copy(data[pointer:], switchkey.seed))
+	}
 
 	for j := uint64(0); j < uint64(len(switchkey.evakey)); j++ {
 
@@ -263,6 +456,10 @@ func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, erro
 
 		pointer += inc
 
+		if compressed {
+			continue
+		}
+
 		if inc, err = switchkey.evakey[j][1].WriteTo(data[pointer : pointer+switchkey.evakey[j][1].GetDataLen(true)]); err != nil {
 			return pointer, err
 		}
@@ -273,11 +470,20 @@ func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, erro
 	return pointer, nil
 }
 
-func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
+// decode decodes a SwitchingKey, which is compressed if expander is not nil.
+func (switchkey *SwitchingKey) decode(data []byte, expander *seedExpander) (pointer uint64, err error) {
 
 	decomposition := uint64(data[0])
+	switchkey.logBase = uint64(data[1])
 
-	pointer = uint64(1)
+	pointer = uint64(2)
+
+	if expander != nil {
+		seedLen := uint64(data[pointer])
+		pointer++
+		switchkey.seed = make([]byte, seedLen)
+		pointer += uint64(copy(switchkey.seed, data[pointer:pointer+seedLen]))
+	}
 
 	switchkey.evakey = make([][2]*ring.Poly, decomposition)
 
@@ -291,6 +497,10 @@ func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
 		}
 		pointer += inc
 
+		if expander != nil {
+			continue
+		}
+
 		switchkey.evakey[j][1] = new(ring.Poly)
 		if inc, err = switchkey.evakey[j][1].DecodePolyNew(data[pointer:]); err != nil {
 			return pointer, err
@@ -299,31 +509,46 @@ func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
 
 	}
 
+	if expander != nil {
+		if err = expander.expandSwitchingKey(switchkey); err != nil {
+			return pointer, err
+		}
+	}
+
 	return pointer, nil
 }
 
 // GetDataLen returns the length in bytes of the target RotationKeys.
 func (rotationkey *RotationKeys) GetDataLen(WithMetaData bool) (dataLen uint64) {
+	return rotationkey.getDataLen(WithMetaData, false)
+}
+
+// GetDataLenCompressed returns the length in bytes of the target RotationKeys once marshaled with MarshalBinaryCompressed.
+func (rotationkey *RotationKeys) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	return rotationkey.getDataLen(WithMetaData, true)
+}
+
+func (rotationkey *RotationKeys) getDataLen(WithMetaData, compressed bool) (dataLen uint64) {
 
 	for i := range rotationkey.evakeyRotColLeft {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyRotColLeft[i].GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyRotColLeft[i].getDataLen(WithMetaData, compressed)
 	}
 
 	for i := range rotationkey.evakeyRotColRight {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyRotColRight[i].GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyRotColRight[i].getDataLen(WithMetaData, compressed)
 	}
 
 	if rotationkey.evakeyRotRow != nil {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyRotRow.GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyRotRow.getDataLen(WithMetaData, compressed)
 	}
 
 	return
@@ -331,8 +556,18 @@ func (rotationkey *RotationKeys) GetDataLen(WithMetaData bool) (dataLen uint64)
 
 // MarshalBinary encodes a RotationKeys struct in a byte slice.
 func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
+	return rotationkey.marshal(false)
+}
+
+// MarshalBinaryCompressed encodes RotationKeys generated by a KeyGenerator in a byte slice, storing the seed of the
+// uniform elements of its SwitchingKeys instead of the elements themselves (see SwitchingKey.MarshalBinaryCompressed).
+func (rotationkey *RotationKeys) MarshalBinaryCompressed() (data []byte, err error) {
+	return rotationkey.marshal(true)
+}
+
+func (rotationkey *RotationKeys) marshal(compressed bool) (data []byte, err error) {
 
-	data = make([]byte, rotationkey.GetDataLen(true))
+	data = make([]byte, rotationkey.getDataLen(true, compressed))
 
 	mappingColL := []uint64{}
 	mappingColR := []uint64{}
@@ -353,7 +588,7 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(RotationLeft)
 		pointer += 4
 
-		if pointer, err = rotationkey.evakeyRotColLeft[i].encode(pointer, data); err != nil {
+		if pointer, err = rotationkey.evakeyRotColLeft[i].encode(pointer, data, compressed); err != nil {
 			return nil, err
 		}
 	}
@@ -364,7 +599,7 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(RotationRight)
 		pointer += 4
 
-		if pointer, err = rotationkey.evakeyRotColRight[i].encode(pointer, data); err != nil {
+		if pointer, err = rotationkey.evakeyRotColRight[i].encode(pointer, data, compressed); err != nil {
 			return nil, err
 		}
 	}
@@ -374,7 +609,7 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(RotationRow)
 		pointer += 4
 
-		if _, err = rotationkey.evakeyRotRow.encode(pointer, data); err != nil {
+		if _, err = rotationkey.evakeyRotRow.encode(pointer, data, compressed); err != nil {
 			return nil, err
 		}
 	}
@@ -384,6 +619,22 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a previously marshaled RotationKeys in the target RotationKeys.
 func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
+	return rotationkey.unmarshal(data, nil)
+}
+
+// UnmarshalBinaryCompressed decodes RotationKeys marshaled with MarshalBinaryCompressed in the target RotationKeys,
+// re-generating the uniform elements of its SwitchingKeys from their seed.
+func (rotationkey *RotationKeys) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	return rotationkey.unmarshal(data, expander)
+}
+
+func (rotationkey *RotationKeys) unmarshal(data []byte, expander *seedExpander) (err error) {
 
 	var rotationType int
 	var rotationNumber uint64
@@ -407,7 +658,7 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 			}
 
 			rotationkey.evakeyRotColLeft[rotationNumber] = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyRotColLeft[rotationNumber].decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyRotColLeft[rotationNumber].decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
@@ -418,14 +669,14 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 			}
 
 			rotationkey.evakeyRotColRight[rotationNumber] = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyRotColRight[rotationNumber].decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyRotColRight[rotationNumber].decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
 		} else if rotationType == RotationRow {
 
 			rotationkey.evakeyRotRow = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyRotRow.decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyRotRow.decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
@@ -441,3 +692,75 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 
 	return nil
 }
+
+// seedExpander re-generates the uniform elements of the compressed ciphertexts and keys from their seed.
+type seedExpander struct {
+	contextQ  *ring.Context
+	contextQP *ring.Context
+}
+
+func newSeedExpander(op string, params *Parameters) (expander *seedExpander, err error) {
+
+	if err = checkParameters(op, params); err != nil {
+		return nil, err
+	}
+
+	expander = new(seedExpander)
+
+	n := uint64(1 << params.LogN)
+
+	if expander.contextQ, err = ring.NewContextWithParams(n, params.Qi); err != nil {
+		return nil, err
+	}
+
+	if len(params.Pi) != 0 {
+		if expander.contextQP, err = ring.NewContextWithParams(n, append(append([]uint64{}, params.Qi...), params.Pi...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return expander, nil
+}
+
+// newCRPGenerator returns a CRPGenerator over the given context seeded with seed, after checking that p, a decoded
+// polynomial of the object to expand, is in the basis of the context.
+func (expander *seedExpander) newCRPGenerator(context *ring.Context, seed []byte, p *ring.Poly) (crpGenerator *ring.CRPGenerator, err error) {
+
+	if context == nil {
+		return nil, errors.New("cannot expand seed: modulus P is empty")
+	}
+
+	if len(p.Coeffs) != len(context.Modulus) || uint64(len(p.Coeffs[0])) != context.N {
+		return nil, errors.New("cannot expand seed: the polynomials do not match the parameters")
+	}
+
+	crpGenerator = ring.NewCRPGenerator(nil, context)
+	crpGenerator.Seed(seed)
+
+	return crpGenerator, nil
+}
+
+// expandSwitchingKey sets the uniform elements of the SwitchingKey, which are generated from its seed in its basis
+// (QP with the special primes Pi, Q with the gadget decomposition).
+func (expander *seedExpander) expandSwitchingKey(switchkey *SwitchingKey) (err error) {
+
+	if len(switchkey.evakey) == 0 {
+		return nil
+	}
+
+	context := expander.contextQP
+	if switchkey.logBase != 0 {
+		context = expander.contextQ
+	}
+
+	var crpGenerator *ring.CRPGenerator
+	if crpGenerator, err = expander.newCRPGenerator(context, switchkey.seed, switchkey.evakey[0][0]); err != nil {
+		return err
+	}
+
+	for j := range switchkey.evakey {
+		switchkey.evakey[j][1] = crpGenerator.ClockNew()
+	}
+
+	return nil
+}
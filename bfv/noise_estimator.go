@@ -0,0 +1,346 @@
+package bfv
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
+)
+
+// NoiseEstimator is an interface for the static estimation of the invariant noise budget of BFV ciphertexts.
+// Each method mirrors an operation of the Evaluator and returns the estimated noise of its output, so that
+// the noise budget consumed by a circuit can be predicted from the Parameters only, before running it.
+//
+// The estimates are heuristic bounds on the infinity norm of the invariant noise: the products by the (non-centered)
+// messages are bounded in the worst case, while the product of two random polynomials is assumed to expand the norm
+// by a factor 2*sqrt(N). They are therefore expected to be within a few bits of the budget measured by Decryptor.InvariantNoiseBudget.
+type NoiseEstimator interface {
+	// Fresh returns the estimated noise of a freshly encrypted ciphertext (at the maximum level).
+	Fresh() *NoiseEstimate
+
+	// Add returns the estimated noise after the addition (or subtraction) of two ciphertexts.
+	Add(op0, op1 *NoiseEstimate) *NoiseEstimate
+
+	// AddPlain returns the estimated noise after the addition (or subtraction) of a ciphertext and a plaintext.
+	AddPlain(op0 *NoiseEstimate) *NoiseEstimate
+
+	// MulScalar returns the estimated noise after the multiplication of a ciphertext by a scalar.
+	MulScalar(op0 *NoiseEstimate, scalar uint64) *NoiseEstimate
+
+	// Mul returns the estimated noise after the multiplication of two ciphertexts (without relinearization).
+	Mul(op0, op1 *NoiseEstimate) *NoiseEstimate
+
+	// MulPlain returns the estimated noise after the multiplication of a ciphertext by a plaintext.
+	MulPlain(op0 *NoiseEstimate) *NoiseEstimate
+
+	// Relinearize returns the estimated noise after the relinearization of a ciphertext to degree 1, with an
+	// EvaluationKey using the gadget decomposition of base 2^logBase, or the special primes Pi if logBase is 0.
+	// Returns an error if the parameters do not enable such a key.
+	Relinearize(op0 *NoiseEstimate, logBase uint64) (*NoiseEstimate, error)
+
+	// SwitchKeys returns the estimated noise after a key-switching, which is also the noise added by
+	// RotateColumns (for each power-of-two rotation it is decomposed into) and RotateRows, with a SwitchingKey
+	// using the gadget decomposition of base 2^logBase, or the special primes Pi if logBase is 0.
+	// Returns an error if the parameters do not enable such a key.
+	SwitchKeys(op0 *NoiseEstimate, logBase uint64) (*NoiseEstimate, error)
+
+	// DropLevel returns the estimated noise after the given number of modulus switchings.
+	DropLevel(op0 *NoiseEstimate, levels uint64) (*NoiseEstimate, error)
+}
+
+// NoiseEstimate is the estimated invariant noise of a ciphertext, as tracked by a NoiseEstimator.
+type NoiseEstimate struct {
+	level  uint64
+	degree uint64
+	logV   float64 // log2 of the infinity norm of the invariant noise
+}
+
+// Level returns the level of the ciphertext of the target NoiseEstimate.
+func (ne *NoiseEstimate) Level() uint64 {
+	return ne.level
+}
+
+// Degree returns the degree of the ciphertext of the target NoiseEstimate.
+func (ne *NoiseEstimate) Degree() uint64 {
+	return ne.degree
+}
+
+// LogNoise returns the log2 of the estimated infinity norm of the invariant noise.
+func (ne *NoiseEstimate) LogNoise() float64 {
+	return ne.logV
+}
+
+// Budget returns the estimated invariant noise budget in bits, that is, -log2(2*||v||), or 0 if it is exhausted.
+func (ne *NoiseEstimate) Budget() int {
+	if budget := int(math.Floor(-ne.logV - 1)); budget > 0 {
+		return budget
+	}
+	return 0
+}
+
+// noiseEstimator is a struct storing the pre-computed values (in log2) required to estimate the noise growth.
+type noiseEstimator struct {
+	params *Parameters
+
+	logN     float64
+	logT     float64
+	logB     float64   // bound on the Gaussian errors
+	logDelta float64   // expansion factor of the product of two random polynomials
+	logQ     []float64 // log2(Q_l) for each level
+	logR     []float64 // log2(Q_l mod T) for each level
+	logKS    []float64 // log2 of the noise added by a key-switching with the special primes at each level, before its division by Q_l
+}
+
+// TryNewNoiseEstimator is the same as NewNoiseEstimator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewNoiseEstimator(params *Parameters) (NoiseEstimator, error) {
+
+	if err := checkParameters("NewNoiseEstimator", params); err != nil {
+		return nil, err
+	}
+
+	return NewNoiseEstimator(params), nil
+}
+
+// NewNoiseEstimator creates a new NoiseEstimator from the provided parameters.
+func NewNoiseEstimator(params *Parameters) NoiseEstimator {
+
+	if err := checkParameters("NewNoiseEstimator", params); err != nil {
+		panic(err)
+	}
+
+	estimator := new(noiseEstimator)
+	estimator.params = params.Copy()
+
+	estimator.logN = float64(params.LogN)
+	estimator.logT = math.Log2(float64(params.T))
+	estimator.logB = math.Log2(6 * params.Sigma)
+	estimator.logDelta = 1 + estimator.logN/2
+
+	estimator.logQ = make([]float64, len(params.Qi))
+	estimator.logR = make([]float64, len(params.Qi))
+
+	Q, R := ring.NewUint(1), new(big.Int)
+	for level, qi := range params.Qi {
+		Q.Mul(Q, ring.NewUint(qi))
+		R.Mod(Q, ring.NewUint(params.T))
+		estimator.logQ[level] = log2Bigint(Q)
+		estimator.logR[level] = log2Bigint(R)
+	}
+
+	if len(params.Pi) != 0 {
+
+		var logP float64
+		for _, pj := range params.Pi {
+			logP += math.Log2(float64(pj))
+		}
+
+		alpha := len(params.Pi)
+
+		estimator.logKS = make([]float64, len(params.Qi))
+		for level := range params.Qi {
+
+			// Largest product of moduli of the CRT decomposition at this level
+			var logQAlpha, logQAlphaMax float64
+			for i, qi := range params.Qi[:level+1] {
+				if i%alpha == 0 {
+					logQAlpha = 0
+				}
+				logQAlpha += math.Log2(float64(qi))
+				logQAlphaMax = math.Max(logQAlphaMax, logQAlpha)
+			}
+
+			beta := math.Ceil(float64(level+1) / float64(alpha))
+
+			// sum_i c2_i * e_i / P + rounding error of the division by P
+			estimator.logKS[level] = logAdd(math.Log2(beta)+estimator.logDelta+estimator.logB+logQAlphaMax-logP, estimator.logExpansion(1)-1)
+		}
+	}
+
+	return estimator
+}
+
+// log2Bigint returns log2(x), or 0 if x is zero.
+func log2Bigint(x *big.Int) float64 {
+	if x.Sign() == 0 {
+		return 0
+	}
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(x).MantExp(mant)
+	m, _ := mant.Float64()
+	return float64(exp) + math.Log2(m)
+}
+
+// logAdd returns log2(2^a + 2^b).
+func logAdd(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log2(1+math.Exp2(b-a))
+}
+
+// logExpansion returns log2(sum_{j=0}^{degree} delta^j), the expansion of the norm of a rounding error of a ciphertext
+// of the given degree after its evaluation on the secret-key.
+func (estimator *noiseEstimator) logExpansion(degree uint64) (logE float64) {
+	for j := uint64(1); j < degree+1; j++ {
+		logE = logAdd(logE, float64(j)*estimator.logDelta)
+	}
+	return
+}
+
+// roundingNoise returns log2 of the invariant noise introduced by a rounding of the coefficients of a ciphertext of the given degree and level.
+func (estimator *noiseEstimator) roundingNoise(level, degree uint64) float64 {
+	return estimator.logT - estimator.logQ[level] + estimator.logExpansion(degree) - 1
+}
+
+// messageNoise returns log2 of the invariant noise (Q_l mod T)*m/Q_l introduced by the encoding of a message m of the given level.
+func (estimator *noiseEstimator) messageNoise(level uint64) float64 {
+	return estimator.logR[level] + estimator.logT - estimator.logQ[level]
+}
+
+// Fresh returns the estimated noise of a freshly encrypted ciphertext: v = (T/Q)(e0 + u*e + e1*s) - (Q mod T)*m/Q.
+func (estimator *noiseEstimator) Fresh() *NoiseEstimate {
+
+	level := uint64(len(estimator.logQ) - 1)
+
+	logE := estimator.logB + math.Log2(1+2*math.Exp2(estimator.logDelta))
+
+	return &NoiseEstimate{
+		level:  level,
+		degree: 1,
+		logV:   logAdd(estimator.logT-estimator.logQ[level]+logE, 2*estimator.logT-1-estimator.logQ[level]),
+	}
+}
+
+// modSwitchTo returns the estimated noise of op0 after being switched to the given level.
+func (estimator *noiseEstimator) modSwitchTo(op0 *NoiseEstimate, level uint64) *NoiseEstimate {
+
+	opOut := &NoiseEstimate{level: op0.level, degree: op0.degree, logV: op0.logV}
+
+	for opOut.level > level {
+		opOut.level--
+		opOut.logV = logAdd(opOut.logV, estimator.roundingNoise(opOut.level, opOut.degree))
+	}
+
+	return opOut
+}
+
+// Add returns the estimated noise after the addition of two ciphertexts, at the smallest level of both: v = v0 + v1.
+func (estimator *noiseEstimator) Add(op0, op1 *NoiseEstimate) *NoiseEstimate {
+
+	level := utils.MinUint64(op0.level, op1.level)
+
+	op0, op1 = estimator.modSwitchTo(op0, level), estimator.modSwitchTo(op1, level)
+
+	return &NoiseEstimate{
+		level:  level,
+		degree: utils.MaxUint64(op0.degree, op1.degree),
+		logV:   logAdd(op0.logV, op1.logV),
+	}
+}
+
+// AddPlain returns the estimated noise after the addition of a ciphertext and a plaintext: v = v0 - (Q mod T)*m/Q.
+func (estimator *noiseEstimator) AddPlain(op0 *NoiseEstimate) *NoiseEstimate {
+	return &NoiseEstimate{
+		level:  op0.level,
+		degree: op0.degree,
+		logV:   logAdd(op0.logV, 2*estimator.logT-1-estimator.logQ[op0.level]),
+	}
+}
+
+// MulScalar returns the estimated noise after the multiplication of a ciphertext by a scalar: v = scalar * v0.
+func (estimator *noiseEstimator) MulScalar(op0 *NoiseEstimate, scalar uint64) *NoiseEstimate {
+	return &NoiseEstimate{
+		level:  op0.level,
+		degree: op0.degree,
+		logV:   op0.logV + math.Log2(math.Max(float64(scalar), 1)),
+	}
+}
+
+// Mul returns the estimated noise after the multiplication of two ciphertexts, at the smallest level of both:
+// v = T*(N + delta*E(d))*(v0 + v1) + delta*v0*v1 + N*T*(Q_l mod T)*m/Q_l + rounding, where E(d) is the expansion of a ciphertext
+// of degree d, N*T bounds the norm of the product by a message and N*T*(Q_l mod T)*m/Q_l is the reduction modulo T of the product of the messages.
+func (estimator *noiseEstimator) Mul(op0, op1 *NoiseEstimate) *NoiseEstimate {
+
+	level := utils.MinUint64(op0.level, op1.level)
+
+	op0, op1 = estimator.modSwitchTo(op0, level), estimator.modSwitchTo(op1, level)
+
+	degree := op0.degree + op1.degree
+
+	logV := estimator.logT + logAdd(estimator.logN, estimator.logDelta+estimator.logExpansion(utils.MaxUint64(op0.degree, op1.degree))) + logAdd(op0.logV, op1.logV)
+	logV = logAdd(logV, estimator.logDelta+op0.logV+op1.logV)
+	logV = logAdd(logV, estimator.logN+estimator.logT+estimator.messageNoise(level))
+	logV = logAdd(logV, estimator.roundingNoise(level, degree))
+
+	return &NoiseEstimate{level: level, degree: degree, logV: logV}
+}
+
+// MulPlain returns the estimated noise after the multiplication of a ciphertext by a plaintext:
+// v = N*T*(v0 + 2*(Q_l mod T)*m/Q_l) + rounding, accounting for the encoding of the plaintext and the reduction modulo T of the product of the messages.
+func (estimator *noiseEstimator) MulPlain(op0 *NoiseEstimate) *NoiseEstimate {
+	return &NoiseEstimate{
+		level:  op0.level,
+		degree: op0.degree,
+		logV:   logAdd(estimator.logN+estimator.logT+logAdd(op0.logV, 1+estimator.messageNoise(op0.level)), estimator.roundingNoise(op0.level, op0.degree)),
+	}
+}
+
+// Relinearize returns the estimated noise after the relinearization of a ciphertext: one key-switching per degree above 1.
+func (estimator *noiseEstimator) Relinearize(op0 *NoiseEstimate, logBase uint64) (opOut *NoiseEstimate, err error) {
+
+	if err = checkKeySwitching("Relinearize", estimator.params, logBase); err != nil {
+		return nil, err
+	}
+
+	opOut = &NoiseEstimate{level: op0.level, degree: op0.degree, logV: op0.logV}
+
+	for opOut.degree > 1 {
+		opOut, _ = estimator.SwitchKeys(opOut, logBase)
+		opOut.degree--
+	}
+
+	return opOut, nil
+}
+
+// SwitchKeys returns the estimated noise after a key-switching: v = v0 + (T/Q_l)*(sum_i c2_i * e_i / P + rounding) with
+// the special primes, or v = v0 + (T/Q_l)*(sum_{i,j} d_ij * e_ij) with the digits d_ij < 2^logBase of the gadget decomposition.
+func (estimator *noiseEstimator) SwitchKeys(op0 *NoiseEstimate, logBase uint64) (*NoiseEstimate, error) {
+
+	if err := checkKeySwitching("SwitchKeys", estimator.params, logBase); err != nil {
+		return nil, err
+	}
+
+	var logKS float64
+	if logBase == 0 {
+		logKS = estimator.logKS[op0.level]
+	} else {
+		logKS = estimator.logKSGadget(op0.level, logBase)
+	}
+
+	return &NoiseEstimate{
+		level:  op0.level,
+		degree: op0.degree,
+		logV:   logAdd(op0.logV, estimator.logT-estimator.logQ[op0.level]+logKS),
+	}, nil
+}
+
+// logKSGadget returns log2 of the noise added by a key-switching with the gadget decomposition of base 2^logBase at the
+// given level, that is, the sum of the products of the (level+1)*GadgetDigits(logBase) digits by the errors of the key.
+func (estimator *noiseEstimator) logKSGadget(level, logBase uint64) float64 {
+
+	digits := (level + 1) * estimator.params.GadgetDigits(logBase)
+
+	return math.Log2(float64(digits)) + estimator.logDelta + float64(logBase) + estimator.logB
+}
+
+// DropLevel returns the estimated noise after the given number of modulus switchings, each of them adding a rounding error.
+// Returns an error if the level of op0 is smaller than levels.
+func (estimator *noiseEstimator) DropLevel(op0 *NoiseEstimate, levels uint64) (*NoiseEstimate, error) {
+
+	if op0.level < levels {
+		return nil, newError("DropLevel", ErrLevelMismatch, "level is smaller than the number of levels to drop")
+	}
+
+	return estimator.modSwitchTo(op0, op0.level-levels), nil
+}
@@ -8,6 +8,7 @@ import (
 type Operand interface {
 	Element() *bfvElement
 	Degree() uint64
+	Level() uint64
 }
 
 // bfvElement is a common struct for Plaintexts and Ciphertexts. It stores a value
@@ -15,10 +16,17 @@ type Operand interface {
 type bfvElement struct {
 	value []*ring.Poly
 	isNTT bool
+
+	// seed from which value[1] of a fresh encryption with the secret-key was generated at level seedLevel, nil
+	// otherwise. It is cleared whenever the element is modified, and seedDigest, the digest of value[1] at the time
+	// of the encryption, detects the modifications made through Value().
+	seed       []byte
+	seedLevel  uint64
+	seedDigest []byte
 }
 
-// newBfvElement creates a new bfvElement of the target degree with zero values.
-func newBfvElement(params *Parameters, degree uint64) *bfvElement {
+// newBfvElement creates a new bfvElement of the target degree and level with zero values.
+func newBfvElement(params *Parameters, degree, level uint64) *bfvElement {
 
 	if !params.isValid {
 		panic("cannot newBfvElement: params not valid (check if they were generated properly)")
@@ -27,13 +35,13 @@ func newBfvElement(params *Parameters, degree uint64) *bfvElement {
 	el := new(bfvElement)
 	el.value = make([]*ring.Poly, degree+1)
 	for i := uint64(0); i < degree+1; i++ {
-		el.value[i] = ring.NewPoly(1<<params.LogN, uint64(len(params.LogQi)))
+		el.value[i] = ring.NewPoly(1<<params.LogN, level+1)
 	}
 	el.isNTT = true
 	return el
 }
 
-func newBfvElementRandom(params *Parameters, degree uint64) *bfvElement {
+func newBfvElementRandom(params *Parameters, degree, level uint64) *bfvElement {
 
 	if !params.isValid {
 		panic("cannot newBfvElementRandom: params not valid (check if they were generated properly)")
@@ -42,7 +50,7 @@ func newBfvElementRandom(params *Parameters, degree uint64) *bfvElement {
 	el := new(bfvElement)
 	el.value = make([]*ring.Poly, degree+1)
 	for i := uint64(0); i < degree+1; i++ {
-		el.value[i] = ring.NewPolyUniform(1<<params.LogN, uint64(len(params.LogQi)))
+		el.value[i] = ring.NewPolyUniform(1<<params.LogN, level+1)
 	}
 	el.isNTT = true
 	return el
@@ -56,6 +64,7 @@ func (el *bfvElement) Value() []*ring.Poly {
 // SetValue assigns the input slice of polynomials to the target bfvElement value.
 func (el *bfvElement) SetValue(value []*ring.Poly) {
 	el.value = value
+	el.seed = nil
 }
 
 // Degree returns the degree of the target bfvElement.
@@ -63,17 +72,24 @@ func (el *bfvElement) Degree() uint64 {
 	return uint64(len(el.value) - 1)
 }
 
+// Level returns the level of the target bfvElement, that is, the index of the last modulus
+// of the chain Qi on which its polynomials are defined.
+func (el *bfvElement) Level() uint64 {
+	return uint64(len(el.value[0].Coeffs) - 1)
+}
+
 // Resize resizes the target bfvElement degree to the degree given as input. If the input degree is bigger, then
 // it will append new empty polynomials; if the degree is smaller, it will delete polynomials until the degree matches
 // the input degree.
 func (el *bfvElement) Resize(params *Parameters, degree uint64) {
+	el.seed = nil
 	if el.Degree() > degree {
 		el.value = el.value[:degree+1]
 	} else if el.Degree() < degree {
 		for el.Degree() < degree {
 			el.value = append(el.value, []*ring.Poly{new(ring.Poly)}...)
-			el.value[el.Degree()].Coeffs = make([][]uint64, len(params.LogQi))
-			for i := 0; i < len(params.LogQi); i++ {
+			el.value[el.Degree()].Coeffs = make([][]uint64, el.Level()+1)
+			for i := uint64(0); i < el.Level()+1; i++ {
 				el.value[el.Degree()].Coeffs[i] = make([]uint64, uint64(1<<params.LogN))
 			}
 		}
@@ -105,13 +121,16 @@ func (el *bfvElement) CopyNew() *bfvElement {
 	return ctxCopy
 }
 
-// Copy copies the value and parameters of the input on the target bfvElement.
+// Copy copies the value and parameters of the input on the target bfvElement. The level of the target bfvElement
+// must be at least the level of the input, and is set to the level of the input.
 func (el *bfvElement) Copy(ctxCopy *bfvElement) {
 	if el != ctxCopy {
 		for i := range ctxCopy.Value() {
-			el.Value()[i].Copy(ctxCopy.Value()[i])
+			el.value[i].Coeffs = el.value[i].Coeffs[:ctxCopy.Level()+1]
+			el.value[i].Copy(ctxCopy.Value()[i])
 		}
 		el.isNTT = ctxCopy.isNTT
+		el.seed = nil
 	}
 }
 
@@ -125,6 +144,7 @@ func (el *bfvElement) NTT(context *ring.Context, c *bfvElement) {
 			context.NTT(el.Value()[i], c.Value()[i])
 		}
 		c.SetIsNTT(true)
+		c.seed = nil
 	}
 }
 
@@ -138,6 +158,7 @@ func (el *bfvElement) InvNTT(context *ring.Context, c *bfvElement) {
 			context.InvNTT(el.Value()[i], c.Value()[i])
 		}
 		c.SetIsNTT(false)
+		c.seed = nil
 	}
 }
 
@@ -146,7 +167,7 @@ func (el *bfvElement) Element() *bfvElement {
 }
 
 func (el *bfvElement) Ciphertext() *Ciphertext {
-	return &Ciphertext{el}
+	return &Ciphertext{bfvElement: el}
 }
 
 func (el *bfvElement) Plaintext() *Plaintext {
@@ -70,12 +70,12 @@ var DefaultParams = []*Parameters{
 		},
 		Sigma: 3.2},
 
-	//logQ1+P = 438
+	//logQ1+P = 437
 	{LogN: 14,
 		T: 65537,
 		LogModuli: LogModuli{
 			LogQi:    []uint64{56, 55, 55, 54, 54, 54},
-			LogPi:    []uint64{55, 55},
+			LogPi:    []uint64{55, 54},
 			LogQiMul: []uint64{60, 60, 60, 60, 60, 60},
 		},
 		Sigma: 3.2},
@@ -187,16 +187,41 @@ func (p *Parameters) Alpha() uint64 {
 	return p.alpha
 }
 
-// Beta returns ceil(#Qi/#Pi).
+// Beta returns ceil(#Qi/#Pi), or 0 if there are no Pi.
 func (p *Parameters) Beta() uint64 {
 	return p.beta
 }
 
+// GadgetDigits returns the number of digits in base 2^logBase of the decomposition of each modulus Qi, i.e., the number
+// of elements per Qi of the SwitchingKeys generated with a gadget decomposition of base 2^logBase.
+// Panics if logBase is zero or larger than the bit-size of the smallest Qi.
+func (p *Parameters) GadgetDigits(logBase uint64) uint64 {
+
+	var maxBitLen uint64
+	for i, qi := range p.Qi {
+
+		if logBase == 0 || logBase > p.LogQi[i] {
+			panic("cannot GadgetDigits: logBase must be between 1 and the bit-size of the smallest Qi")
+		}
+
+		if bitLen := uint64(bits.Len64(qi)); bitLen > maxBitLen {
+			maxBitLen = bitLen
+		}
+	}
+
+	return (maxBitLen + logBase - 1) / logBase
+}
+
 // LogQP returns the bit-length of prod(Qi) * prod(Pi)
 func (p *Parameters) LogQP() uint64 {
 	return p.logQP
 }
 
+// MaxLevel returns #Qi - 1, the level of a freshly encrypted Ciphertext.
+func (p *Parameters) MaxLevel() uint64 {
+	return uint64(len(p.Qi) - 1)
+}
+
 // IsValid returns a true if the parameters are complete and valid, and false otherwise.
 func (p *Parameters) IsValid() bool {
 	return p.isValid
@@ -241,7 +266,7 @@ func (p *Parameters) Equals(other *Parameters) (res bool) {
 		return true
 	}
 
-	res = res && (p.LogN == other.LogN)
+	res = p.LogN == other.LogN
 	res = res && (p.T == other.T)
 	res = res && (p.Sigma == other.Sigma)
 
@@ -258,8 +283,6 @@ func (p *Parameters) Equals(other *Parameters) (res bool) {
 
 	res = res && (p.isValid == other.isValid)
 
-	fmt.Println(res)
-
 	return
 }
 
@@ -359,7 +382,10 @@ func (p *Parameters) GenFromModuli() {
 	}
 
 	p.alpha = uint64(len(p.Pi))
-	p.beta = uint64(math.Ceil(float64(len(p.Qi)) / float64(len(p.Pi))))
+	p.beta = 0
+	if len(p.Pi) != 0 {
+		p.beta = uint64(math.Ceil(float64(len(p.Qi)) / float64(len(p.Pi))))
+	}
 
 	p.isValid = true
 }
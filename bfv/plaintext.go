@@ -10,14 +10,30 @@ type Plaintext struct {
 	value *ring.Poly
 }
 
-// NewPlaintext creates a new plaintext from the target context.
+// TryNewPlaintext is the same as NewPlaintext, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewPlaintext(params *Parameters) (*Plaintext, error) {
+
+	if err := checkParameters("NewPlaintext", params); err != nil {
+		return nil, err
+	}
+
+	return NewPlaintext(params), nil
+}
+
+// NewPlaintext creates a new plaintext at the maximum level from the target parameters.
 func NewPlaintext(params *Parameters) *Plaintext {
+	return NewPlaintextLvl(params, params.MaxLevel())
+}
+
+// NewPlaintextLvl creates a new plaintext at the given level from the target parameters. Such a plaintext
+// can be used with ciphertexts of the same level.
+func NewPlaintextLvl(params *Parameters, level uint64) *Plaintext {
 
-	if !params.isValid {
-		panic("cannot NewPlaintext: params not valid (check if they were generated properly)")
+	if err := checkLevel("NewPlaintext", params, level); err != nil {
+		panic(err)
 	}
 
-	plaintext := &Plaintext{newBfvElement(params, 0), nil}
+	plaintext := &Plaintext{newBfvElement(params, 0, level), nil}
 	plaintext.value = plaintext.bfvElement.value[0]
 	plaintext.isNTT = false
 	return plaintext
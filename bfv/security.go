@@ -0,0 +1,140 @@
+package bfv
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/ring"
+)
+
+// minNoiseBudget is the estimated noise budget (in bits) that GenParameters leaves at the end of the circuit,
+// to absorb the approximation error of the NoiseEstimator.
+const minNoiseBudget = 10
+
+// CheckSecurity returns an error if the parameters do not ensure the given classical security level (128, 192 or 256 bits)
+// for a secret-key of the given distribution, that is, if log2(QP) is larger than the bit-size allowed by the Homomorphic
+// Encryption Security Standard for the ring degree 2^LogN.
+func (p *Parameters) CheckSecurity(securityLevel uint64, secret ring.SecretDistribution) error {
+
+	if !p.isValid {
+		return fmt.Errorf("parameters not generated or invalid")
+	}
+
+	return ring.CheckSecurity(p.LogN, ring.Log2Moduli(p.Qi, p.Pi), securityLevel, secret)
+}
+
+// GenParameters returns the parameters with the smallest LogN enabling the evaluation of a circuit of the given
+// multiplicative depth (each multiplication being followed by a relinearization) on messages modulo T, while ensuring
+// the given classical security level (128, 192 or 256 bits) for the ternary secret-keys of the KeyGenerator.
+// The modulus chain is sized using the NoiseEstimator, such that a few bits of the noise budget remain at the end of
+// the circuit. Returns an error if no parameters with LogN <= 15 allow both the depth and the batching modulo T.
+func GenParameters(T, depth, securityLevel uint64) (params *Parameters, err error) {
+
+	if !ring.IsPrime(T) {
+		return nil, fmt.Errorf("cannot GenParameters: T = %d is not prime", T)
+	}
+
+	for logN := uint64(10); logN < 16; logN++ {
+
+		if T&((2<<logN)-1) != 1 {
+			return nil, fmt.Errorf("cannot GenParameters: T = %d does not allow batching for LogN = %d, which the depth %d requires", T, logN, depth)
+		}
+
+		var maxLogQP uint64
+		if maxLogQP, err = ring.MaxLogQ(logN, securityLevel, ring.SecretTernary); err != nil {
+			return nil, err
+		}
+
+		// Evaluates the circuit with the largest modulus chain allowed, whose moduli, being slightly larger than a
+		// power of two, can exceed 2^maxLogQP by a fraction of a bit
+		if params = genParametersFromLogQP(logN, T, maxLogQP); params != nil && params.CheckSecurity(securityLevel, ring.SecretTernary) != nil {
+			params = genParametersFromLogQP(logN, T, maxLogQP-1)
+		}
+
+		if params == nil || params.CheckSecurity(securityLevel, ring.SecretTernary) != nil {
+			continue
+		}
+
+		budget := estimateBudget(params, depth)
+
+		if budget < minNoiseBudget {
+			continue
+		}
+
+		// Removes the unused noise budget from the modulus chain
+		for logQP := maxLogQP - uint64(budget-minNoiseBudget); logQP < maxLogQP; {
+
+			candidate := genParametersFromLogQP(logN, T, logQP)
+
+			if candidate == nil {
+				break
+			}
+
+			if budget = estimateBudget(candidate, depth); budget >= minNoiseBudget && candidate.CheckSecurity(securityLevel, ring.SecretTernary) == nil {
+				return candidate, nil
+			}
+
+			logQP += uint64(minNoiseBudget-budget) + 1
+		}
+
+		return params, nil
+	}
+
+	return nil, fmt.Errorf("cannot GenParameters: depth %d requires LogN > 15 for %d-bit security", depth, securityLevel)
+}
+
+// genParametersFromLogQP generates parameters whose moduli Qi and Pi (a single special prime) have a total bit-size
+// logQP and are of equal size, and whose moduli QiMul are large enough for the tensoring of the multiplication.
+// Returns nil if logQP is too small to provide two NTT primes for the ring degree 2^logN.
+func genParametersFromLogQP(logN, T, logQP uint64) (params *Parameters) {
+
+	nbModuli := (logQP + MaxModuliSize - 1) / MaxModuliSize
+	if nbModuli < 2 {
+		nbModuli = 2
+	}
+
+	// The NTT primes are equal to 1 modulo 2N
+	if logQP/nbModuli < logN+2 {
+		return nil
+	}
+
+	logModuli := make([]uint64, nbModuli)
+	for i := range logModuli {
+		logModuli[i] = logQP / nbModuli
+		if uint64(i) < logQP%nbModuli {
+			logModuli[i]++
+		}
+	}
+
+	var logQ uint64
+	for _, logqi := range logModuli[:nbModuli-1] {
+		logQ += logqi
+	}
+
+	logQiMul := make([]uint64, (logQ+logN+MaxModuliSize+1)/MaxModuliSize)
+	for i := range logQiMul {
+		logQiMul[i] = MaxModuliSize
+	}
+
+	return NewParametersFromLogModuli(logN, T, LogModuli{
+		LogQi:    logModuli[:nbModuli-1],
+		LogPi:    logModuli[nbModuli-1:],
+		LogQiMul: logQiMul,
+	}, 3.2)
+}
+
+// estimateBudget returns the estimated noise budget after a circuit of the given multiplicative depth.
+func estimateBudget(params *Parameters, depth uint64) int {
+
+	estimator := NewNoiseEstimator(params)
+
+	var err error
+
+	estimate := estimator.Fresh()
+	for i := uint64(0); i < depth; i++ {
+		if estimate, err = estimator.Relinearize(estimator.Mul(estimate, estimate), 0); err != nil {
+			return 0
+		}
+	}
+
+	return estimate.Budget()
+}
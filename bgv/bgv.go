@@ -0,0 +1,46 @@
+// Package bgv implements a RNS-accelerated version of the Brakerski-Gentry-Vaikuntanathan leveled homomorphic encryption scheme.
+// It provides modular arithmetic over the integers. The message is stored in the least significant bits of the ciphertexts
+// and the noise is controlled by modulus switching (rescaling) along a chain of primes.
+//
+// The package is built on the bfv package, from which it uses the parameters, the keys and the key-switching
+// operations, and only implements the encoding in the least significant bits, the tracking of the scale and the rescaling.
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/ring"
+)
+
+// bgvContext is a struct which contains the polynomial contexts required by the BGV specific operations.
+type bgvContext struct {
+
+	// Polynomial degree
+	n uint64
+
+	// Polynomial contexts
+	contextT *ring.Context
+	contextQ *ring.Context
+}
+
+func newBGVContext(params *Parameters) (context *bgvContext) {
+
+	if !params.IsValid() {
+		panic("cannot newBGVContext: params not valid (check if they were generated properly)")
+	}
+
+	context = new(bgvContext)
+	var err error
+
+	N := uint64(1 << params.LogN)
+
+	context.n = N
+
+	if context.contextT, err = ring.NewContextWithParams(N, []uint64{params.T}); err != nil {
+		panic(err)
+	}
+
+	if context.contextQ, err = ring.NewContextWithParams(N, params.Qi); err != nil {
+		panic(err)
+	}
+
+	return
+}
@@ -0,0 +1,636 @@
+package bgv
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
+)
+
+func check(t *testing.T, err error) {
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+}
+
+func testString(opname string, params *Parameters) string {
+	return fmt.Sprintf("%sLogN=%d/logQ=%d", opname, params.LogN, params.LogQP())
+}
+
+type bgvParams struct {
+	params      *Parameters
+	bgvContext  *bgvContext
+	encoder     Encoder
+	kgen        KeyGenerator
+	sk          *SecretKey
+	pk          *PublicKey
+	encryptorPk Encryptor
+	encryptorSk Encryptor
+	decryptor   Decryptor
+	evaluator   Evaluator
+}
+
+type bgvTestParameters struct {
+	bgvParameters []*Parameters
+}
+
+var testParams = new(bgvTestParameters)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+
+	testParams.bgvParameters = []*Parameters{
+		DefaultParams[PN12QP109],
+		DefaultParams[PN13QP218],
+		DefaultParams[PN14QP438],
+	}
+}
+
+func TestBGV(t *testing.T) {
+	t.Run("Parameters", testParameters)
+	t.Run("Encoder", testEncoder)
+	t.Run("Encryptor", testEncryptor)
+	t.Run("Evaluator/Add", testEvaluatorAdd)
+	t.Run("Evaluator/Sub", testEvaluatorSub)
+	t.Run("Evaluator/MulScalar", testEvaluatorMulScalar)
+	t.Run("Evaluator/Mul", testEvaluatorMul)
+	t.Run("Evaluator/Rescale", testEvaluatorRescale)
+	t.Run("Evaluator/KeySwitch", testKeySwitch)
+	t.Run("Evaluator/RotateRows", testRotateRows)
+	t.Run("Evaluator/RotateCols", testRotateCols)
+	t.Run("Evaluator/InnerSum", testInnerSum)
+}
+
+func genBgvParams(contextParameters *Parameters) (params *bgvParams) {
+
+	params = new(bgvParams)
+
+	params.params = contextParameters.Copy()
+
+	params.bgvContext = newBGVContext(contextParameters)
+
+	params.kgen = NewKeyGenerator(contextParameters)
+
+	params.sk, params.pk = params.kgen.GenKeyPair()
+
+	params.encoder = NewEncoder(contextParameters)
+
+	params.encryptorPk = NewEncryptorFromPk(contextParameters, params.pk)
+	params.encryptorSk = NewEncryptorFromSk(contextParameters, params.sk)
+	params.decryptor = NewDecryptor(contextParameters, params.sk)
+
+	params.evaluator = NewEvaluator(contextParameters)
+
+	return
+}
+
+func newTestVectors(params *bgvParams, encryptor Encryptor, t *testing.T) (coeffs *ring.Poly, plaintext *Plaintext, ciphertext *Ciphertext) {
+
+	coeffs = params.bgvContext.contextT.NewUniformPoly()
+
+	plaintext = NewPlaintext(params.params)
+
+	params.encoder.EncodeUint(coeffs.Coeffs[0], plaintext)
+
+	if encryptor != nil {
+		ciphertext = encryptor.EncryptNew(plaintext)
+	}
+
+	return coeffs, plaintext, ciphertext
+}
+
+func verifyTestVectors(params *bgvParams, decryptor Decryptor, coeffs *ring.Poly, element Operand, t *testing.T) {
+
+	var coeffsTest []uint64
+
+	el := element.Element()
+
+	if el.Degree() == 0 {
+
+		coeffsTest = params.encoder.DecodeUint(el.Plaintext())
+
+	} else {
+
+		coeffsTest = params.encoder.DecodeUint(decryptor.DecryptNew(el.Ciphertext()))
+	}
+
+	if utils.EqualSliceUint64(coeffs.Coeffs[0], coeffsTest) != true {
+		t.Errorf("decryption error")
+	}
+}
+
+func testParameters(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		t.Run(testString("Marshalling/", parameters), func(t *testing.T) {
+
+			data, err := parameters.MarshalBinary()
+			check(t, err)
+
+			paramsTest := new(Parameters)
+			err = paramsTest.UnmarshalBinary(data)
+			check(t, err)
+
+			if !parameters.Equals(paramsTest) {
+				t.Errorf("marshal Parameters")
+			}
+		})
+	}
+}
+
+func testEncoder(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		t.Run(testString("Encode&Decode/", parameters), func(t *testing.T) {
+
+			values, plaintext, _ := newTestVectors(params, nil, t)
+
+			verifyTestVectors(params, params.decryptor, values, plaintext, t)
+		})
+
+		t.Run(testString("EncodeInt&DecodeInt/", parameters), func(t *testing.T) {
+
+			coeffs := make([]int64, params.bgvContext.n)
+			for i := range coeffs {
+				coeffs[i] = int64(ring.RandUniform(params.params.T, params.params.T<<1-1)) - int64(params.params.T>>1)
+			}
+
+			plaintext := NewPlaintext(params.params)
+			params.encoder.EncodeInt(coeffs, plaintext)
+
+			coeffsTest := params.encoder.DecodeInt(params.decryptor.DecryptNew(params.encryptorSk.EncryptNew(plaintext)))
+
+			for i := range coeffs {
+				if coeffs[i] != coeffsTest[i] {
+					t.Errorf("decryption error")
+					break
+				}
+			}
+		})
+
+		t.Run(testString("EncodeCoeffs&DecodeCoeffs/", parameters), func(t *testing.T) {
+
+			contextT := params.bgvContext.contextT
+
+			coeffs0 := contextT.NewUniformPoly()
+			coeffs1 := contextT.NewUniformPoly()
+
+			plaintext0 := NewPlaintext(params.params)
+			plaintext1 := NewPlaintext(params.params)
+
+			params.encoder.EncodeCoeffs(coeffs0.Coeffs[0], plaintext0)
+			params.encoder.EncodeCoeffs(coeffs1.Coeffs[0], plaintext1)
+
+			if utils.EqualSliceUint64(coeffs0.Coeffs[0], params.encoder.DecodeCoeffs(plaintext0)) != true {
+				t.Errorf("decoding error")
+			}
+
+			// The product of two plaintexts encoded on the coefficients is the negacyclic product of their polynomials
+			ciphertext := params.encryptorSk.EncryptNew(plaintext0)
+			params.evaluator.Mul(ciphertext, plaintext1, ciphertext)
+			contextT.MulPoly(coeffs0, coeffs1, coeffs0)
+
+			if utils.EqualSliceUint64(coeffs0.Coeffs[0], params.encoder.DecodeCoeffs(params.decryptor.DecryptNew(ciphertext))) != true {
+				t.Errorf("decryption error")
+			}
+		})
+	}
+}
+
+func testEncryptor(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		t.Run(testString("EncryptFromPk/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+
+		t.Run(testString("EncryptFromSk/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorSk, t)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+
+		t.Run(testString("EncryptFromPkLevel0/", parameters), func(t *testing.T) {
+
+			values, plaintext, _ := newTestVectors(params, nil, t)
+
+			ciphertext := NewCiphertext(parameters, 1, 0)
+			params.encryptorPk.Encrypt(plaintext, ciphertext)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testEvaluatorAdd(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		t.Run(testString("CtCtInPlace/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.Add(ciphertext1, ciphertext2, ciphertext1)
+			params.bgvContext.contextT.Add(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("CtCtNew/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext1 = params.evaluator.AddNew(ciphertext1, ciphertext2)
+			params.bgvContext.contextT.Add(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("CtPlain/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.Add(ciphertext1, plaintext2, ciphertext2)
+			params.bgvContext.contextT.Add(values1, values2, values2)
+
+			verifyTestVectors(params, params.decryptor, values2, ciphertext2, t)
+
+			params.evaluator.Add(plaintext2, ciphertext1, ciphertext2)
+
+			verifyTestVectors(params, params.decryptor, values2, ciphertext2, t)
+		})
+	}
+}
+
+func testEvaluatorSub(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		t.Run(testString("CtCtInPlace/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.Sub(ciphertext1, ciphertext2, ciphertext1)
+			params.bgvContext.contextT.Sub(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("CtCtNew/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext1 = params.evaluator.SubNew(ciphertext1, ciphertext2)
+			params.bgvContext.contextT.Sub(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("CtPlain/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			valuesWant := params.bgvContext.contextT.NewPoly()
+
+			params.evaluator.Sub(ciphertext1, plaintext2, ciphertext2)
+			params.bgvContext.contextT.Sub(values1, values2, valuesWant)
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext2, t)
+
+			params.evaluator.Sub(plaintext2, ciphertext1, ciphertext2)
+			params.bgvContext.contextT.Sub(values2, values1, valuesWant)
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext2, t)
+		})
+	}
+}
+
+func testEvaluatorMulScalar(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		t.Run(testString("", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			scalar := ring.RandUniform(params.params.T, params.params.T<<1-1)
+
+			params.evaluator.MulScalar(ciphertext, scalar, ciphertext)
+			params.bgvContext.contextT.MulScalar(values, scalar, values)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testEvaluatorMul(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk, 1)
+
+		t.Run(testString("CtCt/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			receiver := params.evaluator.MulNew(ciphertext1, ciphertext2)
+			params.bgvContext.contextT.MulCoeffs(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+		})
+
+		t.Run(testString("CtPlain/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, _ := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.Mul(ciphertext1, plaintext2, ciphertext1)
+			params.bgvContext.contextT.MulCoeffs(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("Relinearize/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			receiver := NewCiphertext(parameters, ciphertext1.Degree()+ciphertext2.Degree(), ciphertext1.Level())
+			params.evaluator.Mul(ciphertext1, ciphertext2, receiver)
+			params.bgvContext.contextT.MulCoeffs(values1, values2, values1)
+
+			receiver2 := params.evaluator.RelinearizeNew(receiver, rlk)
+			verifyTestVectors(params, params.decryptor, values1, receiver2, t)
+
+			params.evaluator.Relinearize(receiver, rlk, receiver)
+			verifyTestVectors(params, params.decryptor, values1, receiver, t)
+		})
+	}
+}
+
+func testEvaluatorRescale(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk, 1)
+
+		t.Run(testString("MulRelinRescale/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			for ciphertext.Level() > 0 {
+
+				values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+				params.evaluator.Mul(ciphertext, ciphertext2, ciphertext)
+				params.evaluator.Relinearize(ciphertext, rlk, ciphertext)
+				check(t, params.evaluator.Rescale(ciphertext, ciphertext))
+
+				params.bgvContext.contextT.MulCoeffs(values, values2, values)
+
+				verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+			}
+
+			if err := params.evaluator.Rescale(ciphertext, ciphertext); err == nil {
+				t.Errorf("rescale at level 0 should return an error")
+			}
+		})
+
+		t.Run(testString("AddDifferentScales/CtPlain/", parameters), func(t *testing.T) {
+
+			if params.params.MaxLevel() == 0 {
+				t.Skip("#Qi is 1")
+			}
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, plaintext2, _ := newTestVectors(params, nil, t)
+
+			ciphertext1, err := params.evaluator.RescaleNew(ciphertext1)
+			check(t, err)
+
+			params.bgvContext.contextT.Add(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, params.evaluator.AddNew(ciphertext1, plaintext2), t)
+		})
+
+		t.Run(testString("AddDifferentScales/CtCt/", parameters), func(t *testing.T) {
+
+			if params.params.MaxLevel() == 0 {
+				t.Skip("#Qi is 1")
+			}
+
+			// Matching the scales multiplies the noise of one of the operands by up to T/2
+			if params.params.Qi[0] < 1<<40 {
+				t.Skip("not enough noise budget at level 0")
+			}
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorPk, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext1, err := params.evaluator.RescaleNew(ciphertext1)
+			check(t, err)
+
+			params.bgvContext.contextT.Add(values1, values2, values1)
+
+			verifyTestVectors(params, params.decryptor, values1, params.evaluator.AddNew(ciphertext1, ciphertext2), t)
+		})
+
+		t.Run(testString("DropLevel/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext, err := params.evaluator.DropLevelNew(ciphertext, ciphertext.Level())
+			check(t, err)
+
+			if ciphertext.Level() != 0 {
+				t.Errorf("invalid level after DropLevel")
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testKeySwitch(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		sk2 := params.kgen.GenSecretKey()
+		decryptorSk2 := NewDecryptor(parameters, sk2)
+		switchKey := params.kgen.GenSwitchingKey(params.sk, sk2)
+
+		t.Run(testString("InPlace/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.SwitchKeys(ciphertext, switchKey, ciphertext)
+
+			verifyTestVectors(params, decryptorSk2, values, ciphertext, t)
+		})
+
+		t.Run(testString("New/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext = params.evaluator.SwitchKeysNew(ciphertext, switchKey)
+			verifyTestVectors(params, decryptorSk2, values, ciphertext, t)
+		})
+
+		t.Run(testString("Level0/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext, err := params.evaluator.DropLevelNew(ciphertext, ciphertext.Level())
+			check(t, err)
+
+			ciphertext = params.evaluator.SwitchKeysNew(ciphertext, switchKey)
+			verifyTestVectors(params, decryptorSk2, values, ciphertext, t)
+		})
+	}
+}
+
+func testRotateRows(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		rotkey := NewRotationKeys()
+		params.kgen.GenRot(RotationRow, params.sk, 0, rotkey)
+
+		t.Run(testString("InPlace/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.RotateRows(ciphertext, rotkey, ciphertext)
+
+			values.Coeffs[0] = append(values.Coeffs[0][params.bgvContext.n>>1:], values.Coeffs[0][:params.bgvContext.n>>1]...)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+
+		t.Run(testString("New/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			ciphertext = params.evaluator.RotateRowsNew(ciphertext, rotkey)
+
+			values.Coeffs[0] = append(values.Coeffs[0][params.bgvContext.n>>1:], values.Coeffs[0][:params.bgvContext.n>>1]...)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testRotateCols(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		rotkey := params.kgen.GenRotationKeysPow2(params.sk)
+
+		valuesWant := params.bgvContext.contextT.NewPoly()
+		mask := (params.bgvContext.n >> 1) - 1
+		slots := params.bgvContext.n >> 1
+
+		t.Run(testString("InPlace/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			receiver := NewCiphertext(parameters, 1, ciphertext.Level())
+			for n := uint64(1); n < slots; n <<= 1 {
+
+				params.evaluator.RotateColumns(ciphertext, n, rotkey, receiver)
+
+				for i := uint64(0); i < slots; i++ {
+					valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+n)&mask]
+					valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+n)&mask)+slots]
+				}
+
+				verifyTestVectors(params, params.decryptor, valuesWant, receiver, t)
+			}
+		})
+
+		t.Run(testString("Random/", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			for n := 0; n < 4; n++ {
+
+				rand := ring.RandUniform(slots, mask)
+
+				receiver := params.evaluator.RotateColumnsNew(ciphertext, rand, rotkey)
+
+				for i := uint64(0); i < slots; i++ {
+					valuesWant.Coeffs[0][i] = values.Coeffs[0][(i+rand)&mask]
+					valuesWant.Coeffs[0][i+slots] = values.Coeffs[0][((i+rand)&mask)+slots]
+				}
+
+				verifyTestVectors(params, params.decryptor, valuesWant, receiver, t)
+			}
+		})
+	}
+}
+
+func testInnerSum(t *testing.T) {
+
+	for _, parameters := range testParams.bgvParameters {
+
+		params := genBgvParams(parameters)
+
+		rotkey := params.kgen.GenRotationKeysPow2(params.sk)
+
+		t.Run(testString("", parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorPk, t)
+
+			params.evaluator.InnerSum(ciphertext, rotkey, ciphertext)
+
+			var sum uint64
+			for _, c := range values.Coeffs[0] {
+				sum += c
+			}
+			sum %= params.params.T
+
+			for i := range values.Coeffs[0] {
+				values.Coeffs[0][i] = sum
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
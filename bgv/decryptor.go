@@ -0,0 +1,81 @@
+package bgv
+
+// Decryptor is an interface for decryptors
+type Decryptor interface {
+	// DecryptNew decrypts the input ciphertext and returns the result on a new
+	// plaintext.
+	DecryptNew(ciphertext *Ciphertext) *Plaintext
+
+	// Decrypt decrypts the input ciphertext and returns the result on the
+	// provided receiver plaintext.
+	Decrypt(ciphertext *Ciphertext, plaintext *Plaintext)
+}
+
+// decryptor is a structure used to decrypt ciphertexts. It stores the secret-key.
+type decryptor struct {
+	params     *Parameters
+	bgvContext *bgvContext
+	sk         *SecretKey
+}
+
+// NewDecryptor creates a new Decryptor from the parameters with the secret-key
+// given as input.
+func NewDecryptor(params *Parameters, sk *SecretKey) Decryptor {
+	if !params.IsValid() {
+		panic("cannot NewDecryptor: params not valid (check if they were generated properly)")
+	}
+
+	if sk.Get().GetDegree() != int(1<<params.LogN) {
+		panic("cannot NewDecryptor: secret_key degree must match context degree")
+	}
+
+	return &decryptor{
+		params:     params.Copy(),
+		bgvContext: newBGVContext(params),
+		sk:         sk,
+	}
+}
+
+// DecryptNew decrypts the input ciphertext and returns the result on a new plaintext.
+func (decryptor *decryptor) DecryptNew(ciphertext *Ciphertext) *Plaintext {
+	plaintext := NewPlaintext(decryptor.params)
+
+	decryptor.Decrypt(ciphertext, plaintext)
+
+	return plaintext
+}
+
+// Decrypt decrypts the input ciphertext and returns the result on the provided receiver plaintext,
+// which is set to the level and to the scale of the ciphertext. The level of the receiver must be
+// at least the level of the ciphertext. A Horner method is used for evaluating the decryption.
+func (decryptor *decryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) {
+
+	if plaintext.Level() < ciphertext.Level() {
+		panic("cannot Decrypt: plaintext level is smaller than the ciphertext level")
+	}
+
+	context := decryptor.bgvContext.contextQ
+
+	level := ciphertext.Level()
+
+	plaintext.value.Coeffs = plaintext.value.Coeffs[:level+1]
+
+	context.CopyLvl(level, ciphertext.value[ciphertext.Degree()], plaintext.value)
+
+	for i := uint64(ciphertext.Degree()); i > 0; i-- {
+
+		context.MulCoeffsMontgomeryLvl(level, plaintext.value, decryptor.sk.Get(), plaintext.value)
+		context.AddLvl(level, plaintext.value, ciphertext.value[i-1], plaintext.value)
+
+		if i&7 == 7 {
+			context.ReduceLvl(level, plaintext.value, plaintext.value)
+		}
+	}
+
+	if (ciphertext.Degree())&7 != 7 {
+		context.ReduceLvl(level, plaintext.value, plaintext.value)
+	}
+
+	plaintext.scale = ciphertext.scale
+	plaintext.isNTT = true
+}
@@ -0,0 +1,297 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
+	"math/big"
+	"math/bits"
+)
+
+// Encoder is an interface implementing the encoder.
+type Encoder interface {
+	EncodeUint(coeffs []uint64, plaintext *Plaintext)
+	EncodeInt(coeffs []int64, plaintext *Plaintext)
+	DecodeUint(plaintext *Plaintext) (coeffs []uint64)
+	DecodeInt(plaintext *Plaintext) (coeffs []int64)
+	EncodeCoeffs(coeffs []uint64, plaintext *Plaintext)
+	DecodeCoeffs(plaintext *Plaintext) (coeffs []uint64)
+}
+
+// encoder is a structure that stores the parameters to encode values on a plaintext in a SIMD (Single-Instruction Multiple-Data) fashion.
+type encoder struct {
+	params      *Parameters
+	bgvContext  *bgvContext
+	indexMatrix []uint64
+	polypool    *ring.Poly
+	polypoolT   *ring.Poly
+}
+
+// NewEncoder creates a new encoder from the provided parameters.
+func NewEncoder(params *Parameters) Encoder {
+
+	if !params.IsValid() {
+		panic("cannot NewEncoder: params not valid (check if they were generated properly)")
+	}
+
+	bgvContext := newBGVContext(params)
+
+	var m, pos, index1, index2 uint64
+
+	slots := bgvContext.n
+
+	indexMatrix := make([]uint64, slots)
+
+	logN := uint64(bits.Len64(bgvContext.n) - 1)
+
+	rowSize := bgvContext.n >> 1
+	m = (bgvContext.n << 1)
+	pos = 1
+
+	for i := uint64(0); i < rowSize; i++ {
+
+		index1 = (pos - 1) >> 1
+		index2 = (m - pos - 1) >> 1
+
+		indexMatrix[i] = utils.BitReverse64(index1, logN)
+		indexMatrix[i|rowSize] = utils.BitReverse64(index2, logN)
+
+		pos *= bfv.GaloisGen
+		pos &= (m - 1)
+	}
+
+	return &encoder{
+		params:      params.Copy(),
+		bgvContext:  bgvContext,
+		indexMatrix: indexMatrix,
+		polypool:    bgvContext.contextQ.NewPoly(),
+		polypoolT:   bgvContext.contextT.NewPoly(),
+	}
+}
+
+// EncodeUint encodes an uint64 slice of size at most N on a plaintext.
+func (encoder *encoder) EncodeUint(coeffs []uint64, plaintext *Plaintext) {
+
+	if len(coeffs) > len(encoder.indexMatrix) {
+		panic("cannot EncodeUint: invalid input to encode (number of coefficients must be smaller or equal to the context)")
+	}
+
+	for i := 0; i < len(coeffs); i++ {
+		encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]] = coeffs[i] % encoder.params.T
+	}
+
+	for i := len(coeffs); i < len(encoder.indexMatrix); i++ {
+		encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]] = 0
+	}
+
+	encoder.encodePlaintext(plaintext)
+}
+
+// EncodeInt encodes an int64 slice of size at most N on a plaintext. It also encodes the sign of the given integer (as its inverse modulo the plaintext modulus).
+// The sign will correctly decode as long as the absolute value of the coefficient does not exceed half of the plaintext modulus.
+func (encoder *encoder) EncodeInt(coeffs []int64, plaintext *Plaintext) {
+
+	if len(coeffs) > len(encoder.indexMatrix) {
+		panic("cannot EncodeInt: invalid input to encode (number of coefficients must be smaller or equal to the context)")
+	}
+
+	modulus := int64(encoder.params.T)
+
+	for i := 0; i < len(coeffs); i++ {
+
+		value := coeffs[i] % modulus
+
+		if value < 0 {
+			value += modulus
+		}
+
+		encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]] = uint64(value)
+	}
+
+	for i := len(coeffs); i < len(encoder.indexMatrix); i++ {
+		encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]] = 0
+	}
+
+	encoder.encodePlaintext(plaintext)
+}
+
+// EncodeCoeffs encodes an uint64 slice of size at most N directly on the coefficients of the plaintext polynomial, without batching
+// (the remaining coefficients are set to zero). The coefficients are reduced modulo the plaintext modulus.
+func (encoder *encoder) EncodeCoeffs(coeffs []uint64, plaintext *Plaintext) {
+
+	if len(coeffs) > len(encoder.indexMatrix) {
+		panic("cannot EncodeCoeffs: invalid input to encode (number of coefficients must be smaller or equal to the context)")
+	}
+
+	for i := 0; i < len(coeffs); i++ {
+		encoder.polypoolT.Coeffs[0][i] = coeffs[i] % encoder.params.T
+	}
+
+	for i := len(coeffs); i < len(encoder.indexMatrix); i++ {
+		encoder.polypoolT.Coeffs[0][i] = 0
+	}
+
+	encoder.lift(plaintext)
+}
+
+// DecodeCoeffs decodes a plaintext encoded with EncodeCoeffs and returns its coefficients modulo T in a uint64 slice of size N.
+func (encoder *encoder) DecodeCoeffs(plaintext *Plaintext) (coeffs []uint64) {
+
+	encoder.reduce(plaintext)
+
+	coeffs = make([]uint64, encoder.bgvContext.n)
+
+	copy(coeffs, encoder.polypoolT.Coeffs[0])
+
+	return
+}
+
+// DecodeUint decodes a batched plaintext and returns the coefficients in a uint64 slice.
+func (encoder *encoder) DecodeUint(plaintext *Plaintext) (coeffs []uint64) {
+
+	encoder.reduce(plaintext)
+
+	encoder.bgvContext.contextT.NTT(encoder.polypoolT, encoder.polypoolT)
+
+	coeffs = make([]uint64, encoder.bgvContext.n)
+
+	for i := uint64(0); i < encoder.bgvContext.n; i++ {
+		coeffs[i] = encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]]
+	}
+
+	return
+}
+
+// DecodeInt decodes a batched plaintext and returns the coefficients in an int64 slice. It also decodes the sign (by centering the values around the plaintext
+// modulus).
+func (encoder *encoder) DecodeInt(plaintext *Plaintext) (coeffs []int64) {
+
+	var value int64
+
+	encoder.reduce(plaintext)
+
+	encoder.bgvContext.contextT.NTT(encoder.polypoolT, encoder.polypoolT)
+
+	coeffs = make([]int64, encoder.bgvContext.n)
+
+	modulus := int64(encoder.params.T)
+
+	for i := uint64(0); i < encoder.bgvContext.n; i++ {
+
+		value = int64(encoder.polypoolT.Coeffs[0][encoder.indexMatrix[i]])
+
+		coeffs[i] = value
+
+		if value > modulus>>1 {
+			coeffs[i] -= modulus
+		}
+	}
+
+	return coeffs
+}
+
+// encodePlaintext maps the batched values stored on the pool modulo T to their coefficient representation and lifts them on the plaintext.
+func (encoder *encoder) encodePlaintext(plaintext *Plaintext) {
+
+	encoder.bgvContext.contextT.InvNTT(encoder.polypoolT, encoder.polypoolT)
+
+	encoder.lift(plaintext)
+}
+
+// lift centers the coefficients modulo T stored on the pool, copies them on each modulus of the plaintext and puts the
+// plaintext in the NTT domain. The scale of the plaintext is set to one.
+func (encoder *encoder) lift(plaintext *Plaintext) {
+
+	contextQ := encoder.bgvContext.contextQ
+
+	level := plaintext.Level()
+
+	t := encoder.params.T
+	tHalf := t >> 1
+
+	coeffsT := encoder.polypoolT.Coeffs[0]
+
+	for i := uint64(0); i < level+1; i++ {
+
+		qi := contextQ.Modulus[i]
+		coeffsQi := plaintext.value.Coeffs[i]
+
+		for j := uint64(0); j < contextQ.N; j++ {
+			if coeffsT[j] > tHalf {
+				coeffsQi[j] = qi - (t - coeffsT[j])
+			} else {
+				coeffsQi[j] = coeffsT[j]
+			}
+		}
+	}
+
+	contextQ.NTTLvl(level, plaintext.value, plaintext.value)
+
+	plaintext.scale = 1
+	plaintext.isNTT = true
+}
+
+// reduce stores on the pool the coefficients modulo T of the plaintext, divided by its scale.
+// The plaintext coefficients are first centered modulo the product of the moduli at the level of the plaintext.
+func (encoder *encoder) reduce(plaintext *Plaintext) {
+
+	contextQ := encoder.bgvContext.contextQ
+
+	level := plaintext.Level()
+
+	t := encoder.params.T
+
+	pool := encoder.polypool
+	pool.Coeffs = pool.Coeffs[:level+1]
+
+	if plaintext.isNTT {
+		contextQ.InvNTTLvl(level, plaintext.value, pool)
+	} else {
+		contextQ.CopyLvl(level, plaintext.value, pool)
+	}
+
+	coeffsT := encoder.polypoolT.Coeffs[0]
+
+	if level == 0 {
+
+		q0 := contextQ.Modulus[0]
+		q0Half := q0 >> 1
+
+		for j := uint64(0); j < contextQ.N; j++ {
+			if c := pool.Coeffs[0][j]; c > q0Half {
+				coeffsT[j] = (t - (q0-c)%t) % t
+			} else {
+				coeffsT[j] = c % t
+			}
+		}
+
+	} else {
+
+		coeffsBigint := make([]*big.Int, contextQ.N)
+
+		contextQ.PolyToBigint(pool, coeffsBigint)
+
+		Q := ring.NewUint(1)
+		for i := uint64(0); i < level+1; i++ {
+			Q.Mul(Q, ring.NewUint(contextQ.Modulus[i]))
+		}
+
+		QHalf := new(big.Int).Rsh(Q, 1)
+		T := ring.NewUint(t)
+
+		for j := uint64(0); j < contextQ.N; j++ {
+
+			if coeffsBigint[j].Cmp(QHalf) > 0 {
+				coeffsBigint[j].Sub(coeffsBigint[j], Q)
+			}
+
+			coeffsT[j] = coeffsBigint[j].Mod(coeffsBigint[j], T).Uint64()
+		}
+	}
+
+	pool.Coeffs = pool.Coeffs[:len(contextQ.Modulus)]
+
+	if plaintext.scale != 1 {
+		encoder.bgvContext.contextT.MulScalar(encoder.polypoolT, modInverse(plaintext.scale, t), encoder.polypoolT)
+	}
+}
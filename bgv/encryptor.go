@@ -0,0 +1,148 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/ring"
+	"math/big"
+)
+
+// Encryptor in an interface for encryptors
+//
+// encrypt with pk : ciphertext = [pk[0]*u + m + T*e_0, pk[1]*u + T*e_1]
+// encrypt with sk : ciphertext = [-a*sk + m + T*e, a]
+type Encryptor interface {
+	// EncryptNew encrypts the input plaintext using the stored key and returns
+	// the result on a newly created ciphertext at the level of the plaintext.
+	EncryptNew(plaintext *Plaintext) *Ciphertext
+
+	// Encrypt encrypts the input plaintext using the stored key, and returns
+	// the result on the receiver ciphertext, at the level of the receiver.
+	Encrypt(plaintext *Plaintext, ciphertext *Ciphertext)
+
+	// EncryptFromCRPNew encrypts the input plaintext using the stored key and returns
+	// the result on a newly created ciphertext, using the provided polynomial as the
+	// uniform polynomial.
+	EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *Ciphertext
+
+	// EncryptFromCRP encrypts the input plaintext using the stored key and returns
+	// the result on the receiver ciphertext, using the provided polynomial as the
+	// uniform polynomial.
+	EncryptFromCRP(plaintext *Plaintext, ciphertext *Ciphertext, crp *ring.Poly)
+}
+
+// encryptor is a struct used to encrypt plaintexts. The encryptions of zero are done by a bfv Encryptor storing
+// the public-key or the secret-key, and are then multiplied by T, so that their error is a multiple of T.
+type encryptor struct {
+	params     *Parameters
+	bgvContext *bgvContext
+	encryptor  bfv.Encryptor
+
+	// fast is true if the moduli Pi are empty, in which case the encryptions of zero are done in basis Q only
+	fast bool
+
+	// T^-1 mod Q
+	tInv *big.Int
+
+	zero     *bfv.Plaintext
+	ctxpool  *bfv.Ciphertext
+	polypool *ring.Poly
+}
+
+// NewEncryptorFromPk creates a new Encryptor with the provided public-key.
+// This Encryptor can be used to encrypt plaintexts, using the stored key.
+func NewEncryptorFromPk(params *Parameters, pk *PublicKey) Encryptor {
+	enc := newEncryptor(params)
+	enc.encryptor = bfv.NewEncryptorFromPk(params, pk)
+	return enc
+}
+
+// NewEncryptorFromSk creates a new Encryptor with the provided secret-key.
+// This Encryptor can be used to encrypt plaintexts, using the stored key.
+func NewEncryptorFromSk(params *Parameters, sk *SecretKey) Encryptor {
+	enc := newEncryptor(params)
+	enc.encryptor = bfv.NewEncryptorFromSk(params, sk)
+	return enc
+}
+
+func newEncryptor(params *Parameters) *encryptor {
+
+	if !params.IsValid() {
+		panic("cannot newEncryptor: params not valid (check if they were generated properly)")
+	}
+
+	bgvContext := newBGVContext(params)
+
+	return &encryptor{
+		params:     params.Copy(),
+		bgvContext: bgvContext,
+		fast:       len(params.Pi) == 0,
+		tInv:       genTInv(bgvContext.contextQ, params.T),
+		zero:       bfv.NewPlaintext(params),
+		ctxpool:    bfv.NewCiphertext(params, 1),
+		polypool:   bgvContext.contextQ.NewPoly(),
+	}
+}
+
+// EncryptNew encrypts the input plaintext using the stored key and returns
+// the result on a newly created ciphertext.
+func (encryptor *encryptor) EncryptNew(plaintext *Plaintext) *Ciphertext {
+	ciphertext := NewCiphertext(encryptor.params, 1, plaintext.Level())
+	encryptor.Encrypt(plaintext, ciphertext)
+	return ciphertext
+}
+
+// Encrypt encrypts the input plaintext using the stored key, and returns the result
+// on the receiver ciphertext.
+func (encryptor *encryptor) Encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
+	encryptor.encrypt(plaintext, ciphertext, nil)
+}
+
+// EncryptFromCRPNew encrypts the input plaintext using the stored key and the provided
+// uniform polynomial, and returns the result on a newly created ciphertext.
+// Panics if the Encryptor was created with the public-key.
+func (encryptor *encryptor) EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *Ciphertext {
+	ciphertext := NewCiphertext(encryptor.params, 1, plaintext.Level())
+	encryptor.EncryptFromCRP(plaintext, ciphertext, crp)
+	return ciphertext
+}
+
+// EncryptFromCRP encrypts the input plaintext using the stored key and the provided
+// uniform polynomial, and returns the result on the receiver ciphertext.
+// The uniform polynomial must be in the NTT domain and in basis Q.
+// Panics if the Encryptor was created with the public-key.
+func (encryptor *encryptor) EncryptFromCRP(plaintext *Plaintext, ciphertext *Ciphertext, crp *ring.Poly) {
+	encryptor.encrypt(plaintext, ciphertext, crp)
+}
+
+// encrypt encrypts zero with the bfv Encryptor at the maximum level, using T^-1 * crp as the uniform polynomial if crp
+// is not nil, and returns its multiplication by T, to which the plaintext is added, on the receiver ciphertext.
+func (encryptor *encryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext, crp *ring.Poly) {
+
+	if plaintext.Level() < ciphertext.Level() {
+		panic("cannot Encrypt: plaintext level is smaller than the ciphertext level")
+	}
+
+	contextQ := encryptor.bgvContext.contextQ
+
+	switch {
+	case crp != nil:
+		contextQ.MulScalarBigint(crp, encryptor.tInv, encryptor.polypool)
+		encryptor.encryptor.EncryptFromCRPFast(encryptor.zero, encryptor.ctxpool, encryptor.polypool)
+	case encryptor.fast:
+		encryptor.encryptor.EncryptFast(encryptor.zero, encryptor.ctxpool)
+	default:
+		encryptor.encryptor.Encrypt(encryptor.zero, encryptor.ctxpool)
+	}
+
+	level := ciphertext.Level()
+
+	for i := range encryptor.ctxpool.Value() {
+		contextQ.NTTLvl(level, encryptor.ctxpool.Value()[i], ciphertext.value[i])
+		contextQ.MulScalarLvl(level, ciphertext.value[i], encryptor.params.T, ciphertext.value[i])
+	}
+
+	contextQ.AddLvl(level, ciphertext.value[0], plaintext.value, ciphertext.value[0])
+
+	ciphertext.scale = plaintext.scale
+	ciphertext.isNTT = true
+}
@@ -0,0 +1,649 @@
+package bgv
+
+import (
+	"errors"
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
+	"math/big"
+)
+
+// Evaluator is an interface implementing the public methodes of the evaluator.
+type Evaluator interface {
+	Add(op0, op1 Operand, ctOut *Ciphertext)
+	AddNew(op0, op1 Operand) (ctOut *Ciphertext)
+	AddNoMod(op0, op1 Operand, ctOut *Ciphertext)
+	AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext)
+	Sub(op0, op1 Operand, ctOut *Ciphertext)
+	SubNew(op0, op1 Operand) (ctOut *Ciphertext)
+	SubNoMod(op0, op1 Operand, ctOut *Ciphertext)
+	SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext)
+	Neg(op Operand, ctOut *Ciphertext)
+	NegNew(op Operand) (ctOut *Ciphertext)
+	Reduce(op Operand, ctOut *Ciphertext)
+	ReduceNew(op Operand) (ctOut *Ciphertext)
+	MulScalar(op Operand, scalar uint64, ctOut *Ciphertext)
+	MulScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext)
+	Mul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext)
+	MulNew(op0 *Ciphertext, op1 Operand) (ctOut *Ciphertext)
+	Relinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext)
+	RelinearizeNew(ct0 *Ciphertext, evakey *EvaluationKey) (ctOut *Ciphertext)
+	SwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext)
+	SwitchKeysNew(ct0 *Ciphertext, switchkey *SwitchingKey) (ctOut *Ciphertext)
+	RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext)
+	RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext)
+	RotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext)
+	RotateRowsNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOut *Ciphertext)
+	InnerSum(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext)
+	Rescale(ct0, ctOut *Ciphertext) (err error)
+	RescaleNew(ct0 *Ciphertext) (ctOut *Ciphertext, err error)
+	DropLevel(ct0 *Ciphertext, levels uint64) (err error)
+	DropLevelNew(ct0 *Ciphertext, levels uint64) (ctOut *Ciphertext, err error)
+}
+
+// evaluator is a struct that holds the necessary elements to perform the homomorphic operations between ciphertexts and/or plaintexts.
+// It also holds a small memory pool used to store intermediate computations.
+// The key-switching operations are delegated to a bfv Evaluator (see switchKeys).
+type evaluator struct {
+	params *Parameters
+
+	bgvContext *bgvContext
+
+	bfvEvaluator bfv.Evaluator
+
+	// T^-1 mod Q
+	tInv *big.Int
+
+	tensorpool []*ring.Poly
+
+	// polynomials backing the bfv ciphertexts on which the key-switching operations are done
+	keyswitchpool [2][]*ring.Poly
+	ctxbfvpool    [2]*bfv.Ciphertext
+
+	ctxpool *Ciphertext
+}
+
+// NewEvaluator creates a new Evaluator, that can be used to do homomorphic
+// operations on ciphertexts and/or plaintexts. It stores a small pool of polynomials
+// and ciphertexts that will be used for intermediate values.
+func NewEvaluator(params *Parameters) Evaluator {
+
+	if !params.IsValid() {
+		panic("cannot NewEvaluator: params not valid (check if they were generated properly)")
+	}
+
+	bgvContext := newBGVContext(params)
+
+	return &evaluator{
+		params:       params.Copy(),
+		bgvContext:   bgvContext,
+		bfvEvaluator: bfv.NewEvaluator(params),
+		tInv:         genTInv(bgvContext.contextQ, params.T),
+		ctxbfvpool:   [2]*bfv.Ciphertext{bfv.NewCiphertext(params, 1), bfv.NewCiphertext(params, 1)},
+		ctxpool:      NewCiphertext(params, 2, params.MaxLevel()),
+	}
+}
+
+func (evaluator *evaluator) getElemAndCheckBinary(op0, op1, opOut Operand, opOutMinDegree uint64) (el0, el1, elOut *bgvElement) {
+	if op0 == nil || op1 == nil || opOut == nil {
+		panic("cannot getElemAndCheckBinary: operands cannot be nil")
+	}
+
+	if op0.Degree()+op1.Degree() == 0 {
+		panic("cannot getElemAndCheckBinary: operands cannot be both plaintexts")
+	}
+
+	if opOut.Degree() < opOutMinDegree {
+		panic("cannot getElemAndCheckBinary: receiver operand degree is too small")
+	}
+
+	el0, el1, elOut = op0.Element(), op1.Element(), opOut.Element()
+	return
+}
+
+func (evaluator *evaluator) getElemAndCheckUnary(op0, opOut Operand, opOutMinDegree uint64) (el0, elOut *bgvElement) {
+	if op0 == nil || opOut == nil {
+		panic("cannot getElemAndCheckUnary: operand cannot be nil")
+	}
+
+	if op0.Degree() == 0 {
+		panic("cannot getElemAndCheckUnary: operand cannot be plaintext")
+	}
+
+	if opOut.Degree() < opOutMinDegree {
+		panic("cannot getElemAndCheckUnary: receiver operand degree is too small")
+	}
+	el0, elOut = op0.Element(), opOut.Element()
+	return
+}
+
+// newCiphertextBinary returns a new Ciphertext of the largest degree and of the smallest level of the two operands.
+func (evaluator *evaluator) newCiphertextBinary(op0, op1 Operand) (ctOut *Ciphertext) {
+	return NewCiphertext(evaluator.params, utils.MaxUint64(op0.Degree(), op1.Degree()), utils.MinUint64(op0.Level(), op1.Level()))
+}
+
+// setLevel drops the level of the element to the input level.
+func setLevel(el *bgvElement, level uint64) {
+	for i := range el.value {
+		el.value[i].Coeffs = el.value[i].Coeffs[:level+1]
+	}
+}
+
+// matchScales returns the two elements with the same scale. If the scales differ, the plaintext operand (or op1 if both are ciphertexts)
+// is multiplied by the ratio of the scales and stored on the evaluator pool.
+func (evaluator *evaluator) matchScales(el0, el1 *bgvElement, level uint64) (*bgvElement, *bgvElement) {
+
+	if el0.scale == el1.scale {
+		return el0, el1
+	}
+
+	t := evaluator.params.T
+
+	if el0.Degree() == 0 {
+		ratio := ring.MRed(el1.scale, ring.MForm(modInverse(el0.scale, t), t, ring.BRedParams(t)), t, ring.MRedParams(t))
+		return evaluator.scaleOnPool(el0, ratio, el1.scale, level), el1
+	}
+
+	ratio := ring.MRed(el0.scale, ring.MForm(modInverse(el1.scale, t), t, ring.BRedParams(t)), t, ring.MRedParams(t))
+	return el0, evaluator.scaleOnPool(el1, ratio, el0.scale, level)
+}
+
+// scaleOnPool multiplies el by the input scalar, stores the result on the evaluator pool with the input scale and returns it.
+func (evaluator *evaluator) scaleOnPool(el *bgvElement, scalar, scale, level uint64) *bgvElement {
+
+	pool := evaluator.ctxpool.bgvElement
+
+	pool.Resize(evaluator.params, el.Degree())
+
+	for i := range el.value {
+		evaluator.mulScalarLvl(level, el.value[i], scalar, pool.value[i])
+	}
+
+	pool.scale = scale
+
+	return pool
+}
+
+// mulScalarLvl multiplies p0 by the scalar (mod T) using its centered representative, to minimize the noise growth.
+func (evaluator *evaluator) mulScalarLvl(level uint64, p0 *ring.Poly, scalar uint64, p1 *ring.Poly) {
+
+	contextQ := evaluator.bgvContext.contextQ
+
+	t := evaluator.params.T
+
+	scalar %= t
+
+	if scalar > t>>1 {
+		contextQ.MulScalarLvl(level, p0, t-scalar, p1)
+		contextQ.NegLvl(level, p1, p1)
+	} else {
+		contextQ.MulScalarLvl(level, p0, scalar, p1)
+	}
+}
+
+// evaluateInPlace applies the provided function on el0 and el1 at their smallest level, after matching their scales,
+// and returns the result in elOut.
+func (evaluator *evaluator) evaluateInPlace(el0, el1, elOut *bgvElement, evaluate func(uint64, *ring.Poly, *ring.Poly, *ring.Poly)) {
+
+	level := utils.MinUint64(utils.MinUint64(el0.Level(), el1.Level()), elOut.Level())
+
+	maxDegree := utils.MaxUint64(el0.Degree(), el1.Degree())
+	minDegree := utils.MinUint64(el0.Degree(), el1.Degree())
+
+	el0, el1 = evaluator.matchScales(el0, el1, level)
+
+	elOut.Resize(evaluator.params, maxDegree)
+	setLevel(elOut, level)
+
+	for i := uint64(0); i < minDegree+1; i++ {
+		evaluate(level, el0.value[i], el1.value[i], elOut.value[i])
+	}
+
+	// If the inputs degrees differ, it copies the remaining degree on the receiver.
+	var largest *bgvElement
+	if el0.Degree() > el1.Degree() {
+		largest = el0
+	} else if el1.Degree() > el0.Degree() {
+		largest = el1
+	}
+	if largest != nil && largest != elOut {
+		for i := minDegree + 1; i < maxDegree+1; i++ {
+			evaluator.bgvContext.contextQ.CopyLvl(level, largest.value[i], elOut.value[i])
+		}
+	}
+
+	elOut.scale = el0.scale
+	elOut.isNTT = true
+}
+
+// evaluateInPlaceUnary applies the provided function in place on el0 and returns the result in elOut.
+func (evaluator *evaluator) evaluateInPlaceUnary(el0, elOut *bgvElement, evaluate func(uint64, *ring.Poly, *ring.Poly)) {
+
+	level := utils.MinUint64(el0.Level(), elOut.Level())
+
+	setLevel(elOut, level)
+
+	for i := range el0.value {
+		evaluate(level, el0.value[i], elOut.value[i])
+	}
+
+	elOut.scale = el0.scale
+	elOut.isNTT = true
+}
+
+// Add adds op0 to op1 and returns the result in ctOut.
+// The operation is done at the smallest level of the operands; if their scales differ, the plaintext operand
+// (or op1 if both are ciphertexts) is first multiplied by the ratio of the scales.
+func (evaluator *evaluator) Add(op0, op1 Operand, ctOut *Ciphertext) {
+	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	evaluator.evaluateInPlace(el0, el1, elOut, evaluator.bgvContext.contextQ.AddLvl)
+}
+
+// AddNew adds op0 to op1 and creates a new element ctOut to store the result.
+func (evaluator *evaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = evaluator.newCiphertextBinary(op0, op1)
+	evaluator.Add(op0, op1, ctOut)
+	return
+}
+
+// AddNoMod adds op0 to op1 without modular reduction, and returns the result in cOut.
+func (evaluator *evaluator) AddNoMod(op0, op1 Operand, ctOut *Ciphertext) {
+	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	evaluator.evaluateInPlace(el0, el1, elOut, evaluator.bgvContext.contextQ.AddNoModLvl)
+}
+
+// AddNoModNew adds op0 to op1 without modular reduction and creates a new element ctOut to store the result.
+func (evaluator *evaluator) AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = evaluator.newCiphertextBinary(op0, op1)
+	evaluator.AddNoMod(op0, op1, ctOut)
+	return
+}
+
+// Sub subtracts op1 from op0 and returns the result in cOut.
+func (evaluator *evaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
+	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	evaluator.evaluateInPlace(el0, el1, elOut, evaluator.bgvContext.contextQ.SubLvl)
+
+	if el0.Degree() < el1.Degree() {
+		for i := el0.Degree() + 1; i < el1.Degree()+1; i++ {
+			evaluator.bgvContext.contextQ.NegLvl(ctOut.Level(), ctOut.Value()[i], ctOut.Value()[i])
+		}
+	}
+}
+
+// SubNew subtracts op1 from op0 and creates a new element ctOut to store the result.
+func (evaluator *evaluator) SubNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = evaluator.newCiphertextBinary(op0, op1)
+	evaluator.Sub(op0, op1, ctOut)
+	return
+}
+
+// SubNoMod subtracts op1 from op0 without modular reduction and returns the result on ctOut.
+func (evaluator *evaluator) SubNoMod(op0, op1 Operand, ctOut *Ciphertext) {
+	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	evaluator.evaluateInPlace(el0, el1, elOut, evaluator.bgvContext.contextQ.SubNoModLvl)
+
+	if el0.Degree() < el1.Degree() {
+		for i := el0.Degree() + 1; i < el1.Degree()+1; i++ {
+			evaluator.bgvContext.contextQ.NegLvl(ctOut.Level(), ctOut.Value()[i], ctOut.Value()[i])
+		}
+	}
+}
+
+// SubNoModNew subtracts op1 from op0 without modular reduction and creates a new element ctOut to store the result.
+func (evaluator *evaluator) SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = evaluator.newCiphertextBinary(op0, op1)
+	evaluator.SubNoMod(op0, op1, ctOut)
+	return
+}
+
+// Neg negates op and returns the result in ctOut.
+func (evaluator *evaluator) Neg(op Operand, ctOut *Ciphertext) {
+	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
+	evaluator.evaluateInPlaceUnary(el0, elOut, evaluator.bgvContext.contextQ.NegLvl)
+}
+
+// NegNew negates op and creates a new element to store the result.
+func (evaluator *evaluator) NegNew(op Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, op.Degree(), op.Level())
+	evaluator.Neg(op, ctOut)
+	return ctOut
+}
+
+// Reduce applies a modular reduction to op and returns the result in ctOut.
+func (evaluator *evaluator) Reduce(op Operand, ctOut *Ciphertext) {
+	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
+	evaluator.evaluateInPlaceUnary(el0, elOut, evaluator.bgvContext.contextQ.ReduceLvl)
+}
+
+// ReduceNew applies a modular reduction to op and creates a new element ctOut to store the result.
+func (evaluator *evaluator) ReduceNew(op Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, op.Degree(), op.Level())
+	evaluator.Reduce(op, ctOut)
+	return ctOut
+}
+
+// MulScalar multiplies op by a uint64 scalar modulo T and returns the result in ctOut.
+func (evaluator *evaluator) MulScalar(op Operand, scalar uint64, ctOut *Ciphertext) {
+	el0, elOut := evaluator.getElemAndCheckUnary(op, ctOut, op.Degree())
+	fun := func(level uint64, el, elOut *ring.Poly) { evaluator.mulScalarLvl(level, el, scalar, elOut) }
+	evaluator.evaluateInPlaceUnary(el0, elOut, fun)
+}
+
+// MulScalarNew multiplies op by a uint64 scalar modulo T and creates a new element ctOut to store the result.
+func (evaluator *evaluator) MulScalarNew(op Operand, scalar uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, op.Degree(), op.Level())
+	evaluator.MulScalar(op, scalar, ctOut)
+	return
+}
+
+// Mul multiplies op0 by op1 and returns the result in ctOut, at the smallest level of the operands.
+// The degree of the result is the sum of the degrees of the operands (ctOut is resized accordingly), and its scale is the product of their scales.
+func (evaluator *evaluator) Mul(op0 *Ciphertext, op1 Operand, ctOut *Ciphertext) {
+
+	el0, el1, elOut := evaluator.getElemAndCheckBinary(op0, op1, ctOut, 0)
+
+	contextQ := evaluator.bgvContext.contextQ
+
+	level := utils.MinUint64(utils.MinUint64(el0.Level(), el1.Level()), elOut.Level())
+
+	degree0, degree1 := el0.Degree(), el1.Degree()
+
+	pool := evaluator.getTensorPool(int(degree0+1) + int(degree0+degree1+1))
+
+	c0Mont := pool[:degree0+1]
+	tensor := pool[degree0+1:]
+
+	for i := range el0.value {
+		contextQ.MFormLvl(level, el0.value[i], c0Mont[i])
+	}
+
+	for i := range tensor {
+		setLevel(&bgvElement{value: tensor[i : i+1]}, level)
+		tensor[i].Zero()
+	}
+
+	// Tensoring: multiplies each elements of the operands together
+	// and adds them to their corresponding position in the new element
+	// based on their respective degree
+	for i := uint64(0); i < degree0+1; i++ {
+		for j := uint64(0); j < degree1+1; j++ {
+			contextQ.MulCoeffsMontgomeryAndAddLvl(level, c0Mont[i], el1.value[j], tensor[i+j])
+		}
+	}
+
+	elOut.Resize(evaluator.params, degree0+degree1)
+	setLevel(elOut, level)
+
+	for i := range tensor {
+		contextQ.CopyLvl(level, tensor[i], elOut.value[i])
+	}
+
+	elOut.scale = ring.MRed(el0.scale, ring.MForm(el1.scale, evaluator.params.T, ring.BRedParams(evaluator.params.T)), evaluator.params.T, ring.MRedParams(evaluator.params.T))
+	elOut.isNTT = true
+}
+
+// getTensorPool returns n polynomials of the evaluator pool, allocating them if needed.
+func (evaluator *evaluator) getTensorPool(n int) []*ring.Poly {
+	for len(evaluator.tensorpool) < n {
+		evaluator.tensorpool = append(evaluator.tensorpool, evaluator.bgvContext.contextQ.NewPoly())
+	}
+
+	for i := range evaluator.tensorpool {
+		evaluator.tensorpool[i].Coeffs = evaluator.tensorpool[i].Coeffs[:len(evaluator.bgvContext.contextQ.Modulus)]
+	}
+
+	return evaluator.tensorpool[:n]
+}
+
+// MulNew multiplies op0 by op1 and creates a new element ctOut to store the result.
+func (evaluator *evaluator) MulNew(op0 *Ciphertext, op1 Operand) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, op0.Degree()+op1.Degree(), utils.MinUint64(op0.Level(), op1.Level()))
+	evaluator.Mul(op0, op1, ctOut)
+	return
+}
+
+// Relinearize relinearizes the ciphertext ct0 of degree > 1 until it is of degree 1, and returns the result in cOut.
+//
+// It requires a correct evaluation key as additional input:
+//
+// - it must match the secret-key that was used to create the public key under which the current ct0 is encrypted.
+//
+// - it must be of degree high enough to relinearize the input ciphertext to degree 1 (e.g., a ciphertext
+// of degree 3 will require that the evaluation key stores the keys for both degree 3 and degree 2 ciphertexts).
+func (evaluator *evaluator) Relinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) {
+
+	if ct0.Degree() < 2 {
+		if ct0 != ctOut {
+			ctOut.Copy(ct0.Element())
+		}
+		return
+	}
+
+	evaluator.switchKeys(ct0, ctOut, func(ct0, ctOut *bfv.Ciphertext) {
+		evaluator.bfvEvaluator.Relinearize(ct0, evakey, ctOut)
+	})
+}
+
+// RelinearizeNew relinearizes the ciphertext ct0 of degree > 1 until it is of degree 1, and creates a new ciphertext to store the result.
+//
+// Requires a correct evaluation key as additional input:
+//
+// - it must match the secret-key that was used to create the public key under which the current ct0 is encrypted
+//
+// - it must be of degree high enough to relinearize the input ciphertext to degree 1 (e.g., a ciphertext
+// of degree 3 will require that the evaluation key stores the keys for both degree 3 and degree 2 ciphertexts).
+func (evaluator *evaluator) RelinearizeNew(ct0 *Ciphertext, evakey *EvaluationKey) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, 1, ct0.Level())
+	evaluator.Relinearize(ct0, evakey, ctOut)
+	return
+}
+
+// SwitchKeys applies the key-switching procedure to the ciphertext ct0 and returns the result in ctOut. It requires as an additional input a valid switching-key:
+// it must encrypt the target key under the public key under which ct0 is currently encrypted.
+func (evaluator *evaluator) SwitchKeys(ct0 *Ciphertext, switchKey *SwitchingKey, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot SwitchKeys: input and output must be of degree 1 to allow key switching")
+	}
+
+	evaluator.switchKeys(ct0, ctOut, func(ct0, ctOut *bfv.Ciphertext) {
+		evaluator.bfvEvaluator.SwitchKeys(ct0, switchKey, ctOut)
+	})
+}
+
+// SwitchKeysNew applies the key-switching procedure to the ciphertext ct0 and creates a new ciphertext to store the result. It requires as an additional input a valid switching-key:
+// it must encrypt the target key under the public key under which ct0 is currently encrypted.
+func (evaluator *evaluator) SwitchKeysNew(ct0 *Ciphertext, switchkey *SwitchingKey) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, 1, ct0.Level())
+	evaluator.SwitchKeys(ct0, switchkey, ctOut)
+	return
+}
+
+// RotateColumnsNew applies RotateColumns and returns the result in a new Ciphertext.
+func (evaluator *evaluator) RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, 1, ct0.Level())
+	evaluator.RotateColumns(ct0, k, evakey, ctOut)
+	return
+}
+
+// RotateColumns rotates the columns of ct0 by k positions to the left and returns the result in ctOut. As an additional input it requires a RotationKeys struct:
+//
+// - it must either store all the left and right power-of-2 rotations or the specific rotation that is requested.
+//
+// If only the power-of-two rotations are stored, the numbers k and n/2-k will be decomposed in base-2 and the rotation with the lowest
+// hamming weight will be chosen; then the specific rotation will be computed as a sum of powers of two rotations.
+func (evaluator *evaluator) RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot RotateColumns: input and or output must be of degree 1")
+	}
+
+	if k&((evaluator.bgvContext.n>>1)-1) == 0 {
+		ctOut.Copy(ct0.Element())
+		return
+	}
+
+	evaluator.switchKeys(ct0, ctOut, func(ct0, ctOut *bfv.Ciphertext) {
+		evaluator.bfvEvaluator.RotateColumns(ct0, k, evakey, ctOut)
+	})
+}
+
+// RotateRows rotates the rows of ct0 and returns the result in ctOut.
+func (evaluator *evaluator) RotateRows(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot RotateRows: input and/or output must be of degree 1")
+	}
+
+	evaluator.switchKeys(ct0, ctOut, func(ct0, ctOut *bfv.Ciphertext) {
+		evaluator.bfvEvaluator.RotateRows(ct0, evakey, ctOut)
+	})
+}
+
+// RotateRowsNew rotates the rows of ct0 and returns the result a new Ciphertext.
+func (evaluator *evaluator) RotateRowsNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(evaluator.params, 1, ct0.Level())
+	evaluator.RotateRows(ct0, evakey, ctOut)
+	return
+}
+
+// InnerSum computes the inner sum of ct0 and returns the result in ctOut. It requires a rotation key storing all the left powers of two rotations.
+// The resulting vector will be of the form [sum, sum, .., sum, sum].
+func (evaluator *evaluator) InnerSum(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSum: input and output must be of degree 1")
+	}
+
+	cTmp := NewCiphertext(evaluator.params, 1, ct0.Level())
+
+	ctOut.Copy(ct0.Element())
+
+	for i := uint64(1); i < evaluator.bgvContext.n>>1; i <<= 1 {
+		evaluator.RotateColumns(ctOut, i, evakey, cTmp)
+		evaluator.Add(cTmp, ctOut, ctOut)
+	}
+
+	evaluator.RotateRows(ctOut, evakey, cTmp)
+	evaluator.Add(ctOut, cTmp, ctOut)
+}
+
+// RescaleNew divides ct0 by the last modulus in the moduli chain, in a way that preserves its message modulo T, and returns the result in a newly created element.
+func (evaluator *evaluator) RescaleNew(ct0 *Ciphertext) (ctOut *Ciphertext, err error) {
+
+	ctOut = NewCiphertext(evaluator.params, ct0.Degree(), ct0.Level())
+
+	return ctOut, evaluator.Rescale(ct0, ctOut)
+}
+
+// Rescale divides ct0 by the last modulus q_l in the moduli chain and returns the result in ctOut, which consumes one level and divides the noise by q_l.
+// To preserve the message modulo T, the ciphertext is first multiplied by T^-1, divided by q_l with rounding using DivRoundByLastModulusNTT, and then
+// multiplied by T. As a consequence, the message is multiplied by q_l^-1 mod T, which is tracked by the scale of ctOut.
+// The level of ctOut must be at least the level of ct0.
+func (evaluator *evaluator) Rescale(ct0, ctOut *Ciphertext) (err error) {
+
+	if ct0.Level() == 0 {
+		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+	}
+
+	if ct0.Level() > ctOut.Level() {
+		panic("cannot Rescale: receiver Ciphertext level is smaller than the input Ciphertext level")
+	}
+
+	if ct0.Degree() != ctOut.Degree() {
+		panic("cannot Rescale: degrees of receiver Ciphertext and input Ciphertext do not match")
+	}
+
+	contextQ := evaluator.bgvContext.contextQ
+
+	level := ct0.Level()
+
+	ctOut.Copy(ct0.Element())
+
+	for i := range ctOut.value {
+		contextQ.MulScalarBigintLvl(level, ctOut.value[i], evaluator.tInv, ctOut.value[i])
+		contextQ.DivRoundByLastModulusNTT(ctOut.value[i])
+		contextQ.MulScalarLvl(level-1, ctOut.value[i], evaluator.params.T, ctOut.value[i])
+	}
+
+	t := evaluator.params.T
+	qlInv := modInverse(contextQ.Modulus[level]%t, t)
+
+	ctOut.scale = ring.MRed(ctOut.scale, ring.MForm(qlInv, t, ring.BRedParams(t)), t, ring.MRedParams(t))
+
+	return nil
+}
+
+// DropLevelNew reduces the level of ct0 by levels and returns the result in a newly created element.
+// No rescaling is applied during this procedure.
+func (evaluator *evaluator) DropLevelNew(ct0 *Ciphertext, levels uint64) (ctOut *Ciphertext, err error) {
+	ctOut = NewCiphertext(evaluator.params, ct0.Degree(), ct0.Level())
+	ctOut.Copy(ct0.Element())
+	return ctOut, evaluator.DropLevel(ctOut, levels)
+}
+
+// DropLevel reduces the level of ct0 by levels, and returns the result in ct0.
+// No rescaling is applied during this procedure.
+func (evaluator *evaluator) DropLevel(ct0 *Ciphertext, levels uint64) (err error) {
+
+	if ct0.Level() < levels {
+		return errors.New("cannot DropLevel: Ciphertext level is smaller than the number of levels to drop")
+	}
+
+	setLevel(ct0.bgvElement, ct0.Level()-levels)
+
+	return nil
+}
+
+// switchKeys applies the key-switching operation keySwitch of the bfv Evaluator to ct0 and returns the result in ctOut, at the
+// smallest level of the two ciphertexts. The bfv keys having an error that is not a multiple of T, ct0 is multiplied by T^-1 and
+// taken out of the NTT domain before the key-switching, and the result is multiplied by T and put back in the NTT domain after it:
+// the key-switching error is then a multiple of T and the message is preserved.
+func (evaluator *evaluator) switchKeys(ct0, ctOut *Ciphertext, keySwitch func(ct0, ctOut *bfv.Ciphertext)) {
+
+	contextQ := evaluator.bgvContext.contextQ
+
+	level := utils.MinUint64(ct0.Level(), ctOut.Level())
+
+	ctIn := evaluator.bfvCiphertextOnPool(0, ct0.Degree(), level)
+
+	for i := range ct0.value {
+		contextQ.MulScalarBigintLvl(level, ct0.value[i], evaluator.tInv, ctIn.Value()[i])
+		contextQ.InvNTTLvl(level, ctIn.Value()[i], ctIn.Value()[i])
+	}
+
+	ctKeySwitch := evaluator.bfvCiphertextOnPool(1, 1, level)
+
+	keySwitch(ctIn, ctKeySwitch)
+
+	scale := ct0.scale
+
+	ctOut.Resize(evaluator.params, 1)
+	setLevel(ctOut.bgvElement, level)
+
+	for i := range ctOut.value {
+		contextQ.NTTLvl(level, ctKeySwitch.Value()[i], ctOut.value[i])
+		contextQ.MulScalarLvl(level, ctOut.value[i], evaluator.params.T, ctOut.value[i])
+	}
+
+	ctOut.scale = scale
+	ctOut.isNTT = true
+}
+
+// bfvCiphertextOnPool returns the i-th bfv Ciphertext of the evaluator pool, set to the given degree and level.
+func (evaluator *evaluator) bfvCiphertextOnPool(i int, degree, level uint64) *bfv.Ciphertext {
+
+	for uint64(len(evaluator.keyswitchpool[i])) < degree+1 {
+		evaluator.keyswitchpool[i] = append(evaluator.keyswitchpool[i], evaluator.bgvContext.contextQ.NewPoly())
+	}
+
+	value := make([]*ring.Poly, degree+1)
+	for j := range value {
+		value[j] = &ring.Poly{Coeffs: evaluator.keyswitchpool[i][j].Coeffs[:level+1]}
+	}
+
+	evaluator.ctxbfvpool[i].SetValue(value)
+
+	return evaluator.ctxbfvpool[i]
+}
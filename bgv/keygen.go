@@ -0,0 +1,65 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/bfv"
+)
+
+// KeyGenerator is an interface implementing the methods of the keyGenerator. The BGV scheme uses the keys of the BFV scheme:
+// their errors are not multiples of T, which the Evaluator accounts for during the key-switching operations.
+type KeyGenerator = bfv.KeyGenerator
+
+// SecretKey is a structure that stores the SecretKey.
+type SecretKey = bfv.SecretKey
+
+// PublicKey is a structure that stores the PublicKey.
+type PublicKey = bfv.PublicKey
+
+// Rotation is a type used to represent the rotations types.
+type Rotation = bfv.Rotation
+
+// Constants for rotation types
+const (
+	RotationRight = bfv.RotationRight
+	RotationLeft  = bfv.RotationLeft
+	RotationRow   = bfv.RotationRow
+)
+
+// RotationKeys is a structure that stores the switching-keys required during the homomorphic rotations.
+type RotationKeys = bfv.RotationKeys
+
+// EvaluationKey is a structure that stores the switching-keys required during the relinearization.
+type EvaluationKey = bfv.EvaluationKey
+
+// SwitchingKey is a structure that stores the switching-keys required during the key-switching.
+type SwitchingKey = bfv.SwitchingKey
+
+// NewKeyGenerator creates a new KeyGenerator, from which the secret and public keys, as well as the evaluation,
+// rotation and switching keys can be generated.
+func NewKeyGenerator(params *Parameters) KeyGenerator {
+	return bfv.NewKeyGenerator(params)
+}
+
+// NewSecretKey generates a new SecretKey with zero values.
+func NewSecretKey(params *Parameters) *SecretKey {
+	return bfv.NewSecretKey(params)
+}
+
+// NewPublicKey returns a new PublicKey with zero values.
+func NewPublicKey(params *Parameters) (pk *PublicKey) {
+	return bfv.NewPublicKey(params)
+}
+
+// NewRelinKey creates a new EvaluationKey with zero values.
+func NewRelinKey(params *Parameters, maxDegree uint64) (evakey *EvaluationKey) {
+	return bfv.NewRelinKey(params, maxDegree)
+}
+
+// NewSwitchingKey returns a new SwitchingKey with zero values.
+func NewSwitchingKey(params *Parameters) (evakey *SwitchingKey) {
+	return bfv.NewSwitchingKey(params)
+}
+
+// NewRotationKeys returns a new empty RotationKeys struct.
+func NewRotationKeys() (rotKey *RotationKeys) {
+	return bfv.NewRotationKeys()
+}
@@ -0,0 +1,155 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/ring"
+)
+
+// Operand is a common interface for Ciphertext and Plaintext.
+type Operand interface {
+	Element() *bgvElement
+	Degree() uint64
+	Level() uint64
+	Scale() uint64
+}
+
+// bgvElement is a common struct for Plaintexts and Ciphertexts. It stores a value as a slice of polynomials in the NTT domain,
+// the scale (modulo T) by which the underlying message is multiplied, and an isNTT flag that indicates if the element is in the NTT domain.
+type bgvElement struct {
+	value []*ring.Poly
+	scale uint64
+	isNTT bool
+}
+
+// newBgvElement creates a new bgvElement of the target degree and level with zero values.
+func newBgvElement(params *Parameters, degree, level uint64) *bgvElement {
+
+	if !params.IsValid() {
+		panic("cannot newBgvElement: params not valid (check if they were generated properly)")
+	}
+
+	el := new(bgvElement)
+	el.value = make([]*ring.Poly, degree+1)
+	for i := uint64(0); i < degree+1; i++ {
+		el.value[i] = ring.NewPoly(1<<params.LogN, level+1)
+	}
+	el.scale = 1
+	el.isNTT = true
+	return el
+}
+
+func newBgvElementRandom(params *Parameters, degree, level uint64) *bgvElement {
+
+	if !params.IsValid() {
+		panic("cannot newBgvElementRandom: params not valid (check if they were generated properly)")
+	}
+
+	el := new(bgvElement)
+	el.value = make([]*ring.Poly, degree+1)
+	for i := uint64(0); i < degree+1; i++ {
+		el.value[i] = ring.NewPolyUniform(1<<params.LogN, level+1)
+	}
+	el.scale = 1
+	el.isNTT = true
+	return el
+}
+
+// Value returns the value of the target bgvElement (as a slice of polynomials in CRT form).
+func (el *bgvElement) Value() []*ring.Poly {
+	return el.value
+}
+
+// SetValue assigns the input slice of polynomials to the target bgvElement value.
+func (el *bgvElement) SetValue(value []*ring.Poly) {
+	el.value = value
+}
+
+// Degree returns the degree of the target bgvElement.
+func (el *bgvElement) Degree() uint64 {
+	return uint64(len(el.value) - 1)
+}
+
+// Level returns the level of the target bgvElement.
+func (el *bgvElement) Level() uint64 {
+	return uint64(len(el.value[0].Coeffs) - 1)
+}
+
+// Scale returns the scale of the target bgvElement, that is, the factor modulo T by which its message is multiplied.
+func (el *bgvElement) Scale() uint64 {
+	return el.scale
+}
+
+// SetScale sets the scale of the target bgvElement to the input scale.
+func (el *bgvElement) SetScale(scale uint64) {
+	el.scale = scale
+}
+
+// Resize resizes the target bgvElement degree to the degree given as input. If the input degree is bigger, then
+// it will append new empty polynomials; if the degree is smaller, it will delete polynomials until the degree matches
+// the input degree.
+func (el *bgvElement) Resize(params *Parameters, degree uint64) {
+	if el.Degree() > degree {
+		el.value = el.value[:degree+1]
+	} else if el.Degree() < degree {
+		for el.Degree() < degree {
+			el.value = append(el.value, []*ring.Poly{new(ring.Poly)}...)
+			el.value[el.Degree()].Coeffs = make([][]uint64, el.Level()+1)
+			for i := uint64(0); i < el.Level()+1; i++ {
+				el.value[el.Degree()].Coeffs[i] = make([]uint64, 1<<params.LogN)
+			}
+		}
+	}
+}
+
+// IsNTT returns true if the target bgvElement is in the NTT domain, and false otherwise.
+func (el *bgvElement) IsNTT() bool {
+	return el.isNTT
+}
+
+// SetIsNTT assigns the input Boolean value to the isNTT flag of the target bgvElement.
+func (el *bgvElement) SetIsNTT(value bool) {
+	el.isNTT = value
+}
+
+// CopyNew creates a new bgvElement which is a copy of the target bgvElement, and returns the value as
+// a bgvElement.
+func (el *bgvElement) CopyNew() *bgvElement {
+
+	ctxCopy := new(bgvElement)
+
+	ctxCopy.value = make([]*ring.Poly, el.Degree()+1)
+	for i := range el.value {
+		ctxCopy.value[i] = el.value[i].CopyNew()
+	}
+	ctxCopy.scale = el.scale
+	ctxCopy.isNTT = el.isNTT
+
+	return ctxCopy
+}
+
+// Copy copies the value and parameters of the input on the target bgvElement. The level of the target bgvElement
+// must be at least the level of the input.
+func (el *bgvElement) Copy(ctxCopy *bgvElement) {
+	if el != ctxCopy {
+		for i := range ctxCopy.Value() {
+			el.value[i].Coeffs = el.value[i].Coeffs[:ctxCopy.Level()+1]
+			el.value[i].Copy(ctxCopy.Value()[i])
+		}
+		el.scale = ctxCopy.scale
+		el.isNTT = ctxCopy.isNTT
+	}
+}
+
+// Element returns the target bgvElement.
+func (el *bgvElement) Element() *bgvElement {
+	return el
+}
+
+// Ciphertext returns the target bgvElement as a Ciphertext.
+func (el *bgvElement) Ciphertext() *Ciphertext {
+	return &Ciphertext{el}
+}
+
+// Plaintext returns the target bgvElement as a Plaintext.
+func (el *bgvElement) Plaintext() *Plaintext {
+	return &Plaintext{el, el.value[0]}
+}
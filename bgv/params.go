@@ -0,0 +1,40 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/bfv"
+)
+
+const (
+	// PN12QP109 is the index in DefaultParams for logQ1+P = 109
+	PN12QP109 = bfv.PN12QP109
+	// PN13QP218 is the index in DefaultParams for logQ1+P = 218
+	PN13QP218 = bfv.PN13QP218
+	// PN14QP438 is the index in DefaultParams for logQ1+P = 438
+	PN14QP438 = bfv.PN14QP438
+	// PN15QP880 is the index in DefaultParams for logQ1+P = 880
+	PN15QP880 = bfv.PN15QP880
+)
+
+// DefaultParams is a set of default BGV parameters ensuring 128 bit security. They are the default BFV parameters.
+var DefaultParams = bfv.DefaultParams
+
+// Parameters represents a given parameter set for the BGV cryptosystem. The BGV scheme shares the parameters of the BFV scheme:
+// the ciphertexts are defined over the moduli Qi, the keys over the moduli Qi and Pi, and the moduli QiMul are used by the
+// bfv Evaluator to which the key-switching operations are delegated.
+type Parameters = bfv.Parameters
+
+// Moduli stores the NTT primes of the RNS representation.
+type Moduli = bfv.Moduli
+
+// LogModuli stores the bit-length of the NTT primes of the RNS representation.
+type LogModuli = bfv.LogModuli
+
+// NewParametersFromModuli generates a new set or BGV parameters from the input parameters.
+func NewParametersFromModuli(LogN, T uint64, moduli Moduli, sigma float64) (params *Parameters) {
+	return bfv.NewParametersFromModuli(LogN, T, moduli, sigma)
+}
+
+// NewParametersFromLogModuli generates a new set or BGV parameters from the input parameters.
+func NewParametersFromLogModuli(LogN, T uint64, logModuli LogModuli, sigma float64) (params *Parameters) {
+	return bfv.NewParametersFromLogModuli(LogN, T, logModuli, sigma)
+}
@@ -0,0 +1,24 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/ring"
+)
+
+// Plaintext is a bgvElement with only one Poly.
+type Plaintext struct {
+	*bgvElement
+	value *ring.Poly
+}
+
+// NewPlaintext creates a new plaintext at the maximum level from the target parameters. Since a Plaintext stores
+// the plaintext polynomial lifted to the ciphertext modulus, it can be used with Ciphertexts of any level.
+func NewPlaintext(params *Parameters) *Plaintext {
+
+	if !params.IsValid() {
+		panic("cannot NewPlaintext: params not valid (check if they were generated properly)")
+	}
+
+	plaintext := &Plaintext{newBgvElement(params, 0, params.MaxLevel()), nil}
+	plaintext.value = plaintext.bgvElement.value[0]
+	return plaintext
+}
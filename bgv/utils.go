@@ -0,0 +1,20 @@
+package bgv
+
+import (
+	"github.com/ldsec/lattigo/ring"
+	"math/big"
+)
+
+// genTInv returns T^-1 mod Q, which is used to divide an element by a modulus while preserving its value modulo T.
+func genTInv(context *ring.Context, t uint64) *big.Int {
+	return new(big.Int).ModInverse(ring.NewUint(t), context.ModulusBigint)
+}
+
+// modInverse returns x^-1 mod t.
+func modInverse(x, t uint64) uint64 {
+	inv := new(big.Int).ModInverse(ring.NewUint(x), ring.NewUint(t))
+	if inv == nil {
+		panic("cannot modInverse: the input is not invertible modulo T")
+	}
+	return inv.Uint64()
+}
@@ -0,0 +1,187 @@
+package ckks
+
+import (
+	"math"
+)
+
+// autoEvaluator is an Evaluator which automatically manages the levels and the scales of the Ciphertexts.
+// All the methods which are not redefined are those of the wrapped Evaluator.
+type autoEvaluator struct {
+	Evaluator
+	params *Parameters
+}
+
+// TryNewAutoEvaluator is the same as NewAutoEvaluator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewAutoEvaluator(params *Parameters) (Evaluator, error) {
+
+	if err := checkParameters("NewAutoEvaluator", params); err != nil {
+		return nil, err
+	}
+
+	return NewAutoEvaluator(params), nil
+}
+
+// NewAutoEvaluator creates a new Evaluator which automatically manages the levels and the scales of the Ciphertexts,
+// so that they do not need to be tracked by the user:
+//
+// - before the additions and subtractions, the operands are brought to the same level and to exactly the same scale,
+// by multiplying the operand with the highest level by a well chosen integer constant before rescaling it.
+//
+// - after the multiplications (by a Ciphertext, a Plaintext or a constant), the result is rescaled as long as its scale is
+// greater than the default scale (see Rescale).
+//
+// All the other methods behave as for NewEvaluator.
+func NewAutoEvaluator(params *Parameters) Evaluator {
+
+	if err := checkParameters("NewAutoEvaluator", params); err != nil {
+		panic(err)
+	}
+
+	return &autoEvaluator{
+		Evaluator: NewEvaluator(params),
+		params:    params.Copy(),
+	}
+}
+
+// ShallowCopy creates a shallow copy of the target Evaluator (see Evaluator.ShallowCopy), which also manages the levels
+// and the scales automatically.
+func (eval *autoEvaluator) ShallowCopy() Evaluator {
+	return &autoEvaluator{
+		Evaluator: eval.Evaluator.ShallowCopy(),
+		params:    eval.params,
+	}
+}
+
+// WithKey creates a shallow copy of the target Evaluator storing the given keys (see Evaluator.WithKey), which also
+// manages the levels and the scales automatically.
+func (eval *autoEvaluator) WithKey(evakey *EvaluationKey, rotkeys *RotationKeys) Evaluator {
+	return &autoEvaluator{
+		Evaluator: eval.Evaluator.WithKey(evakey, rotkeys),
+		params:    eval.params,
+	}
+}
+
+// AddNew adds op0 to op1 after aligning their levels and scales, and returns the result in a newly created element.
+func (eval *autoEvaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	return eval.Evaluator.AddNew(op0, op1)
+}
+
+// Add adds op0 to op1 after aligning their levels and scales, and returns the result in ctOut.
+func (eval *autoEvaluator) Add(op0, op1 Operand, ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	eval.Evaluator.Add(op0, op1, ctOut)
+}
+
+// AddNoModNew adds op0 to op1 without modular reduction after aligning their levels and scales, and returns the result in a
+// newly created element.
+func (eval *autoEvaluator) AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	return eval.Evaluator.AddNoModNew(op0, op1)
+}
+
+// AddNoMod adds op0 to op1 without modular reduction after aligning their levels and scales, and returns the result in ctOut.
+func (eval *autoEvaluator) AddNoMod(op0, op1 Operand, ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	eval.Evaluator.AddNoMod(op0, op1, ctOut)
+}
+
+// SubNew subtracts op1 from op0 after aligning their levels and scales, and returns the result in a newly created element.
+func (eval *autoEvaluator) SubNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	return eval.Evaluator.SubNew(op0, op1)
+}
+
+// Sub subtracts op1 from op0 after aligning their levels and scales, and returns the result in ctOut.
+func (eval *autoEvaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	eval.Evaluator.Sub(op0, op1, ctOut)
+}
+
+// SubNoModNew subtracts op1 from op0 without modular reduction after aligning their levels and scales, and returns the result
+// in a newly created element.
+func (eval *autoEvaluator) SubNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	return eval.Evaluator.SubNoModNew(op0, op1)
+}
+
+// SubNoMod subtracts op1 from op0 without modular reduction after aligning their levels and scales, and returns the result in ctOut.
+func (eval *autoEvaluator) SubNoMod(op0, op1 Operand, ctOut *Ciphertext) {
+	op0, op1 = eval.align(op0, op1)
+	eval.Evaluator.SubNoMod(op0, op1, ctOut)
+}
+
+// MultByConstNew multiplies ct0 by the constant, rescales the result and returns it in a newly created element.
+func (eval *autoEvaluator) MultByConstNew(ct0 *Ciphertext, constant interface{}) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, ct0.Degree(), ct0.Level(), ct0.Scale())
+	eval.MultByConst(ct0, constant, ctOut)
+	return
+}
+
+// MultByConst multiplies ct0 by the constant, rescales the result and returns it in ctOut.
+func (eval *autoEvaluator) MultByConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+	eval.Evaluator.MultByConst(ct0, constant, ctOut)
+	eval.Evaluator.Rescale(ctOut, eval.params.Scale, ctOut)
+}
+
+// MulRelinNew multiplies op0 by op1, relinearizes the result if an evaluation key is provided, rescales it and returns it in a
+// newly created element.
+func (eval *autoEvaluator) MulRelinNew(op0, op1 Operand, evakey *EvaluationKey) (ctOut *Ciphertext) {
+	ctOut = eval.Evaluator.MulRelinNew(op0, op1, evakey)
+	eval.Evaluator.Rescale(ctOut, eval.params.Scale, ctOut)
+	return
+}
+
+// MulRelin multiplies op0 by op1, relinearizes the result if an evaluation key is provided, rescales it and returns it in ctOut.
+func (eval *autoEvaluator) MulRelin(op0, op1 Operand, evakey *EvaluationKey, ctOut *Ciphertext) {
+	eval.Evaluator.MulRelin(op0, op1, evakey, ctOut)
+	eval.Evaluator.Rescale(ctOut, eval.params.Scale, ctOut)
+}
+
+// align returns the two operands at the same level and with exactly the same scale. If the ratio between the scales is an
+// integer, the operands are returned unchanged, since the wrapped Evaluator already multiplies the operand with the smallest
+// scale by this ratio. Otherwise, the operand with the highest level is brought to the level of the other operand (or one level
+// below if their levels are equal) with the scale of the other operand, which consumes one level.
+func (eval *autoEvaluator) align(op0, op1 Operand) (Operand, Operand) {
+
+	ratio := math.Max(op0.Scale(), op1.Scale()) / math.Min(op0.Scale(), op1.Scale())
+
+	if ratio == math.Trunc(ratio) {
+		return op0, op1
+	}
+
+	if op0.Level() > op1.Level() {
+		return eval.matchScale(op0, op1.Level(), op1.Scale()), op1
+	}
+
+	if op1.Level() == 0 {
+		panic("cannot align: the scales of the operands cannot be matched at level 0")
+	}
+
+	if op0.Level() == op1.Level() {
+		return op0, eval.matchScale(op1, op1.Level()-1, op0.Scale())
+	}
+
+	return op0, eval.matchScale(op1, op0.Level(), op0.Scale())
+}
+
+// matchScale returns op at the given level and with exactly the given scale, by multiplying it by an integer constant
+// before dividing it by the moduli above the target level. The level of op must be strictly greater than the target level.
+func (eval *autoEvaluator) matchScale(op Operand, level uint64, scale float64) (ctOut *Ciphertext) {
+
+	ctOut = eval.DropLevelNew(op.Element().Ciphertext(), op.Level()-level-1)
+
+	constant := math.Round(scale * float64(eval.params.Qi[level+1]) / op.Scale())
+
+	if constant < 1 || constant >= 1<<62 {
+		panic("cannot align: the scales of the operands are too far apart")
+	}
+
+	eval.Evaluator.MultByConst(ctOut, int64(constant), ctOut)
+
+	eval.RescaleMany(ctOut, 1, ctOut)
+
+	ctOut.SetScale(scale)
+
+	return
+}
@@ -0,0 +1,114 @@
+package ckks
+
+import (
+	"math/bits"
+)
+
+func init() {
+	for _, params := range DefaultBootstrappParams {
+		params.GenFromLogModuli()
+	}
+}
+
+// BootstrappParams is a struct storing the parameters of the bootstrapping circuit. It embeds the CKKS Parameters,
+// whose moduli chain must contain, from the top to the bottom, the levels consumed by the CoeffsToSlots, the
+// levels consumed by the modular reduction (EvalMod) and the levels consumed by the SlotsToCoeffs.
+// The remaining levels are the levels available after the bootstrapping.
+type BootstrappParams struct {
+	Parameters
+	H         uint64  // Hamming weight of the secret key
+	SinRange  uint64  // K : the modular reduction is approximated on the interval [-K, K]
+	SinDeg    uint64  // Degree of the Chebyshev interpolant of the scaled cosine
+	SinRescal uint64  // Number of double angle formula evaluations
+	SinScale  float64 // Scale of the ciphertext during the modular reduction (should be close to Q0 and to the EvalMod moduli)
+	CtSDepth  uint64  // Number of levels (and of matrices) of the CoeffsToSlots
+	StCDepth  uint64  // Number of levels (and of matrices) of the SlotsToCoeffs
+}
+
+// DefaultBootstrappParams is a set of default parameters for the bootstrapping. The size of LogQP ensures 128 bit security
+// for a uniform ternary secret, which is slightly reduced by the use of a sparse secret of Hamming weight H.
+var DefaultBootstrappParams = []*BootstrappParams{
+
+	// LogSlots = 15, LogQP = 1547, 8 levels left after the bootstrapping
+	{
+		Parameters: Parameters{
+			LogN:     16,
+			LogSlots: 15,
+			LogModuli: LogModuli{
+				LogQi: []uint64{55, 43, 43, 43, 43, 43, 43, 43, 43, 45, 45, 45, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 56, 56, 56},
+				LogPi: []uint64{60, 60, 60, 60},
+			},
+			Scale: 1 << 43,
+			Sigma: 3.2,
+		},
+		H:         192,
+		SinRange:  25,
+		SinDeg:    63,
+		SinRescal: 3,
+		SinScale:  1 << 55,
+		CtSDepth:  3,
+		StCDepth:  3,
+	},
+
+	// LogSlots = 10, LogQP = 1547, 8 levels left after the bootstrapping
+	{
+		Parameters: Parameters{
+			LogN:     16,
+			LogSlots: 10,
+			LogModuli: LogModuli{
+				LogQi: []uint64{55, 43, 43, 43, 43, 43, 43, 43, 43, 45, 45, 45, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 56, 56, 56},
+				LogPi: []uint64{60, 60, 60, 60},
+			},
+			Scale: 1 << 43,
+			Sigma: 3.2,
+		},
+		H:         192,
+		SinRange:  25,
+		SinDeg:    63,
+		SinRescal: 3,
+		SinScale:  1 << 55,
+		CtSDepth:  3,
+		StCDepth:  3,
+	},
+}
+
+// Copy returns a deep copy of the target BootstrappParams.
+func (b *BootstrappParams) Copy() *BootstrappParams {
+	paramsCopy := &BootstrappParams{
+		Parameters: *b.Parameters.Copy(),
+		H:          b.H,
+		SinRange:   b.SinRange,
+		SinDeg:     b.SinDeg,
+		SinRescal:  b.SinRescal,
+		SinScale:   b.SinScale,
+		CtSDepth:   b.CtSDepth,
+		StCDepth:   b.StCDepth,
+	}
+	return paramsCopy
+}
+
+// SinDepth returns the number of levels consumed by the modular reduction. It is an upper bound that is
+// tight when SinDeg+1 is a power of two.
+func (b *BootstrappParams) SinDepth() uint64 {
+	return uint64(bits.Len64(b.SinDeg)) + 2 + b.SinRescal
+}
+
+// Depth returns the total number of levels consumed by the bootstrapping.
+func (b *BootstrappParams) Depth() uint64 {
+	return b.CtSDepth + b.SinDepth() + b.StCDepth
+}
+
+// CtSLevel returns the level at which the i-th matrix of the CoeffsToSlots is applied.
+func (b *BootstrappParams) CtSLevel(i uint64) uint64 {
+	return b.MaxLevel() - i
+}
+
+// SinLevel returns the level at which the modular reduction starts.
+func (b *BootstrappParams) SinLevel() uint64 {
+	return b.MaxLevel() - b.CtSDepth
+}
+
+// StCLevel returns the level at which the i-th matrix of the SlotsToCoeffs is applied.
+func (b *BootstrappParams) StCLevel(i uint64) uint64 {
+	return b.MaxLevel() - b.CtSDepth - b.SinDepth() - i
+}
@@ -0,0 +1,113 @@
+package ckks
+
+import (
+	"math"
+	"testing"
+)
+
+// testBootstrappParams are small (insecure) parameters used to test the correctness of the bootstrapping.
+var testBootstrappParams = []*BootstrappParams{
+
+	// Full slots
+	{
+		Parameters: Parameters{
+			LogN:     11,
+			LogSlots: 10,
+			LogModuli: LogModuli{
+				LogQi: []uint64{55, 43, 43, 45, 45, 45, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogPi: []uint64{60, 60, 60},
+			},
+			Scale: 1 << 43,
+			Sigma: 3.2,
+		},
+		H:         64,
+		SinRange:  16,
+		SinDeg:    63,
+		SinRescal: 2,
+		SinScale:  1 << 55,
+		CtSDepth:  3,
+		StCDepth:  3,
+	},
+
+	// Sparse slots
+	{
+		Parameters: Parameters{
+			LogN:     11,
+			LogSlots: 7,
+			LogModuli: LogModuli{
+				LogQi: []uint64{55, 43, 43, 45, 45, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55},
+				LogPi: []uint64{60, 60, 60},
+			},
+			Scale: 1 << 43,
+			Sigma: 3.2,
+		},
+		H:         64,
+		SinRange:  16,
+		SinDeg:    63,
+		SinRescal: 2,
+		SinScale:  1 << 55,
+		CtSDepth:  2,
+		StCDepth:  2,
+	},
+}
+
+func init() {
+	for _, params := range testBootstrappParams {
+		params.GenFromLogModuli()
+	}
+}
+
+func TestBootstrapp(t *testing.T) {
+
+	for _, btpParams := range testBootstrappParams {
+
+		params := genCkksBootstrappParams(btpParams)
+
+		btpKey := params.kgen.GenBootstrappingKey(btpParams, params.sk)
+
+		btp := NewBootstrapper(btpParams, btpKey)
+
+		t.Run(testString("Bootstrapp/", &btpParams.Parameters), func(t *testing.T) {
+
+			values, _, ciphertext := newTestVectors(params, params.encryptorSk, 1, t)
+
+			// Consumes all the levels
+			params.evaluator.DropLevel(ciphertext, ciphertext.Level())
+
+			ciphertext = btp.Bootstrapp(ciphertext)
+
+			if ciphertext.Level() != btpParams.MaxLevel()-btpParams.Depth() {
+				t.Errorf("invalid output level: %d != %d", ciphertext.Level(), btpParams.MaxLevel()-btpParams.Depth())
+			}
+
+			if math.Abs(math.Log2(ciphertext.Scale()/btpParams.Scale)) > 8 {
+				t.Errorf("invalid output scale: 2^%.2f", math.Log2(ciphertext.Scale()))
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func genCkksBootstrappParams(btpParams *BootstrappParams) (params *ckksParams) {
+
+	params = new(ckksParams)
+
+	params.params = btpParams.Parameters.Copy()
+
+	params.ckkscontext = newContext(params.params)
+
+	params.kgen = NewKeyGenerator(params.params)
+
+	params.sk, params.pk = params.kgen.GenKeyPairSparse(btpParams.H)
+
+	params.encoder = NewEncoder(params.params)
+
+	params.encryptorPk = NewEncryptorFromPk(params.params, params.pk)
+	params.encryptorSk = NewEncryptorFromSk(params.params, params.sk)
+	params.decryptor = NewDecryptor(params.params, params.sk)
+
+	params.evaluator = NewEvaluator(params.params)
+
+	return
+}
@@ -0,0 +1,623 @@
+package ckks
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/ldsec/lattigo/utils"
+)
+
+// Bootstrapper is a struct storing the elements required to bootstrapp a Ciphertext, i.e. to homomorphically
+// re-encrypt a Ciphertext at level zero to a Ciphertext at a higher level.
+type Bootstrapper struct {
+	params    *BootstrappParams
+	evaluator *evaluator
+	encoder   Encoder
+
+	slots  uint64 // Number of slots of the bootstrapped Ciphertexts
+	repack bool   // If true, the slots are sparse and the real and imaginary parts are evaluated on a single Ciphertext
+
+	chebyCos *ChebyshevInterpolation // Approximation of the scaled cosine
+
+	pDFTInv []*LinearTransform // Pre-encoded CoeffsToSlots matrices
+	pDFT    []*LinearTransform // Pre-encoded SlotsToCoeffs matrices
+
+	relinkey *EvaluationKey
+	rotkeys  *RotationKeys
+}
+
+// BootstrappingKey is a struct storing the evaluation keys required by the Bootstrapper.
+type BootstrappingKey struct {
+	relinkey *EvaluationKey
+	rotkeys  *RotationKeys
+}
+
+// NewBootstrapper creates a new Bootstrapper from the bootstrapping parameters and the bootstrapping key.
+// It pre-computes the Chebyshev approximation of the modular reduction as well as the plaintext diagonals
+// of the CoeffsToSlots and SlotsToCoeffs matrices.
+func NewBootstrapper(btpParams *BootstrappParams, btpKey *BootstrappingKey) (btp *Bootstrapper) {
+
+	if !btpParams.isValid {
+		panic("cannot NewBootstrapper: parameters are invalid (check if the generation was done properly)")
+	}
+
+	if btpParams.LogSlots >= btpParams.LogN {
+		panic("cannot NewBootstrapper: LogSlots must be smaller than LogN")
+	}
+
+	if btpParams.Depth() > btpParams.MaxLevel() {
+		panic("cannot NewBootstrapper: the moduli chain does not have enough levels for the bootstrapping circuit")
+	}
+
+	btp = new(Bootstrapper)
+
+	btp.params = btpParams.Copy()
+
+	btp.slots = 1 << btpParams.LogSlots
+	btp.repack = btpParams.LogSlots < btpParams.LogN-1
+
+	// The modular reduction is evaluated with the scale SinScale
+	paramsSin := btpParams.Parameters.Copy()
+	paramsSin.Scale = btpParams.SinScale
+
+	btp.evaluator = NewEvaluator(paramsSin).(*evaluator)
+	btp.encoder = NewEncoder(&btp.params.Parameters)
+
+	btp.relinkey = btpKey.relinkey
+	btp.rotkeys = btpKey.rotkeys
+
+	// Approximates cos(2*pi*(x-0.25)/2^r) on [-K, K], so that r evaluations of the double angle formula give sin(2*pi*x)
+	K := complex(float64(btpParams.SinRange), 0)
+	scFac := complex(float64(uint64(1)<<btpParams.SinRescal), 0)
+	btp.chebyCos = Approximate(func(x complex128) complex128 {
+		return cmplx.Cos(6.283185307179586 * (x - 0.25) / scFac)
+	}, -K, K, int(btpParams.SinDeg))
+
+	btp.genDFTMatrices()
+
+	return btp
+}
+
+// GenBootstrappingKey generates the relinearization key, the conjugation key and the rotation keys required by a
+// Bootstrapper instantiated with the provided bootstrapping parameters.
+func (keygen *keyGenerator) GenBootstrappingKey(btpParams *BootstrappParams, sk *SecretKey) (btpKey *BootstrappingKey) {
+
+	btpKey = new(BootstrappingKey)
+
+	btpKey.relinkey = keygen.GenRelinKey(sk)
+
+	btpKey.rotkeys = NewRotationKeys()
+
+	keygen.GenRot(Conjugate, sk, 0, btpKey.rotkeys)
+
+	for _, k := range btpParams.rotationsForBootstrapping() {
+		keygen.GenRot(RotationLeft, sk, k, btpKey.rotkeys)
+	}
+
+	return
+}
+
+// Bootstrapp re-encrypts the input Ciphertext at level MaxLevel - Depth and returns the result in a newly created Ciphertext.
+// The input Ciphertext must encode values bounded by one in absolute value, and its scale must be small compared to
+// the first modulus of the moduli chain (the ratio between the two determines the precision of the output).
+func (btp *Bootstrapper) Bootstrapp(ct *Ciphertext) (ctOut *Ciphertext) {
+
+	var ct0, ct1 *Ciphertext
+
+	eval := btp.evaluator
+
+	inputScale := ct.Scale()
+
+	ctOut = ct.CopyNew().Ciphertext()
+
+	// Drops the level to 0
+	if ctOut.Level() != 0 {
+		eval.DropLevel(ctOut, ctOut.Level())
+	}
+
+	// Brings the Ciphertext from Q0 to Q, its coefficients are now of the form Delta*m + Q0*I
+	ctOut = btp.modUp(ctOut)
+
+	// Sets the scale to Q0, so that the slots store (Delta*m + Q0*I)/Q0
+	ctOut.SetScale(float64(btp.params.Qi[0]))
+
+	// Sums the rotations by multiples of the number of slots to cancel the coefficients that are not encoding sparse slots
+	if btp.repack {
+		ctOut = btp.subSum(ctOut)
+	}
+
+	// Moves the coefficients to the slots and splits the real and imaginary parts
+	ct0, ct1 = btp.coeffsToSlots(ctOut)
+
+	// Evaluates the modular reduction
+	ct0 = btp.evaluateSine(ct0)
+	if ct1 != nil {
+		ct1 = btp.evaluateSine(ct1)
+	}
+
+	// Moves the slots back to the coefficients
+	ctOut = btp.slotsToCoeffs(ct0, ct1)
+
+	// The SlotsToCoeffs matrices are scaled by Q0/(2*pi*Scale), so the output stores m*inputScale/Scale
+	ctOut.MulScale(inputScale / btp.params.Scale)
+
+	return ctOut
+}
+
+// modUp takes a Ciphertext at level 0 and returns a Ciphertext at level MaxLevel whose coefficients are the
+// centered lift of the input coefficients.
+func (btp *Bootstrapper) modUp(ct *Ciphertext) (ctOut *Ciphertext) {
+
+	contextQ := btp.evaluator.ckksContext.contextQ
+
+	ctOut = NewCiphertext(&btp.params.Parameters, 1, btp.params.MaxLevel(), ct.Scale())
+
+	q0 := contextQ.Modulus[0]
+	q0Half := q0 >> 1
+
+	var coeff uint64
+
+	for u := range ct.Value() {
+
+		contextQ.InvNTTLvl(0, ct.Value()[u], ct.Value()[u])
+
+		for j := uint64(0); j < contextQ.N; j++ {
+
+			coeff = ct.Value()[u].Coeffs[0][j]
+
+			for i, qi := range contextQ.Modulus {
+				if coeff >= q0Half {
+					ctOut.Value()[u].Coeffs[i][j] = (qi - ((q0 - coeff) % qi)) % qi
+				} else {
+					ctOut.Value()[u].Coeffs[i][j] = coeff % qi
+				}
+			}
+		}
+
+		contextQ.NTT(ctOut.Value()[u], ctOut.Value()[u])
+	}
+
+	return
+}
+
+// subSum sums the input Ciphertext with its rotations by all the multiples of the number of slots, which
+// multiplies the sparse slots by N/(2*slots) and cancels all the coefficients that are not encoding them.
+func (btp *Bootstrapper) subSum(ct *Ciphertext) *Ciphertext {
+
+	eval := btp.evaluator
+
+	tmp := NewCiphertext(&btp.params.Parameters, 1, ct.Level(), ct.Scale())
+
+	for i := btp.params.LogSlots; i < btp.params.LogN-1; i++ {
+		eval.RotateColumns(ct, 1<<i, btp.rotkeys, tmp)
+		eval.Add(ct, tmp, ct)
+	}
+
+	return ct
+}
+
+// coeffsToSlots homomorphically evaluates the inverse special DFT and returns the real and imaginary parts of the result.
+// If the slots are sparse, both parts are returned on the first Ciphertext and the second one is nil.
+func (btp *Bootstrapper) coeffsToSlots(ct *Ciphertext) (ct0, ct1 *Ciphertext) {
+
+	eval := btp.evaluator
+
+	for _, pVec := range btp.pDFTInv {
+		ct = btp.multiplyByDiagMatrice(ct, pVec)
+	}
+
+	ctConj := eval.ConjugateNew(ct, btp.rotkeys)
+
+	// ct0 = 2*Re(ct), ct1 = 2*Im(ct), the factor 1/2 being already included in the matrices
+	ct1 = eval.SubNew(ct, ctConj)
+	eval.DivByi(ct1, ct1)
+
+	ct0 = ct
+	eval.Add(ct0, ctConj, ct0)
+
+	if btp.repack {
+		// Places the imaginary part in the second half of the 2*slots slots
+		eval.RotateColumns(ct1, btp.slots, btp.rotkeys, ctConj)
+		eval.Add(ct0, ctConj, ct0)
+		return ct0, nil
+	}
+
+	return ct0, ct1
+}
+
+// evaluateSine homomorphically evaluates x -> sin(2*pi*x) on the interval [-K, K], which approximates the
+// modular reduction by one when x is close to an integer.
+func (btp *Bootstrapper) evaluateSine(ct *Ciphertext) (res *Ciphertext) {
+
+	eval := btp.evaluator
+
+	res = eval.EvaluateChebyFast(ct, btp.chebyCos, btp.relinkey)
+
+	// Double angle formula : cos(2x) = 2*cos(x)^2 - 1
+	for i := uint64(0); i < btp.params.SinRescal; i++ {
+		eval.MulRelin(res, res, btp.relinkey, res)
+		eval.Rescale(res, btp.params.SinScale, res)
+		eval.Add(res, res, res)
+		eval.AddConst(res, -1, res)
+	}
+
+	return
+}
+
+// slotsToCoeffs homomorphically evaluates the special DFT on the real and imaginary parts and recombines them.
+// If ct1 is nil, ct0 is expected to store both parts in its 2*slots slots.
+func (btp *Bootstrapper) slotsToCoeffs(ct0, ct1 *Ciphertext) (ct *Ciphertext) {
+
+	eval := btp.evaluator
+
+	level := btp.pDFT[0].Level()
+
+	if ct0.Level() > level {
+		eval.DropLevel(ct0, ct0.Level()-level)
+	}
+
+	if ct1 != nil {
+
+		if ct1.Level() > level {
+			eval.DropLevel(ct1, ct1.Level()-level)
+		}
+
+		eval.MultByi(ct1, ct1)
+		eval.Add(ct0, ct1, ct0)
+	}
+
+	ct = ct0
+
+	for _, pVec := range btp.pDFT {
+		ct = btp.multiplyByDiagMatrice(ct, pVec)
+	}
+
+	return
+}
+
+// multiplyByDiagMatrice homomorphically evaluates the product between the pre-encoded matrix and the input Ciphertext,
+// followed by a rescaling. The scale of the output Ciphertext is the scale of the input Ciphertext multiplied by the
+// ratio between the scale of the matrix and the modulus of its level.
+func (btp *Bootstrapper) multiplyByDiagMatrice(ct *Ciphertext, lt *LinearTransform) (res *Ciphertext) {
+
+	eval := btp.evaluator
+
+	if ct.Level() < lt.Level() {
+		panic("cannot multiplyByDiagMatrice: input Ciphertext level is too low")
+	}
+
+	// Scale of the output Ciphertext after the rescaling
+	scale := ct.Scale() * lt.Scale() / float64(btp.params.Qi[lt.Level()])
+
+	res = eval.LinearTransformNew(ct, lt, btp.rotkeys)
+
+	eval.Rescale(res, scale, res)
+
+	return
+}
+
+// genDFTMatrices computes and encodes the diagonals of the CoeffsToSlots and SlotsToCoeffs matrices.
+func (btp *Bootstrapper) genDFTMatrices() {
+
+	params := btp.params
+
+	ctsMatrices, stcMatrices := params.dftMatrices()
+
+	var level uint64
+	var scale float64
+
+	// The plaintext scale is equal to the modulus of the level, so that the rescaling preserves the scale of the Ciphertext
+	btp.pDFTInv = make([]*LinearTransform, len(ctsMatrices))
+	for i, matrix := range ctsMatrices {
+		level = params.CtSLevel(uint64(i))
+		btp.pDFTInv[i] = NewLinearTransformBSGS(&params.Parameters, btp.encoder, matrix, level, float64(params.Qi[level]))
+	}
+
+	// Except for the last SlotsToCoeffs matrix, which brings the scale of the Ciphertext from SinScale to Scale
+	btp.pDFT = make([]*LinearTransform, len(stcMatrices))
+	for i, matrix := range stcMatrices {
+		level = params.StCLevel(uint64(i))
+		scale = float64(params.Qi[level])
+		if i == len(stcMatrices)-1 {
+			scale *= params.Scale / params.SinScale
+		}
+		btp.pDFT[i] = NewLinearTransformBSGS(&params.Parameters, btp.encoder, matrix, level, scale)
+	}
+}
+
+// rotationsForBootstrapping returns the list of left rotations required by the bootstrapping circuit.
+func (b *BootstrappParams) rotationsForBootstrapping() (rotations []uint64) {
+
+	rotations = []uint64{}
+
+	added := make(map[uint64]bool)
+	addRotation := func(k uint64) {
+		k &= (1 << (b.LogN - 1)) - 1
+		if k != 0 && !added[k] {
+			added[k] = true
+			rotations = append(rotations, k)
+		}
+	}
+
+	// SubSum and repacking rotations
+	for i := b.LogSlots; i < b.LogN-1; i++ {
+		addRotation(1 << i)
+	}
+
+	ctsMatrices, stcMatrices := b.dftMatrices()
+
+	// Rotations of the baby-step giant-step evaluation of the DFT matrices
+	for _, matrix := range append(ctsMatrices, stcMatrices...) {
+
+		var slots uint64
+		diags := make([]uint64, 0, len(matrix))
+		for k, diag := range matrix {
+			diags = append(diags, k)
+			slots = uint64(len(diag))
+		}
+
+		for _, k := range utils.BSGSRotations(diags, utils.FindBestBSGSSplit(diags, slots)) {
+			addRotation(k)
+		}
+	}
+
+	return
+}
+
+// dftMatrices returns the diagonals of the CoeffsToSlots and SlotsToCoeffs matrices, in the order in which they
+// are applied. If the slots are sparse, the first CoeffsToSlots matrices only act on the first half of the
+// 2*slots slots, and the first SlotsToCoeffs matrix recombines the real and imaginary parts stored in each half.
+func (b *BootstrappParams) dftMatrices() (ctsMatrices, stcMatrices []map[uint64][]complex128) {
+
+	slots := uint64(1) << b.LogSlots
+	repack := b.LogSlots < b.LogN-1
+
+	// CoeffsToSlots : the stages of the inverse special FFT (without bit-reversal) from the largest to the smallest,
+	// scaled by 1/(2*N/2) to account for the 1/slots normalization, the SubSum and the real/imaginary extraction.
+	ctsStages := make([]map[int][]complex128, b.LogSlots)
+	for i := range ctsStages {
+		ctsStages[i] = invFFTStage(slots>>uint64(i), slots)
+	}
+
+	ctsMatricesSigned := mergeDFTStages(ctsStages, b.CtSDepth, slots)
+
+	scaling := math.Pow(1.0/float64(uint64(1)<<(b.LogN)), 1.0/float64(len(ctsMatricesSigned)))
+	for _, matrix := range ctsMatricesSigned {
+		for _, diag := range matrix {
+			for i := range diag {
+				diag[i] *= complex(scaling, 0)
+			}
+		}
+	}
+
+	ctsMatrices = make([]map[uint64][]complex128, len(ctsMatricesSigned))
+	for i, matrix := range ctsMatricesSigned {
+		if repack {
+			ctsMatrices[i] = embedDiagMatrice(matrix, slots)
+		} else {
+			ctsMatrices[i] = foldDiagMatrice(matrix, slots)
+		}
+	}
+
+	// SlotsToCoeffs : the stages of the special FFT (without bit-reversal) from the smallest to the largest
+	stcStages := make([]map[int][]complex128, b.LogSlots)
+	for i := range stcStages {
+		stcStages[i] = fftStage(2<<uint64(i), slots)
+	}
+
+	stcMatricesSigned := mergeDFTStages(stcStages, b.StCDepth, slots)
+
+	// Scaled by Q0/(2*pi*Scale) to map sin(2*pi*Scale*m/Q0) back to m
+	scaling = math.Pow(float64(b.Qi[0])/(6.283185307179586*b.Scale), 1.0/float64(len(stcMatricesSigned)))
+	for _, matrix := range stcMatricesSigned {
+		for _, diag := range matrix {
+			for i := range diag {
+				diag[i] *= complex(scaling, 0)
+			}
+		}
+	}
+
+	stcMatrices = make([]map[uint64][]complex128, len(stcMatricesSigned))
+	for i, matrix := range stcMatricesSigned {
+		if repack && i == 0 {
+			stcMatrices[i] = repackDiagMatrice(matrix, slots)
+		} else {
+			stcMatrices[i] = foldDiagMatrice(matrix, slots)
+		}
+	}
+
+	return
+}
+
+// fftStage returns the diagonals, indexed by their signed offset, of the butterfly stage of size length of the special FFT.
+func fftStage(length, slots uint64) (matrix map[int][]complex128) {
+
+	lenh := length >> 1
+	lenq := length << 2
+
+	matrix = make(map[int][]complex128)
+	matrix[0] = make([]complex128, slots)
+	matrix[int(lenh)] = make([]complex128, slots)
+	matrix[-int(lenh)] = make([]complex128, slots)
+
+	var w complex128
+	for i := uint64(0); i < slots; i += length {
+		for j, pow5 := uint64(0), uint64(1); j < lenh; j, pow5 = j+1, (pow5*GaloisGen)%lenq {
+			w = cmplx.Exp(complex(0, 6.283185307179586*float64(pow5)/float64(lenq)))
+			matrix[0][i+j] = 1
+			matrix[int(lenh)][i+j] = w
+			matrix[-int(lenh)][i+j+lenh] = 1
+			matrix[0][i+j+lenh] = -w
+		}
+	}
+
+	return
+}
+
+// invFFTStage returns the diagonals, indexed by their signed offset, of the butterfly stage of size length of the inverse special FFT.
+func invFFTStage(length, slots uint64) (matrix map[int][]complex128) {
+
+	lenh := length >> 1
+	lenq := length << 2
+
+	matrix = make(map[int][]complex128)
+	matrix[0] = make([]complex128, slots)
+	matrix[int(lenh)] = make([]complex128, slots)
+	matrix[-int(lenh)] = make([]complex128, slots)
+
+	var w complex128
+	for i := uint64(0); i < slots; i += length {
+		for j, pow5 := uint64(0), uint64(1); j < lenh; j, pow5 = j+1, (pow5*GaloisGen)%lenq {
+			w = cmplx.Exp(complex(0, -6.283185307179586*float64(pow5)/float64(lenq)))
+			matrix[0][i+j] = 1
+			matrix[int(lenh)][i+j] = 1
+			matrix[-int(lenh)][i+j+lenh] = w
+			matrix[0][i+j+lenh] = -w
+		}
+	}
+
+	return
+}
+
+// mergeDFTStages groups the stages, given in the order in which they are applied, into depth matrices.
+// If there are fewer stages than depth, the remaining matrices are the identity.
+func mergeDFTStages(stages []map[int][]complex128, depth, slots uint64) (matrices []map[int][]complex128) {
+
+	matrices = make([]map[int][]complex128, depth)
+
+	nbStages := uint64(len(stages))
+
+	for i, idx := uint64(0), uint64(0); i < depth; i++ {
+
+		identity := make([]complex128, slots)
+		for j := range identity {
+			identity[j] = 1
+		}
+
+		matrices[i] = map[int][]complex128{0: identity}
+
+		// The first matrices are given one additional stage if the stages cannot be evenly distributed
+		merge := nbStages / depth
+		if i < nbStages%depth {
+			merge++
+		}
+
+		for j := uint64(0); j < merge; j++ {
+			matrices[i] = multiplyDiagMatrices(stages[idx], matrices[i], slots)
+			idx++
+		}
+	}
+
+	return
+}
+
+// multiplyDiagMatrices returns the diagonals of the matrix product A*B, where A and B are given by their diagonals
+// indexed by signed offsets, i.e. A[i][i+k] = a[k][i].
+func multiplyDiagMatrices(a, b map[int][]complex128, slots uint64) (c map[int][]complex128) {
+
+	c = make(map[int][]complex128)
+
+	for ka, diagA := range a {
+		for kb, diagB := range b {
+
+			if c[ka+kb] == nil {
+				c[ka+kb] = make([]complex128, slots)
+			}
+
+			diagC := c[ka+kb]
+
+			for i := uint64(0); i < slots; i++ {
+				diagC[i] += diagA[i] * diagB[(i+uint64(ka+int(slots)))%slots]
+			}
+		}
+	}
+
+	for k, diag := range c {
+		if isZero(diag) {
+			delete(c, k)
+		}
+	}
+
+	return
+}
+
+// foldDiagMatrice maps the signed offsets of the diagonals to rotations to the left over slots slots.
+func foldDiagMatrice(matrix map[int][]complex128, slots uint64) (res map[uint64][]complex128) {
+
+	res = make(map[uint64][]complex128)
+
+	for k, diag := range matrix {
+
+		rot := uint64(k+int(slots)) % slots
+
+		if res[rot] == nil {
+			res[rot] = make([]complex128, slots)
+		}
+
+		for i := range diag {
+			res[rot][i] += diag[i]
+		}
+	}
+
+	return
+}
+
+// embedDiagMatrice embeds the matrix M over slots slots into the matrix [[M, 0], [0, 0]] over 2*slots slots.
+func embedDiagMatrice(matrix map[int][]complex128, slots uint64) (res map[uint64][]complex128) {
+
+	res = make(map[uint64][]complex128)
+
+	for k, diag := range matrix {
+
+		rot := uint64(k+int(2*slots)) % (2 * slots)
+
+		if res[rot] == nil {
+			res[rot] = make([]complex128, 2*slots)
+		}
+
+		for i := range diag {
+			res[rot][i] += diag[i]
+		}
+	}
+
+	return
+}
+
+// repackDiagMatrice embeds the matrix M over slots slots into the matrix [[M, i*M], [M, i*M]] over 2*slots slots,
+// which maps the vector [Re(z), Im(z)] to the vector [M*z, M*z].
+func repackDiagMatrice(matrix map[int][]complex128, slots uint64) (res map[uint64][]complex128) {
+
+	res = make(map[uint64][]complex128)
+
+	for k, diag := range matrix {
+
+		rot0 := uint64(k+int(2*slots)) % (2 * slots)
+		rot1 := (rot0 + slots) % (2 * slots)
+
+		if res[rot0] == nil {
+			res[rot0] = make([]complex128, 2*slots)
+		}
+
+		if res[rot1] == nil {
+			res[rot1] = make([]complex128, 2*slots)
+		}
+
+		for i := range diag {
+			res[rot0][i] += diag[i]
+			res[rot0][i+int(slots)] += complex(0, 1) * diag[i]
+			res[rot1][i] += complex(0, 1) * diag[i]
+			res[rot1][i+int(slots)] += diag[i]
+		}
+	}
+
+	return
+}
+
+func isZero(vec []complex128) bool {
+	for i := range vec {
+		if vec[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -9,14 +9,25 @@ type Ciphertext struct {
 	*ckksElement
 }
 
+// TryNewCiphertext is the same as NewCiphertext, but returns an Error instead of panicking if the parameters or the
+// level are invalid.
+func TryNewCiphertext(params *Parameters, degree uint64, level uint64, scale float64) (*Ciphertext, error) {
+
+	if err := checkLevel("NewCiphertext", params, level); err != nil {
+		return nil, err
+	}
+
+	return NewCiphertext(params, degree, level, scale), nil
+}
+
 // NewCiphertext creates a new Ciphertext parameterized by degree, level and scale.
 func NewCiphertext(params *Parameters, degree uint64, level uint64, scale float64) (ciphertext *Ciphertext) {
 
-	if !params.isValid {
-		panic("cannot NewCiphertext: parameters are invalid (check if the generation was done properly)")
+	if err := checkLevel("NewCiphertext", params, level); err != nil {
+		panic(err)
 	}
 
-	ciphertext = &Ciphertext{&ckksElement{}}
+	ciphertext = &Ciphertext{ckksElement: &ckksElement{}}
 
 	ciphertext.value = make([]*ring.Poly, degree+1)
 	for i := uint64(0); i < degree+1; i++ {
@@ -36,7 +47,7 @@ func NewCiphertextRandom(params *Parameters, degree, level uint64, scale float64
 		panic("cannot NewCiphertextRandom: parameters are invalid (check if the generation was done properly)")
 	}
 
-	ciphertext = &Ciphertext{&ckksElement{}}
+	ciphertext = &Ciphertext{ckksElement: &ckksElement{}}
 
 	ciphertext.value = make([]*ring.Poly, degree+1)
 	for i := uint64(0); i < degree+1; i++ {
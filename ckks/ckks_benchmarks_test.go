@@ -12,6 +12,9 @@ func BenchmarkCKKSScheme(b *testing.B) {
 	b.Run("Decrypt", benchDecrypt)
 	b.Run("Evaluator", benchEvaluator)
 	b.Run("HoistedRotations", benchHoistedRotations)
+	b.Run("LinearTransform", benchLinearTransform)
+	b.Run("Comparison", benchComparison)
+	b.Run("ElementaryFunctions", benchElementaryFunctions)
 }
 
 func benchEncoder(b *testing.B) {
@@ -238,3 +241,113 @@ func benchHoistedRotations(b *testing.B) {
 		})
 	}
 }
+
+func benchLinearTransform(b *testing.B) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		params := genCkksParams(parameters)
+
+		slots := uint64(1 << parameters.LogSlots)
+
+		// Banded matrix with 32 non-zero diagonals
+		diagMatrix := make(map[uint64][]complex128)
+		for k := uint64(0); k < 32; k++ {
+			diagMatrix[k] = make([]complex128, slots)
+			for i := range diagMatrix[k] {
+				diagMatrix[k][i] = randomComplex(-1, 1)
+			}
+		}
+
+		level := parameters.MaxLevel()
+		scale := float64(parameters.Qi[level])
+
+		ltNaive := NewLinearTransform(parameters, params.encoder, diagMatrix, level, scale)
+		ltBSGS := NewLinearTransformBSGS(parameters, params.encoder, diagMatrix, level, scale)
+
+		rotkey := NewRotationKeys()
+		for _, k := range append(ltNaive.Rotations(), ltBSGS.Rotations()...) {
+			params.kgen.GenRot(RotationLeft, params.sk, k, rotkey)
+		}
+
+		ciphertext := NewCiphertextRandom(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+		ctOut := NewCiphertext(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+
+		b.Run(testString("Naive/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.LinearTransform(ciphertext, ltNaive, rotkey, ctOut)
+			}
+		})
+
+		b.Run(testString("BSGS/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.LinearTransform(ciphertext, ltBSGS, rotkey, ctOut)
+			}
+		})
+	}
+}
+
+func benchComparison(b *testing.B) {
+
+	sign := NewSignPolynomial(0.1, []int{15, 15})
+
+	for _, parameters := range testParams.ckksParameters {
+
+		if parameters.MaxLevel() < sign.Depth()+1 {
+			continue
+		}
+
+		params := genCkksParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk)
+
+		ciphertext1 := NewCiphertextRandom(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+		ciphertext2 := NewCiphertextRandom(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+
+		b.Run(testString("Sign/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.Sign(ciphertext1, sign, rlk)
+			}
+		})
+
+		b.Run(testString("Max/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.Max(ciphertext1, ciphertext2, sign, rlk)
+			}
+		})
+	}
+}
+
+func benchElementaryFunctions(b *testing.B) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		if parameters.MaxLevel() < 9 {
+			continue
+		}
+
+		params := genCkksParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk)
+
+		ciphertext := NewCiphertextRandom(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+
+		b.Run(testString("Exp/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.Exp(ciphertext, -1, 1, 20, rlk)
+			}
+		})
+
+		b.Run(testString("Sigmoid/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.Sigmoid(ciphertext, -4, 4, 20, rlk)
+			}
+		})
+
+		b.Run(testString("InvSqrt/", parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.evaluator.InvSqrt(ciphertext, 0.5, 4, 20, rlk)
+			}
+		})
+	}
+}
@@ -1,12 +1,13 @@
 package ckks
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"math/cmplx"
 	"math/rand"
-	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,6 +67,8 @@ func init() {
 func TestCKKS(t *testing.T) {
 	t.Run("Encoder", testEncoder)
 	t.Run("Encryptor", testEncryptor)
+	t.Run("Precision", testPrecision)
+	t.Run("Errors", testErrors)
 	t.Run("Evaluator/Add", testEvaluatorAdd)
 	t.Run("Evaluator/Sub", testEvaluatorSub)
 	t.Run("Evaluator/Rescale", testEvaluatorRescale)
@@ -76,9 +79,15 @@ func TestCKKS(t *testing.T) {
 	t.Run("Evaluator/Functions", testFunctions)
 	t.Run("Evaluator/EvaluatePoly", testEvaluatePoly)
 	t.Run("Evaluator/ChebyshevInterpolator", testChebyshevInterpolator)
+	t.Run("Remez", testRemez)
+	t.Run("Evaluator/Comparison", testComparison)
+	t.Run("Evaluator/ElementaryFunctions", testElementaryFunctions)
+	t.Run("Evaluator/AutoEvaluator", testAutoEvaluator)
 	t.Run("Evaluator/SwitchKeys", testSwitchKeys)
 	t.Run("Evaluator/Conjugate", testConjugate)
 	t.Run("Evaluator/RotateColumns", testRotateColumns)
+	t.Run("Evaluator/LinearTransform", testLinearTransform)
+	t.Run("Evaluator/Gadget", testGadget)
 	t.Run("Marshalling", testMarshaller)
 }
 
@@ -168,118 +177,170 @@ func verifyTestVectors(contextParams *ckksParams, decryptor Decryptor, valuesWan
 
 	valuesTest = contextParams.encoder.Decode(plaintextTest, slots)
 
-	var deltaReal, deltaImag float64
+	precStats := GetPrecisionStats(valuesWant, valuesTest)
 
-	var minprec, maxprec, meanprec, medianprec complex128
-
-	diff := make([]complex128, slots)
-
-	minprec = complex(0, 0)
-	maxprec = complex(1, 1)
+	if testParams.verbose {
+		t.Log(precStats.String())
+	}
 
-	meanprec = complex(0, 0)
+	if real(precStats.MedianPrecision) < testParams.medianprec || imag(precStats.MedianPrecision) < testParams.medianprec {
+		t.Errorf("Median precision error: target (%.2f, %.2f) > result (%.2f, %.2f)", testParams.medianprec, testParams.medianprec, real(precStats.MedianPrecision), imag(precStats.MedianPrecision))
+	}
+}
 
-	distribReal := make(map[uint64]uint64)
-	distribImag := make(map[uint64]uint64)
+func testPrecision(t *testing.T) {
 
-	for i := range valuesWant {
+	t.Run("Stats", func(t *testing.T) {
 
-		deltaReal = math.Abs(real(valuesTest[i]) - real(valuesWant[i]))
-		deltaImag = math.Abs(imag(valuesTest[i]) - imag(valuesWant[i]))
+		valuesWant := make([]complex128, 4)
+		valuesTest := []complex128{
+			complex(math.Exp2(-10), math.Exp2(-20)),
+			complex(-math.Exp2(-12), math.Exp2(-20)),
+			complex(math.Exp2(-14), -math.Exp2(-21)),
+			complex(math.Exp2(-16), 0),
+		}
 
-		diff[i] += complex(deltaReal, 0)
-		diff[i] += complex(0, deltaImag)
+		precStats := GetPrecisionStats(valuesWant, valuesTest)
 
-		meanprec += diff[i]
+		if precStats.MinPrecision != complex(10, 20) || precStats.MaxPrecision != complex(16, math.Inf(1)) {
+			t.Errorf("wrong minimum/maximum precision (%v, %v)", precStats.MinPrecision, precStats.MaxPrecision)
+		}
 
-		if real(diff[i]) > real(minprec) {
-			minprec = complex(real(diff[i]), 0)
+		if precStats.MedianDelta != complex((math.Exp2(-12)+math.Exp2(-14))/2, (math.Exp2(-21)+math.Exp2(-20))/2) {
+			t.Errorf("wrong median error %v", precStats.MedianDelta)
 		}
 
-		if imag(diff[i]) > imag(minprec) {
-			minprec = complex(real(minprec), imag(diff[i]))
+		if precStats.MeanDelta != complex((math.Exp2(-10)+math.Exp2(-12)+math.Exp2(-14)+math.Exp2(-16))/4, (2*math.Exp2(-20)+math.Exp2(-21))/4) {
+			t.Errorf("wrong mean error %v", precStats.MeanDelta)
 		}
 
-		if real(diff[i]) < real(maxprec) {
-			maxprec = complex(real(diff[i]), 0)
+		realDist := []PrecisionBin{{10, 1}, {12, 1}, {14, 1}, {16, 1}}
+		imagDist := []PrecisionBin{{20, 2}, {21, 1}, {math.Inf(1), 1}}
+
+		for i := range realDist {
+			if precStats.RealDist[i] != realDist[i] {
+				t.Errorf("wrong real histogram %v", precStats.RealDist)
+			}
 		}
 
-		if imag(diff[i]) < imag(maxprec) {
-			maxprec = complex(real(maxprec), imag(diff[i]))
+		for i := range imagDist {
+			if precStats.ImagDist[i] != imagDist[i] {
+				t.Errorf("wrong imaginary histogram %v", precStats.ImagDist)
+			}
 		}
+	})
 
-		distribReal[uint64(math.Floor(math.Log2(1/real(diff[i]))))]++
-		distribImag[uint64(math.Floor(math.Log2(1/imag(diff[i]))))]++
-	}
+	for _, parameters := range testParams.ckksParameters {
 
-	meanprec /= complex(float64(slots), 0)
-	medianprec = calcmedian(diff)
+		params := genCkksParams(parameters)
 
-	if testParams.verbose {
-		t.Logf("Minimum precision : (%.2f, %.2f) bits \n", math.Log2(1/real(minprec)), math.Log2(1/imag(minprec)))
-		t.Logf("Maximum precision : (%.2f, %.2f) bits \n", math.Log2(1/real(maxprec)), math.Log2(1/imag(maxprec)))
-		t.Logf("Mean    precision : (%.2f, %.2f) bits \n", math.Log2(1/real(meanprec)), math.Log2(1/imag(meanprec)))
-		t.Logf("Median  precision : (%.2f, %.2f) bits \n", math.Log2(1/real(medianprec)), math.Log2(1/imag(medianprec)))
-		t.Log()
-	}
+		t.Run(testString("NoiseStandardDeviation/", parameters), func(t *testing.T) {
+
+			slots := uint64(1 << parameters.LogSlots)
+
+			sigma := float64(1 << 10)
+
+			values, plaintext, _ := newTestVectors(params, nil, 1, t)
+
+			noise := params.ckkscontext.contextQ.NewPoly()
+			params.ckkscontext.contextQ.SampleGaussianNTT(noise, sigma, uint64(6*sigma))
+			params.ckkscontext.contextQ.Add(plaintext.value, noise, plaintext.value)
 
-	if math.Log2(1/real(medianprec)) < testParams.medianprec || math.Log2(1/imag(medianprec)) < testParams.medianprec {
-		t.Errorf("Mean precision error: target (%.2f, %.2f) > result (%.2f, %.2f)", testParams.medianprec, testParams.medianprec, math.Log2(1/real(medianprec)), math.Log2(1/imag(medianprec)))
+			valuesTest := params.encoder.Decode(plaintext, slots)
+
+			if sigmaTest := EstimateNoiseStandardDeviation(values, valuesTest, plaintext.Scale()); math.Abs(sigmaTest-sigma) > 0.05*sigma {
+				t.Errorf("estimated standard deviation %f, expected %f", sigmaTest, sigma)
+			}
+		})
 	}
 }
 
-func calcmedian(values []complex128) (median complex128) {
+func testEncoder(t *testing.T) {
 
-	tmp := make([]float64, len(values))
+	for _, parameters := range testParams.ckksParameters {
 
-	for i := range values {
-		tmp[i] = real(values[i])
-	}
+		params := genCkksParams(parameters)
 
-	sort.Float64s(tmp)
+		t.Run(testString("Encode/", parameters), func(t *testing.T) {
 
-	for i := range values {
-		values[i] = complex(tmp[i], imag(values[i]))
-	}
+			values, plaintext, _ := newTestVectors(params, nil, 1, t)
 
-	for i := range values {
-		tmp[i] = imag(values[i])
-	}
+			verifyTestVectors(params, params.decryptor, values, plaintext, t)
+		})
 
-	sort.Float64s(tmp)
+		t.Run(testString("EncodeReal/", parameters), func(t *testing.T) {
 
-	for i := range values {
-		values[i] = complex(real(values[i]), tmp[i])
-	}
+			slots := uint64(1 << parameters.LogSlots)
 
-	index := len(values) / 2
+			values := make([]float64, slots)
+			for i := range values {
+				values[i] = randomFloat(-1, 1)
+			}
 
-	if len(values)&1 == 1 {
-		return values[index]
-	}
+			plaintext := params.encoder.EncodeRealNew(values, slots)
 
-	if index+1 == len(values) {
-		return values[index]
-	}
+			valuesTest := params.encoder.DecodeReal(plaintext, slots)
 
-	return (values[index] + values[index+1]) / 2
-}
+			valuesWant := make([]complex128, slots)
+			for i := range values {
+				valuesWant[i] = complex(values[i], 0)
+				if math.Abs(valuesTest[i]-values[i]) > math.Exp2(-testParams.medianprec) {
+					t.Errorf("invalid decoded value at index %d: %f != %f", i, valuesTest[i], values[i])
+					break
+				}
+			}
 
-func testEncoder(t *testing.T) {
+			verifyTestVectors(params, params.decryptor, valuesWant, plaintext, t)
+		})
 
-	for _, parameters := range testParams.ckksParameters {
+		t.Run(testString("EncodeCoeffs/", parameters), func(t *testing.T) {
 
-		params := genCkksParams(parameters)
+			N := uint64(1 << parameters.LogN)
 
-		t.Run(testString("Encode/", parameters), func(t *testing.T) {
+			values := make([]float64, N)
+			for i := range values {
+				values[i] = randomFloat(-1, 1)
+			}
 
-			values, plaintext, _ := newTestVectors(params, nil, 1, t)
+			plaintext := NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale)
+			params.encoder.EncodeCoeffs(values, plaintext)
 
-			verifyTestVectors(params, params.decryptor, values, plaintext, t)
+			verifyCoeffs(values, params.encoder.DecodeCoeffs(plaintext), t)
+
+			// Multiplying by 0.5 * X^3 negacyclically shifts the coefficients by 3 positions
+			monomial := make([]float64, 4)
+			monomial[3] = 0.5
+
+			plaintextMonomial := NewPlaintext(parameters, parameters.MaxLevel(), float64(parameters.Qi[parameters.MaxLevel()]))
+			params.encoder.EncodeCoeffs(monomial, plaintextMonomial)
+
+			ciphertext := params.encryptorSk.EncryptNew(plaintext)
+			params.evaluator.MulRelin(ciphertext, plaintextMonomial, nil, ciphertext)
+			params.evaluator.Rescale(ciphertext, parameters.Scale, ciphertext)
+
+			valuesWant := make([]float64, N)
+			for i := uint64(0); i < N; i++ {
+				if i < 3 {
+					valuesWant[i] = -0.5 * values[N-3+i]
+				} else {
+					valuesWant[i] = 0.5 * values[i-3]
+				}
+			}
+
+			verifyCoeffs(valuesWant, params.encoder.DecodeCoeffs(params.decryptor.DecryptNew(ciphertext)), t)
 		})
 	}
 }
 
+func verifyCoeffs(valuesWant, valuesTest []float64, t *testing.T) {
+	for i := range valuesWant {
+		if math.Abs(valuesWant[i]-valuesTest[i]) > math.Exp2(-testParams.medianprec) {
+			t.Errorf("invalid coefficient at index %d: %f != %f", i, valuesTest[i], valuesWant[i])
+			break
+		}
+	}
+}
+
 func testEncryptor(t *testing.T) {
 
 	for _, parameters := range testParams.ckksParameters {
@@ -340,6 +401,123 @@ func testEncryptor(t *testing.T) {
 	}
 }
 
+func testErrors(t *testing.T) {
+
+	parameters := testParams.ckksParameters[0]
+
+	params := genCkksParams(parameters)
+
+	// Keys of another ring degree
+	otherParameters := testParams.ckksParameters[1]
+	otherSk := NewKeyGenerator(otherParameters).GenSecretKey()
+
+	checkError := func(t *testing.T, err, target error) {
+		var ckksErr *Error
+		if !errors.As(err, &ckksErr) || !errors.Is(err, target) {
+			t.Errorf("invalid error: have %v, want %v", err, target)
+		}
+	}
+
+	t.Run(testString("Constructors/", parameters), func(t *testing.T) {
+
+		_, err := TryNewEncoder(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewKeyGenerator(nil)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewEvaluator(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewEncryptorFromPk(parameters, nil)
+		checkError(t, err, ErrMissingKey)
+
+		_, err = TryNewEncryptorFromSk(parameters, otherSk)
+		checkError(t, err, ErrRingDegreeMismatch)
+
+		_, err = TryNewDecryptor(parameters, otherSk)
+		checkError(t, err, ErrRingDegreeMismatch)
+
+		if _, err = TryNewDecryptor(parameters, params.sk); err != nil {
+			t.Error(err)
+		}
+
+		_, err = TryNewCiphertext(parameters, 1, parameters.MaxLevel()+1, parameters.Scale)
+		checkError(t, err, ErrLevelMismatch)
+
+		_, err = TryNewPlaintext(nil, 0, parameters.Scale)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewPublicKey(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewAutoEvaluator(new(Parameters))
+		checkError(t, err, ErrInvalidParameters)
+
+		noSpecialPrimes := parameters.Copy()
+		noSpecialPrimes.Pi = nil
+
+		_, err = TryNewRelinKey(noSpecialPrimes)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewSwitchingKey(noSpecialPrimes)
+		checkError(t, err, ErrInvalidParameters)
+
+		_, err = TryNewLinearTransform(parameters, params.encoder, map[uint64][]complex128{0: make([]complex128, 2), 1: make([]complex128, 4)}, 0, parameters.Scale)
+		checkError(t, err, ErrInvalidMatrix)
+
+		_, err = TryNewLinearTransformBSGS(parameters, params.encoder, map[uint64][]complex128{0: make([]complex128, 3)}, 0, parameters.Scale)
+		checkError(t, err, ErrInvalidMatrix)
+
+		_, err = TryNewLinearTransform(parameters, params.encoder, map[uint64][]complex128{1: make([]complex128, 2), 3: make([]complex128, 2)}, 0, parameters.Scale)
+		checkError(t, err, ErrInvalidMatrix)
+
+		if _, err = TryNewLinearTransform(parameters, params.encoder, map[uint64][]complex128{0: make([]complex128, 2)}, 0, parameters.Scale); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run(testString("Evaluator/", parameters), func(t *testing.T) {
+
+		values1, plaintext, ciphertext1 := newTestVectors(params, params.encryptorSk, 1, t)
+		values2, _, ciphertext2 := newTestVectors(params, params.encryptorSk, 1, t)
+
+		ciphertextOut := NewCiphertext(parameters, 1, parameters.MaxLevel(), parameters.Scale)
+
+		checkError(t, params.evaluator.TryAdd(plaintext, plaintext, ciphertextOut), ErrInvalidOperand)
+		checkError(t, params.evaluator.TrySub(ciphertext1, nil, ciphertextOut), ErrNilOperand)
+		checkError(t, params.evaluator.TryAdd(ciphertext1, NewCiphertext(otherParameters, 1, 0, parameters.Scale), ciphertextOut), ErrRingDegreeMismatch)
+		checkError(t, params.evaluator.TryMulRelin(NewCiphertext(parameters, 2, parameters.MaxLevel(), parameters.Scale), ciphertext1, nil, ciphertextOut), ErrDegreeMismatch)
+		checkError(t, params.evaluator.TryRelinearize(NewCiphertext(parameters, 2, parameters.MaxLevel(), parameters.Scale), nil, ciphertextOut), ErrMissingKey)
+		checkError(t, params.evaluator.TrySwitchKeys(ciphertext1, nil, ciphertextOut), ErrMissingKey)
+		checkError(t, params.evaluator.TryRotateColumns(ciphertext1, 1, new(RotationKeys), ciphertextOut), ErrMissingKey)
+		checkError(t, params.evaluator.TryConjugate(ciphertext1, new(RotationKeys), ciphertextOut), ErrMissingKey)
+
+		ciphertextNotNTT := ciphertext1.CopyNew().Ciphertext()
+		ciphertextNotNTT.SetIsNTT(false)
+		checkError(t, params.evaluator.TryMulRelin(ciphertextNotNTT, ciphertext1, nil, ciphertextOut), ErrNTTMismatch)
+		ciphertextNotNTT.MulScale(parameters.Scale)
+		checkError(t, params.evaluator.Rescale(ciphertextNotNTT, parameters.Scale, ciphertextNotNTT), ErrNTTMismatch)
+
+		ciphertextScale := ciphertext2.CopyNew().Ciphertext()
+		ciphertextScale.MulScale(1.5)
+		checkError(t, params.evaluator.TryAdd(ciphertext1, ciphertextScale, ciphertextOut), ErrScaleMismatch)
+
+		checkError(t, params.evaluator.DropLevel(NewCiphertext(parameters, 1, 0, parameters.Scale), 1), ErrLevelMismatch)
+
+		// A successful operation
+		if err := params.evaluator.TryAdd(ciphertext1, ciphertext2, ciphertextOut); err != nil {
+			t.Error(err)
+		}
+
+		for i := range values1 {
+			values1[i] += values2[i]
+		}
+
+		verifyTestVectors(params, params.decryptor, values1, ciphertextOut, t)
+	})
+}
+
 func testEvaluatorAdd(t *testing.T) {
 
 	for _, parameters := range testParams.ckksParameters {
@@ -683,6 +861,37 @@ func testEvaluatorMul(t *testing.T) {
 
 			verifyTestVectors(params, params.decryptor, values2, ciphertext2, t)
 		})
+
+		t.Run(testString("ShallowCopy/", parameters), func(t *testing.T) {
+
+			evaluator := params.evaluator.WithKey(params.kgen.GenRelinKey(params.sk), nil)
+
+			values := make([][]complex128, 4)
+			ciphertexts := make([]*Ciphertext, 4)
+			for i := range ciphertexts {
+				values[i], _, ciphertexts[i] = newTestVectors(params, params.encryptorSk, 1, t)
+				for j := range values[i] {
+					values[i][j] *= values[i][j]
+				}
+			}
+
+			// Each goroutine squares its ciphertext with its own shallow copy of the evaluator
+			var wg sync.WaitGroup
+			wg.Add(len(ciphertexts))
+			for i := range ciphertexts {
+				go func(evaluator Evaluator, ciphertext *Ciphertext) {
+					evaluator.MulRelin(ciphertext, ciphertext, nil, ciphertext)
+					evaluator.Relinearize(ciphertext, nil, ciphertext)
+					evaluator.Rescale(ciphertext, parameters.Scale, ciphertext)
+					wg.Done()
+				}(evaluator.ShallowCopy(), ciphertexts[i])
+			}
+			wg.Wait()
+
+			for i := range ciphertexts {
+				verifyTestVectors(params, params.decryptor, values[i], ciphertexts[i], t)
+			}
+		})
 	}
 }
 
@@ -827,6 +1036,418 @@ func testChebyshevInterpolator(t *testing.T) {
 
 			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
 		})
+
+		t.Run(testString("Fast/Remez/Sin/", parameters), func(t *testing.T) {
+
+			values, _, _ := newTestVectorsReals(params, nil, -1, 1, t)
+
+			// Maps the values on [-1, -0.25] U [0.25, 1]
+			for i := range values {
+				values[i] = complex(math.Copysign(0.25+0.75*math.Abs(real(values[i])), real(values[i])), 0)
+			}
+
+			plaintext := NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale)
+			params.encoder.Encode(plaintext, values, 1<<parameters.LogSlots)
+			ciphertext := params.encryptorSk.EncryptNew(plaintext)
+
+			intervals := []RemezInterval{{A: -1, B: -0.25, Nodes: 500}, {A: 0.25, B: 1, Nodes: 500}}
+
+			cheby, maxErr := ApproximateRemez(math.Sin, intervals, 15, 50)
+
+			if maxErr > 1e-12 {
+				t.Errorf("invalid Remez approximation error: %e", maxErr)
+			}
+
+			for i := range values {
+				values[i] = cmplx.Sin(values[i])
+			}
+
+			ciphertext = params.evaluator.EvaluateChebyFast(ciphertext, cheby, rlk)
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+	}
+}
+
+func testRemez(t *testing.T) {
+
+	sign := func(x float64) float64 {
+		return math.Copysign(1, x)
+	}
+
+	intervals := []RemezInterval{{A: -1, B: -0.1, Nodes: 1000}, {A: 0.1, B: 1, Nodes: 1000}}
+
+	for _, degree := range []int{15, 31, 63} {
+
+		t.Run(fmt.Sprintf("Sign/degree=%d", degree), func(t *testing.T) {
+
+			cheby, maxErr := ApproximateRemez(sign, intervals, degree, 50)
+
+			chebyInterp := Approximate(func(x complex128) complex128 {
+				return complex(sign(real(x)), 0)
+			}, -1, 1, degree)
+
+			// The minimax approximation must beat the interpolation at the Chebyshev nodes, and its error must equioscillate
+			var errRemez, errInterp float64
+			for _, interval := range intervals {
+				for i := 0; i < interval.Nodes; i++ {
+					x := interval.A + float64(i)*(interval.B-interval.A)/float64(interval.Nodes-1)
+					errRemez = math.Max(errRemez, math.Abs(real(evaluateChebyshevInterpolation(cheby, complex(x, 0)))-sign(x)))
+					errInterp = math.Max(errInterp, math.Abs(real(evaluateChebyshevInterpolation(chebyInterp, complex(x, 0)))-sign(x)))
+				}
+			}
+
+			if errRemez > maxErr*(1+1e-3) {
+				t.Errorf("reported error %e is smaller than the observed error %e", maxErr, errRemez)
+			}
+
+			if errRemez >= errInterp {
+				t.Errorf("minimax error %e is not smaller than the interpolation error %e", errRemez, errInterp)
+			}
+		})
+	}
+}
+
+func evaluateChebyshevInterpolation(cheby *ChebyshevInterpolation, x complex128) complex128 {
+	coeffs := make([]complex128, cheby.degree+1)
+	for i, c := range cheby.coeffs {
+		coeffs[i] = c
+	}
+	return evaluateChebyshevPolynomial(coeffs, x, cheby.a, cheby.b)
+}
+
+func testElementaryFunctions(t *testing.T) {
+
+	// On the default parameters, the precision is limited by the scale: the errors of the homomorphic evaluation (encoding,
+	// rescalings and key-switchings, amplified by the coefficients of the approximation) leave about log2(scale) - 22 bits.
+	// The approximations are asked for this precision, and the output is checked against the sum of the approximation error
+	// and of the evaluation error. The Newton iterations on [1, 10000] require a deeper circuit than these parameters allow.
+	parameters := DefaultParams[PN14QP438]
+	logPrecision := math.Log2(parameters.Scale) - 22
+	testElementaryFunctionsWithParameters(t, parameters, logPrecision, 2*math.Exp2(-logPrecision), false)
+
+	// The scale of the deep parameters allows the target precision of 2^-20, which is then the maximum error. On [1, 10000],
+	// no Chebyshev interpolant of reasonable degree reaches this precision and Newton iterations are used.
+	testElementaryFunctionsWithParameters(t, genDeepTestParameters(), 20, math.Exp2(-20), true)
+}
+
+// testElementaryFunctionsWithParameters evaluates the elementary functions with the target precision logPrecision and checks
+// that the maximum error of the output is at most maxErr. The Newton iterations are tested only if newton is true.
+func testElementaryFunctionsWithParameters(t *testing.T, parameters *Parameters, logPrecision, maxErr float64, newton bool) {
+
+	type elementaryFunction func(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (*Ciphertext, uint64)
+
+	params := genCkksParams(parameters)
+
+	rlk := params.kgen.GenRelinKey(params.sk)
+
+	for _, test := range []struct {
+		name     string
+		eval     elementaryFunction
+		function func(float64) float64
+		a, b     float64
+		newton   bool
+	}{
+		{"Exp", params.evaluator.Exp, math.Exp, -1, 1, false},
+		{"Log", params.evaluator.Log, math.Log, 0.5, 2, false},
+		{"Sigmoid", params.evaluator.Sigmoid, func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }, -4, 4, false},
+		{"Tanh", params.evaluator.Tanh, math.Tanh, -2, 2, false},
+		{"Sqrt", params.evaluator.Sqrt, math.Sqrt, 0.25, 2, false},
+		{"InvSqrt", params.evaluator.InvSqrt, func(x float64) float64 { return 1 / math.Sqrt(x) }, 0.5, 4, false},
+		{"Sqrt/Newton", params.evaluator.Sqrt, math.Sqrt, 1, 10000, true},
+		{"InvSqrt/Newton", params.evaluator.InvSqrt, func(x float64) float64 { return 1 / math.Sqrt(x) }, 1, 10000, true},
+	} {
+
+		if test.newton && !newton {
+			continue
+		}
+
+		t.Run(testString(test.name+"/", parameters), func(t *testing.T) {
+
+			values := make([]complex128, 1<<parameters.LogSlots)
+			for i := range values {
+				values[i] = complex(randomFloat(test.a, test.b), 0)
+			}
+
+			ciphertext := encryptTestValues(params, values)
+
+			ciphertext, levels := test.eval(ciphertext, test.a, test.b, logPrecision, rlk)
+
+			if levels != parameters.MaxLevel()-ciphertext.Level() {
+				t.Errorf("invalid number of levels consumed: %d != %d", levels, parameters.MaxLevel()-ciphertext.Level())
+			}
+
+			for i := range values {
+				values[i] = complex(test.function(real(values[i])), 0)
+			}
+
+			verifyElementaryFunction(params, values, ciphertext, maxErr, t)
+		})
+	}
+}
+
+// verifyElementaryFunction checks that the maximum error of the decrypted values is at most maxErr.
+func verifyElementaryFunction(params *ckksParams, valuesWant []complex128, ciphertext *Ciphertext, maxErr float64, t *testing.T) {
+
+	valuesTest := params.encoder.Decode(params.decryptor.DecryptNew(ciphertext), uint64(len(valuesWant)))
+
+	var err float64
+	for i := range valuesWant {
+		err = math.Max(err, cmplx.Abs(valuesTest[i]-valuesWant[i]))
+	}
+
+	if testParams.verbose {
+		t.Logf("Maximum error : %.2f bits", math.Log2(err))
+	}
+
+	if err > maxErr {
+		t.Errorf("maximum error 2^%.2f > 2^%.2f", math.Log2(err), math.Log2(maxErr))
+	}
+}
+
+func testComparison(t *testing.T) {
+
+	sign := NewSignPolynomial(0.1, []int{15, 15})
+
+	for _, parameters := range testParams.ckksParameters {
+
+		if parameters.MaxLevel() < sign.Depth()+1 {
+			continue
+		}
+
+		params := genCkksParams(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk)
+
+		slots := uint64(1 << parameters.LogSlots)
+
+		t.Run(testString("Sign/", parameters), func(t *testing.T) {
+
+			values := make([]complex128, slots)
+			for i := range values {
+				values[i] = complex(math.Copysign(randomFloat(0.1, 1), randomFloat(-1, 1)), 0)
+			}
+
+			ciphertext := encryptTestValues(params, values)
+
+			ciphertext = params.evaluator.Sign(ciphertext, sign, rlk)
+
+			if ciphertext.Level() != parameters.MaxLevel()-sign.Depth() {
+				t.Errorf("invalid output level: %d != %d", ciphertext.Level(), parameters.MaxLevel()-sign.Depth())
+			}
+
+			for i := range values {
+				values[i] = complex(math.Copysign(1, real(values[i])), 0)
+			}
+
+			verifyTestVectors(params, params.decryptor, values, ciphertext, t)
+		})
+
+		// Pairs of values in [-0.5, 0.5] whose difference is at least Eps
+		values0 := make([]complex128, slots)
+		values1 := make([]complex128, slots)
+		for i := range values0 {
+			a := randomFloat(-0.5, 0)
+			b := a + randomFloat(0.1, 0.5)
+			if randomFloat(-1, 1) > 0 {
+				a, b = b, a
+			}
+			values0[i], values1[i] = complex(a, 0), complex(b, 0)
+		}
+
+		t.Run(testString("Max/", parameters), func(t *testing.T) {
+
+			ciphertext := params.evaluator.Max(encryptTestValues(params, values0), encryptTestValues(params, values1), sign, rlk)
+
+			valuesWant := make([]complex128, slots)
+			for i := range valuesWant {
+				valuesWant[i] = complex(math.Max(real(values0[i]), real(values1[i])), 0)
+			}
+
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+		})
+
+		t.Run(testString("Min/", parameters), func(t *testing.T) {
+
+			ciphertext := params.evaluator.Min(encryptTestValues(params, values0), encryptTestValues(params, values1), sign, rlk)
+
+			valuesWant := make([]complex128, slots)
+			for i := range valuesWant {
+				valuesWant[i] = complex(math.Min(real(values0[i]), real(values1[i])), 0)
+			}
+
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+		})
+	}
+
+	// The max-reduction over the slots requires a deep circuit, so it is tested with small (insecure) parameters and few slots
+	parameters := genDeepTestParameters()
+
+	params := genCkksParams(parameters)
+
+	rlk := params.kgen.GenRelinKey(params.sk)
+
+	rotkeys := NewRotationKeys()
+	for i := uint64(1); i < 1<<parameters.LogSlots; i <<= 1 {
+		params.kgen.GenRot(RotationLeft, params.sk, i, rotkeys)
+	}
+
+	// Values separated by at least 2*Eps
+	values := []complex128{complex(-0.45, 0), complex(-0.2, 0), complex(0.05, 0), complex(0.3, 0)}
+	for i := range values {
+		values[i] += complex(randomFloat(-0.02, 0.02), 0)
+	}
+	for i := range values {
+		j := rand.Intn(i + 1)
+		values[i], values[j] = values[j], values[i]
+	}
+
+	argmax := 0
+	for i := range values {
+		if real(values[i]) > real(values[argmax]) {
+			argmax = i
+		}
+	}
+
+	t.Run(testString("MaxSlots/", parameters), func(t *testing.T) {
+
+		ciphertext := params.evaluator.MaxSlots(encryptTestValues(params, values), sign, rlk, rotkeys)
+
+		valuesWant := make([]complex128, len(values))
+		for i := range valuesWant {
+			valuesWant[i] = values[argmax]
+		}
+
+		verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+	})
+
+	t.Run(testString("ArgMax/", parameters), func(t *testing.T) {
+
+		ciphertext := params.evaluator.ArgMax(encryptTestValues(params, values), sign, rlk, rotkeys)
+
+		valuesWant := make([]complex128, len(values))
+		valuesWant[argmax] = 1
+
+		verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+	})
+}
+
+// genDeepTestParameters returns small (insecure) parameters with 27 levels and 4 slots, to test deep circuits.
+func genDeepTestParameters() (parameters *Parameters) {
+	parameters = &Parameters{
+		LogN:     11,
+		LogSlots: 2,
+		LogModuli: LogModuli{
+			LogQi: []uint64{55, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45, 45},
+			LogPi: []uint64{60, 60, 60},
+		},
+		Scale: 1 << 45,
+		Sigma: 3.2,
+	}
+	parameters.GenFromLogModuli()
+	return
+}
+
+func encryptTestValues(params *ckksParams, values []complex128) (ciphertext *Ciphertext) {
+	plaintext := NewPlaintext(params.params, params.params.MaxLevel(), params.params.Scale)
+	params.encoder.Encode(plaintext, values, 1<<params.params.LogSlots)
+	return params.encryptorSk.EncryptNew(plaintext)
+}
+
+func testAutoEvaluator(t *testing.T) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		params := genCkksParams(parameters)
+
+		evaluator := NewAutoEvaluator(parameters)
+
+		rlk := params.kgen.GenRelinKey(params.sk)
+
+		t.Run(testString("MulRelin/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorSk, 1, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorSk, 1, t)
+
+			for i := range values1 {
+				values1[i] *= values2[i]
+			}
+
+			ciphertext1 = evaluator.MulRelinNew(ciphertext1, ciphertext2, rlk)
+
+			if ciphertext1.Level() != parameters.MaxLevel()-1 {
+				t.Errorf("the result of the multiplication has not been rescaled")
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("AddDifferentLevels/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorSk, 1, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorSk, 1, t)
+
+			// ciphertext2 * ciphertext2 is one level below, with a scale which is not an integer multiple of the default scale
+			ciphertext2 = evaluator.MulRelinNew(ciphertext2, ciphertext2, rlk)
+
+			for i := range values1 {
+				values1[i] += values2[i] * values2[i]
+			}
+
+			ciphertext1 = evaluator.AddNew(ciphertext1, ciphertext2)
+
+			if ciphertext1.Scale() != ciphertext2.Scale() {
+				t.Errorf("the scales have not been matched")
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("SubDifferentScales/", parameters), func(t *testing.T) {
+
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorSk, 1, t)
+
+			values2 := make([]complex128, 1<<parameters.LogSlots)
+			for i := range values2 {
+				values2[i] = randomComplex(-1, 1)
+			}
+
+			// Same level as ciphertext1, with a different scale
+			plaintext := NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale*1.37)
+			params.encoder.Encode(plaintext, values2, 1<<parameters.LogSlots)
+			ciphertext2 := params.encryptorSk.EncryptNew(plaintext)
+
+			for i := range values1 {
+				values1[i] -= values2[i]
+			}
+
+			evaluator.Sub(ciphertext1, ciphertext2, ciphertext1)
+
+			if ciphertext1.Level() != parameters.MaxLevel()-1 {
+				t.Errorf("invalid output level: %d != %d", ciphertext1.Level(), parameters.MaxLevel()-1)
+			}
+
+			verifyTestVectors(params, params.decryptor, values1, ciphertext1, t)
+		})
+
+		t.Run(testString("Circuit/", parameters), func(t *testing.T) {
+
+			// x * y + 0.5 * z + x, without any manual rescaling
+			values1, _, ciphertext1 := newTestVectors(params, params.encryptorSk, 1, t)
+			values2, _, ciphertext2 := newTestVectors(params, params.encryptorSk, 1, t)
+			values3, _, ciphertext3 := newTestVectors(params, params.encryptorSk, 1, t)
+
+			valuesWant := make([]complex128, len(values1))
+			for i := range valuesWant {
+				valuesWant[i] = values1[i]*values2[i] + 0.5*values3[i] + values1[i]
+			}
+
+			ciphertext := evaluator.MulRelinNew(ciphertext1, ciphertext2, rlk)
+			evaluator.Add(ciphertext, evaluator.MultByConstNew(ciphertext3, 0.5), ciphertext)
+			evaluator.Add(ciphertext, ciphertext1, ciphertext)
+
+			verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+		})
 	}
 }
 
@@ -995,6 +1616,196 @@ func testRotateColumns(t *testing.T) {
 	}
 }
 
+func testGadget(t *testing.T) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		logBase := uint64(10)
+
+		// Same moduli without the special primes Pi, which are not needed by the gadget decomposition.
+		moduli := parameters.Moduli.Copy()
+		moduli.Pi = nil
+
+		for _, parameters := range []*Parameters{parameters, NewParametersFromModuli(parameters.LogN, parameters.LogSlots, parameters.Scale, moduli, parameters.Sigma)} {
+
+			params := genCkksParams(parameters)
+
+			// The encryption without P (EncryptFast) is used since Pi might be empty. Since the noise of the key-switching
+			// is not divided by P, the ciphertexts are scaled up (as they would be before a rescaling) to keep the precision.
+			newTestVectorsFast := func(params *ckksParams) (values []complex128, plaintext *Plaintext, ciphertext *Ciphertext) {
+				values, plaintext, _ = newTestVectorsReals(params, nil, -1, 1, t)
+				ciphertext = params.encryptorSk.EncryptFastNew(plaintext)
+				params.evaluator.ScaleUp(ciphertext, math.Exp2(20), ciphertext)
+				return values, plaintext, ciphertext
+			}
+
+			t.Run(testString(fmt.Sprintf("MulRelin/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				rlk := params.kgen.GenRelinKeyGadget(params.sk, logBase)
+
+				values1, _, ciphertext1 := newTestVectorsFast(params)
+				values2, _, ciphertext2 := newTestVectorsFast(params)
+
+				for i := range values1 {
+					values2[i] *= values1[i]
+				}
+
+				params.evaluator.MulRelin(ciphertext1, ciphertext2, rlk, ciphertext1)
+
+				if ciphertext1.Degree() != 1 {
+					t.Errorf("invalid degree after MulRelin: have %d, want 1", ciphertext1.Degree())
+				}
+
+				verifyTestVectors(params, params.decryptor, values2, ciphertext1, t)
+			})
+
+			t.Run(testString(fmt.Sprintf("SwitchKeys/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				sk2 := params.kgen.GenSecretKey()
+				decryptorSk2 := NewDecryptor(parameters, sk2)
+				switchingKey := params.kgen.GenSwitchingKeyGadget(params.sk, sk2, logBase)
+
+				if switchingKey.LogBase() != logBase {
+					t.Errorf("invalid SwitchingKey logBase: have %d, want %d", switchingKey.LogBase(), logBase)
+				}
+
+				values, _, ciphertext := newTestVectorsFast(params)
+
+				params.evaluator.SwitchKeys(ciphertext, switchingKey, ciphertext)
+
+				verifyTestVectors(params, decryptorSk2, values, ciphertext, t)
+			})
+
+			t.Run(testString(fmt.Sprintf("Rotate/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				rotations := []uint64{0, 1, 5}
+
+				rotKey := NewRotationKeys()
+				params.kgen.GenRotGadget(Conjugate, params.sk, 0, logBase, rotKey)
+				for _, n := range rotations[1:] {
+					params.kgen.GenRotGadget(RotationLeft, params.sk, n, logBase, rotKey)
+				}
+
+				values1, _, ciphertext1 := newTestVectorsFast(params)
+
+				values2 := make([]complex128, len(values1))
+
+				ciphertexts := params.evaluator.RotateHoisted(ciphertext1, rotations, rotKey)
+
+				for _, n := range rotations {
+
+					for i := range values1 {
+						values2[i] = values1[(i+int(n))%len(values1)]
+					}
+
+					verifyTestVectors(params, params.decryptor, values2, ciphertexts[n], t)
+
+					if n != 0 {
+						verifyTestVectors(params, params.decryptor, values2, params.evaluator.RotateColumnsNew(ciphertext1, n, rotKey), t)
+					}
+				}
+
+				for i := range values1 {
+					values2[i] = complex(real(values1[i]), -imag(values1[i]))
+				}
+
+				verifyTestVectors(params, params.decryptor, values2, params.evaluator.ConjugateNew(ciphertext1, rotKey), t)
+			})
+
+			t.Run(testString(fmt.Sprintf("Marshalling/Pi=%d/", len(parameters.Pi)), parameters), func(t *testing.T) {
+
+				contextQ := params.ckkscontext.contextQ
+
+				rlk := params.kgen.GenRelinKeyGadget(params.sk, logBase)
+
+				data, err := rlk.MarshalBinary()
+				check(t, err)
+
+				resRlk := new(EvaluationKey)
+				err = resRlk.UnmarshalBinary(data)
+				check(t, err)
+
+				if resRlk.evakey.LogBase() != logBase {
+					t.Errorf("marshal EvaluationKey logBase: have %d, want %d", resRlk.evakey.LogBase(), logBase)
+				}
+
+				evakeyWant := rlk.evakey.evakey
+				evakeyTest := resRlk.evakey.evakey
+
+				if len(evakeyWant) != len(evakeyTest) {
+					t.Errorf("marshal EvaluationKey: have %d elements, want %d", len(evakeyTest), len(evakeyWant))
+					return
+				}
+
+				for j := range evakeyWant {
+					for k := range evakeyWant[j] {
+						if !contextQ.Equal(evakeyWant[j][k], evakeyTest[j][k]) {
+							t.Errorf("marshal EvaluationKey element [%d][%d]", j, k)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func testLinearTransform(t *testing.T) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		params := genCkksParams(parameters)
+
+		slots := uint64(1 << parameters.LogSlots)
+
+		diagMatrix := make(map[uint64][]complex128)
+		for _, k := range []uint64{0, 1, 2, 3, 16, 17, slots - 16, slots - 1} {
+			diagMatrix[k] = make([]complex128, slots)
+			for i := range diagMatrix[k] {
+				diagMatrix[k][i] = randomComplex(-0.125, 0.125)
+			}
+		}
+
+		level := parameters.MaxLevel()
+		scale := float64(parameters.Qi[level])
+
+		for _, lt := range []*LinearTransform{
+			NewLinearTransform(parameters, params.encoder, diagMatrix, level, scale),
+			NewLinearTransformBSGS(parameters, params.encoder, diagMatrix, level, scale),
+		} {
+
+			name := "Naive/"
+			if lt.n1 != 0 {
+				name = "BSGS/"
+			}
+
+			rotKey := NewRotationKeys()
+			for _, k := range lt.Rotations() {
+				params.kgen.GenRot(RotationLeft, params.sk, k, rotKey)
+			}
+
+			t.Run(testString(name, parameters), func(t *testing.T) {
+
+				values, _, ciphertext := newTestVectors(params, params.encryptorSk, 1, t)
+
+				valuesWant := make([]complex128, slots)
+				for k, diag := range diagMatrix {
+					for i := uint64(0); i < slots; i++ {
+						valuesWant[i] += diag[i] * values[(i+k)%slots]
+					}
+				}
+
+				ciphertext = params.evaluator.LinearTransformNew(ciphertext, lt, rotKey)
+
+				if err := params.evaluator.Rescale(ciphertext, parameters.Scale, ciphertext); err != nil {
+					t.Error(err)
+				}
+
+				verifyTestVectors(params, params.decryptor, valuesWant, ciphertext, t)
+			})
+		}
+	}
+}
+
 func testMarshaller(t *testing.T) {
 
 	for _, parameters := range testParams.ckksParameters {
@@ -1198,5 +2009,154 @@ func testMarshaller(t *testing.T) {
 				}
 			}
 		})
+
+		equalSwitchingKeys := func(swkWant, swkTest *SwitchingKey) bool {
+			if swkTest == nil || len(swkWant.evakey) != len(swkTest.evakey) || swkWant.logBase != swkTest.logBase {
+				return false
+			}
+			// The keys of the gadget decomposition are in basis Q only
+			contextKeys := contextQP
+			if swkWant.logBase != 0 {
+				contextKeys = params.ckkscontext.contextQ
+			}
+			for j := range swkWant.evakey {
+				for k := range swkWant.evakey[j] {
+					if !contextKeys.Equal(swkWant.evakey[j][k], swkTest.evakey[j][k]) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		t.Run(testString("Compressed/Ciphertext/", parameters), func(t *testing.T) {
+
+			for _, fast := range []bool{false, true} {
+
+				values, plaintext, _ := newTestVectors(params, nil, 1, t)
+
+				var ciphertextWant *Ciphertext
+				if fast {
+					ciphertextWant = params.encryptorSk.EncryptFastNew(plaintext)
+				} else {
+					ciphertextWant = params.encryptorSk.EncryptNew(plaintext)
+				}
+
+				data, err := ciphertextWant.MarshalBinaryCompressed()
+				check(t, err)
+
+				if uint64(len(data)) != ciphertextWant.GetDataLenCompressed(true) || 2*uint64(len(data)) > ciphertextWant.GetDataLen(true)+2*64 {
+					t.Errorf("invalid compressed Ciphertext size: have %d, uncompressed %d", len(data), ciphertextWant.GetDataLen(true))
+				}
+
+				ciphertextTest := new(Ciphertext)
+				check(t, ciphertextTest.UnmarshalBinaryCompressed(parameters, data))
+
+				if ciphertextWant.Scale() != ciphertextTest.Scale() {
+					t.Errorf("marshal compressed Ciphertext scale")
+				}
+
+				for i := range ciphertextWant.value {
+					if !params.ckkscontext.contextQ.Equal(ciphertextWant.value[i], ciphertextTest.value[i]) {
+						t.Errorf("marshal compressed Ciphertext element [%d]", i)
+					}
+				}
+
+				verifyTestVectors(params, params.decryptor, values, ciphertextTest, t)
+			}
+
+			if _, err := params.encryptorPk.EncryptNew(NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale)).MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a Ciphertext encrypted with the public-key should not be compressible")
+			}
+
+			_, plaintext, _ := newTestVectors(params, nil, 1, t)
+
+			ciphertext := params.encryptorSk.EncryptNew(plaintext)
+			params.evaluator.Add(ciphertext, ciphertext, ciphertext)
+			if _, err := ciphertext.MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a Ciphertext modified by the evaluator should not be compressible")
+			}
+
+			if parameters.MaxLevel() > 0 {
+				ciphertext = params.encryptorSk.EncryptNew(plaintext)
+				check(t, params.evaluator.DropLevel(ciphertext, 1))
+				if _, err := ciphertext.MarshalBinaryCompressed(); err == nil {
+					t.Errorf("a Ciphertext dropped to a lower level should not be compressible")
+				}
+			}
+		})
+
+		t.Run(testString("Compressed/Keys/", parameters), func(t *testing.T) {
+
+			for _, logBase := range []uint64{0, 30} {
+
+				skOut := params.kgen.GenSecretKey()
+
+				var switchingKey *SwitchingKey
+				var evalkey *EvaluationKey
+				rotationKey := NewRotationKeys()
+
+				if logBase == 0 {
+					switchingKey = params.kgen.GenSwitchingKey(params.sk, skOut)
+					evalkey = params.kgen.GenRelinKey(params.sk)
+					params.kgen.GenRot(RotationLeft, params.sk, 1, rotationKey)
+					params.kgen.GenRot(Conjugate, params.sk, 0, rotationKey)
+				} else {
+					switchingKey = params.kgen.GenSwitchingKeyGadget(params.sk, skOut, logBase)
+					evalkey = params.kgen.GenRelinKeyGadget(params.sk, logBase)
+					params.kgen.GenRotGadget(RotationRight, params.sk, 1, logBase, rotationKey)
+				}
+
+				data, err := switchingKey.MarshalBinaryCompressed()
+				check(t, err)
+
+				if uint64(len(data)) != switchingKey.GetDataLenCompressed(true) {
+					t.Errorf("invalid compressed SwitchingKey size: have %d, want %d", len(data), switchingKey.GetDataLenCompressed(true))
+				}
+
+				switchingKeyTest := new(SwitchingKey)
+				check(t, switchingKeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				if !equalSwitchingKeys(switchingKey, switchingKeyTest) {
+					t.Errorf("marshal compressed SwitchingKey (logBase=%d)", logBase)
+				}
+
+				data, err = evalkey.MarshalBinaryCompressed()
+				check(t, err)
+
+				evalkeyTest := new(EvaluationKey)
+				check(t, evalkeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				if !equalSwitchingKeys(evalkey.evakey, evalkeyTest.evakey) {
+					t.Errorf("marshal compressed EvaluationKey (logBase=%d)", logBase)
+				}
+
+				data, err = rotationKey.MarshalBinaryCompressed()
+				check(t, err)
+
+				rotationKeyTest := new(RotationKeys)
+				check(t, rotationKeyTest.UnmarshalBinaryCompressed(parameters, data))
+
+				for i, swk := range rotationKey.evakeyRotColLeft {
+					if !equalSwitchingKeys(swk, rotationKeyTest.evakeyRotColLeft[i]) {
+						t.Errorf("marshal compressed RotationKey RotateLeft %d (logBase=%d)", i, logBase)
+					}
+				}
+
+				for i, swk := range rotationKey.evakeyRotColRight {
+					if !equalSwitchingKeys(swk, rotationKeyTest.evakeyRotColRight[i]) {
+						t.Errorf("marshal compressed RotationKey RotateRight %d (logBase=%d)", i, logBase)
+					}
+				}
+
+				if rotationKey.evakeyConjugate != nil && !equalSwitchingKeys(rotationKey.evakeyConjugate, rotationKeyTest.evakeyConjugate) {
+					t.Errorf("marshal compressed RotationKey Conjugate (logBase=%d)", logBase)
+				}
+			}
+
+			if _, err := NewSwitchingKey(parameters).MarshalBinaryCompressed(); err == nil {
+				t.Errorf("a SwitchingKey without seed should not be compressible")
+			}
+		})
 	}
 }
@@ -0,0 +1,213 @@
+package ckks
+
+import (
+	"math"
+	"math/bits"
+)
+
+// SignPolynomial is a composite minimax approximation of the sign function, given by a sequence of odd polynomials
+// p_1, ..., p_k such that p_k(...p_1(x)) approximates sign(x) for x in [-1, -Eps] U [Eps, 1].
+// Each polynomial is defined on [-1, 1], so that evaluating the polynomial of degree d consumes exactly bits.Len64(d) levels.
+type SignPolynomial struct {
+	eps   float64
+	err   float64
+	polys []*ChebyshevInterpolation
+}
+
+// NewSignPolynomial computes a composite minimax approximation of the sign function on [-1, -eps] U [eps, 1], with one polynomial
+// of odd degree per element of degrees. For the same total depth, a composition of several low degree polynomials achieves a much
+// smaller error than a single high degree polynomial, but requires more multiplications. The error of the approximation is given by Error.
+func NewSignPolynomial(eps float64, degrees []int) (sign *SignPolynomial) {
+
+	if eps <= 0 || eps >= 1 {
+		panic("cannot NewSignPolynomial: eps must be in (0, 1)")
+	}
+
+	if len(degrees) == 0 {
+		panic("cannot NewSignPolynomial: at least one degree is required")
+	}
+
+	sign = &SignPolynomial{eps: eps, polys: make([]*ChebyshevInterpolation, len(degrees))}
+
+	signFunc := func(x float64) float64 {
+		return math.Copysign(1, x)
+	}
+
+	// Lower bound of the absolute value of the input of the current polynomial
+	lower := eps
+
+	for i, degree := range degrees {
+
+		if degree < 1 || degree&1 == 0 {
+			panic("cannot NewSignPolynomial: degrees must be odd")
+		}
+
+		nodes := 8 * (degree + 2)
+		if nodes < 1000 {
+			nodes = 1000
+		}
+
+		intervals := []RemezInterval{{A: -1, B: -lower, Nodes: nodes}, {A: lower, B: 1, Nodes: nodes}}
+
+		cheby, err := ApproximateRemez(signFunc, intervals, degree, 100)
+
+		if err >= 1 {
+			panic("cannot NewSignPolynomial: the approximation does not converge (the degrees are too small for eps)")
+		}
+
+		// The minimax approximation of an odd function on a symmetric domain is odd
+		for k := range cheby.coeffs {
+			if k&1 == 0 {
+				delete(cheby.coeffs, k)
+			}
+		}
+
+		// All the polynomials but the last one are normalized so that their output lies in [-1, 1]
+		if i != len(degrees)-1 {
+			for k := range cheby.coeffs {
+				cheby.coeffs[k] /= complex(1+err, 0)
+			}
+			lower = math.Min((1-err)/(1+err), 1-1e-12)
+		}
+
+		sign.polys[i] = cheby
+		sign.err = err
+	}
+
+	return
+}
+
+// Eps returns the smallest absolute value of an input for which the SignPolynomial approximates the sign function.
+func (sign *SignPolynomial) Eps() float64 {
+	return sign.eps
+}
+
+// Error returns the maximum absolute error of the SignPolynomial for inputs in [-1, -Eps] U [Eps, 1].
+func (sign *SignPolynomial) Error() float64 {
+	return sign.err
+}
+
+// Depth returns the number of levels consumed by the evaluation of the SignPolynomial.
+func (sign *SignPolynomial) Depth() (depth uint64) {
+	for _, poly := range sign.polys {
+		depth += uint64(bits.Len64(poly.degree))
+	}
+	return
+}
+
+// Sign homomorphically evaluates the SignPolynomial on ct0, whose values must be real and in [-1, 1], and returns the result in a newly
+// created Ciphertext. The output approximates sign(x) for |x| >= sign.Eps() and lies in [-1-sign.Error(), 1+sign.Error()] otherwise.
+// It consumes sign.Depth() levels.
+func (eval *evaluator) Sign(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext) {
+
+	if ct0.Level() < sign.Depth() {
+		panic("cannot Sign: input Ciphertext level is too low")
+	}
+
+	ctOut = ct0
+
+	for _, poly := range sign.polys {
+		ctOut = eval.EvaluateChebyEco(ctOut, poly, evakey)
+	}
+
+	return
+}
+
+// Max homomorphically evaluates max(ct0, ct1) = (ct0 + ct1)/2 + (ct0 - ct1)*sign(ct0 - ct1)/2 and returns the result in a newly created Ciphertext.
+// The input Ciphertexts must have the same scale and their difference must be real and in [-1, 1]. The output is exact up to an error
+// of |ct0 - ct1| * sign.Error() / 2 when |ct0 - ct1| >= sign.Eps(), and up to an error of sign.Eps() otherwise.
+// It consumes sign.Depth() + 1 levels.
+func (eval *evaluator) Max(ct0, ct1 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext) {
+	return eval.maxOrMin(ct0, ct1, sign, evakey, true)
+}
+
+// Min homomorphically evaluates min(ct0, ct1) = (ct0 + ct1)/2 - (ct0 - ct1)*sign(ct0 - ct1)/2 and returns the result in a newly created Ciphertext.
+// The same conditions as for Max apply.
+func (eval *evaluator) Min(ct0, ct1 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext) {
+	return eval.maxOrMin(ct0, ct1, sign, evakey, false)
+}
+
+func (eval *evaluator) maxOrMin(ct0, ct1 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey, max bool) (ctOut *Ciphertext) {
+
+	diff := eval.SubNew(ct0, ct1)
+	sum := eval.AddNew(ct0, ct1)
+
+	if diff.Level() < sign.Depth()+1 {
+		panic("cannot Max/Min: input Ciphertext level is too low")
+	}
+
+	// (ct0 - ct1) * sign(ct0 - ct1) / 2, with (ct0 - ct1) / 2 scaled by the modulus of the level of the sign
+	// so that the output scale stays close to the default scale
+	ctOut = eval.Sign(diff, sign, evakey)
+	diff = eval.mulByConstAndMatchScale(diff, 0.5, ctOut.Level(), float64(eval.ckksContext.contextQ.Modulus[ctOut.Level()]))
+	eval.MulRelin(ctOut, diff, evakey, ctOut)
+	eval.Rescale(ctOut, eval.ckksContext.scale, ctOut)
+
+	// (ct0 + ct1) / 2, with exactly the same level and scale
+	sum = eval.mulByConstAndMatchScale(sum, 0.5, ctOut.Level(), ctOut.Scale())
+
+	if max {
+		eval.Add(sum, ctOut, ctOut)
+	} else {
+		eval.Sub(sum, ctOut, ctOut)
+	}
+
+	return
+}
+
+// MaxSlots homomorphically computes the maximum of the slots of ct0 with a max-reduction, and returns a newly created Ciphertext storing it in
+// all the slots. The values must be real and in [-0.5, 0.5], and the rotation keys for the left rotations by 2^i for 0 <= i < LogSlots
+// must be provided. It consumes LogSlots * (sign.Depth() + 1) levels.
+func (eval *evaluator) MaxSlots(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey, rotkeys *RotationKeys) (ctOut *Ciphertext) {
+
+	ctOut = ct0
+
+	for i := uint64(1); i < 1<<eval.params.LogSlots; i <<= 1 {
+		ctOut = eval.Max(ctOut, eval.RotateColumnsNew(ctOut, i, rotkeys), sign, evakey)
+	}
+
+	return
+}
+
+// ArgMax homomorphically computes the one-hot encoding of the index of the maximum of the slots of ct0, and returns it in a newly created
+// Ciphertext: the slot storing the maximum is mapped to one and the other slots to zero. The values must be real and in [-0.5, 0.5], and
+// pairwise separated by at least 2 * sign.Eps(). The same rotation keys as for MaxSlots are required.
+// It consumes (LogSlots + 1) * (sign.Depth() + 1) levels.
+func (eval *evaluator) ArgMax(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey, rotkeys *RotationKeys) (ctOut *Ciphertext) {
+
+	max := eval.MaxSlots(ct0, sign, evakey, rotkeys)
+
+	// x - max + Eps, which is positive only for the slot storing the maximum
+	ctOut = eval.mulByConstAndMatchScale(ct0, 1, max.Level(), max.Scale())
+	eval.Sub(ctOut, max, ctOut)
+	eval.AddConst(ctOut, sign.eps, ctOut)
+
+	// (1 + sign(x - max + Eps)) / 2
+	ctOut = eval.Sign(ctOut, sign, evakey)
+	eval.AddConst(ctOut, 1, ctOut)
+	ctOut.MulScale(2)
+
+	return
+}
+
+// mulByConstAndMatchScale multiplies ct0 by the constant and returns the result in a newly created Ciphertext at the given level and with
+// exactly the given scale, by multiplying ct0 by an integer constant before dividing it by the modulus of the level above.
+// The level of ct0 must be strictly greater than the target level.
+func (eval *evaluator) mulByConstAndMatchScale(ct0 *Ciphertext, constant float64, level uint64, scale float64) (ctOut *Ciphertext) {
+
+	if ct0.Level() <= level {
+		panic("cannot mulByConstAndMatchScale: input Ciphertext level is too low")
+	}
+
+	ctOut = eval.DropLevelNew(ct0, ct0.Level()-level-1)
+
+	qi := float64(eval.ckksContext.contextQ.Modulus[level+1])
+
+	eval.MultByConst(ctOut, int64(math.Round(constant*scale*qi/ct0.Scale())), ctOut)
+
+	eval.RescaleMany(ctOut, 1, ctOut)
+
+	ctOut.SetScale(scale)
+
+	return
+}
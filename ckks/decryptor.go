@@ -19,15 +19,36 @@ type decryptor struct {
 	sk          *SecretKey
 }
 
+// TryNewDecryptor is the same as NewDecryptor, but returns an Error instead of panicking if the parameters are invalid
+// or if the secret-key does not match them.
+func TryNewDecryptor(params *Parameters, sk *SecretKey) (Decryptor, error) {
+
+	if err := checkDecryptor(params, sk); err != nil {
+		return nil, err
+	}
+
+	return NewDecryptor(params, sk), nil
+}
+
+func checkDecryptor(params *Parameters, sk *SecretKey) error {
+
+	if err := checkParameters("NewDecryptor", params); err != nil {
+		return err
+	}
+
+	if sk == nil {
+		return newError("NewDecryptor", ErrMissingKey, "secret-key is nil")
+	}
+
+	return checkPoly("NewDecryptor", params, sk.sk, len(params.Qi)+len(params.Pi), "secret-key")
+}
+
 // NewDecryptor instantiates a new Decryptor that will be able to decrypt ciphertexts
 // encrypted under the provided secret-key.
 func NewDecryptor(params *Parameters, sk *SecretKey) Decryptor {
-	if !params.isValid {
-		panic("cannot newDecryptor: parameters are invalid (check if the generation was done properly)")
-	}
 
-	if sk.sk.GetDegree() != int(1<<params.LogN) {
-		panic("cannot newDecryptor: secret_key degree must match context degree")
+	if err := checkDecryptor(params, sk); err != nil {
+		panic(err)
 	}
 
 	return &decryptor{
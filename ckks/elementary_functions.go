@@ -0,0 +1,296 @@
+package ckks
+
+import (
+	"math"
+	"math/bits"
+)
+
+// maxElementaryDegree is the largest degree of the Chebyshev approximations used to evaluate the elementary functions.
+const maxElementaryDegree = 255
+
+// maxNewtonSteps is the largest number of Newton iterations used to evaluate the inverse square root.
+const maxNewtonSteps = 16
+
+// elementaryErrorPoints is the number of points on which the error of an approximation is measured.
+const elementaryErrorPoints = 1024
+
+// Exp homomorphically evaluates exp(x) for x in [a, b] with an absolute error of at most 2^-logPrecision, and returns the
+// result in a newly created Ciphertext along with the number of levels consumed. The function is approximated by the Chebyshev
+// interpolant of smallest degree reaching the target precision, evaluated with EvaluateChebyFast. The target precision is the precision
+// of the approximation: the precision of the output is also limited by the precision of the homomorphic evaluation.
+func (eval *evaluator) Exp(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+	return eval.evaluateElementary("Exp", ct0, math.Exp, a, b, logPrecision, evakey)
+}
+
+// Log homomorphically evaluates the natural logarithm log(x) for x in [a, b], with 0 < a, with an absolute error of at most
+// 2^-logPrecision, and returns the result in a newly created Ciphertext along with the number of levels consumed.
+// The function is evaluated as for Exp.
+func (eval *evaluator) Log(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	if a <= 0 {
+		panic("cannot Log: the interval must be strictly positive")
+	}
+
+	return eval.evaluateElementary("Log", ct0, math.Log, a, b, logPrecision, evakey)
+}
+
+// Sigmoid homomorphically evaluates the logistic function 1/(1+exp(-x)) for x in [a, b] with an absolute error of at most
+// 2^-logPrecision, and returns the result in a newly created Ciphertext along with the number of levels consumed.
+// The function is evaluated as for Exp.
+func (eval *evaluator) Sigmoid(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	sigmoid := func(x float64) float64 {
+		return 1 / (1 + math.Exp(-x))
+	}
+
+	return eval.evaluateElementary("Sigmoid", ct0, sigmoid, a, b, logPrecision, evakey)
+}
+
+// Tanh homomorphically evaluates tanh(x) for x in [a, b] with an absolute error of at most 2^-logPrecision, and returns the
+// result in a newly created Ciphertext along with the number of levels consumed. The function is evaluated as for Exp.
+func (eval *evaluator) Tanh(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+	return eval.evaluateElementary("Tanh", ct0, math.Tanh, a, b, logPrecision, evakey)
+}
+
+// Sqrt homomorphically evaluates sqrt(x) for x in [a, b], with 0 <= a, with an absolute error of at most 2^-logPrecision, and
+// returns the result in a newly created Ciphertext along with the number of levels consumed. The function is evaluated either with
+// a Chebyshev interpolant, or as x * InvSqrt(x) with Newton iterations (only if 0 < a), whichever consumes the fewest levels.
+func (eval *evaluator) Sqrt(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	if a < 0 {
+		panic("cannot Sqrt: the interval must be positive")
+	}
+
+	return eval.evaluateSqrt("Sqrt", ct0, a, b, logPrecision, false, evakey)
+}
+
+// InvSqrt homomorphically evaluates 1/sqrt(x) for x in [a, b], with 0 < a, with an absolute error of at most 2^-logPrecision,
+// and returns the result in a newly created Ciphertext along with the number of levels consumed. The function is evaluated
+// either with a Chebyshev interpolant, or with Newton iterations y = y * (3 - x * y^2) / 2 starting from a low degree Chebyshev
+// interpolant, whichever consumes the fewest levels. Each Newton iteration consumes two levels.
+func (eval *evaluator) InvSqrt(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	if a <= 0 {
+		panic("cannot InvSqrt: the interval must be strictly positive")
+	}
+
+	return eval.evaluateSqrt("InvSqrt", ct0, a, b, logPrecision, true, evakey)
+}
+
+// evaluateElementary evaluates the Chebyshev interpolant of smallest degree approximating the function on [a, b] with an absolute
+// error of at most 2^-logPrecision.
+func (eval *evaluator) evaluateElementary(name string, ct0 *Ciphertext, function func(float64) float64, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	checkElementaryInterval(name, a, b)
+
+	cheby, _ := findChebyshevApproximation(function, a, b, math.Exp2(-logPrecision), false)
+
+	if cheby == nil {
+		panic("cannot " + name + ": the target precision cannot be reached on the given interval")
+	}
+
+	checkElementaryLevel(name, ct0, chebyshevDepth(cheby))
+
+	ctOut = eval.evaluateChebyElementary(ct0, cheby, evakey)
+
+	return ctOut, ct0.Level() - ctOut.Level()
+}
+
+// evaluateSqrt evaluates sqrt(x) or 1/sqrt(x) on [a, b], with either a Chebyshev interpolant or with Newton iterations on the
+// inverse square root, depending on which one requires the fewest levels.
+func (eval *evaluator) evaluateSqrt(name string, ct0 *Ciphertext, a, b, logPrecision float64, inverse bool, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64) {
+
+	checkElementaryInterval(name, a, b)
+
+	maxErr := math.Exp2(-logPrecision)
+
+	function := math.Sqrt
+	if inverse {
+		function = func(x float64) float64 {
+			return 1 / math.Sqrt(x)
+		}
+	}
+
+	cheby, _ := findChebyshevApproximation(function, a, b, maxErr, false)
+
+	depth := uint64(math.MaxUint64)
+	if cheby != nil {
+		depth = chebyshevDepth(cheby)
+	}
+
+	// The Newton iterations are only considered if they consume strictly fewer levels
+	var chebyNewton *ChebyshevInterpolation
+	var steps uint64
+
+	if a > 0 {
+
+		// Relative error on the inverse square root for which the absolute error of the output is maxErr
+		maxRelErr := maxErr * math.Sqrt(a)
+		if !inverse {
+			maxRelErr = maxErr / math.Sqrt(b)
+		}
+
+		invSqrt := func(x float64) float64 {
+			return 1 / math.Sqrt(x)
+		}
+
+		// The relative error of the initial approximation must be small enough to ensure the convergence of the iterations
+		for relErr := 0.5; relErr > maxRelErr; relErr *= relErr {
+
+			initial, initialErr := findChebyshevApproximation(invSqrt, a, b, relErr, true)
+
+			if initial == nil {
+				break
+			}
+
+			k := newtonSteps(initialErr, maxRelErr)
+
+			if k > maxNewtonSteps {
+				continue
+			}
+
+			newtonDepth := chebyshevDepth(initial) + 2*k
+			if !inverse {
+				newtonDepth++
+			}
+
+			if newtonDepth < depth {
+				depth = newtonDepth
+				chebyNewton, steps = initial, k
+			}
+		}
+	}
+
+	if cheby == nil && chebyNewton == nil {
+		panic("cannot " + name + ": the target precision cannot be reached on the given interval")
+	}
+
+	checkElementaryLevel(name, ct0, depth)
+
+	if chebyNewton == nil {
+		ctOut = eval.evaluateChebyElementary(ct0, cheby, evakey)
+		return ctOut, ct0.Level() - ctOut.Level()
+	}
+
+	ctOut = eval.evaluateChebyElementary(ct0, chebyNewton, evakey)
+
+	// -x/2 with exactly the same scale as the initial approximation
+	xHalf := eval.mulByConstAndMatchScale(ct0, -0.5, ctOut.Level(), ctOut.Scale())
+
+	for i := uint64(0); i < steps; i++ {
+
+		// y = 1.5 * y + (-x/2 * y) * y^2
+		xy := eval.MulRelinNew(xHalf, ctOut, evakey)
+		eval.Rescale(xy, eval.ckksContext.scale, xy)
+
+		yy := eval.MulRelinNew(ctOut, ctOut, evakey)
+		eval.Rescale(yy, eval.ckksContext.scale, yy)
+
+		eval.MulRelin(xy, yy, evakey, xy)
+		eval.Rescale(xy, eval.ckksContext.scale, xy)
+
+		ctOut = eval.mulByConstAndMatchScale(ctOut, 1.5, xy.Level(), xy.Scale())
+		eval.Add(ctOut, xy, ctOut)
+	}
+
+	if !inverse {
+		// sqrt(x) = x * 1/sqrt(x)
+		eval.MulRelin(ctOut, ct0, evakey, ctOut)
+		eval.Rescale(ctOut, eval.ckksContext.scale, ctOut)
+	}
+
+	return ctOut, ct0.Level() - ctOut.Level()
+}
+
+// evaluateChebyElementary evaluates the interpolant with EvaluateChebyFast. If the change of variable from [a, b] to [-1, 1]
+// requires a non-integer multiplication, it is done beforehand with a scale exactly equal to the scale of ct0, which avoids
+// the precision loss caused by the additions of ciphertexts with slightly different scales during the evaluation.
+func (eval *evaluator) evaluateChebyElementary(ct0 *Ciphertext, cheby *ChebyshevInterpolation, evakey *EvaluationKey) (ctOut *Ciphertext) {
+
+	if c := real(2 / (cheby.b - cheby.a)); c != math.Trunc(c) {
+
+		ctOut = eval.mulByConstAndMatchScale(ct0, c, ct0.Level()-1, ct0.Scale())
+		eval.AddConst(ctOut, (-cheby.a-cheby.b)/(cheby.b-cheby.a), ctOut)
+
+		cheby = &ChebyshevInterpolation{coeffs: cheby.coeffs, degree: cheby.degree, a: -1, b: 1}
+
+		return eval.EvaluateChebyFast(ctOut, cheby, evakey)
+	}
+
+	return eval.EvaluateChebyFast(ct0, cheby, evakey)
+}
+
+// findChebyshevApproximation returns the Chebyshev interpolant of smallest degree whose absolute (or relative) error is at most maxErr
+// on [a, b], along with this error. It returns a nil interpolant if no degree up to maxElementaryDegree reaches the target error.
+func findChebyshevApproximation(function func(float64) float64, a, b, maxErr float64, relative bool) (cheby *ChebyshevInterpolation, err float64) {
+
+	f := func(x complex128) complex128 {
+		return complex(function(real(x)), 0)
+	}
+
+	for degree := 1; degree <= maxElementaryDegree; degree++ {
+
+		cheby = Approximate(f, complex(a, 0), complex(b, 0), degree)
+
+		coeffs := make([]complex128, degree+1)
+		for i, c := range cheby.coeffs {
+			coeffs[i] = c
+		}
+
+		err = 0
+		for i := 0; i < elementaryErrorPoints; i++ {
+
+			x := a + (b-a)*float64(i)/float64(elementaryErrorPoints-1)
+			y := function(x)
+
+			e := math.Abs(real(evaluateChebyshevPolynomial(coeffs, complex(x, 0), complex(a, 0), complex(b, 0))) - y)
+			if relative {
+				e /= math.Abs(y)
+			}
+
+			err = math.Max(err, e)
+		}
+
+		if err <= maxErr {
+			return
+		}
+	}
+
+	return nil, 0
+}
+
+// newtonSteps returns the number of Newton iterations for the inverse square root required to reduce the relative error
+// from relErr to at most maxRelErr. An iteration maps a relative error e to at most 1.5 * e^2 + 0.5 * e^3.
+func newtonSteps(relErr, maxRelErr float64) (steps uint64) {
+	for relErr > maxRelErr && steps <= maxNewtonSteps {
+		relErr = 1.5*relErr*relErr + 0.5*relErr*relErr*relErr
+		steps++
+	}
+	return
+}
+
+// chebyshevDepth returns an upper bound on the number of levels consumed by EvaluateChebyFast on the interpolant: one level for
+// the change of variable from [a, b] to [-1, 1] if 2/(b-a) is not an integer, and at most bits.Len64(degree) + 1 levels for the
+// evaluation of the polynomial.
+func chebyshevDepth(cheby *ChebyshevInterpolation) (depth uint64) {
+
+	depth = uint64(bits.Len64(cheby.degree)) + 1
+
+	if c := real(2 / (cheby.b - cheby.a)); c != math.Trunc(c) {
+		depth++
+	}
+
+	return
+}
+
+func checkElementaryInterval(name string, a, b float64) {
+	if a >= b {
+		panic("cannot " + name + ": the interval [a, b] must satisfy a < b")
+	}
+}
+
+func checkElementaryLevel(name string, ct0 *Ciphertext, depth uint64) {
+	if ct0.Level() < depth {
+		panic("cannot " + name + ": input Ciphertext level is too low")
+	}
+}
@@ -11,6 +11,11 @@ type Encoder interface {
 	Encode(plaintext *Plaintext, values []complex128, slots uint64)
 	EncodeNew(values []complex128, slots uint64) (plaintext *Plaintext)
 	Decode(plaintext *Plaintext, slots uint64) (res []complex128)
+	EncodeReal(plaintext *Plaintext, values []float64, slots uint64)
+	EncodeRealNew(values []float64, slots uint64) (plaintext *Plaintext)
+	DecodeReal(plaintext *Plaintext, slots uint64) (res []float64)
+	EncodeCoeffs(values []float64, plaintext *Plaintext)
+	DecodeCoeffs(plaintext *Plaintext) (res []float64)
 }
 
 // encoder is a struct storing the necessary parameters to encode a slice of complex number on a Plaintext.
@@ -27,11 +32,21 @@ type encoder struct {
 	rotGroup     []uint64
 }
 
+// TryNewEncoder is the same as NewEncoder, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewEncoder(params *Parameters) (Encoder, error) {
+
+	if err := checkParameters("NewEncoder", params); err != nil {
+		return nil, err
+	}
+
+	return NewEncoder(params), nil
+}
+
 // NewEncoder creates a new Encoder that is used to encode a slice of complex values of size at most N/2 (the number of slots) on a Plaintext.
 func NewEncoder(params *Parameters) Encoder {
 
-	if !params.isValid {
-		panic("cannot newEncoder: parameters are invalid (check if the generation was done properly)")
+	if err := checkParameters("NewEncoder", params); err != nil {
+		panic(err)
 	}
 
 	m := uint64(2 << params.LogN)
@@ -115,6 +130,41 @@ func (encoder *encoder) Encode(plaintext *Plaintext, values []complex128, slots
 	}
 }
 
+// EncodeRealNew takes a slice of float64 values of size at most N/2 (the number of slots), encodes it on the real part of the
+// slots and returns the result on a newly created Plaintext.
+func (encoder *encoder) EncodeRealNew(values []float64, slots uint64) (plaintext *Plaintext) {
+	plaintext = NewPlaintext(encoder.params, encoder.params.MaxLevel(), encoder.params.Scale)
+	encoder.EncodeReal(plaintext, values, slots)
+	return
+}
+
+// EncodeReal takes a slice of float64 values of size at most N/2 (the number of slots) and encodes it on the real part of the
+// slots of the receiver Plaintext, the imaginary part being set to zero.
+// The plaintext is an element of Z[X]/(X^N+1), so that real values use the N/2 slots of the complex values and the imaginary
+// parts are wasted. The conjugate-invariant ring Z[X+X^-1]/(X^2N+1), which would store N real values, is not provided.
+func (encoder *encoder) EncodeReal(plaintext *Plaintext, values []float64, slots uint64) {
+
+	valuesComplex := make([]complex128, len(values))
+	for i := range values {
+		valuesComplex[i] = complex(values[i], 0)
+	}
+
+	encoder.Encode(plaintext, valuesComplex, slots)
+}
+
+// DecodeReal decodes the Plaintext values to a slice of float64 values of size at most N/2, storing the real part of the slots.
+func (encoder *encoder) DecodeReal(plaintext *Plaintext, slots uint64) (res []float64) {
+
+	valuesComplex := encoder.Decode(plaintext, slots)
+
+	res = make([]float64, len(valuesComplex))
+	for i := range valuesComplex {
+		res[i] = real(valuesComplex[i])
+	}
+
+	return
+}
+
 // Decode decodes the Plaintext values to a slice of complex128 values of size at most N/2.
 func (encoder *encoder) Decode(plaintext *Plaintext, slots uint64) (res []complex128) {
 
@@ -167,6 +217,52 @@ func (encoder *encoder) Decode(plaintext *Plaintext, slots uint64) (res []comple
 	return
 }
 
+// EncodeCoeffs takes a slice of float64 values of size at most N, scales them by the scale of the receiver Plaintext and
+// encodes them directly on the coefficients of its polynomial, without the canonical embedding (the remaining coefficients are set to zero).
+func (encoder *encoder) EncodeCoeffs(values []float64, plaintext *Plaintext) {
+
+	if uint64(len(values)) > encoder.ckksContext.n {
+		panic("cannot EncodeCoeffs: too many values (maximum is N)")
+	}
+
+	copy(encoder.valuesfloat, values)
+
+	scaleUpVecExact(encoder.valuesfloat, plaintext.scale, encoder.ckksContext.contextQ.Modulus[:plaintext.Level()+1], plaintext.value.Coeffs)
+
+	encoder.ckksContext.contextQ.NTTLvl(plaintext.Level(), plaintext.value, plaintext.value)
+
+	for i := uint64(0); i < encoder.ckksContext.n; i++ {
+		encoder.valuesfloat[i] = 0
+	}
+}
+
+// DecodeCoeffs decodes the coefficients of the polynomial of the Plaintext, divided by its scale, to a slice of float64 values of size N.
+func (encoder *encoder) DecodeCoeffs(plaintext *Plaintext) (res []float64) {
+
+	encoder.ckksContext.contextQ.InvNTTLvl(plaintext.Level(), plaintext.value, encoder.polypool)
+	encoder.ckksContext.contextQ.PolyToBigint(encoder.polypool, encoder.bigintCoeffs)
+
+	Q := encoder.ckksContext.bigintChain[plaintext.Level()]
+
+	encoder.qHalf.Set(Q)
+	encoder.qHalf.Rsh(encoder.qHalf, 1)
+
+	res = make([]float64, encoder.ckksContext.n)
+
+	for i := range res {
+
+		// Centers the value around the current modulus
+		encoder.bigintCoeffs[i].Mod(encoder.bigintCoeffs[i], Q)
+		if encoder.bigintCoeffs[i].Cmp(encoder.qHalf) != -1 {
+			encoder.bigintCoeffs[i].Sub(encoder.bigintCoeffs[i], Q)
+		}
+
+		res[i] = scaleDown(encoder.bigintCoeffs[i], plaintext.scale)
+	}
+
+	return
+}
+
 func (encoder *encoder) invfftlazy(values []complex128, N uint64) {
 
 	var lenh, lenq, gap, idx uint64
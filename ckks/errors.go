@@ -0,0 +1,110 @@
+package ckks
+
+import (
+	"errors"
+
+	"github.com/ldsec/lattigo/ring"
+)
+
+// Sentinel errors classifying the failures reported by the CKKS Error. They can be tested with errors.Is.
+var (
+	// ErrInvalidParameters is returned when the parameters are nil, were not generated or do not enable the operation.
+	ErrInvalidParameters = errors.New("parameters are invalid (check if the generation was done properly)")
+	// ErrNilOperand is returned when an input or output of an operation is nil.
+	ErrNilOperand = errors.New("operand cannot be nil")
+	// ErrInvalidOperand is returned when a Plaintext is given where a Ciphertext is required.
+	ErrInvalidOperand = errors.New("operand cannot be plaintext")
+	// ErrDegreeMismatch is returned when the degree of an operand is not the one required by the operation.
+	ErrDegreeMismatch = errors.New("operand degree does not match")
+	// ErrLevelMismatch is returned when the level of an operand is not the one required by the operation, e.g. by Rescale at level 0.
+	ErrLevelMismatch = errors.New("operand level does not match")
+	// ErrRingDegreeMismatch is returned when the ring degree of an operand or a key does not match the parameters.
+	ErrRingDegreeMismatch = errors.New("ring degree does not match the parameters")
+	// ErrNTTMismatch is returned when an operand is not in the NTT domain required by the operation.
+	ErrNTTMismatch = errors.New("operand is not in the NTT domain")
+	// ErrScaleMismatch is returned when the ratio between the scales of the operands is not an integer.
+	ErrScaleMismatch = errors.New("operand scales cannot be matched")
+	// ErrMissingKey is returned when a key is nil or does not store the keys required by the operation.
+	ErrMissingKey = errors.New("key not generated")
+	// ErrInvalidMatrix is returned when the diagonals of a LinearTransform matrix do not have the same power of two size,
+	// at most N/2, or have the same index modulo this size.
+	ErrInvalidMatrix = errors.New("matrix diagonals are invalid")
+)
+
+// Error is the error returned by the TryX variants of the CKKS constructors, of NewLinearTransform, of NewAutoEvaluator
+// and of the Evaluator methods, such as Rescale, and the value with which X panics. It records the name of the operation
+// and the reason of the failure, and wraps one of the sentinel errors ErrX.
+type Error struct {
+	Op     string // Name of the operation
+	Err    error  // Sentinel error classifying the failure
+	Reason string // Description of the failure, Err.Error() if empty
+}
+
+func newError(op string, err error, reason string) *Error {
+	return &Error{Op: op, Err: err, Reason: reason}
+}
+
+// Error returns the description of the target Error.
+func (e *Error) Error() string {
+	if e.Reason == "" {
+		return "cannot " + e.Op + ": " + e.Err.Error()
+	}
+	return "cannot " + e.Op + ": " + e.Reason
+}
+
+// Unwrap returns the sentinel error wrapped by the target Error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// checkParameters returns an Error if params is nil or was not generated.
+func checkParameters(op string, params *Parameters) error {
+	if params == nil || !params.isValid {
+		return newError(op, ErrInvalidParameters, "")
+	}
+	return nil
+}
+
+// checkPoly returns an Error if the polynomial of a key or an operand is nil or does not have the given number of moduli and
+// the ring degree of the parameters.
+func checkPoly(op string, params *Parameters, pol *ring.Poly, moduli int, name string) error {
+
+	if pol == nil {
+		return newError(op, ErrMissingKey, name+" is nil")
+	}
+
+	if len(pol.Coeffs) != moduli || pol.GetDegree() != 1<<params.LogN {
+		return newError(op, ErrRingDegreeMismatch, name+" ring degree or number of moduli does not match the parameters")
+	}
+
+	return nil
+}
+
+// checkLevel returns an Error if params is nil or was not generated, or if level is larger than the maximum level of
+// the parameters.
+func checkLevel(op string, params *Parameters, level uint64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if level > params.MaxLevel() {
+		return newError(op, ErrLevelMismatch, "level is larger than the maximum level of the parameters")
+	}
+
+	return nil
+}
+
+// checkSpecialPrimes returns an Error if params is nil or was not generated, or if the modulus P is empty.
+func checkSpecialPrimes(op string, params *Parameters) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if len(params.Pi) == 0 {
+		return newError(op, ErrInvalidParameters, "modulus P is empty (use the gadget variant)")
+	}
+
+	return nil
+}
@@ -1,7 +1,6 @@
 package ckks
 
 import (
-	"errors"
 	"github.com/ldsec/lattigo/ring"
 	"github.com/ldsec/lattigo/utils"
 	"math"
@@ -47,6 +46,8 @@ type Evaluator interface {
 	RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext)
 	RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext)
 	RotateHoisted(ctIn *Ciphertext, rotations []uint64, rotkeys *RotationKeys) (cOut map[uint64]*Ciphertext)
+	LinearTransformNew(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys) (ctOut *Ciphertext)
+	LinearTransform(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys, ctOut *Ciphertext)
 	ConjugateNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOut *Ciphertext)
 	Conjugate(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext)
 	PowerOf2(el0 *Ciphertext, logPow2 uint64, evakey *EvaluationKey, elOut *Ciphertext)
@@ -57,6 +58,26 @@ type Evaluator interface {
 	EvaluatePolyEco(ct *Ciphertext, coeffs interface{}, evakey *EvaluationKey) (res *Ciphertext)
 	EvaluateChebyFast(ct *Ciphertext, cheby *ChebyshevInterpolation, evakey *EvaluationKey) (res *Ciphertext)
 	EvaluateChebyEco(ct *Ciphertext, cheby *ChebyshevInterpolation, evakey *EvaluationKey) (res *Ciphertext)
+	Sign(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext)
+	Max(ct0, ct1 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext)
+	Min(ct0, ct1 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey) (ctOut *Ciphertext)
+	MaxSlots(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey, rotkeys *RotationKeys) (ctOut *Ciphertext)
+	ArgMax(ct0 *Ciphertext, sign *SignPolynomial, evakey *EvaluationKey, rotkeys *RotationKeys) (ctOut *Ciphertext)
+	Exp(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	Log(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	Sigmoid(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	Tanh(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	Sqrt(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	InvSqrt(ct0 *Ciphertext, a, b, logPrecision float64, evakey *EvaluationKey) (ctOut *Ciphertext, levels uint64)
+	TryAdd(op0, op1 Operand, ctOut *Ciphertext) (err error)
+	TrySub(op0, op1 Operand, ctOut *Ciphertext) (err error)
+	TryMulRelin(op0, op1 Operand, evakey *EvaluationKey, ctOut *Ciphertext) (err error)
+	TryRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) (err error)
+	TrySwitchKeys(ct0 *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) (err error)
+	TryRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) (err error)
+	TryConjugate(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) (err error)
+	ShallowCopy() Evaluator
+	WithKey(evakey *EvaluationKey, rotkeys *RotationKeys) Evaluator
 }
 
 // evaluator is a struct that holds the necessary elements to execute the homomorphic operations between Ciphertexts and/or Plaintexts.
@@ -64,15 +85,52 @@ type Evaluator interface {
 type evaluator struct {
 	params      *Parameters
 	ckksContext *Context
-	ringpool    [6]*ring.Poly
+
+	baseconverter *ring.FastBasisExtender
+	decomposer    *ring.Decomposer
+
+	evakey  *EvaluationKey
+	rotkeys *RotationKeys
+
+	*evaluatorBuffers
+}
+
+// evaluatorBuffers is a struct that holds the memory pool of an evaluator. Unlike the other fields of the evaluator,
+// it is written by the homomorphic operations, and is therefore never shared between evaluators.
+type evaluatorBuffers struct {
+	ringpool [6]*ring.Poly
 
 	poolQ [4]*ring.Poly
 	poolP [3]*ring.Poly
 
 	ctxpool *Ciphertext
+}
 
-	baseconverter *ring.FastBasisExtender
-	decomposer    *ring.Decomposer
+func newEvaluatorBuffers(params *Parameters, ckksContext *Context) *evaluatorBuffers {
+
+	q := ckksContext.contextQ
+	p := ckksContext.contextP
+
+	buffers := new(evaluatorBuffers)
+
+	buffers.ringpool = [6]*ring.Poly{q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly()}
+	buffers.poolQ = [4]*ring.Poly{q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly()}
+	if len(params.Pi) != 0 {
+		buffers.poolP = [3]*ring.Poly{p.NewPoly(), p.NewPoly(), p.NewPoly()}
+	}
+	buffers.ctxpool = NewCiphertext(params, 1, params.MaxLevel(), params.Scale)
+
+	return buffers
+}
+
+// TryNewEvaluator is the same as NewEvaluator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewEvaluator(params *Parameters) (Evaluator, error) {
+
+	if err := checkParameters("NewEvaluator", params); err != nil {
+		return nil, err
+	}
+
+	return NewEvaluator(params), nil
 }
 
 // NewEvaluator creates a new Evaluator, that can be used to do homomorphic
@@ -80,8 +138,8 @@ type evaluator struct {
 // and Ciphertexts that will be used for intermediate values.
 func NewEvaluator(params *Parameters) Evaluator {
 
-	if !params.isValid {
-		panic("cannot newEvaluator: parameters are invalid (check if the generation was done properly)")
+	if err := checkParameters("NewEvaluator", params); err != nil {
+		panic(err)
 	}
 
 	ckksContext := newContext(params)
@@ -90,55 +148,202 @@ func NewEvaluator(params *Parameters) Evaluator {
 
 	var baseconverter *ring.FastBasisExtender
 	var decomposer *ring.Decomposer
-	var poolP [3]*ring.Poly
 	if len(params.Pi) != 0 {
 		baseconverter = ring.NewFastBasisExtender(q, p)
 		decomposer = ring.NewDecomposer(q.Modulus, p.Modulus)
-		poolP = [3]*ring.Poly{p.NewPoly(), p.NewPoly(), p.NewPoly()}
 	}
 
 	return &evaluator{
-		params:        params.Copy(),
-		ckksContext:   ckksContext,
-		ringpool:      [6]*ring.Poly{q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly()},
-		poolQ:         [4]*ring.Poly{q.NewPoly(), q.NewPoly(), q.NewPoly(), q.NewPoly()},
-		poolP:         poolP,
-		ctxpool:       NewCiphertext(params, 1, params.MaxLevel(), params.Scale),
-		baseconverter: baseconverter,
-		decomposer:    decomposer,
+		params:           params.Copy(),
+		ckksContext:      ckksContext,
+		baseconverter:    baseconverter,
+		decomposer:       decomposer,
+		evaluatorBuffers: newEvaluatorBuffers(params, ckksContext),
 	}
 }
 
-func (eval *evaluator) getElemAndCheckBinary(op0, op1, opOut Operand, opOutMinDegree uint64) (el0, el1, elOut *ckksElement) {
-	if op0 == nil || op1 == nil || opOut == nil {
-		panic("operands cannot be nil")
+// ShallowCopy creates a shallow copy of the target evaluator, which shares its read-only precomputations (the contexts,
+// the basis extension and decomposition parameters) and its keys, but has its own memory pool. This is much cheaper than
+// NewEvaluator, and the copy and the target can be used concurrently, e.g., by one evaluator per goroutine.
+func (eval *evaluator) ShallowCopy() Evaluator {
+	return eval.shallowCopy()
+}
+
+// WithKey creates a shallow copy of the target evaluator (see ShallowCopy) that stores the given evaluation and rotation
+// keys (either of which can be nil). The returned evaluator uses them in Relinearize, RotateColumns, RotateHoisted,
+// Conjugate and LinearTransform when they are given a nil key. MulRelin is not affected, as a nil evaluation key
+// disables its relinearization.
+func (eval *evaluator) WithKey(evakey *EvaluationKey, rotkeys *RotationKeys) Evaluator {
+	evalCopy := eval.shallowCopy()
+	evalCopy.evakey = evakey
+	evalCopy.rotkeys = rotkeys
+	return evalCopy
+}
+
+func (eval *evaluator) shallowCopy() *evaluator {
+
+	var baseconverter *ring.FastBasisExtender
+	if eval.baseconverter != nil {
+		baseconverter = eval.baseconverter.ShallowCopy()
 	}
 
-	if op0.Degree()+op1.Degree() == 0 {
-		panic("operands cannot be both plaintext")
+	return &evaluator{
+		params:           eval.params,
+		ckksContext:      eval.ckksContext,
+		baseconverter:    baseconverter,
+		decomposer:       eval.decomposer,
+		evakey:           eval.evakey,
+		rotkeys:          eval.rotkeys,
+		evaluatorBuffers: newEvaluatorBuffers(eval.params, eval.ckksContext),
 	}
+}
 
-	if opOut.Degree() < opOutMinDegree {
-		panic("receiver operand degree is too small")
+// getEvaluationKey returns evakey, or the evaluation key stored by the evaluator if evakey is nil.
+func (eval *evaluator) getEvaluationKey(evakey *EvaluationKey) *EvaluationKey {
+	if evakey == nil {
+		return eval.evakey
+	}
+	return evakey
+}
+
+// getRotationKeys returns rotkeys, or the rotation keys stored by the evaluator if rotkeys is nil.
+func (eval *evaluator) getRotationKeys(rotkeys *RotationKeys) *RotationKeys {
+	if rotkeys == nil {
+		return eval.rotkeys
+	}
+	return rotkeys
+}
+
+func (eval *evaluator) getElemAndCheckBinary(op0, op1, opOut Operand, opOutMinDegree uint64) (el0, el1, elOut *ckksElement) {
+
+	if err := eval.checkBinary("getElemAndCheckBinary", op0, op1, opOut, opOutMinDegree); err != nil {
+		panic(err)
 	}
+
 	el0, el1, elOut = op0.Element(), op1.Element(), opOut.Element()
+	elOut.seed = nil
 	return // TODO: more checks on elements
 }
 
 func (eval *evaluator) getElemAndCheckUnary(op0, opOut Operand, opOutMinDegree uint64) (el0, elOut *ckksElement) {
-	if op0 == nil || opOut == nil {
-		panic("operand cannot be nil")
+
+	if err := eval.checkUnary("getElemAndCheckUnary", op0, opOut, opOutMinDegree); err != nil {
+		panic(err)
+	}
+
+	el0, elOut = op0.Element(), opOut.Element()
+	elOut.seed = nil
+	return // TODO: more checks on elements
+}
+
+// checkOperands returns an Error if one of the operands is nil or if their ring degree does not match the parameters.
+func (eval *evaluator) checkOperands(op string, operands ...Operand) error {
+
+	for _, operand := range operands {
+
+		if isNilOperand(operand) {
+			return newError(op, ErrNilOperand, "operands cannot be nil")
+		}
+
+		for _, pol := range operand.Element().value {
+			if pol.GetDegree() != int(eval.ckksContext.n) {
+				return newError(op, ErrRingDegreeMismatch, "operand ring degree does not match the parameters")
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkBinary returns an Error if the operands of a binary operation are not consistent.
+func (eval *evaluator) checkBinary(op string, op0, op1, opOut Operand, opOutMinDegree uint64) error {
+
+	if err := eval.checkOperands(op, op0, op1, opOut); err != nil {
+		return err
+	}
+
+	if op0.Degree()+op1.Degree() == 0 {
+		return newError(op, ErrInvalidOperand, "operands cannot be both plaintext")
+	}
+
+	if opOut.Degree() < opOutMinDegree {
+		return newError(op, ErrDegreeMismatch, "receiver operand degree is too small")
+	}
+
+	return nil
+}
+
+// checkUnary returns an Error if the operands of a unary operation are not consistent.
+func (eval *evaluator) checkUnary(op string, op0, opOut Operand, opOutMinDegree uint64) error {
+
+	if err := eval.checkOperands(op, op0, opOut); err != nil {
+		return err
 	}
 
 	if op0.Degree() == 0 {
-		panic("operand cannot be plaintext")
+		return newError(op, ErrInvalidOperand, "operand cannot be plaintext")
 	}
 
 	if opOut.Degree() < opOutMinDegree {
-		panic("receiver operand degree is too small")
+		return newError(op, ErrDegreeMismatch, "receiver operand degree is too small")
 	}
-	el0, elOut = op0.Element(), opOut.Element()
-	return // TODO: more checks on elements
+
+	return nil
+}
+
+// scaleTolerance is the relative difference between the ratio of the scales of two operands and its integer part
+// above which the scales of the operands of an addition are considered as not matching.
+const scaleTolerance = 1.0 / 1024
+
+// checkAddition returns an Error if the operands of an addition or a subtraction are not consistent, or if their
+// scales cannot be matched. The scales are matched by multiplying the operand of smaller scale by the integer part
+// of the ratio between the scales, which must therefore be an integer up to the relative tolerance scaleTolerance.
+func (eval *evaluator) checkAddition(op string, op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := eval.checkOperands(op, op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	if err := eval.checkBinary(op, op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree())); err != nil {
+		return err
+	}
+
+	ratio := math.Max(op0.Scale(), op1.Scale()) / math.Min(op0.Scale(), op1.Scale())
+
+	if ratio-math.Floor(ratio) > scaleTolerance*ratio {
+		return newError(op, ErrScaleMismatch, "the ratio between the scales of the operands is not an integer")
+	}
+
+	return nil
+}
+
+// checkKeySwitch returns an Error if the input and output Ciphertexts of a key-switching operation are not of degree 1.
+func (eval *evaluator) checkKeySwitch(op string, ct0, ctOut *Ciphertext) error {
+
+	if err := eval.checkOperands(op, ct0, ctOut); err != nil {
+		return err
+	}
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		return newError(op, ErrDegreeMismatch, "input and output Ciphertext must be of degree 1")
+	}
+
+	return nil
+}
+
+// isNilOperand returns true if the operand, or the element it wraps, is nil.
+func isNilOperand(op Operand) bool {
+	switch el := op.(type) {
+	case nil:
+		return true
+	case *Ciphertext:
+		return el == nil || el.ckksElement == nil
+	case *Plaintext:
+		return el == nil || el.ckksElement == nil
+	case *ckksElement:
+		return el == nil
+	}
+	return false
 }
 
 func (eval *evaluator) newCiphertextBinary(op0, op1 Operand) (ctOut *Ciphertext) {
@@ -176,6 +381,32 @@ func (eval *evaluator) AddNoModNew(op0, op1 Operand) (ctOut *Ciphertext) {
 	return
 }
 
+// TryAdd is the same as Add, but returns an Error instead of panicking if the operands are inconsistent or if their
+// scales cannot be matched, that is, if the ratio between them is not an integer.
+func (eval *evaluator) TryAdd(op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := eval.checkAddition("Add", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	eval.Add(op0, op1, ctOut)
+
+	return nil
+}
+
+// TrySub is the same as Sub, but returns an Error instead of panicking if the operands are inconsistent or if their
+// scales cannot be matched, that is, if the ratio between them is not an integer.
+func (eval *evaluator) TrySub(op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := eval.checkAddition("Sub", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	eval.Sub(op0, op1, ctOut)
+
+	return nil
+}
+
 // Sub subtracts op1 from op0 and returns the result in ctOut.
 func (eval *evaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
 
@@ -344,6 +575,8 @@ func (eval *evaluator) evaluateInPlace(c0, c1, ctOut *ckksElement, evaluate func
 // Neg negates the value of ct0 and returns the result in ctOut.
 func (eval *evaluator) Neg(ct0 *Ciphertext, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	level := utils.MinUint64(ct0.Level(), ctOut.Level())
 
 	if ct0.Degree() != ctOut.Degree() {
@@ -372,6 +605,8 @@ func (eval *evaluator) AddConstNew(ct0 *Ciphertext, constant interface{}) (ctOut
 // AddConst adds the input constant (which can be a uint64, int64, float64 or complex128) to ct0 and returns the result in ctOut.
 func (eval *evaluator) AddConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	var level uint64
 
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
@@ -450,6 +685,8 @@ func (eval *evaluator) AddConst(ct0 *Ciphertext, constant interface{}, ctOut *Ci
 // The scale of the receiver element will be set to the scale that the input element would have after the multiplication by the constant.
 func (eval *evaluator) MultByConstAndAdd(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	var level uint64
 
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
@@ -621,6 +858,8 @@ func (eval *evaluator) MultByConstNew(ct0 *Ciphertext, constant interface{}) (ct
 // needs to be scaled (its rational part is not zero)). The constant can be a uint64, int64, float64 or complex128.
 func (eval *evaluator) MultByConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	var level uint64
 
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
@@ -745,6 +984,8 @@ func (eval *evaluator) MultByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 // It does not change the scale.
 func (eval *evaluator) MultByi(ct0 *Ciphertext, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	var level uint64
 
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
@@ -794,6 +1035,8 @@ func (eval *evaluator) DivByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 // It does not change the scale.
 func (eval *evaluator) DivByi(ct0 *Ciphertext, ctOut *Ciphertext) {
 
+	ctOut.seed = nil
+
 	var level uint64
 
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
@@ -855,6 +1098,8 @@ func (eval *evaluator) MulByPow2New(ct0 *Ciphertext, pow2 uint64) (ctOut *Cipher
 
 // MulByPow2 multiplies ct0 by 2^pow2 and returns the result in ctOut.
 func (eval *evaluator) MulByPow2(ct0 *ckksElement, pow2 uint64, ctOut *ckksElement) {
+	ctOut.seed = nil
+
 	var level uint64
 	level = utils.MinUint64(ct0.Level(), ctOut.Level())
 	for i := range ctOut.Value() {
@@ -877,8 +1122,10 @@ func (eval *evaluator) ReduceNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 // To be used in conjunction with functions that do not apply modular reduction.
 func (eval *evaluator) Reduce(ct0 *Ciphertext, ctOut *Ciphertext) error {
 
+	ctOut.seed = nil
+
 	if ct0.Degree() != ctOut.Degree() {
-		return errors.New("cannot Reduce: degrees of receiver Ciphertext and input Ciphertext do not match")
+		return newError("Reduce", ErrDegreeMismatch, "degrees of receiver Ciphertext and input Ciphertext do not match")
 	}
 
 	for i := range ct0.value {
@@ -900,8 +1147,10 @@ func (eval *evaluator) DropLevelNew(ct0 *Ciphertext, levels uint64) (ctOut *Ciph
 // No rescaling is applied during this procedure.
 func (eval *evaluator) DropLevel(ct0 *Ciphertext, levels uint64) (err error) {
 
+	ct0.seed = nil
+
 	if ct0.Level() == 0 {
-		return errors.New("cannot DropLevel: Ciphertext already at level 0")
+		return newError("DropLevel", ErrLevelMismatch, "Ciphertext already at level 0")
 	}
 
 	level := ct0.Level()
@@ -932,20 +1181,22 @@ func (eval *evaluator) RescaleNew(ct0 *Ciphertext, threshold float64) (ctOut *Ci
 // some error.
 func (eval *evaluator) Rescale(ct0 *Ciphertext, threshold float64, ctOut *Ciphertext) (err error) {
 
+	ctOut.seed = nil
+
 	ringContext := eval.ckksContext.contextQ
 
 	if ct0.Level() == 0 {
-		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+		return newError("Rescale", ErrLevelMismatch, "input Ciphertext already at level 0")
 	}
 
 	if ct0.Level() != ctOut.Level() {
-		panic("cannot Rescale: degrees of receiver Ciphertext and input Ciphertext do not match")
+		return newError("Rescale", ErrLevelMismatch, "levels of receiver Ciphertext and input Ciphertext do not match")
 	}
 
 	if ct0.Scale() >= (threshold*float64(ringContext.Modulus[ctOut.Level()]))/2 {
 
 		if !ct0.IsNTT() {
-			panic("cannot Rescale: input Ciphertext not in NTT")
+			return newError("Rescale", ErrNTTMismatch, "input Ciphertext not in NTT")
 		}
 
 		ctOut.Copy(ct0.Element())
@@ -970,16 +1221,18 @@ func (eval *evaluator) Rescale(ct0 *Ciphertext, threshold float64, ctOut *Cipher
 // RescaleMany applies Rescale several times in a row on the input Ciphertext.
 func (eval *evaluator) RescaleMany(ct0 *Ciphertext, nbRescales uint64, ctOut *Ciphertext) (err error) {
 
+	ctOut.seed = nil
+
 	if ct0.Level() < nbRescales {
-		return errors.New("cannot RescaleMany: input Ciphertext level too low")
+		return newError("RescaleMany", ErrLevelMismatch, "input Ciphertext level too low")
 	}
 
 	if ct0.Level() != ctOut.Level() {
-		panic("cannot RescaleMany: degrees of receiver Ciphertext and input Ciphertext do not match")
+		return newError("RescaleMany", ErrLevelMismatch, "levels of receiver Ciphertext and input Ciphertext do not match")
 	}
 
 	if !ct0.IsNTT() {
-		panic("cannot RescaleMany: input Ciphertext not in NTT")
+		return newError("RescaleMany", ErrNTTMismatch, "input Ciphertext not in NTT")
 	}
 
 	ctOut.Copy(ct0.Element())
@@ -995,6 +1248,44 @@ func (eval *evaluator) RescaleMany(ct0 *Ciphertext, nbRescales uint64, ctOut *Ci
 	return nil
 }
 
+// TryMulRelin is the same as MulRelin, but returns an Error instead of panicking if the operands are inconsistent
+// or not in the NTT domain.
+func (eval *evaluator) TryMulRelin(op0, op1 Operand, evakey *EvaluationKey, ctOut *Ciphertext) error {
+
+	if err := eval.checkMulRelin(op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	eval.MulRelin(op0, op1, evakey, ctOut)
+
+	return nil
+}
+
+func (eval *evaluator) checkMulRelin(op0, op1 Operand, ctOut *Ciphertext) error {
+
+	if err := eval.checkOperands("MulRelin", op0, op1, ctOut); err != nil {
+		return err
+	}
+
+	if err := eval.checkBinary("MulRelin", op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree())); err != nil {
+		return err
+	}
+
+	if op0.Degree() > 1 || op1.Degree() > 1 {
+		return newError("MulRelin", ErrDegreeMismatch, "input elements must be of degree 0 or 1")
+	}
+
+	if !op0.Element().IsNTT() {
+		return newError("MulRelin", ErrNTTMismatch, "op0 must be in NTT")
+	}
+
+	if !op1.Element().IsNTT() {
+		return newError("MulRelin", ErrNTTMismatch, "op1 must be in NTT")
+	}
+
+	return nil
+}
+
 // MulRelinNew multiplies ct0 by ct1 and returns the result in a newly created element. The new scale is
 // the multiplication between the scales of the input elements (addition when the scale is represented in log2). An evaluation
 // key can be provided to apply a relinearization step to reduce the degree of the output element. This evaluation key is only
@@ -1015,7 +1306,13 @@ func (eval *evaluator) MulRelinNew(op0, op1 Operand, evakey *EvaluationKey) (ctO
 // the resulting Ciphertext will be of degree two. This function only accepts Plaintexts (degree zero) and/or Ciphertexts of degree one.
 func (eval *evaluator) MulRelin(op0, op1 Operand, evakey *EvaluationKey, ctOut *Ciphertext) {
 
-	el0, el1, elOut := eval.getElemAndCheckBinary(op0, op1, ctOut, utils.MaxUint64(op0.Degree(), op1.Degree()))
+	if err := eval.checkMulRelin(op0, op1, ctOut); err != nil {
+		panic(err)
+	}
+
+	ctOut.seed = nil
+
+	el0, el1, elOut := op0.Element(), op1.Element(), ctOut.Element()
 
 	level := utils.MinUint64(utils.MinUint64(el0.Level(), el1.Level()), elOut.Level())
 
@@ -1023,18 +1320,6 @@ func (eval *evaluator) MulRelin(op0, op1 Operand, evakey *EvaluationKey, ctOut *
 		eval.DropLevel(elOut.Ciphertext(), elOut.Level()-level)
 	}
 
-	if el0.Degree() > 1 || el1.Degree() > 1 {
-		panic("cannot MulRelin: input elements must be of degree 0 or 1")
-	}
-
-	if !el0.IsNTT() {
-		panic("cannot MulRelin: op0 must be in NTT")
-	}
-
-	if !el1.IsNTT() {
-		panic("cannot MulRelin: op1 must be in NTT")
-	}
-
 	elOut.SetScale(el0.Scale() * el1.Scale())
 
 	context := eval.ckksContext.contextQ
@@ -1142,10 +1427,15 @@ func (eval *evaluator) RelinearizeNew(ct0 *Ciphertext, evakey *EvaluationKey) (c
 
 // Relinearize applies the relinearization procedure on ct0 and returns the result in ctOut. The input Ciphertext must be of degree two.
 func (eval *evaluator) Relinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) {
-	if ct0.Degree() != 2 {
-		panic("cannot Relinearize: input Ciphertext is not of degree 2")
+
+	evakey = eval.getEvaluationKey(evakey)
+
+	if err := eval.checkRelinearize(ct0, evakey, ctOut); err != nil {
+		panic(err)
 	}
 
+	ctOut.seed = nil
+
 	if ctOut != ct0 {
 		ctOut.SetScale(ct0.Scale())
 	}
@@ -1161,6 +1451,41 @@ func (eval *evaluator) Relinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut
 	ctOut.Resize(eval.params, 1)
 }
 
+// TryRelinearize is the same as Relinearize, but returns an Error instead of panicking if the inputs are inconsistent.
+func (eval *evaluator) TryRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) error {
+
+	evakey = eval.getEvaluationKey(evakey)
+
+	if err := eval.checkRelinearize(ct0, evakey, ctOut); err != nil {
+		return err
+	}
+
+	eval.Relinearize(ct0, evakey, ctOut)
+
+	return nil
+}
+
+func (eval *evaluator) checkRelinearize(ct0 *Ciphertext, evakey *EvaluationKey, ctOut *Ciphertext) error {
+
+	if err := eval.checkOperands("Relinearize", ct0, ctOut); err != nil {
+		return err
+	}
+
+	if ct0.Degree() != 2 {
+		return newError("Relinearize", ErrDegreeMismatch, "input Ciphertext is not of degree 2")
+	}
+
+	if ctOut.Degree() < 1 {
+		return newError("Relinearize", ErrDegreeMismatch, "receiver operand degree is too small")
+	}
+
+	if evakey == nil || evakey.evakey == nil {
+		return newError("Relinearize", ErrMissingKey, "evaluation key not generated")
+	}
+
+	return nil
+}
+
 // SwitchKeysNew re-encrypts ct0 under a different key and returns the result in a newly created element.
 // It requires a SwitchingKey, which is computed from the key under which the Ciphertext is currently encrypted,
 // and the key under which the Ciphertext will be re-encrypted.
@@ -1175,10 +1500,12 @@ func (eval *evaluator) SwitchKeysNew(ct0 *Ciphertext, switchingKey *SwitchingKey
 // and the key under which the Ciphertext will be re-encrypted.
 func (eval *evaluator) SwitchKeys(ct0 *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot SwitchKeys: input and output Ciphertext must be of degree 1")
+	if err := eval.checkSwitchKeys(ct0, switchingKey, ctOut); err != nil {
+		panic(err)
 	}
 
+	ctOut.seed = nil
+
 	level := utils.MinUint64(ct0.Level(), ctOut.Level())
 	context := eval.ckksContext.contextQ
 
@@ -1188,6 +1515,31 @@ func (eval *evaluator) SwitchKeys(ct0 *Ciphertext, switchingKey *SwitchingKey, c
 	context.CopyLvl(level, eval.poolQ[2], ctOut.value[1])
 }
 
+// TrySwitchKeys is the same as SwitchKeys, but returns an Error instead of panicking if the inputs are inconsistent.
+func (eval *evaluator) TrySwitchKeys(ct0 *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) error {
+
+	if err := eval.checkSwitchKeys(ct0, switchingKey, ctOut); err != nil {
+		return err
+	}
+
+	eval.SwitchKeys(ct0, switchingKey, ctOut)
+
+	return nil
+}
+
+func (eval *evaluator) checkSwitchKeys(ct0 *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) error {
+
+	if err := eval.checkKeySwitch("SwitchKeys", ct0, ctOut); err != nil {
+		return err
+	}
+
+	if switchingKey == nil {
+		return newError("SwitchKeys", ErrMissingKey, "switching key is nil")
+	}
+
+	return nil
+}
+
 // RotateColumnsNew rotates the columns of ct0 by k positions to the left, and returns the result in a newly created element.
 // If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for the specific rotation needs to be provided.
 func (eval *evaluator) RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *RotationKeys) (ctOut *Ciphertext) {
@@ -1200,10 +1552,14 @@ func (eval *evaluator) RotateColumnsNew(ct0 *Ciphertext, k uint64, evakey *Rotat
 // If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for the specific rotation needs to be provided.
 func (eval *evaluator) RotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot RotateColumns: input and output Ciphertext must be of degree 1")
+	evakey = eval.getRotationKeys(evakey)
+
+	if err := eval.checkRotateColumns(ct0, k, evakey, ctOut); err != nil {
+		panic(err)
 	}
 
+	ctOut.seed = nil
+
 	k &= ((eval.ckksContext.n >> 1) - 1)
 
 	if k == 0 {
@@ -1219,39 +1575,108 @@ func (eval *evaluator) RotateColumns(ct0 *Ciphertext, k uint64, evakey *Rotation
 
 			eval.permuteNTT(ct0, evakey.permuteNTTLeftIndex[k], evakey.evakeyRotColLeft[k], ctOut)
 
+			// If not, it computes the least amount of rotation between left and right required to apply the demanded
+			// rotation from the left and right pow2 rotations
+		} else if utils.HammingWeight64(k) <= utils.HammingWeight64((eval.ckksContext.n>>1)-k) {
+			eval.rotateColumnsLPow2(ct0, k, evakey, ctOut)
 		} else {
+			eval.rotateColumnsRPow2(ct0, (eval.ckksContext.n>>1)-k, evakey, ctOut)
+		}
+	}
+}
 
-			// If not, it checks if the left and right pow2 rotations have been generated
-			hasPow2Rotations := true
-			for i := uint64(1); i < eval.ckksContext.n>>1; i <<= 1 {
-				if evakey.evakeyRotColLeft[i] == nil || evakey.evakeyRotColRight[i] == nil {
-					hasPow2Rotations = false
-					break
-				}
-			}
+// TryRotateColumns is the same as RotateColumns, but returns an Error instead of panicking if the inputs are inconsistent
+// or if the required rotation keys have not been generated.
+func (eval *evaluator) TryRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) error {
 
-			// If yes, it computes the least amount of rotation between left and right required to apply the demanded rotation
-			if hasPow2Rotations {
+	evakey = eval.getRotationKeys(evakey)
 
-				if utils.HammingWeight64(k) <= utils.HammingWeight64((eval.ckksContext.n>>1)-k) {
-					eval.rotateColumnsLPow2(ct0, k, evakey, ctOut)
-				} else {
-					eval.rotateColumnsRPow2(ct0, (eval.ckksContext.n>>1)-k, evakey, ctOut)
-				}
+	if err := eval.checkRotateColumns(ct0, k, evakey, ctOut); err != nil {
+		return err
+	}
 
-				// Otherwise, it returns an error indicating that the keys have not been generated
-			} else {
-				panic("cannot RotateColumns: specific rotation and pow2 rotations have not been generated")
-			}
+	eval.RotateColumns(ct0, k, evakey, ctOut)
+
+	return nil
+}
+
+func (eval *evaluator) checkRotateColumns(ct0 *Ciphertext, k uint64, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	if err := eval.checkKeySwitch("RotateColumns", ct0, ctOut); err != nil {
+		return err
+	}
+
+	k &= ((eval.ckksContext.n >> 1) - 1)
+
+	if k == 0 {
+		return nil
+	}
+
+	if evakey == nil {
+		return newError("RotateColumns", ErrMissingKey, "rotation keys are nil")
+	}
+
+	// It checks in the RotationKeys if the corresponding rotation has been generated
+	if evakey.evakeyRotColLeft[k] != nil {
+		return nil
+	}
+
+	// If not, it checks if the left and right pow2 rotations have been generated
+	for i := uint64(1); i < eval.ckksContext.n>>1; i <<= 1 {
+		if evakey.evakeyRotColLeft[i] == nil || evakey.evakeyRotColRight[i] == nil {
+			return newError("RotateColumns", ErrMissingKey, "specific rotation and pow2 rotations have not been generated")
 		}
 	}
+
+	return nil
 }
 
 // RotateHoisted takes an input Ciphertext and a list of rotations and returns a map of Ciphertext, where each element of the map is the input Ciphertext
 // rotation by one element of the list. It is much faster than sequential calls to RotateColumns.
 func (eval *evaluator) RotateHoisted(ct0 *Ciphertext, rotations []uint64, rotkeys *RotationKeys) (cOut map[uint64]*Ciphertext) {
 
-	// Pre-computation for rotations using hoisting
+	rotkeys = eval.getRotationKeys(rotkeys)
+
+	var c2QiQDecomp, c2QiPDecomp []*ring.Poly
+
+	cOut = make(map[uint64]*Ciphertext)
+
+	for _, i := range rotations {
+
+		i &= ((eval.ckksContext.n >> 1) - 1)
+
+		if i == 0 {
+			cOut[i] = ct0.CopyNew().Ciphertext()
+			continue
+		}
+
+		cOut[i] = NewCiphertext(eval.params, 1, ct0.Level(), ct0.Scale())
+
+		// The decomposition with the special primes cannot be shared with the keys using a gadget decomposition
+		if evakey := rotkeys.evakeyRotColLeft[i]; evakey != nil && evakey.logBase != 0 {
+			eval.permuteNTT(ct0, rotkeys.permuteNTTLeftIndex[i], evakey, cOut[i])
+			continue
+		}
+
+		// The decomposition is only computed if at least one rotation uses the special primes
+		if c2QiQDecomp == nil {
+			c2QiQDecomp, c2QiPDecomp = eval.decomposeHoisted(ct0)
+		}
+
+		eval.switchKeyHoisted(ct0, c2QiQDecomp, c2QiPDecomp, i, rotkeys, cOut[i])
+	}
+
+	return
+}
+
+// decomposeHoisted returns the CRT decomposition of ct0.value[1] split in the moduli Q and P, which is the pre-computation
+// shared by the rotations using hoisting.
+func (eval *evaluator) decomposeHoisted(ct0 *Ciphertext) (c2QiQDecomp, c2QiPDecomp []*ring.Poly) {
+
+	if eval.decomposer == nil {
+		panic("cannot RotateHoisted: modulus P is empty")
+	}
+
 	contextQ := eval.ckksContext.contextQ
 	contextP := eval.ckksContext.contextP
 
@@ -1262,8 +1687,8 @@ func (eval *evaluator) RotateHoisted(ct0 *Ciphertext, rotations []uint64, rotkey
 	alpha := eval.params.Alpha()
 	beta := uint64(math.Ceil(float64(ct0.Level()+1) / float64(alpha)))
 
-	c2QiQDecomp := make([]*ring.Poly, beta)
-	c2QiPDecomp := make([]*ring.Poly, beta)
+	c2QiQDecomp = make([]*ring.Poly, beta)
+	c2QiPDecomp = make([]*ring.Poly, beta)
 
 	for i := uint64(0); i < beta; i++ {
 		c2QiQDecomp[i] = contextQ.NewPoly()
@@ -1271,20 +1696,6 @@ func (eval *evaluator) RotateHoisted(ct0 *Ciphertext, rotations []uint64, rotkey
 		eval.decomposeAndSplitNTT(ct0.Level(), i, c2NTT, c2InvNTT, c2QiQDecomp[i], c2QiPDecomp[i])
 	}
 
-	cOut = make(map[uint64]*Ciphertext)
-
-	for _, i := range rotations {
-
-		i &= ((eval.ckksContext.n >> 1) - 1)
-
-		if i == 0 {
-			cOut[i] = ct0.CopyNew().Ciphertext()
-		} else {
-			cOut[i] = NewCiphertext(eval.params, 1, ct0.Level(), ct0.Scale())
-			eval.switchKeyHoisted(ct0, c2QiQDecomp, c2QiPDecomp, i, rotkeys, cOut[i])
-		}
-	}
-
 	return
 }
 
@@ -1436,19 +1847,47 @@ func (eval *evaluator) ConjugateNew(ct0 *Ciphertext, evakey *RotationKeys) (ctOu
 // If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for the row rotation needs to be provided.
 func (eval *evaluator) Conjugate(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) {
 
-	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot Conjugate: input and output Ciphertext must be of degree 1")
-	}
+	evakey = eval.getRotationKeys(evakey)
 
-	if evakey.evakeyConjugate == nil {
-		panic("cannot Conjugate: rows rotation key not generated")
+	if err := eval.checkConjugate(ct0, evakey, ctOut); err != nil {
+		panic(err)
 	}
 
+	ctOut.seed = nil
+
 	ctOut.SetScale(ct0.Scale())
 
 	eval.permuteNTT(ct0, evakey.permuteNTTConjugateIndex, evakey.evakeyConjugate, ctOut)
 }
 
+// TryConjugate is the same as Conjugate, but returns an Error instead of panicking if the inputs are inconsistent
+// or if the rotation key has not been generated.
+func (eval *evaluator) TryConjugate(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	evakey = eval.getRotationKeys(evakey)
+
+	if err := eval.checkConjugate(ct0, evakey, ctOut); err != nil {
+		return err
+	}
+
+	eval.Conjugate(ct0, evakey, ctOut)
+
+	return nil
+}
+
+func (eval *evaluator) checkConjugate(ct0 *Ciphertext, evakey *RotationKeys, ctOut *Ciphertext) error {
+
+	if err := eval.checkKeySwitch("Conjugate", ct0, ctOut); err != nil {
+		return err
+	}
+
+	if evakey == nil || evakey.evakeyConjugate == nil {
+		return newError("Conjugate", ErrMissingKey, "rows rotation key not generated")
+	}
+
+	return nil
+}
+
 func (eval *evaluator) permuteNTT(ct0 *Ciphertext, index []uint64, evakey *SwitchingKey, ctOut *Ciphertext) {
 
 	var el0, el1 *ring.Poly
@@ -1473,6 +1912,16 @@ func (eval *evaluator) permuteNTT(ct0 *Ciphertext, index []uint64, evakey *Switc
 
 // switchKeysInPlace applies the general key-switching procedure of the form [c0 + cx*evakey[0], c1 + cx*evakey[1]]
 func (eval *evaluator) switchKeysInPlace(level uint64, cx *ring.Poly, evakey *SwitchingKey, p0, p1 *ring.Poly) {
+
+	if evakey.logBase != 0 {
+		eval.switchKeysInPlaceGadget(level, cx, evakey, p0, p1)
+		return
+	}
+
+	if eval.decomposer == nil {
+		panic("cannot switchKeysInPlace: modulus P is empty")
+	}
+
 	var reduce uint64
 
 	contextQ := eval.ckksContext.contextQ
@@ -1557,6 +2006,51 @@ func (eval *evaluator) switchKeysInPlace(level uint64, cx *ring.Poly, evakey *Sw
 	eval.baseconverter.ModDownSplitedNTTPQ(level, pool3Q, pool3P, pool3Q)
 }
 
+// switchKeysInPlaceGadget is the same as switchKeysInPlace for a SwitchingKey using the gadget decomposition of base 2^logBase:
+// each Qi residue of cx is decomposed in base 2^logBase, and each digit is multiplied by the corresponding element of the key
+// in basis Q. The result is returned in p0 and p1, in the NTT domain.
+func (eval *evaluator) switchKeysInPlaceGadget(level uint64, cx *ring.Poly, evakey *SwitchingKey, p0, p1 *ring.Poly) {
+
+	var reduce uint64
+
+	contextQ := eval.ckksContext.contextQ
+
+	c2InvNTT := eval.poolQ[0]
+	c2 := eval.poolQ[3]
+
+	digits := uint64(len(evakey.evakey)) / uint64(len(contextQ.Modulus))
+
+	// We switch the element on which the switching key operation will be conducted out of the NTT domain
+	contextQ.InvNTTLvl(level, cx, c2InvNTT)
+
+	p0.Zero()
+	p1.Zero()
+
+	for i := uint64(0); i < level+1; i++ {
+
+		for j := uint64(0); j < digits; j++ {
+
+			contextQ.DecomposeDigitLvl(level, i, j, evakey.logBase, c2InvNTT, c2)
+			contextQ.NTTLvl(level, c2, c2)
+
+			contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey.evakey[i*digits+j][0], c2, p0)
+			contextQ.MulCoeffsMontgomeryAndAddNoModLvl(level, evakey.evakey[i*digits+j][1], c2, p1)
+
+			if reduce&7 == 7 {
+				contextQ.ReduceLvl(level, p0, p0)
+				contextQ.ReduceLvl(level, p1, p1)
+			}
+
+			reduce++
+		}
+	}
+
+	if (reduce-1)&7 != 7 {
+		contextQ.ReduceLvl(level, p0, p0)
+		contextQ.ReduceLvl(level, p1, p1)
+	}
+}
+
 // decomposeAndSplitNTT decomposes the input polynomial into the target CRT basis.
 func (eval *evaluator) decomposeAndSplitNTT(level, beta uint64, c2NTT, c2InvNTT, c2QiQ, c2QiP *ring.Poly) {
 
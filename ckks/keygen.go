@@ -2,6 +2,7 @@ package ckks
 
 import (
 	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
 	"math"
 )
 
@@ -17,15 +18,23 @@ type KeyGenerator interface {
 	GenSwitchingKey(skInput, skOutput *SecretKey) (newevakey *SwitchingKey)
 	GenRotationKeysPow2(skOutput *SecretKey) (rotKey *RotationKeys)
 	GenRot(rotType Rotation, sk *SecretKey, k uint64, rotKey *RotationKeys)
+	GenBootstrappingKey(btpParams *BootstrappParams, sk *SecretKey) (btpKey *BootstrappingKey)
+	GenRelinKeyGadget(sk *SecretKey, logBase uint64) (evakey *EvaluationKey)
+	GenSwitchingKeyGadget(skInput, skOutput *SecretKey, logBase uint64) (newevakey *SwitchingKey)
+	GenRotationKeysPow2Gadget(skOutput *SecretKey, logBase uint64) (rotKey *RotationKeys)
+	GenRotGadget(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys)
 }
 
 // KeyGenerator is a structure that stores the elements required to create new keys,
 // as well as a small memory pool for intermediate values.
 type keyGenerator struct {
-	params      *Parameters
-	ckksContext *Context
-	ringContext *ring.Context
-	polypool    *ring.Poly
+	params           *Parameters
+	ckksContext      *Context
+	ringContext      *ring.Context
+	gaussianSamplerQ *ring.KYSampler
+	crpGeneratorQ    *ring.CRPGenerator
+	crpGeneratorQP   *ring.CRPGenerator
+	polypool         *ring.Poly
 }
 
 // SecretKey is a structure that stores the SecretKey
@@ -65,8 +74,14 @@ type EvaluationKey struct {
 }
 
 // SwitchingKey is a structure that stores the switching-keys required during the key-switching.
+// It either uses the RNS decomposition with the special primes Pi (logBase = 0), or the RNS decomposition combined with
+// a digit decomposition of each Qi in base 2^logBase, in which case its elements are in basis Qi only.
+// The SwitchingKeys generated by a KeyGenerator store the seed from which their uniform elements were generated, so
+// that they can be compressed (see SwitchingKey.MarshalBinaryCompressed).
 type SwitchingKey struct {
-	evakey [][2]*ring.Poly
+	evakey  [][2]*ring.Poly
+	logBase uint64
+	seed    []byte
 }
 
 // Get returns the switching key backing slice
@@ -74,22 +89,41 @@ func (swk *SwitchingKey) Get() [][2]*ring.Poly {
 	return swk.evakey
 }
 
+// LogBase returns the logarithm of the base of the gadget decomposition of the target SwitchingKey, or 0 if it
+// uses the RNS decomposition with the special primes Pi.
+func (swk *SwitchingKey) LogBase() uint64 {
+	return swk.logBase
+}
+
+// TryNewKeyGenerator is the same as NewKeyGenerator, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewKeyGenerator(params *Parameters) (KeyGenerator, error) {
+
+	if err := checkParameters("NewKeyGenerator", params); err != nil {
+		return nil, err
+	}
+
+	return NewKeyGenerator(params), nil
+}
+
 // NewKeyGenerator creates a new KeyGenerator, from which the secret and public keys, as well as the evaluation,
 // rotation and switching keys can be generated.
 func NewKeyGenerator(params *Parameters) KeyGenerator {
 
-	if !params.isValid {
-		panic("cannot NewKeyGenerator: parameters are invalid (check if the generation was done properly)")
+	if err := checkParameters("NewKeyGenerator", params); err != nil {
+		panic(err)
 	}
 
 	ckksContext := newContext(params)
 	ringContext := ckksContext.contextQP
 
 	return &keyGenerator{
-		params:      params.Copy(),
-		ckksContext: ckksContext,
-		ringContext: ringContext,
-		polypool:    ringContext.NewPoly(),
+		params:           params.Copy(),
+		ckksContext:      ckksContext,
+		ringContext:      ringContext,
+		gaussianSamplerQ: ckksContext.contextQ.NewKYSampler(params.Sigma, int(6*params.Sigma)),
+		crpGeneratorQ:    ring.NewCRPGenerator(nil, ckksContext.contextQ),
+		crpGeneratorQP:   ring.NewCRPGenerator(nil, ringContext),
+		polypool:         ringContext.NewPoly(),
 	}
 }
 
@@ -112,11 +146,21 @@ func (keygen *keyGenerator) GenSecretKeySparse(hw uint64) (sk *SecretKey) {
 	return sk
 }
 
+// TryNewSecretKey is the same as NewSecretKey, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewSecretKey(params *Parameters) (*SecretKey, error) {
+
+	if err := checkParameters("NewSecretKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewSecretKey(params), nil
+}
+
 // NewSecretKey generates a new SecretKey with zero values.
 func NewSecretKey(params *Parameters) *SecretKey {
 
-	if !params.isValid {
-		panic("cannot NewSecretkey: parameters are invalid (check if the generation was done properly)")
+	if err := checkParameters("NewSecretKey", params); err != nil {
+		panic(err)
 	}
 
 	sk := new(SecretKey)
@@ -150,11 +194,21 @@ func (keygen *keyGenerator) GenPublicKey(sk *SecretKey) (pk *PublicKey) {
 	return pk
 }
 
+// TryNewPublicKey is the same as NewPublicKey, but returns an Error instead of panicking if the parameters are invalid.
+func TryNewPublicKey(params *Parameters) (*PublicKey, error) {
+
+	if err := checkParameters("NewPublicKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewPublicKey(params), nil
+}
+
 // NewPublicKey returns a new PublicKey with zero values.
 func NewPublicKey(params *Parameters) (pk *PublicKey) {
 
-	if !params.isValid {
-		panic("cannot NewPublicKey: parameters are invalid (check if the generation was done properly)")
+	if err := checkParameters("NewPublicKey", params); err != nil {
+		panic(err)
 	}
 
 	pk = new(PublicKey)
@@ -204,11 +258,22 @@ func (keygen *keyGenerator) GenRelinKey(sk *SecretKey) (evakey *EvaluationKey) {
 	return
 }
 
+// TryNewRelinKey is the same as NewRelinKey, but returns an Error instead of panicking if the parameters are invalid or
+// do not have special primes Pi.
+func TryNewRelinKey(params *Parameters) (*EvaluationKey, error) {
+
+	if err := checkSpecialPrimes("NewRelinKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewRelinKey(params), nil
+}
+
 // NewRelinKey returns a new EvaluationKey with zero values.
 func NewRelinKey(params *Parameters) (evakey *EvaluationKey) {
 
-	if !params.isValid {
-		panic("cannot NewRelinKey: parameters are invalid (check if the generation was done properly)")
+	if err := checkSpecialPrimes("NewRelinKey", params); err != nil {
+		panic(err)
 	}
 
 	evakey = new(EvaluationKey)
@@ -227,6 +292,31 @@ func NewRelinKey(params *Parameters) (evakey *EvaluationKey) {
 	return
 }
 
+// GenRelinKeyGadget is the same as GenRelinKey, but generates an EvaluationKey using the gadget decomposition of
+// base 2^logBase (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRelinKeyGadget(sk *SecretKey, logBase uint64) (evakey *EvaluationKey) {
+
+	evakey = new(EvaluationKey)
+	keygen.ckksContext.contextQ.MulCoeffsMontgomery(sk.Get(), sk.Get(), keygen.polypool)
+	evakey.evakey = keygen.newSwitchingKeyGadget(keygen.polypool, sk.Get(), logBase)
+	keygen.polypool.Zero()
+
+	return
+}
+
+// NewRelinKeyGadget returns a new EvaluationKey with zero values, using the gadget decomposition of base 2^logBase.
+func NewRelinKeyGadget(params *Parameters, logBase uint64) (evakey *EvaluationKey) {
+
+	if !params.isValid {
+		panic("cannot NewRelinKeyGadget: parameters are invalid (check if the generation was done properly)")
+	}
+
+	evakey = new(EvaluationKey)
+	evakey.evakey = NewSwitchingKeyGadget(params, logBase)
+
+	return
+}
+
 // Get returns the slice of switching keys of the evaluation-key.
 func (evk *EvaluationKey) Get() *SwitchingKey {
 	return evk.evakey
@@ -234,13 +324,27 @@ func (evk *EvaluationKey) Get() *SwitchingKey {
 
 // Set sets the target Evaluation key with the input polynomials.
 func (evk *EvaluationKey) Set(rlk [][2]*ring.Poly) {
+	evk.SetGadget(rlk, 0)
+}
+
+// SetGadget sets the target Evaluation key with the input polynomials, which are the elements of a gadget decomposition
+// of base 2^logBase (see GenRelinKeyGadget).
+func (evk *EvaluationKey) SetGadget(rlk [][2]*ring.Poly, logBase uint64) {
+	evk.evakey = newSwitchingKeyFromPolys(rlk, logBase)
+}
+
+// newSwitchingKeyFromPolys creates a new SwitchingKey storing a copy of the input polynomials.
+func newSwitchingKeyFromPolys(evakey [][2]*ring.Poly, logBase uint64) (swk *SwitchingKey) {
 
-	evk.evakey = new(SwitchingKey)
-	evk.evakey.evakey = make([][2]*ring.Poly, len(rlk))
-	for j := range rlk {
-		evk.evakey.evakey[j][0] = rlk[j][0].CopyNew()
-		evk.evakey.evakey[j][1] = rlk[j][1].CopyNew()
+	swk = new(SwitchingKey)
+	swk.logBase = logBase
+	swk.evakey = make([][2]*ring.Poly, len(evakey))
+	for j := range evakey {
+		swk.evakey[j][0] = evakey[j][0].CopyNew()
+		swk.evakey[j][1] = evakey[j][1].CopyNew()
 	}
+
+	return
 }
 
 // GenSwitchingKey generates a new key-switching key, that will re-encrypt a Ciphertext encrypted under the input key into the output key.
@@ -257,11 +361,22 @@ func (keygen *keyGenerator) GenSwitchingKey(skInput, skOutput *SecretKey) (newev
 	return
 }
 
+// TryNewSwitchingKey is the same as NewSwitchingKey, but returns an Error instead of panicking if the parameters are invalid or
+// do not have special primes Pi.
+func TryNewSwitchingKey(params *Parameters) (*SwitchingKey, error) {
+
+	if err := checkSpecialPrimes("NewSwitchingKey", params); err != nil {
+		return nil, err
+	}
+
+	return NewSwitchingKey(params), nil
+}
+
 // NewSwitchingKey returns a new SwitchingKey with zero values.
 func NewSwitchingKey(params *Parameters) (evakey *SwitchingKey) {
 
-	if !params.isValid {
-		panic("cannot NewSwitchingKey: parameters are invalid (check if the generation was done properly)")
+	if err := checkSpecialPrimes("NewSwitchingKey", params); err != nil {
+		panic(err)
 	}
 
 	evakey = new(SwitchingKey)
@@ -279,6 +394,80 @@ func NewSwitchingKey(params *Parameters) (evakey *SwitchingKey) {
 	return
 }
 
+// GenSwitchingKeyGadget generates a new key-switching key, that will re-encrypt a Ciphertext encrypted under the input key into
+// the output key, using the RNS decomposition combined with a digit decomposition of each Qi in base 2^logBase instead of the
+// special primes Pi. The key has GadgetDigits(logBase) elements per Qi, and the noise added by the key-switching grows with 2^logBase.
+func (keygen *keyGenerator) GenSwitchingKeyGadget(skInput, skOutput *SecretKey, logBase uint64) (newevakey *SwitchingKey) {
+	return keygen.newSwitchingKeyGadget(skInput.Get(), skOutput.Get(), logBase)
+}
+
+// NewSwitchingKeyGadget returns a new SwitchingKey with zero values, using the gadget decomposition of base 2^logBase.
+func NewSwitchingKeyGadget(params *Parameters, logBase uint64) (evakey *SwitchingKey) {
+
+	if !params.isValid {
+		panic("cannot NewSwitchingKeyGadget: parameters are invalid (check if the generation was done properly)")
+	}
+
+	evakey = new(SwitchingKey)
+	evakey.logBase = logBase
+	evakey.evakey = make([][2]*ring.Poly, uint64(len(params.Qi))*params.GadgetDigits(logBase))
+
+	for i := range evakey.evakey {
+		evakey.evakey[i][0] = ring.NewPoly(1<<params.LogN, uint64(len(params.Qi)))
+		evakey.evakey[i][1] = ring.NewPoly(1<<params.LogN, uint64(len(params.Qi)))
+	}
+
+	return
+}
+
+// newSwitchingKeyGadget generates the elements [-a*skOut + skIn * (qiBarre*qiStar) * 2^(logBase*j) + e, a] in basis Q,
+// for each Qi and each digit j.
+func (keygen *keyGenerator) newSwitchingKeyGadget(skIn, skOut *ring.Poly, logBase uint64) (switchingkey *SwitchingKey) {
+
+	context := keygen.ckksContext.contextQ
+
+	digits := keygen.params.GadgetDigits(logBase)
+
+	switchingkey = new(SwitchingKey)
+	switchingkey.logBase = logBase
+	switchingkey.evakey = make([][2]*ring.Poly, uint64(len(context.Modulus))*digits)
+	switchingkey.seed = utils.NewSeed()
+
+	keygen.crpGeneratorQ.Seed(switchingkey.seed)
+
+	for i, qi := range context.Modulus {
+
+		bredParams := context.GetBredParams()[i]
+
+		for j := uint64(0); j < digits; j++ {
+
+			index := uint64(i)*digits + j
+
+			// e
+			switchingkey.evakey[index][0] = keygen.gaussianSamplerQ.SampleNTTNew()
+			context.MForm(switchingkey.evakey[index][0], switchingkey.evakey[index][0])
+
+			// a (since a is uniform, we consider we already sample it in the NTT and Montgomery domain)
+			switchingkey.evakey[index][1] = keygen.crpGeneratorQ.ClockNew()
+
+			// e + skIn * (qiBarre*qiStar) * 2^(logBase*j)
+			// (qiBarre*qiStar)%qi = 1, else 0
+			pow2 := ring.ModExp(2, logBase*j, qi)
+			p0tmp := skIn.Coeffs[i]
+			p1tmp := switchingkey.evakey[index][0].Coeffs[i]
+
+			for w := uint64(0); w < context.N; w++ {
+				p1tmp[w] = ring.CRed(p1tmp[w]+ring.BRed(p0tmp[w], pow2, qi, bredParams), qi)
+			}
+
+			// skIn * (qiBarre*qiStar) * 2^(logBase*j) - a * skOut + e
+			context.MulCoeffsMontgomeryAndSub(switchingkey.evakey[index][1], skOut, switchingkey.evakey[index][0])
+		}
+	}
+
+	return
+}
+
 func (keygen *keyGenerator) newSwitchingKey(skIn, skOut *ring.Poly) (switchingkey *SwitchingKey) {
 
 	switchingkey = new(SwitchingKey)
@@ -295,6 +484,9 @@ func (keygen *keyGenerator) newSwitchingKey(skIn, skOut *ring.Poly) (switchingke
 	var index uint64
 
 	switchingkey.evakey = make([][2]*ring.Poly, beta)
+	switchingkey.seed = utils.NewSeed()
+
+	keygen.crpGeneratorQP.Seed(switchingkey.seed)
 
 	for i := uint64(0); i < beta; i++ {
 
@@ -303,7 +495,7 @@ func (keygen *keyGenerator) newSwitchingKey(skIn, skOut *ring.Poly) (switchingke
 		context.MForm(switchingkey.evakey[i][0], switchingkey.evakey[i][0])
 
 		// a (since a is uniform, we consider we already sample it in the NTT and Montgomery domain)
-		switchingkey.evakey[i][1] = keygen.ringContext.NewUniformPoly()
+		switchingkey.evakey[i][1] = keygen.crpGeneratorQP.ClockNew()
 
 		// e + (skIn * P) * (q_star * q_tild) mod QP
 		//
@@ -350,6 +542,17 @@ func (keygen *keyGenerator) GenRot(rotType Rotation, sk *SecretKey, k uint64, ro
 		panic("Cannot GenRot: modulus P is empty")
 	}
 
+	keygen.genRot(rotType, sk, k, 0, rotKey)
+}
+
+// GenRotGadget is the same as GenRot, but generates the SwitchingKey using the gadget decomposition of base 2^logBase
+// (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRotGadget(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys) {
+	keygen.genRot(rotType, sk, k, logBase, rotKey)
+}
+
+func (keygen *keyGenerator) genRot(rotType Rotation, sk *SecretKey, k, logBase uint64, rotKey *RotationKeys) {
+
 	switch rotType {
 	case RotationLeft:
 
@@ -363,7 +566,7 @@ func (keygen *keyGenerator) GenRot(rotType Rotation, sk *SecretKey, k uint64, ro
 
 		if rotKey.evakeyRotColLeft[k] == nil && k != 0 {
 			rotKey.permuteNTTLeftIndex[k] = ring.PermuteNTTIndex(GaloisGen, k, keygen.ringContext.N)
-			rotKey.evakeyRotColLeft[k] = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElRotColLeft[k])
+			rotKey.evakeyRotColLeft[k] = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElRotColLeft[k], logBase)
 		}
 
 	case RotationRight:
@@ -378,12 +581,12 @@ func (keygen *keyGenerator) GenRot(rotType Rotation, sk *SecretKey, k uint64, ro
 
 		if rotKey.evakeyRotColRight[k] == nil && k != 0 {
 			rotKey.permuteNTTRightIndex[k] = ring.PermuteNTTIndex(GaloisGen, 2*keygen.ringContext.N-k, keygen.ringContext.N)
-			rotKey.evakeyRotColRight[k] = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElRotColRight[k])
+			rotKey.evakeyRotColRight[k] = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElRotColRight[k], logBase)
 		}
 
 	case Conjugate:
 		rotKey.permuteNTTConjugateIndex = ring.PermuteNTTIndex(2*keygen.ringContext.N-1, 1, keygen.ringContext.N)
-		rotKey.evakeyConjugate = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElConjugate)
+		rotKey.evakeyConjugate = keygen.genrotKey(sk.Get(), keygen.ckksContext.galElConjugate, logBase)
 	}
 }
 
@@ -394,6 +597,17 @@ func (keygen *keyGenerator) GenRotationKeysPow2(skOutput *SecretKey) (rotKey *Ro
 		panic("Cannot GenRotationKeysPow2: modulus P is empty")
 	}
 
+	return keygen.genRotationKeysPow2(skOutput, 0)
+}
+
+// GenRotationKeysPow2Gadget is the same as GenRotationKeysPow2, but generates the SwitchingKeys using the gadget decomposition
+// of base 2^logBase (see GenSwitchingKeyGadget), which does not require the special primes Pi.
+func (keygen *keyGenerator) GenRotationKeysPow2Gadget(skOutput *SecretKey, logBase uint64) (rotKey *RotationKeys) {
+	return keygen.genRotationKeysPow2(skOutput, logBase)
+}
+
+func (keygen *keyGenerator) genRotationKeysPow2(skOutput *SecretKey, logBase uint64) (rotKey *RotationKeys) {
+
 	rotKey = new(RotationKeys)
 
 	rotKey.evakeyRotColLeft = make(map[uint64]*SwitchingKey)
@@ -407,12 +621,12 @@ func (keygen *keyGenerator) GenRotationKeysPow2(skOutput *SecretKey) (rotKey *Ro
 		rotKey.permuteNTTLeftIndex[n] = ring.PermuteNTTIndex(GaloisGen, n, keygen.ringContext.N)
 		rotKey.permuteNTTRightIndex[n] = ring.PermuteNTTIndex(GaloisGen, 2*keygen.ringContext.N-n, keygen.ringContext.N)
 
-		rotKey.evakeyRotColLeft[n] = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElRotColLeft[n])
-		rotKey.evakeyRotColRight[n] = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElRotColRight[n])
+		rotKey.evakeyRotColLeft[n] = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElRotColLeft[n], logBase)
+		rotKey.evakeyRotColRight[n] = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElRotColRight[n], logBase)
 	}
 
 	rotKey.permuteNTTConjugateIndex = ring.PermuteNTTIndex(2*keygen.ringContext.N-1, 1, keygen.ringContext.N)
-	rotKey.evakeyConjugate = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElConjugate)
+	rotKey.evakeyConjugate = keygen.genrotKey(skOutput.Get(), keygen.ckksContext.galElConjugate, logBase)
 	return
 }
 
@@ -423,6 +637,17 @@ func (rotKey *RotationKeys) SetRotKey(params *Parameters, evakey [][2]*ring.Poly
 		panic("cannot SetRotKey: parameters are invalid (check if the generation was done properly)")
 	}
 
+	rotKey.SetRotKeyGadget(params, evakey, rotType, k, 0)
+}
+
+// SetRotKeyGadget sets the target RotationKeys' SwitchingKey for the specified rotation type and amount with the input
+// polynomials, which are the elements of a gadget decomposition of base 2^logBase (see GenRotGadget).
+func (rotKey *RotationKeys) SetRotKeyGadget(params *Parameters, evakey [][2]*ring.Poly, rotType Rotation, k, logBase uint64) {
+
+	if !params.isValid {
+		panic("cannot SetRotKeyGadget: parameters are invalid (check if the generation was done properly)")
+	}
+
 	switch rotType {
 	case RotationLeft:
 
@@ -438,12 +663,7 @@ func (rotKey *RotationKeys) SetRotKey(params *Parameters, evakey [][2]*ring.Poly
 
 			rotKey.permuteNTTLeftIndex[k] = ring.PermuteNTTIndex(GaloisGen, k, 1<<params.LogN)
 
-			rotKey.evakeyRotColLeft[k] = new(SwitchingKey)
-			rotKey.evakeyRotColLeft[k].evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyRotColLeft[k].evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyRotColLeft[k].evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyRotColLeft[k] = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 
 	case RotationRight:
@@ -460,12 +680,7 @@ func (rotKey *RotationKeys) SetRotKey(params *Parameters, evakey [][2]*ring.Poly
 
 			rotKey.permuteNTTRightIndex[k] = ring.PermuteNTTIndex(GaloisGen, (2<<params.LogN)-1-k, 1<<params.LogN)
 
-			rotKey.evakeyRotColRight[k] = new(SwitchingKey)
-			rotKey.evakeyRotColRight[k].evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyRotColRight[k].evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyRotColRight[k].evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyRotColRight[k] = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 
 	case Conjugate:
@@ -474,20 +689,21 @@ func (rotKey *RotationKeys) SetRotKey(params *Parameters, evakey [][2]*ring.Poly
 
 			rotKey.permuteNTTConjugateIndex = ring.PermuteNTTIndex((2<<params.LogN)-1, 1, 1<<params.LogN)
 
-			rotKey.evakeyConjugate = new(SwitchingKey)
-			rotKey.evakeyConjugate.evakey = make([][2]*ring.Poly, len(evakey))
-			for j := range evakey {
-				rotKey.evakeyConjugate.evakey[j][0] = evakey[j][0].CopyNew()
-				rotKey.evakeyConjugate.evakey[j][1] = evakey[j][1].CopyNew()
-			}
+			rotKey.evakeyConjugate = newSwitchingKeyFromPolys(evakey, logBase)
 		}
 	}
 }
 
-func (keygen *keyGenerator) genrotKey(skOutput *ring.Poly, gen uint64) (switchingkey *SwitchingKey) {
+func (keygen *keyGenerator) genrotKey(skOutput *ring.Poly, gen, logBase uint64) (switchingkey *SwitchingKey) {
 
 	ring.PermuteNTT(skOutput, gen, keygen.polypool)
-	switchingkey = keygen.newSwitchingKey(keygen.polypool, skOutput)
+
+	if logBase != 0 {
+		switchingkey = keygen.newSwitchingKeyGadget(keygen.polypool, skOutput, logBase)
+	} else {
+		switchingkey = keygen.newSwitchingKey(keygen.polypool, skOutput)
+	}
+
 	keygen.polypool.Zero()
 
 	return
@@ -0,0 +1,276 @@
+package ckks
+
+import (
+	"github.com/ldsec/lattigo/utils"
+	"math/bits"
+)
+
+// LinearTransform is a struct storing a plaintext matrix given by its non-zero diagonals, pre-encoded on Plaintexts,
+// which can be homomorphically multiplied with an encrypted vector. The diagonal of index k is the vector
+// (M[0][k], M[1][k+1], ..., M[slots-1][k+slots-1]), where the indexes are taken modulo the number of slots.
+type LinearTransform struct {
+	logSlots uint64
+	n1       uint64 // Size of the baby-step (0 if the baby-step giant-step algorithm is not used)
+	level    uint64
+	scale    float64
+	vec      map[uint64]*Plaintext
+}
+
+// TryNewLinearTransform is the same as NewLinearTransform, but returns an Error instead of panicking if the parameters,
+// the level or the diagonals are invalid.
+func TryNewLinearTransform(params *Parameters, encoder Encoder, diagMatrix map[uint64][]complex128, level uint64, scale float64) (*LinearTransform, error) {
+
+	if _, err := checkDiagMatrix("NewLinearTransform", params, diagMatrix, level); err != nil {
+		return nil, err
+	}
+
+	return NewLinearTransform(params, encoder, diagMatrix, level, scale), nil
+}
+
+// NewLinearTransform encodes the non-zero diagonals of a matrix, indexed by their rotation to the left, on Plaintexts
+// at the given level and scale. The resulting LinearTransform is evaluated with one hoisted rotation per diagonal.
+// All the diagonals must have the same size, which must be a power of two and is the number of slots of the transform.
+func NewLinearTransform(params *Parameters, encoder Encoder, diagMatrix map[uint64][]complex128, level uint64, scale float64) (lt *LinearTransform) {
+
+	slots, err := checkDiagMatrix("NewLinearTransform", params, diagMatrix, level)
+	if err != nil {
+		panic(err)
+	}
+
+	lt = &LinearTransform{
+		logSlots: uint64(bits.Len64(slots) - 1),
+		level:    level,
+		scale:    scale,
+		vec:      make(map[uint64]*Plaintext),
+	}
+
+	for k, diag := range diagMatrix {
+		k &= slots - 1
+		lt.vec[k] = NewPlaintext(params, level, scale)
+		encoder.Encode(lt.vec[k], diag, slots)
+	}
+
+	return
+}
+
+// TryNewLinearTransformBSGS is the same as NewLinearTransformBSGS, but returns an Error instead of panicking if the
+// parameters, the level or the diagonals are invalid.
+func TryNewLinearTransformBSGS(params *Parameters, encoder Encoder, diagMatrix map[uint64][]complex128, level uint64, scale float64) (*LinearTransform, error) {
+
+	if _, err := checkDiagMatrix("NewLinearTransformBSGS", params, diagMatrix, level); err != nil {
+		return nil, err
+	}
+
+	return NewLinearTransformBSGS(params, encoder, diagMatrix, level, scale), nil
+}
+
+// NewLinearTransformBSGS encodes the non-zero diagonals of a matrix, indexed by their rotation to the left, on Plaintexts
+// at the given level and scale. The resulting LinearTransform is evaluated with the baby-step giant-step algorithm,
+// which requires much fewer rotations (and rotation keys) than NewLinearTransform when the matrix has many non-zero diagonals.
+// All the diagonals must have the same size, which must be a power of two and is the number of slots of the transform.
+func NewLinearTransformBSGS(params *Parameters, encoder Encoder, diagMatrix map[uint64][]complex128, level uint64, scale float64) (lt *LinearTransform) {
+
+	slots, err := checkDiagMatrix("NewLinearTransformBSGS", params, diagMatrix, level)
+	if err != nil {
+		panic(err)
+	}
+
+	lt = &LinearTransform{
+		logSlots: uint64(bits.Len64(slots) - 1),
+		level:    level,
+		scale:    scale,
+		vec:      make(map[uint64]*Plaintext),
+	}
+
+	diags := make([]uint64, 0, len(diagMatrix))
+	for k := range diagMatrix {
+		diags = append(diags, k&(slots-1))
+	}
+
+	lt.n1 = utils.FindBestBSGSSplit(diags, slots)
+
+	values := make([]complex128, slots)
+
+	for k, diag := range diagMatrix {
+
+		k &= slots - 1
+
+		// The diagonals are pre-rotated to the right by the giant-step, which can then be factored out
+		giant := k - (k % lt.n1)
+
+		for i := uint64(0); i < slots; i++ {
+			values[i] = diag[(i+slots-giant)&(slots-1)]
+		}
+
+		lt.vec[k] = NewPlaintext(params, level, scale)
+		encoder.Encode(lt.vec[k], values, slots)
+	}
+
+	return
+}
+
+// LogSlots returns the log2 of the number of slots of the LinearTransform.
+func (lt *LinearTransform) LogSlots() uint64 {
+	return lt.logSlots
+}
+
+// Level returns the level of the encoded diagonals of the LinearTransform.
+func (lt *LinearTransform) Level() uint64 {
+	return lt.level
+}
+
+// Scale returns the scale of the encoded diagonals of the LinearTransform.
+func (lt *LinearTransform) Scale() float64 {
+	return lt.scale
+}
+
+// Rotations returns the list of rotations to the left for which a rotation key must be generated
+// (with KeyGenerator.GenRot and RotationLeft) to evaluate the LinearTransform.
+func (lt *LinearTransform) Rotations() (rotations []uint64) {
+
+	if lt.n1 == 0 {
+		return utils.NaiveRotations(lt.diags())
+	}
+
+	return utils.BSGSRotations(lt.diags(), lt.n1)
+}
+
+// diags returns the indexes of the non-zero diagonals of the LinearTransform.
+func (lt *LinearTransform) diags() (diags []uint64) {
+
+	diags = make([]uint64, 0, len(lt.vec))
+	for k := range lt.vec {
+		diags = append(diags, k)
+	}
+
+	return
+}
+
+// LinearTransformNew homomorphically multiplies the plaintext matrix of the LinearTransform with the vector encrypted in ct0,
+// and returns the result in a newly created Ciphertext. The scale of the output is the product of the scales of ct0 and of the
+// LinearTransform, and no rescaling is applied.
+func (eval *evaluator) LinearTransformNew(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 1, utils.MinUint64(ct0.Level(), lt.level), ct0.Scale()*lt.scale)
+	eval.LinearTransform(ct0, lt, rotkeys, ctOut)
+	return
+}
+
+// LinearTransform homomorphically multiplies the plaintext matrix of the LinearTransform with the vector encrypted in ct0,
+// and returns the result in ctOut. The scale of the output is the product of the scales of ct0 and of the LinearTransform,
+// and no rescaling is applied. The rotation keys for all the rotations returned by lt.Rotations() must be provided.
+func (eval *evaluator) LinearTransform(ct0 *Ciphertext, lt *LinearTransform, rotkeys *RotationKeys, ctOut *Ciphertext) {
+
+	rotkeys = eval.getRotationKeys(rotkeys)
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot LinearTransform: input and output Ciphertext must be of degree 1")
+	}
+
+	if ct0 == ctOut {
+		panic("cannot LinearTransform: input and output Ciphertext must be different")
+	}
+
+	level := utils.MinUint64(ct0.Level(), lt.level)
+
+	if ctOut.Level() < level {
+		panic("cannot LinearTransform: output Ciphertext level is too low")
+	}
+
+	ctIn := ct0
+	if ct0.Level() > level {
+		ctIn = eval.DropLevelNew(ct0, ct0.Level()-level)
+	}
+
+	if ctOut.Level() > level {
+		eval.DropLevel(ctOut, ctOut.Level()-level)
+	}
+
+	for i := range ctOut.Value() {
+		ctOut.Value()[i].Zero()
+	}
+	ctOut.seed = nil
+
+	ctOut.SetScale(ctIn.Scale() * lt.scale)
+
+	tmp := NewCiphertext(eval.params, 1, level, ctOut.Scale())
+
+	if lt.n1 == 0 {
+
+		rotations := make([]uint64, 0, len(lt.vec))
+		for k := range lt.vec {
+			rotations = append(rotations, k)
+		}
+
+		ctRot := eval.RotateHoisted(ctIn, rotations, rotkeys)
+
+		for k, pt := range lt.vec {
+			eval.MulRelin(ctRot[k], pt, nil, tmp)
+			eval.Add(ctOut, tmp, ctOut)
+		}
+
+		return
+	}
+
+	// Baby-step giant-step : M*v = sum_{g} rot_{g}(sum_{b} rot_{-g}(diag_{g+b}) * rot_{b}(v))
+	babySteps, giantSteps := utils.BSGSIndex(lt.diags(), lt.n1)
+
+	ctRot := eval.RotateHoisted(ctIn, babySteps, rotkeys)
+
+	inner := NewCiphertext(eval.params, 1, level, ctOut.Scale())
+
+	for _, giant := range giantSteps {
+
+		for i := range inner.Value() {
+			inner.Value()[i].Zero()
+		}
+
+		for _, baby := range babySteps {
+			if pt, ok := lt.vec[giant+baby]; ok {
+				eval.MulRelin(ctRot[baby], pt, nil, tmp)
+				eval.Add(inner, tmp, inner)
+			}
+		}
+
+		if giant != 0 {
+			eval.RotateColumns(inner, giant, rotkeys, tmp)
+			eval.Add(ctOut, tmp, ctOut)
+		} else {
+			eval.Add(ctOut, inner, ctOut)
+		}
+	}
+}
+
+// checkDiagMatrix checks the parameters and the level, and that all the diagonals have the same power of two size,
+// at most N/2, and distinct indexes modulo this size, and returns this size.
+func checkDiagMatrix(op string, params *Parameters, diagMatrix map[uint64][]complex128, level uint64) (slots uint64, err error) {
+
+	if err = checkLevel(op, params, level); err != nil {
+		return 0, err
+	}
+
+	for _, diag := range diagMatrix {
+
+		if slots == 0 {
+			slots = uint64(len(diag))
+		}
+
+		if uint64(len(diag)) != slots {
+			return 0, newError(op, ErrInvalidMatrix, "all the diagonals must have the same size")
+		}
+	}
+
+	if slots == 0 || slots&(slots-1) != 0 || slots > 1<<(params.LogN-1) {
+		return 0, newError(op, ErrInvalidMatrix, "the size of the diagonals must be a power of two between 1 and N/2")
+	}
+
+	// The diagonals are indexed modulo the number of slots, so two distinct keys must not give the same diagonal
+	indexes := make(map[uint64]bool)
+	for k := range diagMatrix {
+		if indexes[k&(slots-1)] {
+			return 0, newError(op, ErrInvalidMatrix, "two diagonals have the same index modulo the number of slots")
+		}
+		indexes[k&(slots-1)] = true
+	}
+
+	return slots, nil
+}
@@ -1,7 +1,9 @@
 package ckks
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"github.com/ldsec/lattigo/ring"
 	"math"
 )
@@ -19,6 +21,106 @@ func (ciphertext *Ciphertext) GetDataLen(WithMetaData bool) (dataLen uint64) {
 	return dataLen
 }
 
+// GetDataLenCompressed returns the length in bytes of the target Ciphertext once marshaled with MarshalBinaryCompressed.
+func (ciphertext *Ciphertext) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	if WithMetaData {
+		dataLen += 10
+	}
+
+	return dataLen + uint64(len(ciphertext.seed)) + ciphertext.value[0].GetDataLen(WithMetaData)
+}
+
+// MarshalBinaryCompressed encodes a fresh Ciphertext encrypted with the secret-key in a byte slice, storing the seed of its
+// uniform element instead of the element itself, which halves its size. Returns an error if the Ciphertext has no seed,
+// which is the case if it was encrypted with the public-key or from a CRP, or if it was modified after its encryption
+// (e.g. as the receiver of an evaluation, or with DropLevel), since the seed is then cleared, or if its uniform element
+// was overwritten through Value() (e.g. as the output of a multiparty protocol).
+func (ciphertext *Ciphertext) MarshalBinaryCompressed() (data []byte, err error) {
+
+	if ciphertext.seed == nil {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the ciphertext has no seed (only the fresh encryptions with the secret-key can be compressed)")
+	}
+
+	if ciphertext.Degree() != 1 || ciphertext.Level() != ciphertext.seedLevel {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the ciphertext must be of degree 1 and at the level of its encryption")
+	}
+
+	if !bytes.Equal(ciphertext.value[1].Digest(ciphertext.seedLevel), ciphertext.seedDigest) {
+		return nil, errors.New("cannot MarshalBinaryCompressed: the uniform element of the ciphertext was modified after its encryption")
+	}
+
+	data = make([]byte, ciphertext.GetDataLenCompressed(true))
+
+	binary.LittleEndian.PutUint64(data[0:8], math.Float64bits(ciphertext.Scale()))
+
+	if ciphertext.isNTT {
+		data[8] = 1
+	}
+
+	data[9] = uint8(len(ciphertext.seed))
+
+	pointer := 10 + uint64(copy(data[10:], ciphertext.seed))
+
+	if _, err = ciphertext.value[0].WriteTo(data[pointer:]); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinaryCompressed decodes a Ciphertext marshaled with MarshalBinaryCompressed in the target Ciphertext,
+// re-generating its uniform element from the seed.
+func (ciphertext *Ciphertext) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	if len(data) < 10 || len(data) < 10+int(data[9]) {
+		return errors.New("cannot UnmarshalBinaryCompressed: data is too short")
+	}
+
+	ciphertext.ckksElement = new(ckksElement)
+
+	ciphertext.value = make([]*ring.Poly, 2)
+
+	ciphertext.scale = math.Float64frombits(binary.LittleEndian.Uint64(data[0:8]))
+
+	if uint8(data[8]) == 1 {
+		ciphertext.isNTT = true
+	}
+
+	pointer := 10 + uint64(data[9])
+
+	ciphertext.seed = make([]byte, data[9])
+	copy(ciphertext.seed, data[10:pointer])
+
+	ciphertext.value[0] = new(ring.Poly)
+
+	if _, err = ciphertext.value[0].DecodePolyNew(data[pointer:]); err != nil {
+		return err
+	}
+
+	var crpGenerator *ring.CRPGenerator
+	if crpGenerator, err = expander.newCRPGenerator(expander.contextQ, ciphertext.seed, ciphertext.value[0]); err != nil {
+		return err
+	}
+
+	ciphertext.seedLevel = uint64(len(ciphertext.value[0].Coeffs) - 1)
+
+	// a is generated in the NTT domain
+	ciphertext.value[1] = crpGenerator.ClockNew()
+
+	if !ciphertext.isNTT {
+		expander.contextQ.InvNTT(ciphertext.value[1], ciphertext.value[1])
+	}
+
+	ciphertext.seedDigest = ciphertext.value[1].Digest(ciphertext.seedLevel)
+
+	return nil
+}
+
 // MarshalBinary encodes a Ciphertext on a byte slice. The total size
 // in byte is 4 + 8* N * numberModuliQ * (degree + 1).
 func (ciphertext *Ciphertext) MarshalBinary() (data []byte, err error) {
@@ -165,6 +267,11 @@ func (evaluationkey *EvaluationKey) GetDataLen(WithMetaData bool) (dataLen uint6
 	return evaluationkey.evakey.GetDataLen(WithMetaData)
 }
 
+// GetDataLenCompressed returns the length in bytes of the target EvaluationKey once marshaled with MarshalBinaryCompressed.
+func (evaluationkey *EvaluationKey) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	return evaluationkey.evakey.GetDataLenCompressed(WithMetaData)
+}
+
 // MarshalBinary encodes an evaluation key in a byte slice.
 func (evaluationkey *EvaluationKey) MarshalBinary() (data []byte, err error) {
 
@@ -174,32 +281,63 @@ func (evaluationkey *EvaluationKey) MarshalBinary() (data []byte, err error) {
 
 	data = make([]byte, dataLen)
 
-	if _, err = evaluationkey.evakey.encode(pointer, data); err != nil {
+	if _, err = evaluationkey.evakey.encode(pointer, data, false); err != nil {
 		return nil, err
 	}
 
 	return data, nil
 }
 
+// MarshalBinaryCompressed encodes an EvaluationKey generated by a KeyGenerator in a byte slice, storing the seed of the
+// uniform elements of its SwitchingKey instead of the elements themselves (see SwitchingKey.MarshalBinaryCompressed).
+func (evaluationkey *EvaluationKey) MarshalBinaryCompressed() (data []byte, err error) {
+	return evaluationkey.evakey.MarshalBinaryCompressed()
+}
+
 // UnmarshalBinary decodes a previously marshaled evaluation-key in the target evaluation-key.
 func (evaluationkey *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
 	evaluationkey.evakey = new(SwitchingKey)
-	if _, err = evaluationkey.evakey.decode(data); err != nil {
+	if _, err = evaluationkey.evakey.decode(data, nil); err != nil {
 		return err
 	}
 	return nil
 }
 
+// UnmarshalBinaryCompressed decodes an EvaluationKey marshaled with MarshalBinaryCompressed in the target EvaluationKey,
+// re-generating the uniform elements of its SwitchingKey from their seed.
+func (evaluationkey *EvaluationKey) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+	evaluationkey.evakey = new(SwitchingKey)
+	return evaluationkey.evakey.UnmarshalBinaryCompressed(params, data)
+}
+
 // GetDataLen returns the length in bytes of the target SwitchingKey.
 func (switchkey *SwitchingKey) GetDataLen(WithMetaData bool) (dataLen uint64) {
+	return switchkey.getDataLen(WithMetaData, false)
+}
+
+// GetDataLenCompressed returns the length in bytes of the target SwitchingKey once marshaled with MarshalBinaryCompressed.
+func (switchkey *SwitchingKey) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	return switchkey.getDataLen(WithMetaData, true)
+}
+
+func (switchkey *SwitchingKey) getDataLen(WithMetaData, compressed bool) (dataLen uint64) {
 
 	if WithMetaData {
-		dataLen++
+		dataLen += 2
+	}
+
+	if compressed {
+		if WithMetaData {
+			dataLen++
+		}
+		dataLen += uint64(len(switchkey.seed))
 	}
 
 	for j := uint64(0); j < uint64(len(switchkey.evakey)); j++ {
 		dataLen += switchkey.evakey[j][0].GetDataLen(WithMetaData)
-		dataLen += switchkey.evakey[j][1].GetDataLen(WithMetaData)
+		if !compressed {
+			dataLen += switchkey.evakey[j][1].GetDataLen(WithMetaData)
+		}
 	}
 
 	return
@@ -210,7 +348,21 @@ func (switchkey *SwitchingKey) MarshalBinary() (data []byte, err error) {
 
 	data = make([]byte, switchkey.GetDataLen(true))
 
-	if _, err = switchkey.encode(0, data); err != nil {
+	if _, err = switchkey.encode(0, data, false); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MarshalBinaryCompressed encodes a SwitchingKey generated by a KeyGenerator in a byte slice, storing the seed of its
+// uniform elements instead of the elements themselves, which halves its size. Returns an error if the SwitchingKey
+// has no seed, which is the case if it was not generated by a KeyGenerator (e.g. if it was set from polynomials).
+func (switchkey *SwitchingKey) MarshalBinaryCompressed() (data []byte, err error) {
+
+	data = make([]byte, switchkey.GetDataLenCompressed(true))
+
+	if _, err = switchkey.encode(0, data, true); err != nil {
 		return nil, err
 	}
 
@@ -220,22 +372,53 @@ func (switchkey *SwitchingKey) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decode a previously marshaled SwitchingKey in the target SwitchingKey.
 func (switchkey *SwitchingKey) UnmarshalBinary(data []byte) (err error) {
 
-	if _, err = switchkey.decode(data); err != nil {
+	if _, err = switchkey.decode(data, nil); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, error) {
+// UnmarshalBinaryCompressed decodes a SwitchingKey marshaled with MarshalBinaryCompressed in the target SwitchingKey,
+// re-generating its uniform elements from the seed.
+func (switchkey *SwitchingKey) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	if _, err = switchkey.decode(data, expander); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (switchkey *SwitchingKey) encode(pointer uint64, data []byte, compressed bool) (uint64, error) {
 
 	var err error
 
 	var inc uint64
 
+	if len(switchkey.evakey) > 0xFF {
+		return pointer, errors.New("cannot encode SwitchingKey: more than 255 decomposition elements")
+	}
+
+	if compressed && switchkey.seed == nil {
+		return pointer, errors.New("cannot encode SwitchingKey: no seed (only the keys generated by a KeyGenerator can be compressed)")
+	}
+
 	data[pointer] = uint8(len(switchkey.evakey))
+	data[pointer+1] = uint8(switchkey.logBase)
 
-	pointer++
+	pointer += 2
+
+	if compressed {
+		data[pointer] = uint8(len(switchkey.seed))
+		pointer++
+		pointer += uint64(copy(data[pointer:], switchkey.seed))
+	}
 
 	for j := uint64(0); j < uint64(len(switchkey.evakey)); j++ {
 
@@ -245,6 +428,10 @@ func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, erro
 
 		pointer += inc
 
+		if compressed {
+			continue
+		}
+
 		if inc, err = switchkey.evakey[j][1].WriteTo(data[pointer:]); err != nil {
 			return pointer, err
 		}
@@ -255,11 +442,20 @@ func (switchkey *SwitchingKey) encode(pointer uint64, data []byte) (uint64, erro
 	return pointer, nil
 }
 
-func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
+// decode decodes a SwitchingKey, which is compressed if expander is not nil.
+func (switchkey *SwitchingKey) decode(data []byte, expander *seedExpander) (pointer uint64, err error) {
 
 	decomposition := uint64(data[0])
+	switchkey.logBase = uint64(data[1])
 
-	pointer = uint64(1)
+	pointer = uint64(2)
+
+	if expander != nil {
+		seedLen := uint64(data[pointer])
+		pointer++
+		switchkey.seed = make([]byte, seedLen)
+		pointer += uint64(copy(switchkey.seed, data[pointer:pointer+seedLen]))
+	}
 
 	switchkey.evakey = make([][2]*ring.Poly, decomposition)
 
@@ -273,6 +469,10 @@ func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
 		}
 		pointer += inc
 
+		if expander != nil {
+			continue
+		}
+
 		switchkey.evakey[j][1] = new(ring.Poly)
 		if inc, err = switchkey.evakey[j][1].DecodePolyNew(data[pointer:]); err != nil {
 			return pointer, err
@@ -281,30 +481,45 @@ func (switchkey *SwitchingKey) decode(data []byte) (pointer uint64, err error) {
 
 	}
 
+	if expander != nil {
+		if err = expander.expandSwitchingKey(switchkey); err != nil {
+			return pointer, err
+		}
+	}
+
 	return pointer, nil
 }
 
 // GetDataLen returns the length in bytes of the target RotationKeys.
 func (rotationkey *RotationKeys) GetDataLen(WithMetaData bool) (dataLen uint64) {
+	return rotationkey.getDataLen(WithMetaData, false)
+}
+
+// GetDataLenCompressed returns the length in bytes of the target RotationKeys once marshaled with MarshalBinaryCompressed.
+func (rotationkey *RotationKeys) GetDataLenCompressed(WithMetaData bool) (dataLen uint64) {
+	return rotationkey.getDataLen(WithMetaData, true)
+}
+
+func (rotationkey *RotationKeys) getDataLen(WithMetaData, compressed bool) (dataLen uint64) {
 	for i := range rotationkey.evakeyRotColLeft {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyRotColLeft[i].GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyRotColLeft[i].getDataLen(WithMetaData, compressed)
 	}
 
 	for i := range rotationkey.evakeyRotColRight {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyRotColRight[i].GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyRotColRight[i].getDataLen(WithMetaData, compressed)
 	}
 
 	if rotationkey.evakeyConjugate != nil {
 		if WithMetaData {
 			dataLen += 4
 		}
-		dataLen += rotationkey.evakeyConjugate.GetDataLen(WithMetaData)
+		dataLen += rotationkey.evakeyConjugate.getDataLen(WithMetaData, compressed)
 	}
 
 	return
@@ -312,8 +527,18 @@ func (rotationkey *RotationKeys) GetDataLen(WithMetaData bool) (dataLen uint64)
 
 // MarshalBinary encodes a RotationKeys structure in a byte slice.
 func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
+	return rotationkey.marshal(false)
+}
+
+// MarshalBinaryCompressed encodes RotationKeys generated by a KeyGenerator in a byte slice, storing the seed of the
+// uniform elements of its SwitchingKeys instead of the elements themselves (see SwitchingKey.MarshalBinaryCompressed).
+func (rotationkey *RotationKeys) MarshalBinaryCompressed() (data []byte, err error) {
+	return rotationkey.marshal(true)
+}
 
-	data = make([]byte, rotationkey.GetDataLen(true))
+func (rotationkey *RotationKeys) marshal(compressed bool) (data []byte, err error) {
+
+	data = make([]byte, rotationkey.getDataLen(true, compressed))
 
 	mappingColL := []uint64{}
 	mappingColR := []uint64{}
@@ -334,7 +559,9 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(RotationLeft)
 		pointer += 4
 
-		pointer, _ = rotationkey.evakeyRotColLeft[i].encode(pointer, data)
+		if pointer, err = rotationkey.evakeyRotColLeft[i].encode(pointer, data, compressed); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, i := range mappingColR {
@@ -343,7 +570,9 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(RotationRight)
 		pointer += 4
 
-		pointer, _ = rotationkey.evakeyRotColRight[i].encode(pointer, data)
+		if pointer, err = rotationkey.evakeyRotColRight[i].encode(pointer, data, compressed); err != nil {
+			return nil, err
+		}
 	}
 
 	if rotationkey.evakeyConjugate != nil {
@@ -351,7 +580,9 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 		data[pointer] = uint8(Conjugate)
 		pointer += 4
 
-		_, _ = rotationkey.evakeyConjugate.encode(pointer, data)
+		if _, err = rotationkey.evakeyConjugate.encode(pointer, data, compressed); err != nil {
+			return nil, err
+		}
 	}
 
 	return data, nil
@@ -359,6 +590,22 @@ func (rotationkey *RotationKeys) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a previously marshaled RotationKeys in the target RotationKeys.
 func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
+	return rotationkey.unmarshal(data, nil)
+}
+
+// UnmarshalBinaryCompressed decodes RotationKeys marshaled with MarshalBinaryCompressed in the target RotationKeys,
+// re-generating the uniform elements of its SwitchingKeys from their seed.
+func (rotationkey *RotationKeys) UnmarshalBinaryCompressed(params *Parameters, data []byte) (err error) {
+
+	var expander *seedExpander
+	if expander, err = newSeedExpander("UnmarshalBinaryCompressed", params); err != nil {
+		return err
+	}
+
+	return rotationkey.unmarshal(data, expander)
+}
+
+func (rotationkey *RotationKeys) unmarshal(data []byte, expander *seedExpander) (err error) {
 
 	var rotationType int
 	var rotationNumber uint64
@@ -386,7 +633,7 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 			}
 
 			rotationkey.evakeyRotColLeft[rotationNumber] = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyRotColLeft[rotationNumber].decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyRotColLeft[rotationNumber].decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
@@ -401,7 +648,7 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 			}
 
 			rotationkey.evakeyRotColRight[rotationNumber] = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyRotColRight[rotationNumber].decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyRotColRight[rotationNumber].decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
@@ -416,7 +663,7 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 		} else if rotationType == Conjugate {
 
 			rotationkey.evakeyConjugate = new(SwitchingKey)
-			if inc, err = rotationkey.evakeyConjugate.decode(data[pointer:]); err != nil {
+			if inc, err = rotationkey.evakeyConjugate.decode(data[pointer:], expander); err != nil {
 				return err
 			}
 
@@ -436,3 +683,75 @@ func (rotationkey *RotationKeys) UnmarshalBinary(data []byte) (err error) {
 
 	return nil
 }
+
+// seedExpander re-generates the uniform elements of the compressed ciphertexts and keys from their seed.
+type seedExpander struct {
+	contextQ  *ring.Context
+	contextQP *ring.Context
+}
+
+func newSeedExpander(op string, params *Parameters) (expander *seedExpander, err error) {
+
+	if err = checkParameters(op, params); err != nil {
+		return nil, err
+	}
+
+	expander = new(seedExpander)
+
+	n := uint64(1 << params.LogN)
+
+	if expander.contextQ, err = ring.NewContextWithParams(n, params.Qi); err != nil {
+		return nil, err
+	}
+
+	if len(params.Pi) != 0 {
+		if expander.contextQP, err = ring.NewContextWithParams(n, append(append([]uint64{}, params.Qi...), params.Pi...)); err != nil {
+			return nil, err
+		}
+	}
+
+	return expander, nil
+}
+
+// newCRPGenerator returns a CRPGenerator over the given context seeded with seed, after checking that p, a decoded
+// polynomial of the object to expand, is in the basis of the context.
+func (expander *seedExpander) newCRPGenerator(context *ring.Context, seed []byte, p *ring.Poly) (crpGenerator *ring.CRPGenerator, err error) {
+
+	if context == nil {
+		return nil, errors.New("cannot expand seed: modulus P is empty")
+	}
+
+	if len(p.Coeffs) != len(context.Modulus) || uint64(len(p.Coeffs[0])) != context.N {
+		return nil, errors.New("cannot expand seed: the polynomials do not match the parameters")
+	}
+
+	crpGenerator = ring.NewCRPGenerator(nil, context)
+	crpGenerator.Seed(seed)
+
+	return crpGenerator, nil
+}
+
+// expandSwitchingKey sets the uniform elements of the SwitchingKey, which are generated from its seed in its basis
+// (QP with the special primes Pi, Q with the gadget decomposition).
+func (expander *seedExpander) expandSwitchingKey(switchkey *SwitchingKey) (err error) {
+
+	if len(switchkey.evakey) == 0 {
+		return nil
+	}
+
+	context := expander.contextQP
+	if switchkey.logBase != 0 {
+		context = expander.contextQ
+	}
+
+	var crpGenerator *ring.CRPGenerator
+	if crpGenerator, err = expander.newCRPGenerator(context, switchkey.seed, switchkey.evakey[0][0]); err != nil {
+		return err
+	}
+
+	for j := range switchkey.evakey {
+		switchkey.evakey[j][1] = crpGenerator.ClockNew()
+	}
+
+	return nil
+}
@@ -17,6 +17,13 @@ type ckksElement struct {
 	value []*ring.Poly
 	scale float64
 	isNTT bool
+
+	// seed from which value[1] of a fresh encryption with the secret-key was generated at level seedLevel, nil
+	// otherwise. It is cleared whenever the element is modified, and seedDigest, the digest of value[1] at the time
+	// of the encryption, detects the modifications made through Value().
+	seed       []byte
+	seedLevel  uint64
+	seedDigest []byte
 }
 
 // newCkksElement returns a new ckksElement with zero values.
@@ -32,6 +39,7 @@ func (el *ckksElement) Value() []*ring.Poly {
 // SetValue sets the input slice of polynomials as the value of the target element.
 func (el *ckksElement) SetValue(value []*ring.Poly) {
 	el.value = value
+	el.seed = nil
 }
 
 // Degree returns the degree of the target element.
@@ -66,6 +74,7 @@ func (el *ckksElement) DivScale(scale float64) {
 
 // Resize resizes the degree of the target element.
 func (el *ckksElement) Resize(params *Parameters, degree uint64) {
+	el.seed = nil
 	if el.Degree() > degree {
 		el.value = el.value[:degree+1]
 	} else if el.Degree() < degree {
@@ -99,6 +108,7 @@ func (el *ckksElement) NTT(context *ring.Context, c *ckksElement) error {
 			context.NTTLvl(el.Level(), el.Value()[i], c.Value()[i])
 		}
 		c.SetIsNTT(true)
+		c.seed = nil
 	}
 	return nil
 }
@@ -113,6 +123,7 @@ func (el *ckksElement) InvNTT(context *ring.Context, c *ckksElement) error {
 			context.InvNTTLvl(el.Level(), el.Value()[i], c.Value()[i])
 		}
 		c.SetIsNTT(false)
+		c.seed = nil
 	}
 	return nil
 }
@@ -141,6 +152,7 @@ func (el *ckksElement) Copy(ctxCopy *ckksElement) (err error) {
 		}
 
 		el.CopyParams(ctxCopy)
+		el.seed = nil
 	}
 	return nil
 }
@@ -158,7 +170,7 @@ func (el *ckksElement) Element() *ckksElement {
 
 // Ciphertext sets the target element type to Ciphertext.
 func (el *ckksElement) Ciphertext() *Ciphertext {
-	return &Ciphertext{el}
+	return &Ciphertext{ckksElement: el}
 }
 
 // Plaintext sets the target element type to Plaintext.
@@ -50,12 +50,12 @@ var DefaultParams = []*Parameters{
 		Scale: 1 << 32,
 		Sigma: 3.2},
 
-	//LogQi = 218
+	//LogQi = 217
 	{LogN: 13,
 		LogSlots: 12,
 		LogModuli: LogModuli{
 			LogQi: []uint64{33, 30, 30, 30, 30, 30},
-			LogPi: []uint64{35},
+			LogPi: []uint64{34},
 		},
 		Scale: 1 << 30,
 		Sigma: 3.2},
@@ -190,11 +190,31 @@ func (p *Parameters) Alpha() uint64 {
 	return p.alpha
 }
 
-// Beta returns ceil(#Qi/#Pi).
+// Beta returns ceil(#Qi/#Pi), or 0 if there are no Pi.
 func (p *Parameters) Beta() uint64 {
 	return p.beta
 }
 
+// GadgetDigits returns the number of digits in base 2^logBase of the decomposition of each modulus Qi, i.e., the number
+// of elements per Qi of the SwitchingKeys generated with a gadget decomposition of base 2^logBase.
+// Panics if logBase is zero or larger than the bit-size of the smallest Qi.
+func (p *Parameters) GadgetDigits(logBase uint64) uint64 {
+
+	var maxBitLen uint64
+	for i, qi := range p.Qi {
+
+		if logBase == 0 || logBase > p.LogQi[i] {
+			panic("cannot GadgetDigits: logBase must be between 1 and the bit-size of the smallest Qi")
+		}
+
+		if bitLen := uint64(bits.Len64(qi)); bitLen > maxBitLen {
+			maxBitLen = bitLen
+		}
+	}
+
+	return (maxBitLen + logBase - 1) / logBase
+}
+
 // LogQP returns the bitlength of prod(Qi) * prod(Pi)
 func (p *Parameters) LogQP() uint64 {
 	return p.logQP
@@ -367,7 +387,10 @@ func (p *Parameters) GenFromModuli() {
 	}
 
 	p.alpha = uint64(len(p.Pi))
-	p.beta = uint64(math.Ceil(float64(len(p.Qi)) / float64(len(p.Pi))))
+	p.beta = 0
+	if len(p.Pi) != 0 {
+		p.beta = uint64(math.Ceil(float64(len(p.Qi)) / float64(len(p.Pi))))
+	}
 
 	p.isValid = true
 }
@@ -1,6 +1,7 @@
 package ckks
 
 import (
+	"github.com/ldsec/lattigo/ring"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -26,3 +27,32 @@ func TestParams_BinaryMarshaller(t *testing.T) {
 		}
 	})
 }
+
+func TestParams_Security(t *testing.T) {
+	t.Run("CheckSecurity", func(t *testing.T) {
+		for _, params := range DefaultParams {
+			if params.LogN < 16 {
+				assert.Nil(t, params.CheckSecurity(128, ring.SecretTernary))
+			} else {
+				assert.NotNil(t, params.CheckSecurity(128, ring.SecretTernary))
+			}
+		}
+		assert.NotNil(t, DefaultParams[PN14QP438].CheckSecurity(256, ring.SecretTernary))
+	})
+	t.Run("GenParameters", func(t *testing.T) {
+		params, err := GenParameters(40, 5, 128)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(14), params.LogN)
+		assert.Equal(t, uint64(5), params.MaxLevel())
+		assert.Equal(t, float64(1<<40), params.Scale)
+		assert.Nil(t, params.CheckSecurity(128, ring.SecretTernary))
+
+		params, err = GenParameters(40, 5, 256)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(15), params.LogN)
+		assert.Nil(t, params.CheckSecurity(256, ring.SecretTernary))
+
+		_, err = GenParameters(60, 30, 128)
+		assert.NotNil(t, err)
+	})
+}
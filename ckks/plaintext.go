@@ -10,11 +10,22 @@ type Plaintext struct {
 	value *ring.Poly
 }
 
+// TryNewPlaintext is the same as NewPlaintext, but returns an Error instead of panicking if the parameters or the
+// level are invalid.
+func TryNewPlaintext(params *Parameters, level uint64, scale float64) (*Plaintext, error) {
+
+	if err := checkLevel("NewPlaintext", params, level); err != nil {
+		return nil, err
+	}
+
+	return NewPlaintext(params, level, scale), nil
+}
+
 // NewPlaintext creates a new Plaintext of level level and scale scale.
 func NewPlaintext(params *Parameters, level uint64, scale float64) *Plaintext {
 
-	if !params.isValid {
-		panic("cannot NewPlaintext: parameters are invalid (check if the generation was done properly)")
+	if err := checkLevel("NewPlaintext", params, level); err != nil {
+		panic(err)
 	}
 
 	plaintext := &Plaintext{&ckksElement{}, nil}
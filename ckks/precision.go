@@ -0,0 +1,159 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PrecisionStats is a struct storing statistics about the precision of decrypted CKKS values.
+// For each complex field, the real part refers to the real part of the values and the imaginary
+// part to their imaginary part. The precisions are given in bits, that is, -log2 of the absolute error.
+type PrecisionStats struct {
+	MinPrecision    complex128
+	MaxPrecision    complex128
+	MeanPrecision   complex128
+	MedianPrecision complex128
+
+	MinDelta    complex128
+	MaxDelta    complex128
+	MeanDelta   complex128
+	MedianDelta complex128
+
+	RealDist []PrecisionBin
+	ImagDist []PrecisionBin
+}
+
+// PrecisionBin is a bin of the error histogram of a PrecisionStats: Count values have a precision
+// of at least Prec bits and of less than Prec+1 bits.
+type PrecisionBin struct {
+	Prec  float64
+	Count uint64
+}
+
+// GetPrecisionStats returns the precision statistics of the decrypted values valuesTest with respect
+// to the expected values valuesWant. Both slices must have the same length.
+func GetPrecisionStats(valuesWant, valuesTest []complex128) (prec *PrecisionStats) {
+
+	if len(valuesWant) != len(valuesTest) {
+		panic("cannot GetPrecisionStats: valuesWant and valuesTest must have the same length")
+	}
+
+	if len(valuesWant) == 0 {
+		panic("cannot GetPrecisionStats: values are empty")
+	}
+
+	prec = new(PrecisionStats)
+
+	deltaReal := make([]float64, len(valuesWant))
+	deltaImag := make([]float64, len(valuesWant))
+
+	distribReal := make(map[float64]uint64)
+	distribImag := make(map[float64]uint64)
+
+	var meanReal, meanImag float64
+
+	for i := range valuesWant {
+
+		deltaReal[i] = math.Abs(real(valuesTest[i]) - real(valuesWant[i]))
+		deltaImag[i] = math.Abs(imag(valuesTest[i]) - imag(valuesWant[i]))
+
+		meanReal += deltaReal[i]
+		meanImag += deltaImag[i]
+
+		distribReal[math.Floor(deltaToPrecision(deltaReal[i]))]++
+		distribImag[math.Floor(deltaToPrecision(deltaImag[i]))]++
+	}
+
+	meanReal /= float64(len(valuesWant))
+	meanImag /= float64(len(valuesWant))
+
+	sort.Float64s(deltaReal)
+	sort.Float64s(deltaImag)
+
+	prec.MinDelta = complex(deltaReal[0], deltaImag[0])
+	prec.MaxDelta = complex(deltaReal[len(deltaReal)-1], deltaImag[len(deltaImag)-1])
+	prec.MeanDelta = complex(meanReal, meanImag)
+	prec.MedianDelta = complex(median(deltaReal), median(deltaImag))
+
+	// The smallest error gives the maximum precision
+	prec.MinPrecision = complex(deltaToPrecision(real(prec.MaxDelta)), deltaToPrecision(imag(prec.MaxDelta)))
+	prec.MaxPrecision = complex(deltaToPrecision(real(prec.MinDelta)), deltaToPrecision(imag(prec.MinDelta)))
+	prec.MeanPrecision = complex(deltaToPrecision(meanReal), deltaToPrecision(meanImag))
+	prec.MedianPrecision = complex(deltaToPrecision(real(prec.MedianDelta)), deltaToPrecision(imag(prec.MedianDelta)))
+
+	prec.RealDist = sortedHistogram(distribReal)
+	prec.ImagDist = sortedHistogram(distribImag)
+
+	return
+}
+
+// String returns a printable summary of the target PrecisionStats.
+func (prec *PrecisionStats) String() string {
+	return fmt.Sprintf("Minimum precision : (%.2f, %.2f) bits\n", real(prec.MinPrecision), imag(prec.MinPrecision)) +
+		fmt.Sprintf("Maximum precision : (%.2f, %.2f) bits\n", real(prec.MaxPrecision), imag(prec.MaxPrecision)) +
+		fmt.Sprintf("Mean    precision : (%.2f, %.2f) bits\n", real(prec.MeanPrecision), imag(prec.MeanPrecision)) +
+		fmt.Sprintf("Median  precision : (%.2f, %.2f) bits\n", real(prec.MedianPrecision), imag(prec.MedianPrecision))
+}
+
+// EstimateNoiseStandardDeviation estimates the standard deviation of the error in the coefficients of a decrypted
+// plaintext of the given scale, from the expected values valuesWant and the values valuesTest decoded from it.
+// It assumes that the error coefficients are independent and identically distributed, in which case the error of
+// each of the n decoded slots has a variance 2*n*sigma^2/scale^2, split equally between its real and imaginary parts.
+func EstimateNoiseStandardDeviation(valuesWant, valuesTest []complex128, scale float64) (sigma float64) {
+
+	if len(valuesWant) != len(valuesTest) {
+		panic("cannot EstimateNoiseStandardDeviation: valuesWant and valuesTest must have the same length")
+	}
+
+	if len(valuesWant) == 0 {
+		panic("cannot EstimateNoiseStandardDeviation: values are empty")
+	}
+
+	slots := float64(len(valuesWant))
+
+	var mean complex128
+	for i := range valuesWant {
+		mean += valuesTest[i] - valuesWant[i]
+	}
+	mean /= complex(slots, 0)
+
+	var variance float64
+	for i := range valuesWant {
+		delta := valuesTest[i] - valuesWant[i] - mean
+		variance += real(delta)*real(delta) + imag(delta)*imag(delta)
+	}
+	variance /= slots
+
+	return scale * math.Sqrt(variance/(2*slots))
+}
+
+// deltaToPrecision returns -log2(delta), which is +Inf if delta is zero.
+func deltaToPrecision(delta float64) float64 {
+	return math.Log2(1 / delta)
+}
+
+// median returns the median of a sorted slice.
+func median(values []float64) float64 {
+
+	index := len(values) / 2
+
+	if len(values)&1 == 1 {
+		return values[index]
+	}
+
+	return (values[index-1] + values[index]) / 2
+}
+
+// sortedHistogram returns the bins of the histogram sorted by increasing precision.
+func sortedHistogram(distrib map[float64]uint64) (bins []PrecisionBin) {
+
+	bins = make([]PrecisionBin, 0, len(distrib))
+	for prec, count := range distrib {
+		bins = append(bins, PrecisionBin{Prec: prec, Count: count})
+	}
+
+	sort.Slice(bins, func(i, j int) bool { return bins[i].Prec < bins[j].Prec })
+
+	return
+}
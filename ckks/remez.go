@@ -0,0 +1,426 @@
+package ckks
+
+import (
+	"math"
+)
+
+// RemezInterval is an interval [A, B] of the domain on which the minimax approximation is computed.
+// Nodes is the number of points sampled on the interval to locate the extrema of the approximation error.
+type RemezInterval struct {
+	A, B  float64
+	Nodes int
+}
+
+// remezRelativeThreshold is the relative difference between the largest and the smallest extrema of the approximation error
+// under which the error is considered to be equioscillating, i.e. the approximation to be minimax.
+const remezRelativeThreshold = 1e-6
+
+// ApproximateRemez computes the minimax polynomial approximation of degree degree of the input function on the union of the given
+// intervals, using the multi-interval Remez exchange algorithm. The intervals must be sorted and disjoint. The approximation is
+// returned as a ChebyshevInterpolation on [intervals[0].A, intervals[len(intervals)-1].B], which can be evaluated with EvaluateChebyFast
+// or EvaluateChebyEco, along with the maximum absolute error of the approximation on the intervals.
+// The algorithm stops when the error equioscillates or after maxIter iterations.
+func ApproximateRemez(function func(float64) float64, intervals []RemezInterval, degree, maxIter int) (cheby *ChebyshevInterpolation, maxErr float64) {
+
+	checkRemezParameters(intervals, degree, maxIter)
+
+	a := intervals[0].A
+	b := intervals[len(intervals)-1].B
+
+	remez := &remezState{
+		function:  function,
+		intervals: intervals,
+		degree:    degree,
+		a:         a,
+		b:         b,
+	}
+
+	nodes := remez.initialNodes()
+
+	var bestCoeffs []float64
+
+	maxErr = math.Inf(1)
+
+	for i := 0; i < maxIter; i++ {
+
+		remez.solve(nodes)
+
+		extrema, ok := remez.findExtrema()
+
+		// The extremum with the largest absolute error is never discarded by findExtrema
+		minAbs, maxAbs := math.Inf(1), 0.0
+		for _, e := range extrema {
+			minAbs = math.Min(minAbs, math.Abs(e.err))
+			maxAbs = math.Max(maxAbs, math.Abs(e.err))
+		}
+
+		// The best approximation is kept, since the iterations can stall once the error reaches the numerical precision
+		if maxAbs < maxErr {
+			maxErr = maxAbs
+			bestCoeffs = remez.coeffs
+		}
+
+		if !ok || maxAbs-minAbs <= remezRelativeThreshold*maxAbs {
+			break
+		}
+
+		for j := range nodes {
+			nodes[j] = extrema[j].x
+		}
+	}
+
+	cheby = new(ChebyshevInterpolation)
+	cheby.coeffs = make(map[uint64]complex128)
+	cheby.a = complex(a, 0)
+	cheby.b = complex(b, 0)
+	cheby.degree = uint64(degree)
+
+	for i, c := range bestCoeffs {
+		cheby.coeffs[uint64(i)] = complex(c, 0)
+	}
+
+	return
+}
+
+func checkRemezParameters(intervals []RemezInterval, degree, maxIter int) {
+
+	if len(intervals) == 0 {
+		panic("cannot ApproximateRemez: at least one interval is required")
+	}
+
+	if degree < 0 {
+		panic("cannot ApproximateRemez: degree must be positive")
+	}
+
+	if maxIter < 1 {
+		panic("cannot ApproximateRemez: maxIter must be at least one")
+	}
+
+	nodes := 0
+
+	for i, interval := range intervals {
+
+		if interval.A >= interval.B {
+			panic("cannot ApproximateRemez: intervals must be of the form [A, B] with A < B")
+		}
+
+		if i > 0 && intervals[i-1].B >= interval.A {
+			panic("cannot ApproximateRemez: intervals must be sorted and disjoint")
+		}
+
+		if interval.Nodes < 2 {
+			panic("cannot ApproximateRemez: each interval must have at least two nodes")
+		}
+
+		nodes += interval.Nodes
+	}
+
+	if nodes < 2*(degree+2) {
+		panic("cannot ApproximateRemez: the total number of nodes must be at least twice the degree + 2")
+	}
+}
+
+// remezState stores the current state of the Remez exchange algorithm.
+type remezState struct {
+	function  func(float64) float64
+	intervals []RemezInterval
+	degree    int
+	a, b      float64
+	coeffs    []float64 // Coefficients of the current approximation in the Chebyshev basis on [a, b]
+}
+
+// remezExtremum is a point of the domain along with the approximation error at this point.
+type remezExtremum struct {
+	x   float64
+	err float64
+}
+
+// initialNodes returns degree+2 reference points, distributed on the intervals proportionally to their measure for the Chebyshev
+// density on [a, b], and placed on each interval at evenly spaced angles. This approximates the distribution of the extrema of the
+// error of the minimax approximation.
+func (remez *remezState) initialNodes() (nodes []float64) {
+
+	n := remez.degree + 2
+
+	// Angles of the endpoints of the intervals, and measure of the intervals for the Chebyshev density
+	angles := make([][2]float64, len(remez.intervals))
+	weights := make([]float64, len(remez.intervals))
+	measure := 0.0
+	for i, interval := range remez.intervals {
+		angles[i][0] = remez.toAngle(interval.A)
+		angles[i][1] = remez.toAngle(interval.B)
+		weights[i] = angles[i][0] - angles[i][1]
+		measure += weights[i]
+	}
+
+	// The measure can vanish numerically if all the intervals are too small with respect to [a, b], in which
+	// case the points are distributed evenly among the intervals
+	if measure <= 0 {
+		for i := range weights {
+			weights[i] = 1
+		}
+		measure = float64(len(weights))
+	}
+
+	counts := make([]int, len(remez.intervals))
+	remainders := make([]float64, len(remez.intervals))
+	sum := 0
+	for i := range remez.intervals {
+		share := float64(n) * weights[i] / measure
+		counts[i] = int(share)
+		remainders[i] = share - float64(counts[i])
+		sum += counts[i]
+	}
+
+	// Distributes the remaining points to the intervals with the largest remainders
+	for ; sum < n; sum++ {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		counts[best]++
+		remainders[best] = -1
+	}
+
+	nodes = make([]float64, 0, n)
+
+	for i := range remez.intervals {
+
+		switch counts[i] {
+		case 0:
+		case 1:
+			nodes = append(nodes, remez.fromAngle(0.5*(angles[i][0]+angles[i][1])))
+		default:
+			for j := 0; j < counts[i]; j++ {
+				nodes = append(nodes, remez.fromAngle(angles[i][0]-(angles[i][0]-angles[i][1])*float64(j)/float64(counts[i]-1)))
+			}
+		}
+	}
+
+	return
+}
+
+// toAngle returns the angle theta in [0, pi] such that the image of x in [-1, 1] is cos(theta).
+func (remez *remezState) toAngle(x float64) float64 {
+	return math.Acos(math.Max(-1, math.Min(1, (2*x-remez.a-remez.b)/(remez.b-remez.a))))
+}
+
+// fromAngle maps the angle theta to the point of [a, b] whose image in [-1, 1] is cos(theta).
+func (remez *remezState) fromAngle(theta float64) float64 {
+	return 0.5*(remez.a+remez.b) + 0.5*(remez.b-remez.a)*math.Cos(theta)
+}
+
+// solve computes the polynomial p of degree degree and the error E such that p(x_i) - f(x_i) = (-1)^i * E on the reference points.
+func (remez *remezState) solve(nodes []float64) {
+
+	n := remez.degree + 2
+
+	matrix := make([][]float64, n)
+	vector := make([]float64, n)
+
+	for i, x := range nodes {
+
+		matrix[i] = make([]float64, n)
+
+		remez.chebyshevBasis(x, matrix[i][:n-1])
+
+		if i&1 == 0 {
+			matrix[i][n-1] = -1
+		} else {
+			matrix[i][n-1] = 1
+		}
+
+		vector[i] = remez.function(x)
+	}
+
+	remez.coeffs = solveLinearSystem(matrix, vector)[:n-1]
+}
+
+// chebyshevBasis evaluates the Chebyshev polynomials T_0, ..., T_{len(basis)-1} on [a, b] at x.
+func (remez *remezState) chebyshevBasis(x float64, basis []float64) {
+
+	u := (2*x - remez.a - remez.b) / (remez.b - remez.a)
+
+	for i := range basis {
+		switch i {
+		case 0:
+			basis[i] = 1
+		case 1:
+			basis[i] = u
+		default:
+			basis[i] = 2*u*basis[i-1] - basis[i-2]
+		}
+	}
+}
+
+// eval returns the error p(x) - f(x) of the current approximation at x.
+func (remez *remezState) eval(x float64) float64 {
+
+	u := (2*x - remez.a - remez.b) / (remez.b - remez.a)
+
+	var y, Tprev, T, Tnext float64
+
+	Tprev, T = 1, u
+	y = remez.coeffs[0]
+	for i := 1; i < len(remez.coeffs); i++ {
+		y += T * remez.coeffs[i]
+		Tnext = 2*u*T - Tprev
+		Tprev, T = T, Tnext
+	}
+
+	return y - remez.function(x)
+}
+
+// findExtrema samples the error of the current approximation on the intervals and returns degree+2 of its local extrema
+// with alternating signs, ordered increasingly. It returns false if the error does not alternate on enough points.
+func (remez *remezState) findExtrema() (extrema []remezExtremum, ok bool) {
+
+	extrema = []remezExtremum{}
+
+	var current remezExtremum
+	var currentSign float64
+
+	for _, interval := range remez.intervals {
+
+		step := (interval.B - interval.A) / float64(interval.Nodes-1)
+
+		for j := 0; j < interval.Nodes; j++ {
+
+			x := interval.A + float64(j)*step
+			if j == interval.Nodes-1 {
+				x = interval.B
+			}
+
+			e := remez.eval(x)
+
+			// Refines the location of the extremum if it is strictly inside the interval
+			if j > 0 && j < interval.Nodes-1 && math.Abs(e) >= math.Abs(remez.eval(x-step)) && math.Abs(e) >= math.Abs(remez.eval(x+step)) {
+				x = remez.refineExtremum(x-step, x+step, math.Copysign(1, e))
+				e = remez.eval(x)
+			}
+
+			sign := math.Copysign(1, e)
+
+			if len(extrema) == 0 && currentSign == 0 {
+				current, currentSign = remezExtremum{x, e}, sign
+				continue
+			}
+
+			if sign == currentSign {
+				if math.Abs(e) > math.Abs(current.err) {
+					current = remezExtremum{x, e}
+				}
+			} else {
+				extrema = append(extrema, current)
+				current, currentSign = remezExtremum{x, e}, sign
+			}
+		}
+	}
+
+	extrema = append(extrema, current)
+
+	n := remez.degree + 2
+
+	// Removes extrema while preserving the alternation of the signs
+	for len(extrema) > n {
+
+		if len(extrema) == n+1 {
+			if math.Abs(extrema[0].err) < math.Abs(extrema[len(extrema)-1].err) {
+				extrema = extrema[1:]
+			} else {
+				extrema = extrema[:len(extrema)-1]
+			}
+			continue
+		}
+
+		idx := 0
+		for i := range extrema {
+			if math.Abs(extrema[i].err) < math.Abs(extrema[idx].err) {
+				idx = i
+			}
+		}
+
+		switch {
+		case idx == 0:
+			extrema = extrema[1:]
+		case idx == len(extrema)-1:
+			extrema = extrema[:len(extrema)-1]
+		default:
+			// The two neighbours have the same sign, so the smallest of them is removed along with the extremum
+			if math.Abs(extrema[idx-1].err) < math.Abs(extrema[idx+1].err) {
+				extrema = append(extrema[:idx-1], extrema[idx+1:]...)
+			} else {
+				extrema = append(extrema[:idx], extrema[idx+2:]...)
+			}
+		}
+	}
+
+	return extrema, len(extrema) == n
+}
+
+// refineExtremum locates the maximum of sign * (p(x) - f(x)) on [l, r] with a golden-section search.
+func (remez *remezState) refineExtremum(l, r, sign float64) float64 {
+
+	invPhi := (math.Sqrt(5) - 1) / 2
+
+	x1 := r - invPhi*(r-l)
+	x2 := l + invPhi*(r-l)
+	f1 := sign * remez.eval(x1)
+	f2 := sign * remez.eval(x2)
+
+	for i := 0; i < 64 && r-l > 1e-15*math.Max(1, math.Abs(l)); i++ {
+		if f1 < f2 {
+			l, x1, f1 = x1, x2, f2
+			x2 = l + invPhi*(r-l)
+			f2 = sign * remez.eval(x2)
+		} else {
+			r, x2, f2 = x2, x1, f1
+			x1 = r - invPhi*(r-l)
+			f1 = sign * remez.eval(x1)
+		}
+	}
+
+	return 0.5 * (l + r)
+}
+
+// solveLinearSystem solves the square linear system matrix * x = vector with Gaussian elimination and partial pivoting.
+// The inputs are modified in place.
+func solveLinearSystem(matrix [][]float64, vector []float64) (x []float64) {
+
+	n := len(vector)
+
+	for col := 0; col < n; col++ {
+
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(matrix[row][col]) > math.Abs(matrix[pivot][col]) {
+				pivot = row
+			}
+		}
+
+		matrix[col], matrix[pivot] = matrix[pivot], matrix[col]
+		vector[col], vector[pivot] = vector[pivot], vector[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := matrix[row][col] / matrix[col][col]
+			for k := col; k < n; k++ {
+				matrix[row][k] -= factor * matrix[col][k]
+			}
+			vector[row] -= factor * vector[col]
+		}
+	}
+
+	x = make([]float64, n)
+
+	for row := n - 1; row >= 0; row-- {
+		x[row] = vector[row]
+		for k := row + 1; k < n; k++ {
+			x[row] -= matrix[row][k] * x[k]
+		}
+		x[row] /= matrix[row][row]
+	}
+
+	return
+}
@@ -0,0 +1,79 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ldsec/lattigo/ring"
+)
+
+// CheckSecurity returns an error if the parameters do not ensure the given classical security level (128, 192 or 256 bits)
+// for a secret-key of the given distribution, that is, if log2(QP) is larger than the bit-size allowed by the Homomorphic
+// Encryption Security Standard for the ring degree 2^LogN.
+func (p *Parameters) CheckSecurity(securityLevel uint64, secret ring.SecretDistribution) error {
+
+	if !p.isValid {
+		return fmt.Errorf("parameters not generated or invalid")
+	}
+
+	return ring.CheckSecurity(p.LogN, ring.Log2Moduli(p.Qi, p.Pi), securityLevel, secret)
+}
+
+// GenParameters returns the parameters with the smallest LogN enabling the evaluation of a circuit of the given
+// multiplicative depth with the scale 2^logScale, while ensuring the given classical security level (128, 192 or 256 bits)
+// for the ternary secret-keys of the KeyGenerator. The modulus chain is made of a first modulus of logScale+10 bits
+// (at most 60), leaving room for the integer part of the messages, followed by depth moduli of logScale bits,
+// and a single special modulus as large as the first one. The number of slots is set to N/2.
+// Returns an error if no parameters with LogN <= 15 allow the depth.
+func GenParameters(logScale, depth, securityLevel uint64) (params *Parameters, err error) {
+
+	if logScale > MaxModuliSize {
+		return nil, fmt.Errorf("cannot GenParameters: logScale is larger than %d", MaxModuliSize)
+	}
+
+	if depth+1 > MaxModuliCount {
+		return nil, fmt.Errorf("cannot GenParameters: depth is larger than %d", MaxModuliCount-1)
+	}
+
+	logQ0 := logScale + 10
+	if logQ0 > MaxModuliSize {
+		logQ0 = MaxModuliSize
+	}
+
+	logModuli := LogModuli{
+		LogQi: make([]uint64, depth+1),
+		LogPi: []uint64{logQ0},
+	}
+
+	logModuli.LogQi[0] = logQ0
+	for i := uint64(1); i < depth+1; i++ {
+		logModuli.LogQi[i] = logScale
+	}
+
+	logQP := 2*logQ0 + depth*logScale
+
+	for logN := uint64(10); logN < 16; logN++ {
+
+		// The NTT primes are equal to 1 modulo 2N
+		if logScale < logN+2 {
+			continue
+		}
+
+		var maxLogQP uint64
+		if maxLogQP, err = ring.MaxLogQ(logN, securityLevel, ring.SecretTernary); err != nil {
+			return nil, err
+		}
+
+		if logQP > maxLogQP {
+			continue
+		}
+
+		params = NewParametersFromLogModuli(logN, logN-1, math.Exp2(float64(logScale)), logModuli, 3.2)
+
+		if params.CheckSecurity(securityLevel, ring.SecretTernary) == nil {
+			return params, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot GenParameters: depth %d with logScale %d requires LogN > 15 for %d-bit security", depth, logScale, securityLevel)
+}
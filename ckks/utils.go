@@ -57,7 +57,7 @@ func scaleUpVecExact(values []float64, n float64, moduli []uint64, coeffs [][]ui
 
 	for i := range values {
 
-		if n*values[i] > 1.8446744073709552e+19 {
+		if n*values[i] > 1.8446744073709552e+19 || n*values[i] < -1.8446744073709552e+19 {
 
 			isNegative = false
 			if values[i] < 0 {
@@ -1,8 +1,10 @@
 package dbfv
 
 import (
+	"fmt"
 	"github.com/ldsec/lattigo/bfv"
 	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
 	"testing"
 )
 
@@ -14,6 +16,7 @@ func Benchmark_DBFV(b *testing.B) {
 	b.Run("PublicKeySwitching", benchPublicKeySwitching)
 	b.Run("RotKeyGen", benchRotKeyGen)
 	b.Run("Refresh", benchRefresh)
+	b.Run("AggregationTree", benchAggregationTree)
 }
 
 func benchPublicKeyGen(b *testing.B) {
@@ -413,3 +416,50 @@ func benchRefresh(b *testing.B) {
 		})
 	}
 }
+
+func benchAggregationTree(b *testing.B) {
+
+	// The aggregation is benchmarked for a large number of parties, with zero shares
+	parties := uint64(64)
+
+	for _, parameters := range testParams.contexts {
+
+		ckg := NewCKGProtocol(parameters)
+
+		shares := make([]utils.Share, parties)
+		for i := range shares {
+			shares[i] = ckg.BindShare(ckg.AllocateShares())
+		}
+		shareOut := ckg.BindShare(ckg.AllocateShares())
+
+		b.Run(testString("Linear/", parties, parameters), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				shareOut.Aggregate(shares[0], shares[1])
+				for _, share := range shares[2:] {
+					shareOut.Aggregate(shareOut, share)
+				}
+			}
+		})
+
+		for _, arity := range []uint64{2, 8} {
+
+			tree := utils.NewAggregationTree(arity)
+
+			b.Run(testString(fmt.Sprintf("Tree/arity=%d/", arity), parties, parameters), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := tree.Aggregate(shares, shareOut); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run(testString(fmt.Sprintf("TreeWithDigest/arity=%d/", arity), parties, parameters), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := tree.AggregateAndDigest(shares, shareOut); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
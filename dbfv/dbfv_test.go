@@ -1,6 +1,8 @@
 package dbfv
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -65,10 +67,55 @@ func Test_DBFV(t *testing.T) {
 	t.Run("RelinKeyGenNaive", testRelinKeyGenNaive)
 	t.Run("KeySwitching", testKeyswitching)
 	t.Run("PublicKeySwitching", testPublicKeySwitching)
+	t.Run("Threshold", testThreshold)
+	t.Run("AggregationTree", testAggregationTree)
 	t.Run("RotKeyGenRotRows", testRotKeyGenRotRows)
 	t.Run("RotKeyGenRotCols", testRotKeyGenRotCols)
 	t.Run("Refresh", testRefresh)
+	t.Run("Sharing", testSharing)
+	t.Run("Gadget", testGadget)
+	t.Run("Errors", testErrors)
+}
 
+func testErrors(t *testing.T) {
+
+	params := new(bfv.Parameters)
+
+	_, errCKS := TryNewCKSProtocol(params, 3.2)
+	_, errPCKS := TryNewPCKSProtocol(params, 3.2)
+	_, errCKG := TryNewCKGProtocol(params)
+	_, errEKG := TryNewEkgProtocol(params)
+	_, errRKG := TryNewRKGProtocolNaive(params)
+	_, errRTG := TryNewRotKGProtocol(params)
+	_, errRefresh := TryNewRefreshProtocol(nil)
+	_, errThr := TryNewThresholdizerProtocol(params, 2)
+	_, errCmb := TryNewCombinerProtocol(params, 2)
+	_, errE2S := TryNewE2SProtocol(params, 3.2, 40)
+	_, errS2E := TryNewS2EProtocol(params, 3.2)
+	_, errCRP := TryNewCRPGenerator(params, nil)
+	_, errE2SSmudging := TryNewE2SProtocol(bfv.DefaultParams[bfv.PN12QP109], 1, 40)
+	_, errE2SFlooding := TryNewE2SProtocol(bfv.DefaultParams[bfv.PN12QP109], 1<<30, 40)
+	_, errThrZero := TryNewThresholdizerProtocol(bfv.DefaultParams[bfv.PN12QP109], 0)
+
+	// Parameters without the special primes Pi only enable the gadget decomposition
+	moduli := bfv.DefaultParams[bfv.PN12QP109].Moduli.Copy()
+	moduli.Pi = nil
+	paramsNoPi := bfv.NewParametersFromModuli(12, 65537, moduli, 3.2)
+
+	_, errEKGNoPi := TryNewEkgProtocol(paramsNoPi)
+	_, errRTGNoPi := TryNewRotKGProtocol(paramsNoPi)
+	_, errEKGGadget := TryNewEkgProtocolGadget(paramsNoPi, 64)
+
+	if _, err := TryNewEkgProtocolGadget(paramsNoPi, 16); err != nil {
+		t.Error(err)
+	}
+
+	for _, err := range []error{errCKS, errPCKS, errCKG, errEKG, errRKG, errRTG, errRefresh, errEKGNoPi, errRTGNoPi, errEKGGadget, errThr, errCmb, errThrZero, errE2S, errS2E, errE2SSmudging, errE2SFlooding, errCRP} {
+		var schemeErr *bfv.Error
+		if !errors.As(err, &schemeErr) || !errors.Is(err, bfv.ErrInvalidParameters) {
+			t.Errorf("invalid error: have %v, want %v", err, bfv.ErrInvalidParameters)
+		}
+	}
 }
 
 func genDBFVTestContext(params *bfv.Parameters) (testCtx *dbfvTestContext) {
@@ -431,6 +478,166 @@ func testPublicKeySwitching(t *testing.T) {
 	}
 }
 
+func testThreshold(t *testing.T) {
+
+	parties := testParams.parties
+	threshold := parties - 1
+
+	for _, parameters := range testParams.contexts {
+		testCtx := genDBFVTestContext(parameters)
+
+		sk0Shards := testCtx.sk0Shards
+		sk1Shards := testCtx.sk1Shards
+		pk1 := testCtx.pk1
+		encryptorPk0 := testCtx.encryptorPk0
+		decryptorSk1 := testCtx.decryptorSk1
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*ThresholdizerProtocol
+				*CombinerProtocol
+				pk        ShamirPublicKey
+				tsk0      ShamirShare
+				tsk1      ShamirShare
+				s0        *ring.Poly
+				s1        *ring.Poly
+				share     ShamirShare
+				cks       *CKSProtocol
+				pcks      *PCKSProtocol
+				cksShare  CKSShare
+				pcksShare PCKSShare
+			}
+
+			thrParties := make([]*Party, parties)
+			for i := uint64(0); i < parties; i++ {
+				p := new(Party)
+				p.ThresholdizerProtocol = NewThresholdizerProtocol(parameters, threshold)
+				p.CombinerProtocol = NewCombinerProtocol(parameters, threshold)
+				p.pk = ShamirPublicKey(i + 1)
+				p.tsk0 = p.ThresholdizerProtocol.AllocateShare()
+				p.tsk1 = p.ThresholdizerProtocol.AllocateShare()
+				p.share = p.ThresholdizerProtocol.AllocateShare()
+				p.s0 = testCtx.dbfvContext.contextQP.NewPoly()
+				p.s1 = testCtx.dbfvContext.contextQP.NewPoly()
+				thrParties[i] = p
+			}
+
+			// Each party thresholdizes its additive shares of sk0 and sk1
+			for _, shards := range []struct {
+				sk  []*bfv.SecretKey
+				tsk func(p *Party) ShamirShare
+			}{
+				{sk0Shards, func(p *Party) ShamirShare { return p.tsk0 }},
+				{sk1Shards, func(p *Party) ShamirShare { return p.tsk1 }},
+			} {
+				for i, sender := range thrParties {
+					polynomial := sender.GenShamirPolynomial(shards.sk[i].Get())
+					for _, recipient := range thrParties {
+						sender.ThresholdizerProtocol.GenShare(polynomial, recipient.pk, sender.share)
+						sender.ThresholdizerProtocol.AggregateShares(shards.tsk(recipient), sender.share, shards.tsk(recipient))
+					}
+				}
+			}
+
+			// Only threshold parties are active
+			activeParties := thrParties[1:]
+			active := make([]ShamirPublicKey, len(activeParties))
+			for i, p := range activeParties {
+				active[i] = p.pk
+			}
+
+			t.Run("Duplicates", func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("GenAdditiveShare should panic on duplicate active ShamirPublicKeys")
+					}
+				}()
+				P := thrParties[0]
+				P.GenAdditiveShare([]ShamirPublicKey{P.pk, P.pk + 1, P.pk + 1}, P.pk, P.tsk0, P.s0)
+			})
+
+			for _, p := range activeParties {
+				p.GenAdditiveShare(active, p.pk, p.tsk0, p.s0)
+				p.GenAdditiveShare(active, p.pk, p.tsk1, p.s1)
+				p.cks = NewCKSProtocol(parameters, 6.36)
+				p.pcks = NewPCKSProtocol(parameters, 6.36)
+				p.cksShare = p.cks.AllocateShare()
+				p.pcksShare = p.pcks.AllocateShares()
+			}
+			P0 := activeParties[0]
+
+			coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, t)
+
+			for i, p := range activeParties {
+				p.cks.GenShare(p.s0, p.s1, ciphertext, p.cksShare)
+				p.pcks.GenShare(p.s0, pk1, ciphertext, p.pcksShare)
+				if i > 0 {
+					P0.cks.AggregateShares(p.cksShare, P0.cksShare, P0.cksShare)
+					P0.pcks.AggregateShares(p.pcksShare, P0.pcksShare, P0.pcksShare)
+				}
+			}
+
+			ksCiphertext := bfv.NewCiphertext(parameters, 1)
+			P0.cks.KeySwitch(P0.cksShare, ciphertext, ksCiphertext)
+			verifyTestVectors(testCtx, decryptorSk1, coeffs, ksCiphertext, t)
+
+			P0.pcks.KeySwitch(P0.pcksShare, ciphertext, ksCiphertext)
+			verifyTestVectors(testCtx, decryptorSk1, coeffs, ksCiphertext, t)
+		})
+	}
+}
+
+func testAggregationTree(t *testing.T) {
+
+	parties := testParams.parties
+
+	for _, parameters := range testParams.contexts {
+		testCtx := genDBFVTestContext(parameters)
+
+		sk0Shards := testCtx.sk0Shards
+		sk1Shards := testCtx.sk1Shards
+		pk1 := testCtx.pk1
+		encryptorPk0 := testCtx.encryptorPk0
+		decryptorSk1 := testCtx.decryptorSk1
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, t)
+
+			cks := NewCKSProtocol(parameters, 6.36)
+			pcks := NewPCKSProtocol(parameters, 6.36)
+
+			cksShares := make([]utils.Share, parties)
+			pcksShares := make([]utils.Share, parties)
+			for i := uint64(0); i < parties; i++ {
+				cksShare := cks.AllocateShare()
+				cks.GenShare(sk0Shards[i].Get(), sk1Shards[i].Get(), ciphertext, cksShare)
+				cksShares[i] = cks.BindShare(cksShare)
+
+				pcksShare := pcks.AllocateShares()
+				pcks.GenShare(sk0Shards[i].Get(), pk1, ciphertext, pcksShare)
+				pcksShares[i] = pcks.BindShare(pcksShare)
+			}
+
+			tree := utils.NewAggregationTree(2)
+
+			cksCombined := cks.AllocateShare()
+			check(t, tree.Aggregate(cksShares, cks.BindShare(cksCombined)))
+
+			ksCiphertext := bfv.NewCiphertext(parameters, 1)
+			cks.KeySwitch(cksCombined, ciphertext, ksCiphertext)
+			verifyTestVectors(testCtx, decryptorSk1, coeffs, ksCiphertext, t)
+
+			pcksCombined := pcks.AllocateShares()
+			check(t, tree.Aggregate(pcksShares, pcks.BindShare(pcksCombined)))
+
+			pcks.KeySwitch(pcksCombined, ciphertext, ksCiphertext)
+			verifyTestVectors(testCtx, decryptorSk1, coeffs, ksCiphertext, t)
+		})
+	}
+}
+
 func testRotKeyGenRotRows(t *testing.T) {
 
 	parties := testParams.parties
@@ -679,6 +886,249 @@ func testRefresh(t *testing.T) {
 	}
 }
 
+func testSharing(t *testing.T) {
+
+	parties := testParams.parties
+
+	// Bound on the decryption noise of the fresh test ciphertexts, hidden with 40 bits of statistical security
+	noiseBound := float64(1 << 10)
+	lambda := uint64(40)
+
+	for _, parameters := range testParams.contexts {
+		testCtx := genDBFVTestContext(parameters)
+
+		encryptorPk0 := testCtx.encryptorPk0
+		decryptorSk0 := testCtx.decryptorSk0
+		sk0Shards := testCtx.sk0Shards
+		encoder := testCtx.encoder
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				e2s         *E2SProtocol
+				s2e         *S2EProtocol
+				s           *ring.Poly
+				secretShare AdditiveShare
+				publicShare CKSShare
+			}
+
+			sharingParties := make([]*Party, parties)
+			for i := range sharingParties {
+				p := new(Party)
+				p.e2s = NewE2SProtocol(parameters, noiseBound, lambda)
+				p.s2e = NewS2EProtocol(parameters, parameters.Sigma)
+				p.s = sk0Shards[i].Get()
+				p.secretShare, p.publicShare = p.e2s.AllocateShares()
+				sharingParties[i] = p
+			}
+
+			P0 := sharingParties[0]
+
+			coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, t)
+
+			ciphertextLow, err := testCtx.evaluator.DropLevelNew(ciphertext, 1)
+			check(t, err)
+
+			if err := P0.e2s.TryGenShare(P0.s, ciphertextLow, P0.secretShare, P0.publicShare); !errors.Is(err, bfv.ErrLevelMismatch) {
+				t.Errorf("invalid error: have %v, want %v", err, bfv.ErrLevelMismatch)
+			}
+
+			// Encryption to shares, with P0 receiving the aggregated public share
+			for i, p := range sharingParties {
+				p.e2s.GenShare(p.s, ciphertext, p.secretShare, p.publicShare)
+				if i > 0 {
+					P0.e2s.AggregateShares(p.publicShare, P0.publicShare, P0.publicShare)
+				}
+			}
+
+			P0.e2s.GetShare(P0.secretShare, P0.publicShare, ciphertext, P0.secretShare)
+
+			// The sum of the additive shares is the plaintext
+			sum := testCtx.contextT.NewPoly()
+			for _, p := range sharingParties {
+				testCtx.contextT.Add(sum, p.secretShare.Poly, sum)
+			}
+
+			plaintext := bfv.NewPlaintext(parameters)
+			lift(sum, plaintext.Value()[0], testCtx.dbfvContext)
+
+			if !utils.EqualSliceUint64(coeffs, encoder.DecodeUint(plaintext)) {
+				t.Error("the additive shares do not sum to the plaintext")
+			}
+
+			// Shares to encryption
+			crpGenerator := ring.NewCRPGenerator(nil, testCtx.contextQ)
+			crpGenerator.Seed([]byte{})
+			crp := crpGenerator.ClockNew()
+
+			for i, p := range sharingParties {
+				p.s2e.GenShare(p.s, crp, p.secretShare, p.publicShare)
+				if i > 0 {
+					P0.s2e.AggregateShares(p.publicShare, P0.publicShare, P0.publicShare)
+				}
+			}
+
+			// The output is a fresh encryption with the secret-key, whose seed must not be marshaled once overwritten
+			ciphertextOut := bfv.NewEncryptorFromSk(parameters, testCtx.sk0).EncryptNew(bfv.NewPlaintext(parameters))
+			P0.s2e.GetEncryption(P0.publicShare, crp, ciphertextOut)
+
+			if _, err := ciphertextOut.MarshalBinaryCompressed(); err == nil {
+				t.Error("MarshalBinaryCompressed should fail on a ciphertext overwritten by GetEncryption")
+			}
+
+			verifyTestVectors(testCtx, decryptorSk0, coeffs, ciphertextOut, t)
+		})
+	}
+}
+
+func testGadget(t *testing.T) {
+
+	parties := testParams.parties
+
+	logBase := uint64(30)
+
+	for _, parameters := range testParams.contexts {
+
+		// Same moduli without the special primes Pi, which are not needed by the gadget decomposition.
+		moduli := parameters.Moduli.Copy()
+		moduli.Pi = nil
+		parameters = bfv.NewParametersFromModuli(parameters.LogN, parameters.T, moduli, parameters.Sigma)
+
+		testCtx := genDBFVTestContext(parameters)
+
+		sk0Shards := testCtx.sk0Shards
+		decryptorSk0 := testCtx.decryptorSk0
+		evaluator := testCtx.evaluator
+
+		// The encryption without P is used since Pi is empty.
+		newTestVectorsFast := func() (coeffs []uint64, ciphertext *bfv.Ciphertext) {
+			coeffsPol := testCtx.contextT.NewUniformPoly()
+			plaintext := bfv.NewPlaintext(parameters)
+			testCtx.encoder.EncodeUint(coeffsPol.Coeffs[0], plaintext)
+			return coeffsPol.Coeffs[0], testCtx.encryptorPk0.EncryptFastNew(plaintext)
+		}
+
+		crpGenerator := ring.NewCRPGenerator(nil, testCtx.contextQP)
+		crpGenerator.Seed([]byte{})
+		crp := make([]*ring.Poly, uint64(len(parameters.Qi))*parameters.GadgetDigits(logBase))
+
+		for i := range crp {
+			crp[i] = crpGenerator.ClockNew()
+		}
+
+		t.Run(testString("RelinKeyGen/", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*RKGProtocol
+				u      *ring.Poly
+				s      *ring.Poly
+				share1 RKGShareRoundOne
+				share2 RKGShareRoundTwo
+				share3 RKGShareRoundThree
+			}
+
+			rkgParties := make([]*Party, parties)
+
+			for i := range rkgParties {
+				p := new(Party)
+				p.RKGProtocol = NewEkgProtocolGadget(parameters, logBase)
+				p.u = p.RKGProtocol.NewEphemeralKey(1.0 / 3.0)
+				p.s = sk0Shards[i].Get()
+				p.share1, p.share2, p.share3 = p.RKGProtocol.AllocateShares()
+				rkgParties[i] = p
+			}
+
+			P0 := rkgParties[0]
+
+			for i, p := range rkgParties {
+				p.GenShareRoundOne(p.u, p.s, crp, p.share1)
+				if i > 0 {
+					P0.AggregateShareRoundOne(p.share1, P0.share1, P0.share1)
+				}
+			}
+
+			for i, p := range rkgParties {
+				p.GenShareRoundTwo(P0.share1, p.s, crp, p.share2)
+				if i > 0 {
+					P0.AggregateShareRoundTwo(p.share2, P0.share2, P0.share2)
+				}
+			}
+
+			for i, p := range rkgParties {
+				p.GenShareRoundThree(P0.share2, p.u, p.s, p.share3)
+				if i > 0 {
+					P0.AggregateShareRoundThree(p.share3, P0.share3, P0.share3)
+				}
+			}
+
+			evk := bfv.NewRelinKeyGadget(parameters, 1, logBase)
+			P0.GenRelinearizationKey(P0.share2, P0.share3, evk)
+
+			coeffs, ciphertext := newTestVectorsFast()
+
+			for i := range coeffs {
+				coeffs[i] *= coeffs[i]
+				coeffs[i] %= testCtx.contextT.Modulus[0]
+			}
+
+			res := bfv.NewCiphertext(parameters, 1)
+			evaluator.Relinearize(evaluator.MulNew(ciphertext, ciphertext), evk, res)
+
+			verifyTestVectors(testCtx, decryptorSk0, coeffs, res, t)
+		})
+
+		t.Run(testString("RotKeyGen/", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*RTGProtocol
+				s     *ring.Poly
+				share RTGShare
+			}
+
+			rtgParties := make([]*Party, parties)
+			for i := uint64(0); i < parties; i++ {
+				p := new(Party)
+				p.RTGProtocol = NewRotKGProtocolGadget(parameters, logBase)
+				p.s = sk0Shards[i].Get()
+				p.share = p.AllocateShare()
+				rtgParties[i] = p
+			}
+
+			P0 := rtgParties[0]
+
+			rotkey := bfv.NewRotationKeys()
+
+			for _, rotType := range []bfv.Rotation{bfv.RotationLeft, bfv.RotationRow} {
+				for i, p := range rtgParties {
+					p.GenShare(rotType, 1, p.s, crp, &p.share)
+					if i > 0 {
+						P0.Aggregate(p.share, P0.share, P0.share)
+					}
+				}
+
+				P0.Finalize(P0.share, crp, rotkey)
+			}
+
+			coeffs, ciphertext := newTestVectorsFast()
+
+			mask := (testCtx.n >> 1) - 1
+
+			coeffsWant := make([]uint64, testCtx.n)
+
+			for i := uint64(0); i < testCtx.n>>1; i++ {
+				coeffsWant[i] = coeffs[(i+1)&mask]
+				coeffsWant[i+(testCtx.n>>1)] = coeffs[((i+1)&mask)+(testCtx.n>>1)]
+			}
+
+			verifyTestVectors(testCtx, decryptorSk0, coeffsWant, evaluator.RotateColumnsNew(ciphertext, 1, rotkey), t)
+
+			coeffsWant = append(coeffs[testCtx.n>>1:], coeffs[:testCtx.n>>1]...)
+
+			verifyTestVectors(testCtx, decryptorSk0, coeffsWant, evaluator.RotateRowsNew(ciphertext, rotkey), t)
+		})
+	}
+}
+
 func newTestVectors(contextParams *dbfvTestContext, encryptor bfv.Encryptor, t *testing.T) (coeffs []uint64, plaintext *bfv.Plaintext, ciphertext *bfv.Ciphertext) {
 	coeffsPol := contextParams.contextT.NewUniformPoly()
 	plaintext = bfv.NewPlaintext(contextParams.params)
@@ -845,6 +1295,19 @@ func Test_Marshalling(t *testing.T) {
 			}
 
 		}
+
+		// The decryption and recryption shares can also be sent separately
+		decryptAfter, recryptAfter := new(RefreshShareDecrypt), new(RefreshShareRecrypt)
+
+		for _, pair := range [][2]utils.BinaryShare{{&refreshshare.RefreshShareDecrypt, decryptAfter}, {&refreshshare.RefreshShareRecrypt, recryptAfter}} {
+			data, err := pair[0].MarshalBinary()
+			check(t, err)
+			check(t, pair[1].UnmarshalBinary(data))
+		}
+
+		if !contextQ.Equal(decryptAfter.Poly, refreshshare.RefreshShareDecrypt.Poly) || !contextQ.Equal(recryptAfter.Poly, refreshshare.RefreshShareRecrypt.Poly) {
+			t.Error("Resulting of marshalling not the same as original : RefreshShareDecrypt, RefreshShareRecrypt")
+		}
 	})
 
 	t.Run(fmt.Sprintf("RTG/N=%d/limbQ=%d/limbsP=%d", contextQ.N, len(contextQ.Modulus), len(contextPKeys.Modulus)), func(t *testing.T) {
@@ -1003,4 +1466,48 @@ func Test_Relin_Marshalling(t *testing.T) {
 		}
 	})
 
+	t.Run(fmt.Sprintf("RLKGNaive/N=%d/limbQ=%d/limbsP=%d", contextQ.N, len(contextQ.Modulus), len(contextPKeys.Modulus)), func(t *testing.T) {
+
+		kgen := bfv.NewKeyGenerator(params)
+		sk, pk := kgen.GenKeyPair()
+
+		rkg := NewRKGProtocolNaive(params)
+		r1, r2 := rkg.AllocateShares()
+		rkg.GenShareRoundOne(sk.Get(), pk.Get(), r1)
+		rkg.GenShareRoundTwo(r1, sk.Get(), pk.Get(), r2)
+
+		for round, share := range []utils.BinaryShare{&r1, &r2} {
+
+			data, err := share.MarshalBinary()
+			check(t, err)
+
+			var shareAfter utils.BinaryShare = new(RKGNaiveShareRoundOne)
+			if round == 1 {
+				shareAfter = new(RKGNaiveShareRoundTwo)
+			}
+			check(t, shareAfter.UnmarshalBinary(data))
+
+			dataAfter, err := shareAfter.MarshalBinary()
+			check(t, err)
+
+			if !bytes.Equal(data, dataAfter) {
+				t.Errorf("result after marshalling is not the same as before marshalling for the naive relinearization key round %d", round+1)
+			}
+		}
+
+		// The pairs of polynomials are validated before being decoded
+		if _, err := new(RKGNaiveShareRoundOne).MarshalBinary(); err == nil {
+			t.Error("an empty share was marshaled")
+		}
+
+		data, err := r1.MarshalBinary()
+		check(t, err)
+
+		for _, data := range [][]byte{nil, {0}, {1, 0}, data[:len(data)-1], append([]byte{data[0] + 1}, data[1:]...)} {
+			if err := new(RKGNaiveShareRoundOne).UnmarshalBinary(data); err == nil {
+				t.Errorf("an invalid share of %d bytes was unmarshaled", len(data))
+			}
+		}
+	})
+
 }
@@ -0,0 +1,102 @@
+package dbfv
+
+import (
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/ring"
+)
+
+// checkParameters returns a bfv.Error wrapping bfv.ErrInvalidParameters if params is nil or was not generated.
+func checkParameters(op string, params *bfv.Parameters) error {
+	if params == nil || !params.IsValid() {
+		return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters}
+	}
+	return nil
+}
+
+// checkKeySwitching returns a bfv.Error wrapping bfv.ErrInvalidParameters if params do not enable the generation of the
+// key-switching keys with the gadget decomposition of base 2^logBase, or with the special primes Pi if logBase is 0.
+func checkKeySwitching(op string, params *bfv.Parameters, logBase uint64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if logBase == 0 {
+		if len(params.Pi) == 0 {
+			return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters, Reason: "modulus P is empty"}
+		}
+		return nil
+	}
+
+	for _, logQi := range params.LogQi {
+		if logBase > logQi {
+			return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters, Reason: "logBase is larger than the bit-size of the smallest Qi"}
+		}
+	}
+
+	return nil
+}
+
+// checkThreshold returns a bfv.Error wrapping bfv.ErrInvalidParameters if params is nil or was not generated, or if
+// threshold is zero.
+func checkThreshold(op string, params *bfv.Parameters, threshold uint64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if threshold == 0 {
+		return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters, Reason: "threshold must be at least 1"}
+	}
+
+	return nil
+}
+
+// checkSmudging returns a bfv.Error wrapping bfv.ErrInvalidParameters if params is nil or was not generated, or if
+// sigmaSmudging is smaller than the standard deviation of the encryption noise.
+func checkSmudging(op string, params *bfv.Parameters, sigmaSmudging float64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if sigmaSmudging < params.Sigma {
+		return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters, Reason: "sigmaSmudging is smaller than the standard deviation of the encryption noise"}
+	}
+
+	return nil
+}
+
+// checkFlooding returns a bfv.Error wrapping bfv.ErrInvalidParameters if params is nil or was not generated, if
+// noiseBound is smaller than the standard deviation of the encryption noise, or if the flooding bound
+// 2^lambda * noiseBound is not smaller than Q/(2T), above which the flooded plaintext cannot be decrypted.
+func checkFlooding(op string, params *bfv.Parameters, noiseBound float64, lambda uint64) error {
+
+	if err := checkSmudging(op, params, noiseBound); err != nil {
+		return err
+	}
+
+	bound := floodingBound(noiseBound, lambda)
+	bound.Mul(bound, ring.NewUint(params.T<<1))
+
+	if bound.Cmp(modulusBigint(params.Qi)) >= 0 {
+		return &bfv.Error{Op: op, Err: bfv.ErrInvalidParameters, Reason: "the flooding bound 2^lambda * noiseBound exceeds Q/(2T)"}
+	}
+
+	return nil
+}
+
+// checkCiphertextLevel returns a bfv.Error wrapping bfv.ErrNilOperand if ct is nil, or bfv.ErrLevelMismatch if ct is
+// not at the given level.
+func checkCiphertextLevel(op string, ct *bfv.Ciphertext, level uint64) error {
+
+	if ct == nil {
+		return &bfv.Error{Op: op, Err: bfv.ErrNilOperand}
+	}
+
+	if ct.Level() != level {
+		return &bfv.Error{Op: op, Err: bfv.ErrLevelMismatch, Reason: "ciphertext is not at the maximum level"}
+	}
+
+	return nil
+}
@@ -32,13 +32,23 @@ func (share *CKSShare) UnmarshalBinary(data []byte) error {
 
 }
 
+// TryNewCKSProtocol is the same as NewCKSProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewCKSProtocol(params *bfv.Parameters, sigmaSmudging float64) (*CKSProtocol, error) {
+
+	if err := checkParameters("NewCKSProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewCKSProtocol(params, sigmaSmudging), nil
+}
+
 // NewCKSProtocol creates a new CKSProtocol that will be used to operate a collective key-switching on a ciphertext encrypted under a collective public-key, whose
 // secret-shares are distributed among j parties, re-encrypting the ciphertext under another public-key, whose secret-shares are also known to the
 // parties.
 func NewCKSProtocol(params *bfv.Parameters, sigmaSmudging float64) *CKSProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewCKSProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewCKSProtocol", params); err != nil {
+		panic(err)
 	}
 
 	context := newDbfvContext(params)
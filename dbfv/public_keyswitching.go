@@ -71,12 +71,22 @@ func (share *PCKSShare) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// TryNewPCKSProtocol is the same as NewPCKSProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewPCKSProtocol(params *bfv.Parameters, sigmaSmudging float64) (*PCKSProtocol, error) {
+
+	if err := checkParameters("NewPCKSProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewPCKSProtocol(params, sigmaSmudging), nil
+}
+
 // NewPCKSProtocol creates a new PCKSProtocol object and will be used to re-encrypt a ciphertext ctx encrypted under a secret-shared key among j parties under a new
 // collective public-key.
 func NewPCKSProtocol(params *bfv.Parameters, sigmaSmudging float64) *PCKSProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewPCKSProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewPCKSProtocol", params); err != nil {
+		panic(err)
 	}
 
 	context := newDbfvContext(params)
@@ -17,10 +17,30 @@ type RefreshProtocol struct {
 }
 
 // RefreshShareDecrypt is a struct storing the decrpytion share.
-type RefreshShareDecrypt *ring.Poly
+type RefreshShareDecrypt struct {
+	*ring.Poly
+}
+
+// UnmarshalBinary decodes a marshaled decryption share on the target decryption share.
+func (share *RefreshShareDecrypt) UnmarshalBinary(data []byte) error {
+	if share.Poly == nil {
+		share.Poly = new(ring.Poly)
+	}
+	return share.Poly.UnmarshalBinary(data)
+}
 
 // RefreshShareRecrypt is a struct storing the recrpytion share.
-type RefreshShareRecrypt *ring.Poly
+type RefreshShareRecrypt struct {
+	*ring.Poly
+}
+
+// UnmarshalBinary decodes a marshaled recryption share on the target recryption share.
+func (share *RefreshShareRecrypt) UnmarshalBinary(data []byte) error {
+	if share.Poly == nil {
+		share.Poly = new(ring.Poly)
+	}
+	return share.Poly.UnmarshalBinary(data)
+}
 
 // RefreshShare is a struct storing the decryption and recryption shares.
 type RefreshShare struct {
@@ -30,21 +50,21 @@ type RefreshShare struct {
 
 // MarshalBinary encodes a RefreshShare on a slice of bytes.
 func (share *RefreshShare) MarshalBinary() ([]byte, error) {
-	lenDecrypt := (*share.RefreshShareDecrypt).GetDataLen(true)
-	lenRecrypt := (*share.RefreshShareRecrypt).GetDataLen(true)
+	lenDecrypt := share.RefreshShareDecrypt.GetDataLen(true)
+	lenRecrypt := share.RefreshShareRecrypt.GetDataLen(true)
 
 	data := make([]byte, lenDecrypt+lenRecrypt+2*8) // 2 * 3 to write the len of lenDecrypt and lenRecrypt.
 	binary.BigEndian.PutUint64(data[0:8], lenDecrypt)
 	binary.BigEndian.PutUint64(data[8:16], lenRecrypt)
 
 	ptr := uint64(16)
-	tmp, err := (*share.RefreshShareDecrypt).WriteTo(data[ptr : ptr+lenDecrypt])
+	tmp, err := share.RefreshShareDecrypt.WriteTo(data[ptr : ptr+lenDecrypt])
 	if err != nil {
 		return []byte{}, err
 	}
 
 	ptr += tmp
-	tmp, err = (*share.RefreshShareRecrypt).WriteTo(data[ptr : ptr+lenRecrypt])
+	tmp, err = share.RefreshShareRecrypt.WriteTo(data[ptr : ptr+lenRecrypt])
 	if err != nil {
 		return []byte{}, err
 	}
@@ -57,29 +77,33 @@ func (share *RefreshShare) UnmarshalBinary(data []byte) error {
 	lenDecrypt := binary.BigEndian.Uint64(data[0:8])
 	lenRecrypt := binary.BigEndian.Uint64(data[8:16])
 	ptr := uint64(16)
-	if share.RefreshShareRecrypt == nil || share.RefreshShareDecrypt == nil {
-		share.RefreshShareRecrypt = new(ring.Poly)
-		share.RefreshShareDecrypt = new(ring.Poly)
-
-	}
-
-	err := (*share.RefreshShareDecrypt).UnmarshalBinary(data[ptr : ptr+lenDecrypt])
+	err := share.RefreshShareDecrypt.UnmarshalBinary(data[ptr : ptr+lenDecrypt])
 	if err != nil {
 		return err
 	}
 	ptr += lenDecrypt
-	err = (*share.RefreshShareRecrypt).UnmarshalBinary(data[ptr : ptr+lenRecrypt])
+	err = share.RefreshShareRecrypt.UnmarshalBinary(data[ptr : ptr+lenRecrypt])
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// TryNewRefreshProtocol is the same as NewRefreshProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRefreshProtocol(params *bfv.Parameters) (*RefreshProtocol, error) {
+
+	if err := checkParameters("NewRefreshProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewRefreshProtocol(params), nil
+}
+
 // NewRefreshProtocol creates a new Refresh protocol instance.
 func NewRefreshProtocol(params *bfv.Parameters) (refreshProtocol *RefreshProtocol) {
 
-	if !params.IsValid() {
-		panic("cannot NewRefreshProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewRefreshProtocol", params); err != nil {
+		panic(err)
 	}
 
 	context := newDbfvContext(params)
@@ -97,8 +121,8 @@ func NewRefreshProtocol(params *bfv.Parameters) (refreshProtocol *RefreshProtoco
 
 // AllocateShares allocates the shares of the Refresh protocol.
 func (rfp *RefreshProtocol) AllocateShares() RefreshShare {
-	return RefreshShare{rfp.context.contextQ.NewPoly(),
-		rfp.context.contextQ.NewPoly()}
+	return RefreshShare{RefreshShareDecrypt{rfp.context.contextQ.NewPoly()},
+		RefreshShareRecrypt{rfp.context.contextQ.NewPoly()}}
 }
 
 // GenShares generates a share for the Refresh protocol.
@@ -114,16 +138,16 @@ func (rfp *RefreshProtocol) GenShares(sk *ring.Poly, ciphertext *bfv.Ciphertext,
 
 	// h0 = s*ct[1]
 	contextQ.NTT(ciphertext.Value()[1], rfp.tmp1)
-	contextQ.MulCoeffsMontgomery(sk, rfp.tmp1, share.RefreshShareDecrypt)
+	contextQ.MulCoeffsMontgomery(sk, rfp.tmp1, share.RefreshShareDecrypt.Poly)
 
-	contextQ.InvNTT(share.RefreshShareDecrypt, share.RefreshShareDecrypt)
+	contextQ.InvNTT(share.RefreshShareDecrypt.Poly, share.RefreshShareDecrypt.Poly)
 
 	// h0 = s*ct[1]*P
-	contextQ.MulScalarBigint(share.RefreshShareDecrypt, contextP.ModulusBigint, share.RefreshShareDecrypt)
+	contextQ.MulScalarBigint(share.RefreshShareDecrypt.Poly, contextP.ModulusBigint, share.RefreshShareDecrypt.Poly)
 
 	// h0 = s*ct[1]*P + e
 	sampler.Sample(rfp.tmp1)
-	contextQ.Add(share.RefreshShareDecrypt, rfp.tmp1, share.RefreshShareDecrypt)
+	contextQ.Add(share.RefreshShareDecrypt.Poly, rfp.tmp1, share.RefreshShareDecrypt.Poly)
 
 	for x, i := 0, uint64(len(contextQ.Modulus)); i < uint64(len(rfp.context.contextQP.Modulus)); x, i = x+1, i+1 {
 		tmphP := rfp.hP.Coeffs[x]
@@ -134,7 +158,7 @@ func (rfp *RefreshProtocol) GenShares(sk *ring.Poly, ciphertext *bfv.Ciphertext,
 	}
 
 	// h0 = (s*ct[1]*P + e)/P
-	rfp.baseconverter.ModDownSplitedPQ(level, share.RefreshShareDecrypt, rfp.hP, share.RefreshShareDecrypt)
+	rfp.baseconverter.ModDownSplitedPQ(level, share.RefreshShareDecrypt.Poly, rfp.hP, share.RefreshShareDecrypt.Poly)
 
 	// h1 = -s*a
 	contextKeys.NTT(crs, rfp.tmp1)
@@ -146,28 +170,28 @@ func (rfp *RefreshProtocol) GenShares(sk *ring.Poly, ciphertext *bfv.Ciphertext,
 	sampler.SampleAndAdd(rfp.tmp2)
 
 	// h1 = (-s*a + e')/P
-	rfp.baseconverter.ModDownPQ(level, rfp.tmp2, share.RefreshShareRecrypt)
+	rfp.baseconverter.ModDownPQ(level, rfp.tmp2, share.RefreshShareRecrypt.Poly)
 
 	// mask = (uniform plaintext in [0, T-1]) * floor(Q/T)
 	coeffs := contextT.NewUniformPoly()
 	lift(coeffs, rfp.tmp1, rfp.context)
 
 	// h0 = (s*ct[1]*P + e)/P + mask
-	contextQ.Add(share.RefreshShareDecrypt, rfp.tmp1, share.RefreshShareDecrypt)
+	contextQ.Add(share.RefreshShareDecrypt.Poly, rfp.tmp1, share.RefreshShareDecrypt.Poly)
 
 	// h1 = (-s*a + e')/P - mask
-	contextQ.Sub(share.RefreshShareRecrypt, rfp.tmp1, share.RefreshShareRecrypt)
+	contextQ.Sub(share.RefreshShareRecrypt.Poly, rfp.tmp1, share.RefreshShareRecrypt.Poly)
 }
 
 // Aggregate sums share1 and share2 on shareOut.
 func (rfp *RefreshProtocol) Aggregate(share1, share2, shareOut RefreshShare) {
-	rfp.context.contextQ.Add(share1.RefreshShareDecrypt, share2.RefreshShareDecrypt, shareOut.RefreshShareDecrypt)
-	rfp.context.contextQ.Add(share1.RefreshShareRecrypt, share2.RefreshShareRecrypt, shareOut.RefreshShareRecrypt)
+	rfp.context.contextQ.Add(share1.RefreshShareDecrypt.Poly, share2.RefreshShareDecrypt.Poly, shareOut.RefreshShareDecrypt.Poly)
+	rfp.context.contextQ.Add(share1.RefreshShareRecrypt.Poly, share2.RefreshShareRecrypt.Poly, shareOut.RefreshShareRecrypt.Poly)
 }
 
 // Decrypt operates a masked decryption on the input ciphertext using the provided decryption shares.
 func (rfp *RefreshProtocol) Decrypt(ciphertext *bfv.Ciphertext, shareDecrypt RefreshShareDecrypt, sharePlaintext *ring.Poly) {
-	rfp.context.contextQ.Add(ciphertext.Value()[0], shareDecrypt, sharePlaintext)
+	rfp.context.contextQ.Add(ciphertext.Value()[0], shareDecrypt.Poly, sharePlaintext)
 }
 
 // Recode decodes and re-encode (removing the error) the masked decrypted ciphertext.
@@ -182,7 +206,7 @@ func (rfp *RefreshProtocol) Recode(sharePlaintext *ring.Poly, sharePlaintextOut
 func (rfp *RefreshProtocol) Recrypt(sharePlaintext *ring.Poly, crs *ring.Poly, shareRecrypt RefreshShareRecrypt, ciphertextOut *bfv.Ciphertext) {
 
 	// ciphertext[0] = (-crs*s + e')/P + m
-	rfp.context.contextQ.Add(sharePlaintext, shareRecrypt, ciphertextOut.Value()[0])
+	rfp.context.contextQ.Add(sharePlaintext, shareRecrypt.Poly, ciphertextOut.Value()[0])
 
 	// ciphertext[1] = crs/P
 	rfp.baseconverter.ModDownPQ(uint64(len(ciphertextOut.Value()[1].Coeffs)-1), crs, ciphertextOut.Value()[1])
@@ -27,11 +27,21 @@ func (share *CKGShare) UnmarshalBinary(data []byte) error {
 
 }
 
+// TryNewCKGProtocol is the same as NewCKGProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewCKGProtocol(params *bfv.Parameters) (*CKGProtocol, error) {
+
+	if err := checkParameters("NewCKGProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewCKGProtocol(params), nil
+}
+
 // NewCKGProtocol creates a new CKGProtocol instance
 func NewCKGProtocol(params *bfv.Parameters) *CKGProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewCKGProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewCKGProtocol", params); err != nil {
+		panic(err)
 	}
 
 	context := newDbfvContext(params)
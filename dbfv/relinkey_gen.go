@@ -1,7 +1,6 @@
 package dbfv
 
 import (
-	"errors"
 	"github.com/ldsec/lattigo/bfv"
 	"github.com/ldsec/lattigo/ring"
 )
@@ -10,6 +9,7 @@ import (
 // generation protocol.
 type RKGProtocol struct {
 	context  *dbfvContext
+	gadget   *keySwitchingGadget
 	tmpPoly1 *ring.Poly
 	tmpPoly2 *ring.Poly
 	polypool *ring.Poly
@@ -67,63 +67,13 @@ func (share *RKGShareRoundOne) UnmarshalBinary(data []byte) error {
 
 // MarshalBinary encodes the target element on a slice of bytes.
 func (share *RKGShareRoundTwo) MarshalBinary() ([]byte, error) {
-	//we have modulus * bitLog * Len of 1 ring rings
-	rLength := ((*share)[0])[0].GetDataLen(true)
-	data := make([]byte, 1+2*rLength*uint64(len(*share)))
-	if len(*share) > 0xFF {
-		return []byte{}, errors.New("RKGShareRoundTwo : uint8 overflow on length")
-	}
-	data[0] = uint8(len(*share))
-
-	//write all of our rings in the data.
-	//write all the polys
-	ptr := uint64(1)
-	for _, elem := range *share {
-		_, err := elem[0].WriteTo(data[ptr : ptr+rLength])
-		if err != nil {
-			return []byte{}, err
-		}
-		ptr += rLength
-		_, err = elem[1].WriteTo(data[ptr : ptr+rLength])
-		if err != nil {
-			return []byte{}, err
-		}
-		ptr += rLength
-	}
-
-	return data, nil
-
+	return marshalPolyPairs(*share)
 }
 
 // UnmarshalBinary decodes a slice of bytes on the target element.
-func (share *RKGShareRoundTwo) UnmarshalBinary(data []byte) error {
-	lenShare := data[0]
-	rLength := (len(data) - 1) / (2 * int(lenShare))
-
-	if *share == nil {
-		*share = make([][2]*ring.Poly, lenShare)
-	}
-	ptr := (1)
-	for i := (0); i < int(lenShare); i++ {
-		if (*share)[i][0] == nil || (*share)[i][1] == nil {
-			(*share)[i][0] = new(ring.Poly)
-			(*share)[i][1] = new(ring.Poly)
-		}
-
-		err := (*share)[i][0].UnmarshalBinary(data[ptr : ptr+rLength])
-		if err != nil {
-			return err
-		}
-		ptr += rLength
-		err = (*share)[i][1].UnmarshalBinary(data[ptr : ptr+rLength])
-		if err != nil {
-			return err
-		}
-		ptr += rLength
-
-	}
-
-	return nil
+func (share *RKGShareRoundTwo) UnmarshalBinary(data []byte) (err error) {
+	*share, err = unmarshalPolyPairs(data)
+	return err
 }
 
 // MarshalBinary encodes the target element on a slice of bytes.
@@ -169,31 +119,69 @@ func (share *RKGShareRoundThree) UnmarshalBinary(data []byte) error {
 
 // AllocateShares allocates the shares of the EKG protocol.
 func (ekg *RKGProtocol) AllocateShares() (r1 RKGShareRoundOne, r2 RKGShareRoundTwo, r3 RKGShareRoundThree) {
-	r1 = make([]*ring.Poly, ekg.context.params.Beta())
-	r2 = make([][2]*ring.Poly, ekg.context.params.Beta())
-	r3 = make([]*ring.Poly, ekg.context.params.Beta())
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
-		r1[i] = ekg.context.contextQP.NewPoly()
-		r2[i][0] = ekg.context.contextQP.NewPoly()
-		r2[i][1] = ekg.context.contextQP.NewPoly()
-		r3[i] = ekg.context.contextQP.NewPoly()
+	r1 = make([]*ring.Poly, ekg.gadget.size)
+	r2 = make([][2]*ring.Poly, ekg.gadget.size)
+	r3 = make([]*ring.Poly, ekg.gadget.size)
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		r1[i] = ekg.gadget.contextKeys.NewPoly()
+		r2[i][0] = ekg.gadget.contextKeys.NewPoly()
+		r2[i][1] = ekg.gadget.contextKeys.NewPoly()
+		r3[i] = ekg.gadget.contextKeys.NewPoly()
 	}
 
 	return
 }
 
+// TryNewEkgProtocol is the same as NewEkgProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewEkgProtocol(params *bfv.Parameters) (*RKGProtocol, error) {
+
+	if err := checkKeySwitching("NewEkgProtocol", params, 0); err != nil {
+		return nil, err
+	}
+
+	return NewEkgProtocol(params), nil
+}
+
 // NewEkgProtocol creates a new RKGProtocol object that will be used to generate a collective evaluation-key
 // among j parties in the given context with the given bit-decomposition.
 func NewEkgProtocol(params *bfv.Parameters) *RKGProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewEkgProtocol : params not valid (check if they where generated properly)")
+	if err := checkKeySwitching("NewEkgProtocol", params, 0); err != nil {
+		panic(err)
 	}
 
+	return newEkgProtocol(params, 0)
+}
+
+// TryNewEkgProtocolGadget is the same as NewEkgProtocolGadget, but returns an error instead of panicking if the parameters are invalid.
+func TryNewEkgProtocolGadget(params *bfv.Parameters, logBase uint64) (*RKGProtocol, error) {
+
+	if err := checkKeySwitching("NewEkgProtocolGadget", params, logBase); err != nil {
+		return nil, err
+	}
+
+	return NewEkgProtocolGadget(params, logBase), nil
+}
+
+// NewEkgProtocolGadget creates a new RKGProtocol object that will be used to generate a collective evaluation-key using
+// the gadget decomposition of base 2^logBase (see bfv.GenRelinKeyGadget), which does not require the special primes Pi.
+// The protocol takes len(params.Qi)*params.GadgetDigits(logBase) CRPs, and its output must be allocated with bfv.NewRelinKeyGadget.
+func NewEkgProtocolGadget(params *bfv.Parameters, logBase uint64) *RKGProtocol {
+
+	if err := checkKeySwitching("NewEkgProtocolGadget", params, logBase); err != nil {
+		panic(err)
+	}
+
+	return newEkgProtocol(params, logBase)
+}
+
+func newEkgProtocol(params *bfv.Parameters, logBase uint64) *RKGProtocol {
+
 	context := newDbfvContext(params)
 
 	ekg := new(RKGProtocol)
 	ekg.context = context
+	ekg.gadget = newKeySwitchingGadget(context, logBase)
 
 	ekg.tmpPoly1 = ekg.context.contextQP.NewPoly()
 	ekg.tmpPoly2 = ekg.context.contextQP.NewPoly()
@@ -206,7 +194,7 @@ func NewEkgProtocol(params *bfv.Parameters) *RKGProtocol {
 // Each party is required to pre-compute a secret additional ephemeral key in addition to its share
 // of the collective secret-key.
 func (ekg *RKGProtocol) NewEphemeralKey(p float64) (ephemeralKey *ring.Poly) {
-	return ekg.context.contextQP.SampleTernaryMontgomeryNTTNew(p)
+	return ekg.gadget.contextKeys.SampleTernaryMontgomeryNTTNew(p)
 }
 
 // GenShareRoundOne is the first of three rounds of the RKGProtocol protocol. Each party generates a pseudo encryption of
@@ -214,43 +202,22 @@ func (ekg *RKGProtocol) NewEphemeralKey(p float64) (ephemeralKey *ring.Poly) {
 // j-1 parties.
 func (ekg *RKGProtocol) GenShareRoundOne(u, sk *ring.Poly, crp []*ring.Poly, shareOut RKGShareRoundOne) {
 
-	var index uint64
-
-	// Given a base decomposition w_i (here the CRT decomposition)
-	// computes [-u*a_i + P*s_i + e_i]
+	// Given a base decomposition w_i (the CRT decomposition, or the CRT decomposition combined with
+	// a decomposition in base 2^logBase) computes [-u*a_i + s*w_i + e_i]
 	// where a_i = crp_i
 
-	ekg.polypool.Copy(sk)
-
-	ekg.context.contextQP.MulScalarBigint(ekg.polypool, ekg.context.contextP.ModulusBigint, ekg.polypool)
+	ekg.gadget.scaleSecret(sk, ekg.polypool)
 
-	ekg.context.contextQP.InvMForm(ekg.polypool, ekg.polypool)
-
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// h = e
-		ekg.context.gaussianSampler.SampleNTT(shareOut[i])
-
-		// h = sk*CrtBaseDecompQi + e
-		for j := uint64(0); j < ekg.context.params.Alpha(); j++ {
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i])
 
-			index = i*ekg.context.params.Alpha() + j
-			qi := ekg.context.contextQP.Modulus[index]
-			tmp0 := ekg.polypool.Coeffs[index]
-			tmp1 := shareOut[i].Coeffs[index]
-
-			for w := uint64(0); w < ekg.context.contextQP.N; w++ {
-				tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
-			}
-
-			// Handles the case where nb pj does not divides nb qi
-			if index == uint64(len(ekg.context.params.LogQi)-1) {
-				break
-			}
-		}
+		// h = sk*w_i + e
+		ekg.gadget.addElement(i, ekg.polypool, shareOut[i])
 
 		// h = sk*CrtBaseDecompQi + -u*a + e
-		ekg.context.contextQP.MulCoeffsMontgomeryAndSub(u, crp[i], shareOut[i])
+		ekg.gadget.contextKeys.MulCoeffsMontgomeryAndSub(u, crp[i], shareOut[i])
 	}
 
 	ekg.polypool.Zero()
@@ -261,8 +228,8 @@ func (ekg *RKGProtocol) GenShareRoundOne(u, sk *ring.Poly, crp []*ring.Poly, sha
 // AggregateShareRoundOne adds share1 and share2 on shareOut.
 func (ekg *RKGProtocol) AggregateShareRoundOne(share1, share2, shareOut RKGShareRoundOne) {
 
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
-		ekg.context.contextQP.Add(share1[i], share2[i], shareOut[i])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		ekg.gadget.contextKeys.Add(share1[i], share2[i], shareOut[i])
 	}
 
 }
@@ -278,22 +245,22 @@ func (ekg *RKGProtocol) GenShareRoundTwo(round1 RKGShareRoundOne, sk *ring.Poly,
 
 	// Each sample is of the form [-u*a_i + s*w_i + e_i]
 	// So for each element of the base decomposition w_i :
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// Computes [(sum samples)*sk + e_1i, sk*a + e_2i]
 
 		// (AggregateShareRoundTwo samples) * sk
-		ekg.context.contextQP.MulCoeffsMontgomery(round1[i], sk, shareOut[i][0])
+		ekg.gadget.contextKeys.MulCoeffsMontgomery(round1[i], sk, shareOut[i][0])
 
 		// (AggregateShareRoundTwo samples) * sk + e_1i
-		ekg.context.gaussianSampler.SampleNTT(ekg.tmpPoly1)
-		ekg.context.contextQP.Add(shareOut[i][0], ekg.tmpPoly1, shareOut[i][0])
+		ekg.gadget.gaussianSampler.SampleNTT(ekg.tmpPoly1)
+		ekg.gadget.contextKeys.Add(shareOut[i][0], ekg.tmpPoly1, shareOut[i][0])
 
 		// Second Element
 		// e_2i
-		ekg.context.gaussianSampler.SampleNTT(shareOut[i][1])
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i][1])
 		// s*a + e_2i
-		ekg.context.contextQP.MulCoeffsMontgomeryAndAdd(sk, crp[i], shareOut[i][1])
+		ekg.gadget.contextKeys.MulCoeffsMontgomeryAndAdd(sk, crp[i], shareOut[i][1])
 	}
 
 }
@@ -306,9 +273,9 @@ func (ekg *RKGProtocol) GenShareRoundTwo(round1 RKGShareRoundOne, sk *ring.Poly,
 // = [s * (-u*a + s*w + e) + e_1, s*a + e_2].
 func (ekg *RKGProtocol) AggregateShareRoundTwo(share1, share2, shareOut RKGShareRoundTwo) {
 
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
-		ekg.context.contextQP.Add(share1[i][0], share2[i][0], shareOut[i][0])
-		ekg.context.contextQP.Add(share1[i][1], share2[i][1], shareOut[i][1])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		ekg.gadget.contextKeys.Add(share1[i][0], share2[i][0], shareOut[i][0])
+		ekg.gadget.contextKeys.Add(share1[i][1], share2[i][1], shareOut[i][1])
 	}
 
 }
@@ -322,20 +289,20 @@ func (ekg *RKGProtocol) AggregateShareRoundTwo(share1, share2, shareOut RKGShare
 func (ekg *RKGProtocol) GenShareRoundThree(round2 RKGShareRoundTwo, u, sk *ring.Poly, shareOut RKGShareRoundThree) {
 
 	// (u_i - s_i)
-	ekg.context.contextQP.Sub(u, sk, ekg.tmpPoly1)
+	ekg.gadget.contextKeys.Sub(u, sk, ekg.tmpPoly1)
 
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// (u - s) * (sum [x][s*a_i + e_2i]) + e3i
-		ekg.context.gaussianSampler.SampleNTT(shareOut[i])
-		ekg.context.contextQP.MulCoeffsMontgomeryAndAdd(ekg.tmpPoly1, round2[i][1], shareOut[i])
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i])
+		ekg.gadget.contextKeys.MulCoeffsMontgomeryAndAdd(ekg.tmpPoly1, round2[i][1], shareOut[i])
 	}
 }
 
 // AggregateShareRoundThree adds share1 and share2 on shareOut.
 func (ekg *RKGProtocol) AggregateShareRoundThree(share1, share2, shareOut RKGShareRoundThree) {
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
-		ekg.context.contextQP.Add(share1[i], share2[i], shareOut[i])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		ekg.gadget.contextKeys.Add(share1[i], share2[i], shareOut[i])
 	}
 }
 
@@ -343,13 +310,13 @@ func (ekg *RKGProtocol) AggregateShareRoundThree(share1, share2, shareOut RKGSha
 func (ekg *RKGProtocol) GenRelinearizationKey(round2 RKGShareRoundTwo, round3 RKGShareRoundThree, evalKeyOut *bfv.EvaluationKey) {
 
 	key := evalKeyOut.Get()[0].Get()
-	for i := uint64(0); i < ekg.context.params.Beta(); i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
-		ekg.context.contextQP.Add(round2[i][0], round3[i], key[i][0])
+		ekg.gadget.contextKeys.Add(round2[i][0], round3[i], key[i][0])
 		key[i][1].Copy(round2[i][1])
 
-		ekg.context.contextQP.MForm(key[i][0], key[i][0])
-		ekg.context.contextQP.MForm(key[i][1], key[i][1])
+		ekg.gadget.contextKeys.MForm(key[i][0], key[i][0])
+		ekg.gadget.contextKeys.MForm(key[i][1], key[i][1])
 
 	}
 }
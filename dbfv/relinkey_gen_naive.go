@@ -11,12 +11,22 @@ type RKGProtocolNaive struct {
 	polypool *ring.Poly
 }
 
+// TryNewRKGProtocolNaive is the same as NewRKGProtocolNaive, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRKGProtocolNaive(params *bfv.Parameters) (*RKGProtocolNaive, error) {
+
+	if err := checkParameters("NewRKGProtocolNaive", params); err != nil {
+		return nil, err
+	}
+
+	return NewRKGProtocolNaive(params), nil
+}
+
 // NewRKGProtocolNaive creates a new RKGProtocolNaive object that will be used to generate a collective evaluation-key
 // among j parties in the given context with the given bit-decomposition.
 func NewRKGProtocolNaive(params *bfv.Parameters) (rkg *RKGProtocolNaive) {
 
-	if !params.IsValid() {
-		panic("cannot NewRKGProtocolNaive : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewRKGProtocolNaive", params); err != nil {
+		panic(err)
 	}
 
 	context := newDbfvContext(params)
@@ -32,6 +42,28 @@ type RKGNaiveShareRoundOne [][2]*ring.Poly
 // RKGNaiveShareRoundTwo is a struct holding the round two shares of the RKG Naive protocol.
 type RKGNaiveShareRoundTwo [][2]*ring.Poly
 
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *RKGNaiveShareRoundOne) MarshalBinary() ([]byte, error) {
+	return marshalPolyPairs(*share)
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RKGNaiveShareRoundOne) UnmarshalBinary(data []byte) (err error) {
+	*share, err = unmarshalPolyPairs(data)
+	return err
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *RKGNaiveShareRoundTwo) MarshalBinary() ([]byte, error) {
+	return marshalPolyPairs(*share)
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RKGNaiveShareRoundTwo) UnmarshalBinary(data []byte) (err error) {
+	*share, err = unmarshalPolyPairs(data)
+	return err
+}
+
 // AllocateShares shares allocates the shares of the RKG Naive protocol
 func (rkg *RKGProtocolNaive) AllocateShares() (r1 RKGNaiveShareRoundOne, r2 RKGNaiveShareRoundTwo) {
 	contextKeys := rkg.context.contextQP
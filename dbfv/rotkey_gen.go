@@ -10,6 +10,7 @@ import (
 // RTGProtocol is the structure storing the parameters for the collective rotation-keys generation.
 type RTGProtocol struct {
 	context *dbfvContext
+	gadget  *keySwitchingGadget
 
 	galElRotRow uint64
 	galElRotCol map[bfv.Rotation][]uint64
@@ -71,29 +72,67 @@ func (share *RTGShare) UnmarshalBinary(data []byte) error {
 
 // AllocateShare allocates the shares of the RTG protocol.
 func (rtg *RTGProtocol) AllocateShare() (rtgShare RTGShare) {
-	rtgShare.Value = make([]*ring.Poly, rtg.context.params.Beta())
-	for i := uint64(0); i < rtg.context.params.Beta(); i++ {
-		rtgShare.Value[i] = rtg.context.contextQP.NewPoly()
+	rtgShare.Value = make([]*ring.Poly, rtg.gadget.size)
+	for i := uint64(0); i < rtg.gadget.size; i++ {
+		rtgShare.Value[i] = rtg.gadget.contextKeys.NewPoly()
 	}
 	return
 }
 
+// TryNewRotKGProtocol is the same as NewRotKGProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRotKGProtocol(params *bfv.Parameters) (*RTGProtocol, error) {
+
+	if err := checkKeySwitching("NewRotKGProtocol", params, 0); err != nil {
+		return nil, err
+	}
+
+	return NewRotKGProtocol(params), nil
+}
+
 // NewRotKGProtocol creates a new rotkg object and will be used to generate collective rotation-keys from a shared secret-key among j parties.
 func NewRotKGProtocol(params *bfv.Parameters) (rtg *RTGProtocol) {
 
-	if !params.IsValid() {
-		panic("cannot NewRotKGProtocol : params not valid (check if they where generated properly)")
+	if err := checkKeySwitching("NewRotKGProtocol", params, 0); err != nil {
+		panic(err)
+	}
+
+	return newRotKGProtocol(params, 0)
+}
+
+// TryNewRotKGProtocolGadget is the same as NewRotKGProtocolGadget, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRotKGProtocolGadget(params *bfv.Parameters, logBase uint64) (*RTGProtocol, error) {
+
+	if err := checkKeySwitching("NewRotKGProtocolGadget", params, logBase); err != nil {
+		return nil, err
+	}
+
+	return NewRotKGProtocolGadget(params, logBase), nil
+}
+
+// NewRotKGProtocolGadget creates a new rotkg object that will be used to generate collective rotation-keys using the gadget
+// decomposition of base 2^logBase (see bfv.GenRotGadget), which does not require the special primes Pi.
+// The protocol takes len(params.Qi)*params.GadgetDigits(logBase) CRPs.
+func NewRotKGProtocolGadget(params *bfv.Parameters, logBase uint64) (rtg *RTGProtocol) {
+
+	if err := checkKeySwitching("NewRotKGProtocolGadget", params, logBase); err != nil {
+		panic(err)
 	}
 
+	return newRotKGProtocol(params, logBase)
+}
+
+func newRotKGProtocol(params *bfv.Parameters, logBase uint64) (rtg *RTGProtocol) {
+
 	context := newDbfvContext(params)
 
 	rtg = new(RTGProtocol)
 	rtg.context = context
+	rtg.gadget = newKeySwitchingGadget(context, logBase)
 
-	rtg.tmpSwitchKey = make([][2]*ring.Poly, rtg.context.params.Beta())
+	rtg.tmpSwitchKey = make([][2]*ring.Poly, rtg.gadget.size)
 	for i := range rtg.tmpSwitchKey {
-		rtg.tmpSwitchKey[i][0] = context.contextQP.NewPoly()
-		rtg.tmpSwitchKey[i][1] = context.contextQP.NewPoly()
+		rtg.tmpSwitchKey[i][0] = rtg.gadget.contextKeys.NewPoly()
+		rtg.tmpSwitchKey[i][1] = rtg.gadget.contextKeys.NewPoly()
 	}
 
 	rtg.tmpPoly = context.contextQP.NewPoly()
@@ -138,41 +177,22 @@ func (rtg *RTGProtocol) GenShare(rotType bfv.Rotation, k uint64, sk *ring.Poly,
 
 func (rtg *RTGProtocol) genShare(sk *ring.Poly, galEl uint64, crp []*ring.Poly, evakey []*ring.Poly) {
 
-	contextKeys := rtg.context.contextQP
+	contextKeys := rtg.gadget.contextKeys
 
 	ring.PermuteNTT(sk, galEl, rtg.tmpPoly)
 
-	contextKeys.MulScalarBigint(rtg.tmpPoly, rtg.context.contextP.ModulusBigint, rtg.tmpPoly)
-	contextKeys.InvMForm(rtg.tmpPoly, rtg.tmpPoly)
+	rtg.gadget.scaleSecret(rtg.tmpPoly, rtg.tmpPoly)
 
-	var index uint64
-
-	for i := uint64(0); i < rtg.context.params.Beta(); i++ {
+	for i := uint64(0); i < rtg.gadget.size; i++ {
 
 		// e
-		evakey[i] = rtg.context.gaussianSampler.SampleNTTNew()
+		evakey[i] = rtg.gadget.gaussianSampler.SampleNTTNew()
 
 		// a is the CRP
 
 		// e + sk_in * (qiBarre*qiStar) * 2^w
 		// (qiBarre*qiStar)%qi = 1, else 0
-		for j := uint64(0); j < rtg.context.params.Alpha(); j++ {
-
-			index = i*rtg.context.params.Alpha() + j
-
-			qi := contextKeys.Modulus[index]
-			tmp0 := rtg.tmpPoly.Coeffs[index]
-			tmp1 := evakey[i].Coeffs[index]
-
-			for w := uint64(0); w < contextKeys.N; w++ {
-				tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
-			}
-
-			// Handles the case where nb pj does not divides nb qi
-			if index >= uint64(len(rtg.context.contextQ.Modulus)-1) {
-				break
-			}
-		}
+		rtg.gadget.addElement(i, rtg.tmpPoly, evakey[i])
 
 		// sk_in * (qiBarre*qiStar) * 2^w - a*sk + e
 		contextKeys.MulCoeffsMontgomeryAndSub(crp[i], sk, evakey[i])
@@ -188,7 +208,7 @@ func (rtg *RTGProtocol) genShare(sk *ring.Poly, galEl uint64, crp []*ring.Poly,
 //
 // [sum(a*a_j + (pi(a_j) - a_j) + e_j), a]
 func (rtg *RTGProtocol) Aggregate(share1, share2, shareOut RTGShare) {
-	contextKeys := rtg.context.contextQP
+	contextKeys := rtg.gadget.contextKeys
 
 	if share1.Type != share2.Type || share1.K != share2.K {
 		panic("cannot aggregate shares of different types")
@@ -196,7 +216,7 @@ func (rtg *RTGProtocol) Aggregate(share1, share2, shareOut RTGShare) {
 
 	shareOut.Type = share1.Type
 	shareOut.K = share1.K
-	for i := uint64(0); i < rtg.context.params.Beta(); i++ {
+	for i := uint64(0); i < rtg.gadget.size; i++ {
 		contextKeys.Add(share1.Value[i], share2.Value[i], shareOut.Value[i])
 	}
 }
@@ -206,10 +226,10 @@ func (rtg *RTGProtocol) Finalize(share RTGShare, crp []*ring.Poly, rotKey *bfv.R
 
 	k := share.K & ((rtg.context.n >> 1) - 1)
 
-	for i := uint64(0); i < rtg.context.params.Beta(); i++ {
+	for i := uint64(0); i < rtg.gadget.size; i++ {
 		rtg.tmpSwitchKey[i][0].Copy(share.Value[i])
-		rtg.context.contextQP.MForm(crp[i], rtg.tmpSwitchKey[i][1])
+		rtg.gadget.contextKeys.MForm(crp[i], rtg.tmpSwitchKey[i][1])
 	}
 
-	rotKey.SetRotKey(share.Type, k, rtg.tmpSwitchKey)
+	rotKey.SetRotKeyGadget(share.Type, k, rtg.gadget.logBase, rtg.tmpSwitchKey)
 }
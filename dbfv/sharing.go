@@ -0,0 +1,232 @@
+package dbfv
+
+import (
+	"math/big"
+
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/ring"
+)
+
+// AdditiveShare is a struct holding an additive secret-share of a plaintext, as a polynomial of R_t in the
+// coefficient domain. The plaintext is the sum modulo t of the additive shares of all the parties.
+type AdditiveShare struct {
+	*ring.Poly
+}
+
+// UnmarshalBinary decodes a marshaled additive share on the target additive share.
+func (share *AdditiveShare) UnmarshalBinary(data []byte) error {
+	if share.Poly == nil {
+		share.Poly = new(ring.Poly)
+	}
+	return share.Poly.UnmarshalBinary(data)
+}
+
+// E2SProtocol is the structure storing the parameters and temporary buffers for the encryption-to-shares protocol,
+// which turns a ciphertext encrypted under the collective secret-key into additive secret-shares of its plaintext.
+//
+// Each party i samples a uniform mask M_i in R_t, which is its additive share, and computes the public share
+// h_i = s_i*ct[1] + e_i - Delta*M_i, where e_i is a flooding noise. The party receiving the aggregated public share
+// decrypts ct[0] + sum h_i = Delta*(m - sum M_i) + e and adds its own mask, so that the additive shares of the
+// parties sum to m.
+type E2SProtocol struct {
+	context *dbfvContext
+
+	floodingBound *big.Int
+
+	tmp    *ring.Poly
+	scaler *ring.SimpleScaler
+}
+
+// TryNewE2SProtocol is the same as NewE2SProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewE2SProtocol(params *bfv.Parameters, noiseBound float64, lambda uint64) (*E2SProtocol, error) {
+
+	if err := checkFlooding("NewE2SProtocol", params, noiseBound, lambda); err != nil {
+		return nil, err
+	}
+
+	return NewE2SProtocol(params, noiseBound, lambda), nil
+}
+
+// NewE2SProtocol creates a new E2SProtocol instance. The public shares are flooded with a noise whose coefficients are
+// uniformly distributed in [-B, B], with B = 2^lambda * noiseBound, so that a decryption noise whose coefficients are
+// at most noiseBound is hidden with a statistical distance of at most 2^-lambda per coefficient (N * 2^-lambda for the
+// whole polynomial). The decryption noise of a ciphertext depends on the circuit that produced it, and thus noiseBound
+// must bound the noise of all the ciphertexts on which the protocol is run; it cannot be smaller than the standard
+// deviation of the encryption noise. The flooding noise is sampled as a big integer reduced modulo each Qi, so that B
+// is only limited by the modulus Q: B must be smaller than Q/(2T), and the sum of the flooding noises of all the parties
+// must stay below Q/(2T) for the additive shares to be correct.
+func NewE2SProtocol(params *bfv.Parameters, noiseBound float64, lambda uint64) *E2SProtocol {
+
+	if err := checkFlooding("NewE2SProtocol", params, noiseBound, lambda); err != nil {
+		panic(err)
+	}
+
+	context := newDbfvContext(params)
+
+	e2s := new(E2SProtocol)
+	e2s.context = context
+	e2s.floodingBound = floodingBound(noiseBound, lambda)
+	e2s.tmp = context.contextQ.NewPoly()
+	e2s.scaler = ring.NewSimpleScaler(params.T, context.contextQ)
+
+	return e2s
+}
+
+// AllocateShares allocates the additive share and the public share of the E2S protocol.
+func (e2s *E2SProtocol) AllocateShares() (AdditiveShare, CKSShare) {
+	return AdditiveShare{e2s.context.contextT.NewPoly()}, CKSShare{e2s.context.contextQ.NewPoly()}
+}
+
+// GenShare generates the additive share of the party on secretShareOut, and its public share
+//
+// [s_i * ct[1] + e_i - Delta * M_i]
+//
+// on publicShareOut. The public shares of all the parties must be aggregated and sent to the party receiving the
+// last additive share. The ciphertext must be at the maximum level.
+func (e2s *E2SProtocol) GenShare(sk *ring.Poly, ct *bfv.Ciphertext, secretShareOut AdditiveShare, publicShareOut CKSShare) {
+	if err := e2s.TryGenShare(sk, ct, secretShareOut, publicShareOut); err != nil {
+		panic(err)
+	}
+}
+
+// TryGenShare is the same as GenShare, but returns an error instead of panicking if the ciphertext is nil or is not at
+// the maximum level.
+func (e2s *E2SProtocol) TryGenShare(sk *ring.Poly, ct *bfv.Ciphertext, secretShareOut AdditiveShare, publicShareOut CKSShare) error {
+
+	if err := checkCiphertextLevel("GenShare", ct, e2s.context.params.MaxLevel()); err != nil {
+		return err
+	}
+
+	contextQ := e2s.context.contextQ
+
+	// h = s*ct[1]
+	contextQ.NTT(ct.Value()[1], e2s.tmp)
+	contextQ.MulCoeffsMontgomery(e2s.tmp, sk, publicShareOut.Poly)
+	contextQ.InvNTT(publicShareOut.Poly, publicShareOut.Poly)
+
+	// h = s*ct[1] + e
+	contextQ.SampleUniformBigAndAddLvl(e2s.context.params.MaxLevel(), publicShareOut.Poly, e2s.floodingBound)
+
+	// M = uniform plaintext in [0, T-1]
+	e2s.context.contextT.UniformPoly(secretShareOut.Poly)
+
+	// h = s*ct[1] + e - Delta*M
+	lift(secretShareOut.Poly, e2s.tmp, e2s.context)
+	contextQ.Sub(publicShareOut.Poly, e2s.tmp, publicShareOut.Poly)
+
+	return nil
+}
+
+// AggregateShares sums the public shares share1 and share2 on shareOut.
+func (e2s *E2SProtocol) AggregateShares(share1, share2, shareOut CKSShare) {
+	e2s.context.contextQ.Add(share1.Poly, share2.Poly, shareOut.Poly)
+}
+
+// GetShare decrypts the ciphertext with the aggregated public share of all the parties (including the target
+// party), and adds the result to the additive share secretShare of the target party, which gives its final
+// additive share on secretShareOut. The ciphertext must be at the maximum level.
+func (e2s *E2SProtocol) GetShare(secretShare AdditiveShare, aggregatePublicShare CKSShare, ct *bfv.Ciphertext, secretShareOut AdditiveShare) {
+	if err := e2s.TryGetShare(secretShare, aggregatePublicShare, ct, secretShareOut); err != nil {
+		panic(err)
+	}
+}
+
+// TryGetShare is the same as GetShare, but returns an error instead of panicking if the ciphertext is nil or is not at
+// the maximum level.
+func (e2s *E2SProtocol) TryGetShare(secretShare AdditiveShare, aggregatePublicShare CKSShare, ct *bfv.Ciphertext, secretShareOut AdditiveShare) error {
+
+	if err := checkCiphertextLevel("GetShare", ct, e2s.context.params.MaxLevel()); err != nil {
+		return err
+	}
+
+	// Delta*(m - sum M_i) + e
+	e2s.context.contextQ.Add(ct.Value()[0], aggregatePublicShare.Poly, e2s.tmp)
+
+	// m - sum M_i
+	e2s.scaler.Scale(e2s.tmp, e2s.tmp)
+
+	e2s.context.contextT.Add(e2s.tmp, secretShare.Poly, secretShareOut.Poly)
+
+	return nil
+}
+
+// S2EProtocol is the structure storing the parameters and temporary buffers for the shares-to-encryption protocol,
+// which turns additive secret-shares of a plaintext into a ciphertext encrypting it under the collective
+// secret-key.
+//
+// Given a common reference polynomial a, each party i computes the share h_i = -s_i*a + Delta*M_i + e_i from its
+// additive share M_i, and the ciphertext (sum h_i, a) decrypts to Delta*m + e.
+type S2EProtocol struct {
+	context *dbfvContext
+
+	sigmaSmudging float64
+
+	tmp *ring.Poly
+}
+
+// TryNewS2EProtocol is the same as NewS2EProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewS2EProtocol(params *bfv.Parameters, sigmaSmudging float64) (*S2EProtocol, error) {
+
+	if err := checkSmudging("NewS2EProtocol", params, sigmaSmudging); err != nil {
+		return nil, err
+	}
+
+	return NewS2EProtocol(params, sigmaSmudging), nil
+}
+
+// NewS2EProtocol creates a new S2EProtocol instance. The shares are smudged with a gaussian noise of standard
+// deviation sigmaSmudging, which must be at least the standard deviation of the encryption noise.
+func NewS2EProtocol(params *bfv.Parameters, sigmaSmudging float64) *S2EProtocol {
+
+	if err := checkSmudging("NewS2EProtocol", params, sigmaSmudging); err != nil {
+		panic(err)
+	}
+
+	context := newDbfvContext(params)
+
+	s2e := new(S2EProtocol)
+	s2e.context = context
+	s2e.sigmaSmudging = sigmaSmudging
+	s2e.tmp = context.contextQ.NewPoly()
+
+	return s2e
+}
+
+// AllocateShare allocates the share of the S2E protocol.
+func (s2e *S2EProtocol) AllocateShare() CKSShare {
+	return CKSShare{s2e.context.contextQ.NewPoly()}
+}
+
+// GenShare generates the share
+//
+// [-s_i * crs + Delta * M_i + e_i]
+//
+// of the party from its additive share M_i, where crs is a uniform polynomial of R_Q common to all the parties.
+func (s2e *S2EProtocol) GenShare(sk *ring.Poly, crs *ring.Poly, secretShare AdditiveShare, shareOut CKSShare) {
+
+	contextQ := s2e.context.contextQ
+
+	// h = -s*crs
+	contextQ.NTT(crs, s2e.tmp)
+	contextQ.MulCoeffsMontgomery(s2e.tmp, sk, shareOut.Poly)
+	contextQ.Neg(shareOut.Poly, shareOut.Poly)
+	contextQ.InvNTT(shareOut.Poly, shareOut.Poly)
+
+	// h = -s*crs + e
+	contextQ.SampleGaussianAndAdd(shareOut.Poly, s2e.sigmaSmudging, uint64(6*s2e.sigmaSmudging))
+
+	// h = -s*crs + Delta*M + e
+	lift(secretShare.Poly, s2e.tmp, s2e.context)
+	contextQ.Add(shareOut.Poly, s2e.tmp, shareOut.Poly)
+}
+
+// AggregateShares sums share1 and share2 on shareOut.
+func (s2e *S2EProtocol) AggregateShares(share1, share2, shareOut CKSShare) {
+	s2e.context.contextQ.Add(share1.Poly, share2.Poly, shareOut.Poly)
+}
+
+// GetEncryption sets ctOut to the ciphertext (sum h_i, crs) given the aggregated share of all the parties.
+func (s2e *S2EProtocol) GetEncryption(aggregateShare CKSShare, crs *ring.Poly, ctOut *bfv.Ciphertext) {
+	s2e.context.contextQ.Copy(aggregateShare.Poly, ctOut.Value()[0])
+	s2e.context.contextQ.Copy(crs, ctOut.Value()[1])
+}
@@ -0,0 +1,159 @@
+package dckks
+
+import (
+	"github.com/ldsec/lattigo/utils"
+)
+
+// The shares of all the protocols can be marshaled to be sent between the parties, and the shares bound to their
+// protocol can be aggregated by a utils.AggregationTree.
+var (
+	_ utils.BinaryShare = (*CKGShare)(nil)
+	_ utils.BinaryShare = (*CKSShare)(nil)
+	_ utils.BinaryShare = (*PCKSShare)(nil)
+	_ utils.BinaryShare = (*RefreshShareDecrypt)(nil)
+	_ utils.BinaryShare = (*RefreshShareRecrypt)(nil)
+	_ utils.BinaryShare = (*RKGShareRoundOne)(nil)
+	_ utils.BinaryShare = (*RKGShareRoundTwo)(nil)
+	_ utils.BinaryShare = (*RKGShareRoundThree)(nil)
+	_ utils.BinaryShare = (*RKGNaiveShareRoundOne)(nil)
+	_ utils.BinaryShare = (*RKGNaiveShareRoundTwo)(nil)
+	_ utils.BinaryShare = (*RTGShare)(nil)
+	_ utils.BinaryShare = (*AdditiveShare)(nil)
+	_ utils.BinaryShare = (*ShamirShare)(nil)
+	_ utils.Share       = (*boundCKGShare)(nil)
+	_ utils.Share       = (*boundCKSShare)(nil)
+	_ utils.Share       = (*boundPCKSShare)(nil)
+	_ utils.Share       = (*boundRTGShare)(nil)
+)
+
+// boundCKGShare is a CKGShare bound to its CKGProtocol, which implements the utils.Share interface.
+type boundCKGShare struct {
+	ckg   *CKGProtocol
+	share CKGShare
+}
+
+// BindShare returns the share as a utils.Share aggregated by the target CKGProtocol, so that the shares of the
+// parties can be aggregated by a utils.AggregationTree.
+func (ckg *CKGProtocol) BindShare(share CKGShare) utils.Share {
+	return &boundCKGShare{ckg: ckg, share: share}
+}
+
+// Allocate allocates a new CKGShare bound to the same CKGProtocol.
+func (s *boundCKGShare) Allocate() utils.Share {
+	return s.ckg.BindShare(s.ckg.AllocateShares())
+}
+
+// Aggregate aggregates share1 and share2 on the target share.
+func (s *boundCKGShare) Aggregate(share1, share2 utils.Share) {
+	s.ckg.AggregateShares(share1.(*boundCKGShare).share, share2.(*boundCKGShare).share, s.share)
+}
+
+// MarshalBinary encodes the target share on a slice of bytes.
+func (s *boundCKGShare) MarshalBinary() ([]byte, error) {
+	return s.share.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target share.
+func (s *boundCKGShare) UnmarshalBinary(data []byte) error {
+	return (&s.share).UnmarshalBinary(data)
+}
+
+// boundCKSShare is a CKSShare bound to its CKSProtocol, which implements the utils.Share interface.
+type boundCKSShare struct {
+	cks   *CKSProtocol
+	share CKSShare
+}
+
+// BindShare returns the share as a utils.Share aggregated by the target CKSProtocol, so that the shares of the
+// parties can be aggregated by a utils.AggregationTree.
+func (cks *CKSProtocol) BindShare(share CKSShare) utils.Share {
+	return &boundCKSShare{cks: cks, share: share}
+}
+
+// Allocate allocates a new CKSShare, at the level of the target share, bound to the same CKSProtocol.
+func (s *boundCKSShare) Allocate() utils.Share {
+	return s.cks.BindShare(CKSShare{s.cks.dckksContext.contextQ.NewPolyLvl(s.share.Level())})
+}
+
+// Aggregate aggregates share1 and share2 on the target share.
+func (s *boundCKSShare) Aggregate(share1, share2 utils.Share) {
+	s.cks.AggregateShares(share1.(*boundCKSShare).share, share2.(*boundCKSShare).share, s.share)
+}
+
+// MarshalBinary encodes the target share on a slice of bytes.
+func (s *boundCKSShare) MarshalBinary() ([]byte, error) {
+	return s.share.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target share.
+func (s *boundCKSShare) UnmarshalBinary(data []byte) error {
+	return (&s.share).UnmarshalBinary(data)
+}
+
+// boundPCKSShare is a PCKSShare bound to its PCKSProtocol, which implements the utils.Share interface.
+type boundPCKSShare struct {
+	pcks  *PCKSProtocol
+	share PCKSShare
+}
+
+// BindShare returns the share as a utils.Share aggregated by the target PCKSProtocol, so that the shares of the
+// parties can be aggregated by a utils.AggregationTree.
+func (pcks *PCKSProtocol) BindShare(share PCKSShare) utils.Share {
+	return &boundPCKSShare{pcks: pcks, share: share}
+}
+
+// Allocate allocates a new PCKSShare, at the level of the target share, bound to the same PCKSProtocol.
+func (s *boundPCKSShare) Allocate() utils.Share {
+	return s.pcks.BindShare(s.pcks.AllocateShares(s.share.Level()))
+}
+
+// Aggregate aggregates share1 and share2 on the target share.
+func (s *boundPCKSShare) Aggregate(share1, share2 utils.Share) {
+	s.pcks.AggregateShares(share1.(*boundPCKSShare).share, share2.(*boundPCKSShare).share, s.share)
+}
+
+// MarshalBinary encodes the target share on a slice of bytes.
+func (s *boundPCKSShare) MarshalBinary() ([]byte, error) {
+	return s.share.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target share.
+func (s *boundPCKSShare) UnmarshalBinary(data []byte) error {
+	return (&s.share).UnmarshalBinary(data)
+}
+
+// boundRTGShare is a RTGShare bound to its RTGProtocol, which implements the utils.Share interface.
+type boundRTGShare struct {
+	rtg   *RTGProtocol
+	share *RTGShare
+}
+
+// BindShare returns the share as a utils.Share aggregated by the target RTGProtocol, so that the shares of the
+// parties can be aggregated by a utils.AggregationTree.
+func (rtg *RTGProtocol) BindShare(share *RTGShare) utils.Share {
+	return &boundRTGShare{rtg: rtg, share: share}
+}
+
+// Allocate allocates a new RTGShare, for the same rotation as the target share, bound to the same RTGProtocol.
+func (s *boundRTGShare) Allocate() utils.Share {
+	share := s.rtg.AllocateShare()
+	share.Type, share.K = s.share.Type, s.share.K
+	return s.rtg.BindShare(&share)
+}
+
+// Aggregate aggregates share1 and share2 on the target share.
+func (s *boundRTGShare) Aggregate(share1, share2 utils.Share) {
+	s1, s2 := share1.(*boundRTGShare).share, share2.(*boundRTGShare).share
+	s.rtg.Aggregate(*s1, *s2, *s.share)
+	s.share.Type, s.share.K = s1.Type, s1.K
+}
+
+// MarshalBinary encodes the target share on a slice of bytes.
+func (s *boundRTGShare) MarshalBinary() ([]byte, error) {
+	return s.share.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target share.
+func (s *boundRTGShare) UnmarshalBinary(data []byte) error {
+	return s.share.UnmarshalBinary(data)
+}
@@ -3,7 +3,7 @@ package dckks
 import (
 	"github.com/ldsec/lattigo/ckks"
 	"github.com/ldsec/lattigo/ring"
-	"math"
+	"math/big"
 )
 
 type dckksContext struct {
@@ -35,16 +35,18 @@ func newDckksContext(params *ckks.Parameters) (context *dckksContext) {
 
 	context.n = n
 
-	context.alpha = uint64(len(params.Pi))
-	context.beta = uint64(math.Ceil(float64(len(params.Qi)) / float64(context.alpha)))
+	context.alpha = params.Alpha()
+	context.beta = params.Beta()
 
 	var err error
 	if context.contextQ, err = ring.NewContextWithParams(n, params.Qi); err != nil {
 		panic(err)
 	}
 
-	if context.contextP, err = ring.NewContextWithParams(n, params.Pi); err != nil {
-		panic(err)
+	if len(params.Pi) != 0 {
+		if context.contextP, err = ring.NewContextWithParams(n, params.Pi); err != nil {
+			panic(err)
+		}
 	}
 
 	if context.contextQP, err = ring.NewContextWithParams(n, append(params.Qi, params.Pi...)); err != nil {
@@ -56,8 +58,121 @@ func newDckksContext(params *ckks.Parameters) (context *dckksContext) {
 	return
 }
 
+// TryNewCRPGenerator is the same as NewCRPGenerator, but returns an error instead of panicking if the parameters are invalid.
+func TryNewCRPGenerator(params *ckks.Parameters, key []byte) (*ring.CRPGenerator, error) {
+
+	if err := checkParameters("NewCRPGenerator", params); err != nil {
+		return nil, err
+	}
+
+	return NewCRPGenerator(params, key), nil
+}
+
 // NewCRPGenerator creates a CRPGenerator
 func NewCRPGenerator(params *ckks.Parameters, key []byte) *ring.CRPGenerator {
+
+	if err := checkParameters("NewCRPGenerator", params); err != nil {
+		panic(err)
+	}
+
 	ctx := newDckksContext(params)
 	return ring.NewCRPGenerator(key, ctx.contextQP)
 }
+
+// keySwitchingGadget stores the decomposition used by the collectively generated key-switching keys: either the RNS
+// decomposition with the special primes Pi (logBase = 0), or the RNS decomposition combined with a digit decomposition
+// of each Qi in base 2^logBase, in which case the keys are in basis Q only (see ckks.GenSwitchingKeyGadget).
+type keySwitchingGadget struct {
+	logBase uint64
+
+	// Number of elements of the decomposition
+	size uint64
+
+	alpha  uint64
+	digits uint64
+	levels uint64
+
+	modulusP *big.Int
+
+	contextKeys     *ring.Context
+	gaussianSampler *ring.KYSampler
+}
+
+func newKeySwitchingGadget(context *dckksContext, logBase uint64) (gadget *keySwitchingGadget) {
+
+	params := context.params
+
+	gadget = new(keySwitchingGadget)
+	gadget.logBase = logBase
+	gadget.levels = uint64(len(params.Qi))
+
+	if logBase == 0 {
+		gadget.alpha = context.alpha
+		gadget.size = context.beta
+		gadget.modulusP = context.contextP.ModulusBigint
+		gadget.contextKeys = context.contextQP
+		gadget.gaussianSampler = context.gaussianSampler
+	} else {
+		gadget.digits = params.GadgetDigits(logBase)
+		gadget.size = gadget.levels * gadget.digits
+		gadget.contextKeys = context.contextQ
+		gadget.gaussianSampler = context.contextQ.NewKYSampler(params.Sigma, int(params.Sigma*6))
+	}
+
+	return
+}
+
+// scaleSecret sets skOut to skIn outside of the Montgomery form, multiplied by P if the decomposition uses the special primes.
+func (gadget *keySwitchingGadget) scaleSecret(skIn, skOut *ring.Poly) {
+
+	gadget.contextKeys.Copy(skIn, skOut)
+
+	if gadget.logBase == 0 {
+		gadget.contextKeys.MulScalarBigint(skOut, gadget.modulusP, skOut)
+	}
+
+	gadget.contextKeys.InvMForm(skOut, skOut)
+}
+
+// addElement adds on p the i-th element of the decomposition applied to sk, which must have been scaled with scaleSecret :
+// sk*P*(qiBarre*qiStar) for the i-th group of alpha moduli, or sk*(qiBarre*qiStar)*2^(logBase*j) for the j-th digit of
+// the modulus of index i/digits.
+func (gadget *keySwitchingGadget) addElement(i uint64, sk, p *ring.Poly) {
+
+	contextKeys := gadget.contextKeys
+
+	if gadget.logBase == 0 {
+
+		for j := uint64(0); j < gadget.alpha; j++ {
+
+			index := i*gadget.alpha + j
+
+			qi := contextKeys.Modulus[index]
+			tmp0 := sk.Coeffs[index]
+			tmp1 := p.Coeffs[index]
+
+			for w := uint64(0); w < contextKeys.N; w++ {
+				tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
+			}
+
+			// Handles the case where nb pj does not divides nb qi
+			if index >= gadget.levels-1 {
+				break
+			}
+		}
+
+		return
+	}
+
+	index := i / gadget.digits
+
+	qi := contextKeys.Modulus[index]
+	bredParams := contextKeys.GetBredParams()[index]
+	pow2 := ring.ModExp(2, gadget.logBase*(i%gadget.digits), qi)
+	tmp0 := sk.Coeffs[index]
+	tmp1 := p.Coeffs[index]
+
+	for w := uint64(0); w < contextKeys.N; w++ {
+		tmp1[w] = ring.CRed(tmp1[w]+ring.BRed(tmp0[w], pow2, qi, bredParams), qi)
+	}
+}
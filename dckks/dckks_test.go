@@ -1,6 +1,8 @@
 package dckks
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/ldsec/lattigo/ckks"
 	"github.com/ldsec/lattigo/ring"
+	"github.com/ldsec/lattigo/utils"
 )
 
 func check(t *testing.T, err error) {
@@ -74,9 +77,194 @@ func TestDCKKS(t *testing.T) {
 	t.Run("RelinKeyGenNaive", testRelinKeyGenNaive)
 	t.Run("KeySwitching", testKeyswitching)
 	t.Run("PublicKeySwitching", testPublicKeySwitching)
+	t.Run("Threshold", testThreshold)
+	t.Run("AggregationTree", testAggregationTree)
 	t.Run("RotKeyGenConjugate", testRotKeyGenConjugate)
 	t.Run("RotKeyGenCols", testRotKeyGenCols)
 	t.Run("Refresh", testRefresh)
+	t.Run("Sharing", testSharing)
+	t.Run("Gadget", testGadget)
+	t.Run("Marshalling", testMarshalling)
+	t.Run("Errors", testErrors)
+}
+
+func testErrors(t *testing.T) {
+
+	params := new(ckks.Parameters)
+
+	_, errCKS := TryNewCKSProtocol(params, 3.2)
+	_, errPCKS := TryNewPCKSProtocol(params, 3.2)
+	_, errCKG := TryNewCKGProtocol(params)
+	_, errEKG := TryNewEkgProtocol(params)
+	_, errRKG := TryNewRKGProtocolNaive(params)
+	_, errRTG := TryNewRotKGProtocol(params)
+	_, errRefresh := TryNewRefreshProtocol(nil)
+	_, errThr := TryNewThresholdizerProtocol(params, 2)
+	_, errCmb := TryNewCombinerProtocol(params, 2)
+	_, errE2S := TryNewE2SProtocol(params, 3.2, 40)
+	_, errS2E := TryNewS2EProtocol(params, 3.2)
+	_, errCRP := TryNewCRPGenerator(params, nil)
+	_, errE2SSmudging := TryNewE2SProtocol(ckks.DefaultParams[ckks.PN12QP109], 1, 40)
+	_, errE2SFlooding := TryNewE2SProtocol(ckks.DefaultParams[ckks.PN12QP109], 1<<30, 40)
+	_, errThrZero := TryNewThresholdizerProtocol(ckks.DefaultParams[ckks.PN12QP109], 0)
+
+	// Parameters without the special primes Pi only enable the gadget decomposition
+	moduli := ckks.DefaultParams[ckks.PN12QP109].Moduli.Copy()
+	moduli.Pi = nil
+	paramsNoPi := ckks.NewParametersFromModuli(12, 11, 1<<30, moduli, 3.2)
+
+	_, errEKGNoPi := TryNewEkgProtocol(paramsNoPi)
+	_, errRTGNoPi := TryNewRotKGProtocol(paramsNoPi)
+	_, errEKGGadget := TryNewEkgProtocolGadget(paramsNoPi, 64)
+
+	if _, err := TryNewEkgProtocolGadget(paramsNoPi, 16); err != nil {
+		t.Error(err)
+	}
+
+	for _, err := range []error{errCKS, errPCKS, errCKG, errEKG, errRKG, errRTG, errRefresh, errEKGNoPi, errRTGNoPi, errEKGGadget, errThr, errCmb, errThrZero, errE2S, errS2E, errE2SSmudging, errE2SFlooding, errCRP} {
+		var schemeErr *ckks.Error
+		if !errors.As(err, &schemeErr) || !errors.Is(err, ckks.ErrInvalidParameters) {
+			t.Errorf("invalid error: have %v, want %v", err, ckks.ErrInvalidParameters)
+		}
+	}
+}
+
+func testMarshalling(t *testing.T) {
+
+	for _, parameters := range testParams.ckksParameters {
+
+		params := gendckksTestContext(parameters)
+
+		sk0 := params.sk0Shards[0].Get()
+		sk1 := params.sk1Shards[0].Get()
+
+		t.Run(testString("", 1, parameters), func(t *testing.T) {
+
+			_, _, ciphertext := newTestVectors(params, params.encryptorPk0, 1, t)
+
+			params.evaluator.DropLevel(ciphertext, 1)
+
+			crpGenerator := ring.NewCRPGenerator(nil, params.dckksContext.contextQP)
+			crpGenerator.Seed([]byte{})
+			crp := make([]*ring.Poly, parameters.Beta())
+			for i := range crp {
+				crp[i] = crpGenerator.ClockNew()
+			}
+
+			ckg := NewCKGProtocol(parameters)
+			ckgShare := ckg.AllocateShares()
+			ckg.GenShare(sk0, crp[0], ckgShare)
+
+			cks := NewCKSProtocol(parameters, 6.36)
+			cksShare := CKSShare{params.dckksContext.contextQ.NewPolyLvl(ciphertext.Level())}
+			cks.GenShare(sk0, sk1, ciphertext, cksShare)
+
+			pcks := NewPCKSProtocol(parameters, 6.36)
+			pcksShare := pcks.AllocateShares(ciphertext.Level())
+			pcks.GenShare(sk0, params.pk1, ciphertext, pcksShare)
+
+			refresh := NewRefreshProtocol(parameters)
+			refreshShareDecrypt, refreshShareRecrypt := refresh.AllocateShares(ciphertext.Level())
+			refresh.GenShares(sk0, ciphertext.Level(), 1, ciphertext, crp[0].CopyNew(), refreshShareDecrypt, refreshShareRecrypt)
+
+			ekg := NewEkgProtocol(parameters)
+			rkgShare1, rkgShare2, rkgShare3 := ekg.AllocateShares()
+			u := ekg.NewEphemeralKey(1.0 / 3.0)
+			ekg.GenShareRoundOne(u, sk0, crp, rkgShare1)
+			ekg.GenShareRoundTwo(rkgShare1, sk0, crp, rkgShare2)
+			ekg.GenShareRoundThree(rkgShare2, u, sk0, rkgShare3)
+
+			rkgNaive := NewRKGProtocolNaive(parameters)
+			rkgNaiveShare1, rkgNaiveShare2 := rkgNaive.AllocateShares()
+			rkgNaive.GenShareRoundOne(sk0, params.pk0.Get(), rkgNaiveShare1)
+			rkgNaive.GenShareRoundTwo(rkgNaiveShare1, sk0, params.pk0.Get(), rkgNaiveShare2)
+
+			rtg := NewRotKGProtocol(parameters)
+			rtgShare := rtg.AllocateShare()
+			rtg.GenShare(ckks.RotationLeft, 5, sk0, crp, &rtgShare)
+
+			thr := NewThresholdizerProtocol(parameters, 2)
+			thrShare := thr.AllocateShare()
+			thr.GenShare(thr.GenShamirPolynomial(sk0), 1, thrShare)
+
+			e2s := NewE2SProtocol(parameters, parameters.Sigma, 40)
+			additiveShare, e2sShare := e2s.AllocateShares(ciphertext.Level())
+			e2s.GenShare(sk0, ciphertext, additiveShare, e2sShare)
+
+			shares := []struct {
+				name     string
+				share    utils.BinaryShare
+				received utils.BinaryShare
+			}{
+				{"CKG", &ckgShare, new(CKGShare)},
+				{"CKS", &cksShare, new(CKSShare)},
+				{"PCKS", &pcksShare, new(PCKSShare)},
+				{"RefreshDecrypt", &refreshShareDecrypt, new(RefreshShareDecrypt)},
+				{"RefreshRecrypt", &refreshShareRecrypt, new(RefreshShareRecrypt)},
+				{"RKGRoundOne", &rkgShare1, new(RKGShareRoundOne)},
+				{"RKGRoundTwo", &rkgShare2, new(RKGShareRoundTwo)},
+				{"RKGRoundThree", &rkgShare3, new(RKGShareRoundThree)},
+				{"RKGNaiveRoundOne", &rkgNaiveShare1, new(RKGNaiveShareRoundOne)},
+				{"RKGNaiveRoundTwo", &rkgNaiveShare2, new(RKGNaiveShareRoundTwo)},
+				{"RTG", &rtgShare, new(RTGShare)},
+				{"Threshold", &thrShare, new(ShamirShare)},
+				{"Additive", &additiveShare, new(AdditiveShare)},
+			}
+
+			for _, s := range shares {
+
+				data, err := s.share.MarshalBinary()
+				check(t, err)
+				check(t, s.received.UnmarshalBinary(data))
+
+				dataReceived, err := s.received.MarshalBinary()
+				check(t, err)
+
+				if !bytes.Equal(data, dataReceived) {
+					t.Errorf("%s share: the unmarshaled share differs from the marshaled share", s.name)
+				}
+			}
+
+			if received := shares[1].received.(*CKSShare); received.Level() != ciphertext.Level() || !params.dckksContext.contextQ.EqualLvl(ciphertext.Level(), received.Poly, cksShare.Poly) {
+				t.Error("CKS share: invalid unmarshaled share")
+			}
+
+			if received := shares[2].received.(*PCKSShare); received.Level() != ciphertext.Level() {
+				t.Error("PCKS share: invalid level of the unmarshaled share")
+			}
+
+			if received := shares[3].received.(*RefreshShareDecrypt); received.Level() != ciphertext.Level() {
+				t.Error("Refresh share: invalid level of the unmarshaled decryption share")
+			}
+
+			if received := shares[4].received.(*RefreshShareRecrypt); received.Level() != parameters.MaxLevel() {
+				t.Error("Refresh share: invalid level of the unmarshaled recryption share")
+			}
+
+			// A share cannot be marshaled with a level inconsistent with its polynomials
+			if _, err := marshalPolysLvl(ciphertext.Level()+1, cksShare.Poly); err == nil {
+				t.Error("a share was marshaled at an invalid level")
+			}
+
+			if err := new(CKSShare).UnmarshalBinary(nil); err == nil {
+				t.Error("an empty share was unmarshaled")
+			}
+
+			// The pairs of polynomials are validated before being decoded
+			if _, err := new(RKGNaiveShareRoundOne).MarshalBinary(); err == nil {
+				t.Error("an empty share was marshaled")
+			}
+
+			dataNaive, err := rkgNaiveShare1.MarshalBinary()
+			check(t, err)
+
+			for _, data := range [][]byte{nil, {0}, {1, 0}, dataNaive[:len(dataNaive)-1], append([]byte{dataNaive[0] + 1}, dataNaive[1:]...)} {
+				if err := new(RKGNaiveShareRoundOne).UnmarshalBinary(data); err == nil {
+					t.Errorf("an invalid share of %d bytes was unmarshaled", len(data))
+				}
+			}
+		})
+	}
 }
 
 func gendckksTestContext(contextParameters *ckks.Parameters) (params *dckksTestContext) {
@@ -454,6 +642,166 @@ func testPublicKeySwitching(t *testing.T) {
 	}
 }
 
+func testThreshold(t *testing.T) {
+
+	parties := testParams.parties
+	threshold := parties - 1
+
+	for _, parameters := range testParams.ckksParameters {
+		params := gendckksTestContext(parameters)
+
+		sk0Shards := params.sk0Shards
+		sk1Shards := params.sk1Shards
+		pk1 := params.pk1
+		encryptorPk0 := params.encryptorPk0
+		decryptorSk1 := params.decryptorSk1
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*ThresholdizerProtocol
+				*CombinerProtocol
+				pk        ShamirPublicKey
+				tsk0      ShamirShare
+				tsk1      ShamirShare
+				s0        *ring.Poly
+				s1        *ring.Poly
+				share     ShamirShare
+				cks       *CKSProtocol
+				pcks      *PCKSProtocol
+				cksShare  CKSShare
+				pcksShare PCKSShare
+			}
+
+			thrParties := make([]*Party, parties)
+			for i := uint64(0); i < parties; i++ {
+				p := new(Party)
+				p.ThresholdizerProtocol = NewThresholdizerProtocol(parameters, threshold)
+				p.CombinerProtocol = NewCombinerProtocol(parameters, threshold)
+				p.pk = ShamirPublicKey(i + 1)
+				p.tsk0 = p.ThresholdizerProtocol.AllocateShare()
+				p.tsk1 = p.ThresholdizerProtocol.AllocateShare()
+				p.share = p.ThresholdizerProtocol.AllocateShare()
+				p.s0 = params.dckksContext.contextQP.NewPoly()
+				p.s1 = params.dckksContext.contextQP.NewPoly()
+				thrParties[i] = p
+			}
+
+			// Each party thresholdizes its additive shares of sk0 and sk1
+			for _, shards := range []struct {
+				sk  []*ckks.SecretKey
+				tsk func(p *Party) ShamirShare
+			}{
+				{sk0Shards, func(p *Party) ShamirShare { return p.tsk0 }},
+				{sk1Shards, func(p *Party) ShamirShare { return p.tsk1 }},
+			} {
+				for i, sender := range thrParties {
+					polynomial := sender.GenShamirPolynomial(shards.sk[i].Get())
+					for _, recipient := range thrParties {
+						sender.ThresholdizerProtocol.GenShare(polynomial, recipient.pk, sender.share)
+						sender.ThresholdizerProtocol.AggregateShares(shards.tsk(recipient), sender.share, shards.tsk(recipient))
+					}
+				}
+			}
+
+			// Only threshold parties are active
+			activeParties := thrParties[1:]
+			active := make([]ShamirPublicKey, len(activeParties))
+			for i, p := range activeParties {
+				active[i] = p.pk
+			}
+
+			coeffs, _, ciphertext := newTestVectors(params, encryptorPk0, 1, t)
+
+			t.Run("Duplicates", func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("GenAdditiveShare should panic on duplicate active ShamirPublicKeys")
+					}
+				}()
+				P := thrParties[0]
+				P.GenAdditiveShare([]ShamirPublicKey{P.pk, P.pk + 1, P.pk + 1}, P.pk, P.tsk0, P.s0)
+			})
+
+			for _, p := range activeParties {
+				p.GenAdditiveShare(active, p.pk, p.tsk0, p.s0)
+				p.GenAdditiveShare(active, p.pk, p.tsk1, p.s1)
+				p.cks = NewCKSProtocol(parameters, 6.36)
+				p.pcks = NewPCKSProtocol(parameters, 6.36)
+				p.cksShare = p.cks.AllocateShare()
+				p.pcksShare = p.pcks.AllocateShares(ciphertext.Level())
+			}
+			P0 := activeParties[0]
+
+			for i, p := range activeParties {
+				p.cks.GenShare(p.s0, p.s1, ciphertext, p.cksShare)
+				p.pcks.GenShare(p.s0, pk1, ciphertext, p.pcksShare)
+				if i > 0 {
+					P0.cks.AggregateShares(p.cksShare, P0.cksShare, P0.cksShare)
+					P0.pcks.AggregateShares(p.pcksShare, P0.pcksShare, P0.pcksShare)
+				}
+			}
+
+			ksCiphertext := ckks.NewCiphertext(parameters, 1, ciphertext.Level(), ciphertext.Scale())
+			P0.cks.KeySwitch(P0.cksShare, ciphertext, ksCiphertext)
+			verifyTestVectors(params, decryptorSk1, coeffs, ksCiphertext, t)
+
+			P0.pcks.KeySwitch(P0.pcksShare, ciphertext, ksCiphertext)
+			verifyTestVectors(params, decryptorSk1, coeffs, ksCiphertext, t)
+		})
+	}
+}
+
+func testAggregationTree(t *testing.T) {
+
+	parties := testParams.parties
+
+	for _, parameters := range testParams.ckksParameters {
+		params := gendckksTestContext(parameters)
+
+		sk0Shards := params.sk0Shards
+		sk1Shards := params.sk1Shards
+		pk1 := params.pk1
+		encryptorPk0 := params.encryptorPk0
+		decryptorSk1 := params.decryptorSk1
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			coeffs, _, ciphertext := newTestVectors(params, encryptorPk0, 1, t)
+
+			cks := NewCKSProtocol(parameters, 6.36)
+			pcks := NewPCKSProtocol(parameters, 6.36)
+
+			cksShares := make([]utils.Share, parties)
+			pcksShares := make([]utils.Share, parties)
+			for i := uint64(0); i < parties; i++ {
+				cksShare := cks.AllocateShare()
+				cks.GenShare(sk0Shards[i].Get(), sk1Shards[i].Get(), ciphertext, cksShare)
+				cksShares[i] = cks.BindShare(cksShare)
+
+				pcksShare := pcks.AllocateShares(ciphertext.Level())
+				pcks.GenShare(sk0Shards[i].Get(), pk1, ciphertext, pcksShare)
+				pcksShares[i] = pcks.BindShare(pcksShare)
+			}
+
+			tree := utils.NewAggregationTree(2)
+
+			cksCombined := cks.AllocateShare()
+			check(t, tree.Aggregate(cksShares, cks.BindShare(cksCombined)))
+
+			ksCiphertext := ckks.NewCiphertext(parameters, 1, ciphertext.Level(), ciphertext.Scale())
+			cks.KeySwitch(cksCombined, ciphertext, ksCiphertext)
+			verifyTestVectors(params, decryptorSk1, coeffs, ksCiphertext, t)
+
+			pcksCombined := pcks.AllocateShares(ciphertext.Level())
+			check(t, tree.Aggregate(pcksShares, pcks.BindShare(pcksCombined)))
+
+			pcks.KeySwitch(pcksCombined, ciphertext, ksCiphertext)
+			verifyTestVectors(params, decryptorSk1, coeffs, ksCiphertext, t)
+		})
+	}
+}
+
 func testRotKeyGenConjugate(t *testing.T) {
 
 	parties := testParams.parties
@@ -641,8 +989,8 @@ func testRefresh(t *testing.T) {
 			for i, p := range RefreshParties {
 				p.GenShares(p.s, levelStart, parties, ciphertext, crp, p.share1, p.share2)
 				if i > 0 {
-					P0.Aggregate(p.share1, P0.share1, P0.share1)
-					P0.Aggregate(p.share2, P0.share2, P0.share2)
+					P0.Aggregate(p.share1.Poly, P0.share1.Poly, P0.share1.Poly)
+					P0.Aggregate(p.share2.Poly, P0.share2.Poly, P0.share2.Poly)
 				}
 			}
 
@@ -661,6 +1009,266 @@ func testRefresh(t *testing.T) {
 	}
 }
 
+func testSharing(t *testing.T) {
+
+	parties := testParams.parties
+
+	// Bound on the decryption noise of the fresh test ciphertexts, hidden with 40 bits of statistical security
+	noiseBound := float64(1 << 10)
+	lambda := uint64(40)
+
+	for _, parameters := range testParams.ckksParameters {
+
+		params := gendckksTestContext(parameters)
+
+		encryptorPk0 := params.encryptorPk0
+		decryptorSk0 := params.decryptorSk0
+		sk0Shards := params.sk0Shards
+		contextQ := params.dckksContext.contextQ
+
+		t.Run(testString("", parties, parameters), func(t *testing.T) {
+
+			// The flooding noise costs about lambda + log2(noiseBound) bits of precision, so the test vectors are
+			// encrypted with a scale of 2^80 to keep the precision of the shares above the target precision.
+			coeffs, plaintext, _ := newTestVectors(params, encryptorPk0, 1, t)
+			plaintext.SetScale(1 << 80)
+			params.encoder.Encode(plaintext, coeffs, uint64(1<<parameters.LogSlots))
+			ciphertext := encryptorPk0.EncryptNew(plaintext)
+
+			params.evaluator.DropLevel(ciphertext, 1)
+
+			level := ciphertext.Level()
+
+			type Party struct {
+				e2s         *E2SProtocol
+				s2e         *S2EProtocol
+				s           *ring.Poly
+				secretShare AdditiveShare
+				publicShare CKSShare
+			}
+
+			sharingParties := make([]*Party, parties)
+			for i := range sharingParties {
+				p := new(Party)
+				p.e2s = NewE2SProtocol(parameters, noiseBound, lambda)
+				p.s2e = NewS2EProtocol(parameters, parameters.Sigma)
+				p.s = sk0Shards[i].Get()
+				p.secretShare, p.publicShare = p.e2s.AllocateShares(level)
+				sharingParties[i] = p
+			}
+
+			P0 := sharingParties[0]
+
+			if err := P0.e2s.TryGenShare(P0.s, ckks.NewCiphertext(parameters, 1, 0, ciphertext.Scale()), P0.secretShare, P0.publicShare); !errors.Is(err, ckks.ErrLevelMismatch) {
+				t.Errorf("invalid error: have %v, want %v", err, ckks.ErrLevelMismatch)
+			}
+
+			// Encryption to shares, with P0 receiving the aggregated public share
+			for i, p := range sharingParties {
+				p.e2s.GenShare(p.s, ciphertext, p.secretShare, p.publicShare)
+				if i > 0 {
+					P0.e2s.AggregateShares(p.publicShare, P0.publicShare, P0.publicShare)
+				}
+			}
+
+			P0.e2s.GetShare(P0.secretShare, P0.publicShare, ciphertext, P0.secretShare)
+
+			// The sum of the additive shares is the plaintext
+			plaintext = ckks.NewPlaintext(parameters, level, ciphertext.Scale())
+			for _, p := range sharingParties {
+				contextQ.AddLvl(level, plaintext.Value()[0], p.secretShare.Poly, plaintext.Value()[0])
+			}
+			contextQ.NTTLvl(level, plaintext.Value()[0], plaintext.Value()[0])
+
+			verifyTestVectors(params, decryptorSk0, coeffs, plaintext, t)
+
+			// Shares to encryption
+			crpGenerator := ring.NewCRPGenerator(nil, contextQ)
+			crpGenerator.Seed([]byte{})
+			crp := crpGenerator.ClockNew()
+
+			for i, p := range sharingParties {
+				p.s2e.GenShare(p.s, crp, p.secretShare, p.publicShare)
+				if i > 0 {
+					P0.s2e.AggregateShares(p.publicShare, P0.publicShare, P0.publicShare)
+				}
+			}
+
+			ciphertextOut := ckks.NewCiphertext(parameters, 1, level, ciphertext.Scale())
+			P0.s2e.GetEncryption(P0.publicShare, crp, ciphertextOut)
+
+			verifyTestVectors(params, decryptorSk0, coeffs, ciphertextOut, t)
+
+			// The seed of a fresh encryption with the secret-key must not be marshaled once overwritten by GetEncryption
+			ciphertextSeeded := ckks.NewEncryptorFromSk(parameters, params.sk0).EncryptNew(ckks.NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale))
+			P0.s2e.GetEncryption(P0.publicShare, crp, ciphertextSeeded)
+
+			if _, err := ciphertextSeeded.MarshalBinaryCompressed(); err == nil {
+				t.Error("MarshalBinaryCompressed should fail on a ciphertext overwritten by GetEncryption")
+			}
+		})
+	}
+}
+
+func testGadget(t *testing.T) {
+
+	parties := testParams.parties
+
+	logBase := uint64(10)
+
+	for _, parameters := range testParams.ckksParameters {
+
+		// Same moduli without the special primes Pi, which are not needed by the gadget decomposition.
+		moduli := parameters.Moduli.Copy()
+		moduli.Pi = nil
+		parameters = ckks.NewParametersFromModuli(parameters.LogN, parameters.LogSlots, parameters.Scale, moduli, parameters.Sigma)
+
+		params := gendckksTestContext(parameters)
+
+		evaluator := params.evaluator
+		encryptorPk0 := params.encryptorPk0
+		decryptorSk0 := params.decryptorSk0
+		sk0Shards := params.sk0Shards
+
+		// The encryption without P is used since Pi is empty. Since the noise of the key-switching
+		// is not divided by P, the ciphertexts are scaled up to keep the precision.
+		newTestVectorsFast := func() (values []complex128, ciphertext *ckks.Ciphertext) {
+			slots := uint64(1 << parameters.LogSlots)
+			values = make([]complex128, slots)
+			for i := range values {
+				values[i] = randomComplex(1)
+			}
+			plaintext := ckks.NewPlaintext(parameters, parameters.MaxLevel(), parameters.Scale)
+			params.encoder.Encode(plaintext, values, slots)
+			ciphertext = encryptorPk0.EncryptFastNew(plaintext)
+			evaluator.ScaleUp(ciphertext, math.Exp2(20), ciphertext)
+			return values, ciphertext
+		}
+
+		crpGenerator := ring.NewCRPGenerator(nil, params.dckksContext.contextQP)
+		crpGenerator.Seed([]byte{})
+		crp := make([]*ring.Poly, uint64(len(parameters.Qi))*parameters.GadgetDigits(logBase))
+
+		for i := range crp {
+			crp[i] = crpGenerator.ClockNew()
+		}
+
+		t.Run(testString("RelinKeyGen/", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*RKGProtocol
+				u      *ring.Poly
+				s      *ring.Poly
+				share1 RKGShareRoundOne
+				share2 RKGShareRoundTwo
+				share3 RKGShareRoundThree
+			}
+
+			rkgParties := make([]*Party, parties)
+
+			for i := range rkgParties {
+				p := new(Party)
+				p.RKGProtocol = NewEkgProtocolGadget(parameters, logBase)
+				p.u = p.NewEphemeralKey(1.0 / 3.0)
+				p.s = sk0Shards[i].Get()
+				p.share1, p.share2, p.share3 = p.AllocateShares()
+				rkgParties[i] = p
+			}
+
+			P0 := rkgParties[0]
+
+			for i, p := range rkgParties {
+				p.GenShareRoundOne(p.u, p.s, crp, p.share1)
+				if i > 0 {
+					P0.AggregateShareRoundOne(p.share1, P0.share1, P0.share1)
+				}
+			}
+
+			for i, p := range rkgParties {
+				p.GenShareRoundTwo(P0.share1, p.s, crp, p.share2)
+				if i > 0 {
+					P0.AggregateShareRoundTwo(p.share2, P0.share2, P0.share2)
+				}
+			}
+
+			for i, p := range rkgParties {
+				p.GenShareRoundThree(P0.share2, p.u, p.s, p.share3)
+				if i > 0 {
+					P0.AggregateShareRoundThree(p.share3, P0.share3, P0.share3)
+				}
+			}
+
+			evk := ckks.NewRelinKeyGadget(parameters, logBase)
+			P0.GenRelinearizationKey(P0.share2, P0.share3, evk)
+
+			coeffs, ciphertext := newTestVectorsFast()
+
+			for i := range coeffs {
+				coeffs[i] *= coeffs[i]
+			}
+
+			evaluator.MulRelin(ciphertext, ciphertext, evk, ciphertext)
+
+			if ciphertext.Degree() != 1 {
+				t.Errorf("invalid degree after MulRelin: have %d, want 1", ciphertext.Degree())
+			}
+
+			verifyTestVectors(params, decryptorSk0, coeffs, ciphertext, t)
+		})
+
+		t.Run(testString("RotKeyGen/", parties, parameters), func(t *testing.T) {
+
+			type Party struct {
+				*RTGProtocol
+				s     *ring.Poly
+				share RTGShare
+			}
+
+			rtgParties := make([]*Party, parties)
+			for i := uint64(0); i < parties; i++ {
+				p := new(Party)
+				p.RTGProtocol = NewRotKGProtocolGadget(parameters, logBase)
+				p.s = sk0Shards[i].Get()
+				p.share = p.AllocateShare()
+				rtgParties[i] = p
+			}
+
+			P0 := rtgParties[0]
+
+			rotkey := ckks.NewRotationKeys()
+
+			for _, rotType := range []ckks.Rotation{ckks.RotationLeft, ckks.Conjugate} {
+				for i, p := range rtgParties {
+					p.GenShare(rotType, 1, p.s, crp, &p.share)
+					if i > 0 {
+						P0.Aggregate(p.share, P0.share, P0.share)
+					}
+				}
+
+				P0.Finalize(parameters, P0.share, crp, rotkey)
+			}
+
+			coeffs, ciphertext := newTestVectorsFast()
+
+			slots := uint64(1 << parameters.LogSlots)
+
+			coeffsWant := make([]complex128, slots)
+
+			for i := uint64(0); i < slots; i++ {
+				coeffsWant[i] = coeffs[(i+1)%slots]
+			}
+
+			verifyTestVectors(params, decryptorSk0, coeffsWant, evaluator.RotateColumnsNew(ciphertext, 1, rotkey), t)
+
+			for i := uint64(0); i < slots; i++ {
+				coeffsWant[i] = complex(real(coeffs[i]), -imag(coeffs[i]))
+			}
+
+			verifyTestVectors(params, decryptorSk0, coeffsWant, evaluator.ConjugateNew(ciphertext, rotkey), t)
+		})
+	}
+}
+
 func newTestVectors(contextParams *dckksTestContext, encryptor ckks.Encryptor, a float64, t *testing.T) (values []complex128, plaintext *ckks.Plaintext, ciphertext *ckks.Ciphertext) {
 
 	slots := uint64(1 << contextParams.params.LogSlots)
@@ -0,0 +1,86 @@
+package dckks
+
+import (
+	"github.com/ldsec/lattigo/ckks"
+)
+
+// checkParameters returns a ckks.Error wrapping ckks.ErrInvalidParameters if params is nil or was not generated.
+func checkParameters(op string, params *ckks.Parameters) error {
+	if params == nil || !params.IsValid() {
+		return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters}
+	}
+	return nil
+}
+
+// checkKeySwitching returns a ckks.Error wrapping ckks.ErrInvalidParameters if params do not enable the generation of the
+// key-switching keys with the gadget decomposition of base 2^logBase, or with the special primes Pi if logBase is 0.
+func checkKeySwitching(op string, params *ckks.Parameters, logBase uint64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if logBase == 0 {
+		if len(params.Pi) == 0 {
+			return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters, Reason: "modulus P is empty"}
+		}
+		return nil
+	}
+
+	for _, logQi := range params.LogQi {
+		if logBase > logQi {
+			return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters, Reason: "logBase is larger than the bit-size of the smallest Qi"}
+		}
+	}
+
+	return nil
+}
+
+// checkThreshold returns a ckks.Error wrapping ckks.ErrInvalidParameters if params is nil or was not generated, or if
+// threshold is zero.
+func checkThreshold(op string, params *ckks.Parameters, threshold uint64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if threshold == 0 {
+		return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters, Reason: "threshold must be at least 1"}
+	}
+
+	return nil
+}
+
+// checkSmudging returns a ckks.Error wrapping ckks.ErrInvalidParameters if params is nil or was not generated, or if
+// sigmaSmudging is smaller than the standard deviation of the encryption noise.
+func checkSmudging(op string, params *ckks.Parameters, sigmaSmudging float64) error {
+
+	if err := checkParameters(op, params); err != nil {
+		return err
+	}
+
+	if sigmaSmudging < params.Sigma {
+		return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters, Reason: "sigmaSmudging is smaller than the standard deviation of the encryption noise"}
+	}
+
+	return nil
+}
+
+// checkFlooding returns a ckks.Error wrapping ckks.ErrInvalidParameters if params is nil or was not generated, if
+// noiseBound is smaller than the standard deviation of the encryption noise, or if the flooding bound
+// 2^lambda * noiseBound is not smaller than Q/2.
+func checkFlooding(op string, params *ckks.Parameters, noiseBound float64, lambda uint64) error {
+
+	if err := checkSmudging(op, params, noiseBound); err != nil {
+		return err
+	}
+
+	bound := floodingBound(noiseBound, lambda)
+	bound.Lsh(bound, 1)
+
+	if bound.Cmp(modulusBigint(params.Qi)) >= 0 {
+		return &ckks.Error{Op: op, Err: ckks.ErrInvalidParameters, Reason: "the flooding bound 2^lambda * noiseBound exceeds Q/2"}
+	}
+
+	return nil
+}
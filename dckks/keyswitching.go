@@ -20,15 +20,47 @@ type CKSProtocol struct {
 }
 
 // CKSShare is a struct holding a share of the CKS protocol.
-type CKSShare *ring.Poly
+type CKSShare struct {
+	*ring.Poly
+}
+
+// Level returns the level of the target share.
+func (share CKSShare) Level() uint64 {
+	return uint64(len(share.Coeffs) - 1)
+}
+
+// MarshalBinary encodes the target share, with its level, on a slice of bytes.
+func (share CKSShare) MarshalBinary() ([]byte, error) {
+	return marshalPolysLvl(share.Level(), share.Poly)
+}
+
+// UnmarshalBinary decodes a marshaled CKS share on the target CKS share.
+func (share *CKSShare) UnmarshalBinary(data []byte) error {
+	_, polys, err := unmarshalPolysLvl(data, 1)
+	if err != nil {
+		return err
+	}
+	share.Poly = polys[0]
+	return nil
+}
+
+// TryNewCKSProtocol is the same as NewCKSProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewCKSProtocol(params *ckks.Parameters, sigmaSmudging float64) (*CKSProtocol, error) {
+
+	if err := checkParameters("NewCKSProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewCKSProtocol(params, sigmaSmudging), nil
+}
 
 // NewCKSProtocol creates a new CKSProtocol that will be used to operate a collective key-switching on a ciphertext encrypted under a collective public-key, whose
 // secret-shares are distributed among j parties, re-encrypting the ciphertext under another public-key, whose secret-shares are also known to the
 // parties.
 func NewCKSProtocol(params *ckks.Parameters, sigmaSmudging float64) (cks *CKSProtocol) {
 
-	if !params.IsValid() {
-		panic("cannot NewCKSProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewCKSProtocol", params); err != nil {
+		panic(err)
 	}
 
 	cks = new(CKSProtocol)
@@ -50,7 +82,7 @@ func NewCKSProtocol(params *ckks.Parameters, sigmaSmudging float64) (cks *CKSPro
 
 // AllocateShare allocates the share of the CKS protocol.
 func (cks *CKSProtocol) AllocateShare() CKSShare {
-	return cks.dckksContext.contextQ.NewPoly()
+	return CKSShare{cks.dckksContext.contextQ.NewPoly()}
 }
 
 // GenShare is the first and unique round of the CKSProtocol protocol. Each party holding a ciphertext ctx encrypted under a collective publick-key must
@@ -71,13 +103,13 @@ func (cks *CKSProtocol) genShareDelta(skDelta *ring.Poly, ct *ckks.Ciphertext, s
 	contextQ := cks.dckksContext.contextQ
 	contextP := cks.dckksContext.contextP
 
-	contextQ.MulCoeffsMontgomeryLvl(ct.Level(), ct.Value()[1], skDelta, shareOut)
+	contextQ.MulCoeffsMontgomeryLvl(ct.Level(), ct.Value()[1], skDelta, shareOut.Poly)
 
-	contextQ.MulScalarBigintLvl(ct.Level(), shareOut, contextP.ModulusBigint, shareOut)
+	contextQ.MulScalarBigintLvl(ct.Level(), shareOut.Poly, contextP.ModulusBigint, shareOut.Poly)
 
 	// TODO : improve by only computing the NTT for the required primes
 	cks.gaussianSamplerSmudge.SampleNTT(cks.tmp)
-	contextQ.AddLvl(ct.Level(), shareOut, cks.tmp, shareOut)
+	contextQ.AddLvl(ct.Level(), shareOut.Poly, cks.tmp, shareOut.Poly)
 
 	for x, i := 0, uint64(len(contextQ.Modulus)); i < uint64(len(cks.dckksContext.contextQP.Modulus)); x, i = x+1, i+1 {
 		tmp0 := cks.tmp.Coeffs[i]
@@ -87,7 +119,7 @@ func (cks *CKSProtocol) genShareDelta(skDelta *ring.Poly, ct *ckks.Ciphertext, s
 		}
 	}
 
-	cks.baseconverter.ModDownSplitedNTTPQ(ct.Level(), shareOut, cks.hP, shareOut)
+	cks.baseconverter.ModDownSplitedNTTPQ(ct.Level(), shareOut.Poly, cks.hP, shareOut.Poly)
 
 	cks.hP.Zero()
 	cks.tmp.Zero()
@@ -97,12 +129,12 @@ func (cks *CKSProtocol) genShareDelta(skDelta *ring.Poly, ct *ckks.Ciphertext, s
 //
 // [ctx[0] + sum((skInput_i - skOutput_i) * ctx[0] + e_i), ctx[1]]
 func (cks *CKSProtocol) AggregateShares(share1, share2, shareOut CKSShare) {
-	cks.dckksContext.contextQ.AddLvl(uint64(len(share1.Coeffs)-1), share1, share2, shareOut)
+	cks.dckksContext.contextQ.AddLvl(share1.Level(), share1.Poly, share2.Poly, shareOut.Poly)
 }
 
 // KeySwitch performs the actual keyswitching operation on a ciphertext ct and put the result in ctOut
 func (cks *CKSProtocol) KeySwitch(combined CKSShare, ct *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
 	ctOut.SetScale(ct.Scale())
-	cks.dckksContext.contextQ.AddLvl(ct.Level(), ct.Value()[0], combined, ctOut.Value()[0])
+	cks.dckksContext.contextQ.AddLvl(ct.Level(), ct.Value()[0], combined.Poly, ctOut.Value()[0])
 	cks.dckksContext.contextQ.CopyLvl(ct.Level(), ct.Value()[1], ctOut.Value()[1])
 }
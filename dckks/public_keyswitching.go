@@ -23,12 +23,42 @@ type PCKSProtocol struct {
 // PCKSShare is a struct storing the share of the PCKS protocol.
 type PCKSShare [2]*ring.Poly
 
+// Level returns the level of the target share.
+func (share PCKSShare) Level() uint64 {
+	return uint64(len(share[0].Coeffs) - 1)
+}
+
+// MarshalBinary encodes the target share, with its level, on a slice of bytes.
+func (share PCKSShare) MarshalBinary() ([]byte, error) {
+	return marshalPolysLvl(share.Level(), share[0], share[1])
+}
+
+// UnmarshalBinary decodes a marshaled PCKS share on the target PCKS share.
+func (share *PCKSShare) UnmarshalBinary(data []byte) error {
+	_, polys, err := unmarshalPolysLvl(data, 2)
+	if err != nil {
+		return err
+	}
+	share[0], share[1] = polys[0], polys[1]
+	return nil
+}
+
+// TryNewPCKSProtocol is the same as NewPCKSProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewPCKSProtocol(params *ckks.Parameters, sigmaSmudging float64) (*PCKSProtocol, error) {
+
+	if err := checkParameters("NewPCKSProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewPCKSProtocol(params, sigmaSmudging), nil
+}
+
 // NewPCKSProtocol creates a new PCKSProtocol object and will be used to re-encrypt a ciphertext ctx encrypted under a secret-shared key mong j parties under a new
 // collective public-key.
 func NewPCKSProtocol(params *ckks.Parameters, sigmaSmudging float64) *PCKSProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewPCKSProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewPCKSProtocol", params); err != nil {
+		panic(err)
 	}
 
 	pcks := new(PCKSProtocol)
@@ -98,7 +128,7 @@ func (pcks *PCKSProtocol) GenShare(sk *ring.Poly, pk *ckks.PublicKey, ct *ckks.C
 // [ctx[0] + sum(s_i * ctx[0] + u_i * pk[0] + e_0i), sum(u_i * pk[1] + e_1i)]
 func (pcks *PCKSProtocol) AggregateShares(share1, share2, shareOut PCKSShare) {
 
-	level := uint64(len(share1[0].Coeffs)) - 1
+	level := share1.Level()
 	pcks.dckksContext.contextQ.AddLvl(level, share1[0], share2[0], shareOut[0])
 	pcks.dckksContext.contextQ.AddLvl(level, share1[1], share2[1], shareOut[1])
 }
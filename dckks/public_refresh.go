@@ -14,16 +14,70 @@ type RefreshProtocol struct {
 }
 
 // RefreshShareDecrypt is a struct storing the masked decryption share.
-type RefreshShareDecrypt *ring.Poly
+type RefreshShareDecrypt struct {
+	*ring.Poly
+}
+
+// Level returns the level of the target share.
+func (share RefreshShareDecrypt) Level() uint64 {
+	return uint64(len(share.Coeffs) - 1)
+}
+
+// MarshalBinary encodes the target share, with its level, on a slice of bytes.
+func (share RefreshShareDecrypt) MarshalBinary() ([]byte, error) {
+	return marshalPolysLvl(share.Level(), share.Poly)
+}
+
+// UnmarshalBinary decodes a marshaled decryption share on the target decryption share.
+func (share *RefreshShareDecrypt) UnmarshalBinary(data []byte) error {
+	_, polys, err := unmarshalPolysLvl(data, 1)
+	if err != nil {
+		return err
+	}
+	share.Poly = polys[0]
+	return nil
+}
 
 // RefreshShareRecrypt is a struct storing the masked recryption share.
-type RefreshShareRecrypt *ring.Poly
+type RefreshShareRecrypt struct {
+	*ring.Poly
+}
+
+// Level returns the level of the target share.
+func (share RefreshShareRecrypt) Level() uint64 {
+	return uint64(len(share.Coeffs) - 1)
+}
+
+// MarshalBinary encodes the target share, with its level, on a slice of bytes.
+func (share RefreshShareRecrypt) MarshalBinary() ([]byte, error) {
+	return marshalPolysLvl(share.Level(), share.Poly)
+}
+
+// UnmarshalBinary decodes a marshaled recryption share on the target recryption share.
+func (share *RefreshShareRecrypt) UnmarshalBinary(data []byte) error {
+	_, polys, err := unmarshalPolysLvl(data, 1)
+	if err != nil {
+		return err
+	}
+	share.Poly = polys[0]
+	return nil
+}
+
+// TryNewRefreshProtocol is the same as NewRefreshProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRefreshProtocol(params *ckks.Parameters) (*RefreshProtocol, error) {
+
+	if err := checkParameters("NewRefreshProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewRefreshProtocol(params), nil
+}
 
 // NewRefreshProtocol creates a new instance of the Refresh protocol.
 func NewRefreshProtocol(params *ckks.Parameters) (refreshProtocol *RefreshProtocol) {
 
-	if !params.IsValid() {
-		panic("cannot NewRefreshProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewRefreshProtocol", params); err != nil {
+		panic(err)
 	}
 
 	refreshProtocol = new(RefreshProtocol)
@@ -36,7 +90,7 @@ func NewRefreshProtocol(params *ckks.Parameters) (refreshProtocol *RefreshProtoc
 
 // AllocateShares allocates the shares of the Refresh protocol.
 func (refreshProtocol *RefreshProtocol) AllocateShares(levelStart uint64) (RefreshShareDecrypt, RefreshShareRecrypt) {
-	return refreshProtocol.dckksContext.contextQ.NewPolyLvl(levelStart), refreshProtocol.dckksContext.contextQ.NewPoly()
+	return RefreshShareDecrypt{refreshProtocol.dckksContext.contextQ.NewPolyLvl(levelStart)}, RefreshShareRecrypt{refreshProtocol.dckksContext.contextQ.NewPoly()}
 }
 
 // GenShares generates the decryption and recryption shares of the Refresh protocol.
@@ -63,45 +117,45 @@ func (refreshProtocol *RefreshProtocol) GenShares(sk *ring.Poly, levelStart, nPa
 	}
 
 	// h0 = mask (at level min)
-	context.SetCoefficientsBigintLvl(levelStart, refreshProtocol.maskBigint, shareDecrypt)
+	context.SetCoefficientsBigintLvl(levelStart, refreshProtocol.maskBigint, shareDecrypt.Poly)
 	// h1 = mask (at level max)
-	context.SetCoefficientsBigint(refreshProtocol.maskBigint, shareRecrypt)
+	context.SetCoefficientsBigint(refreshProtocol.maskBigint, shareRecrypt.Poly)
 
 	for i := range refreshProtocol.maskBigint {
 		refreshProtocol.maskBigint[i] = new(big.Int)
 	}
 
-	context.NTTLvl(levelStart, shareDecrypt, shareDecrypt)
-	context.NTT(shareRecrypt, shareRecrypt)
+	context.NTTLvl(levelStart, shareDecrypt.Poly, shareDecrypt.Poly)
+	context.NTT(shareRecrypt.Poly, shareRecrypt.Poly)
 
 	// h0 = sk*c1 + mask
-	context.MulCoeffsMontgomeryAndAddLvl(levelStart, sk, ciphertext.Value()[1], shareDecrypt)
+	context.MulCoeffsMontgomeryAndAddLvl(levelStart, sk, ciphertext.Value()[1], shareDecrypt.Poly)
 
 	// h1 = sk*a + mask
-	context.MulCoeffsMontgomeryAndAdd(sk, crs, shareRecrypt)
+	context.MulCoeffsMontgomeryAndAdd(sk, crs, shareRecrypt.Poly)
 
 	// h0 = sk*c1 + mask + e0
 	sampler.SampleNTT(refreshProtocol.tmp)
-	context.AddLvl(levelStart, shareDecrypt, refreshProtocol.tmp, shareDecrypt)
+	context.AddLvl(levelStart, shareDecrypt.Poly, refreshProtocol.tmp, shareDecrypt.Poly)
 
 	// h1 = sk*a + mask + e1
 	sampler.SampleNTT(refreshProtocol.tmp)
-	context.Add(shareRecrypt, refreshProtocol.tmp, shareRecrypt)
+	context.Add(shareRecrypt.Poly, refreshProtocol.tmp, shareRecrypt.Poly)
 
 	// h1 = -sk*c1 - mask - e0
-	context.Neg(shareRecrypt, shareRecrypt)
+	context.Neg(shareRecrypt.Poly, shareRecrypt.Poly)
 
 	refreshProtocol.tmp.Zero()
 }
 
-// Aggregate adds share1 with share2 on shareOut.
+// Aggregate adds share1 with share2 on shareOut, which are the polynomials of either decryption or recryption shares.
 func (refreshProtocol *RefreshProtocol) Aggregate(share1, share2, shareOut *ring.Poly) {
 	refreshProtocol.dckksContext.contextQ.AddLvl(uint64(len(share1.Coeffs)-1), share1, share2, shareOut)
 }
 
 // Decrypt operates a masked decryption on the ciphertext with the given decryption share.
 func (refreshProtocol *RefreshProtocol) Decrypt(ciphertext *ckks.Ciphertext, shareDecrypt RefreshShareDecrypt) {
-	refreshProtocol.dckksContext.contextQ.AddLvl(ciphertext.Level(), ciphertext.Value()[0], shareDecrypt, ciphertext.Value()[0])
+	refreshProtocol.dckksContext.contextQ.AddLvl(ciphertext.Level(), ciphertext.Value()[0], shareDecrypt.Poly, ciphertext.Value()[0])
 }
 
 // Recode takes a masked decrypted ciphertext at modulus Q_0 and returns the same masked decrypted ciphertext at modulus Q_L, with Q_0 << Q_L.
@@ -141,7 +195,7 @@ func (refreshProtocol *RefreshProtocol) Recode(ciphertext *ckks.Ciphertext) {
 // Recrypt operates a masked recryption on the masked decrypted ciphertext.
 func (refreshProtocol *RefreshProtocol) Recrypt(ciphertext *ckks.Ciphertext, crs *ring.Poly, shareRecrypt RefreshShareRecrypt) {
 
-	refreshProtocol.dckksContext.contextQ.Add(ciphertext.Value()[0], shareRecrypt, ciphertext.Value()[0])
+	refreshProtocol.dckksContext.contextQ.Add(ciphertext.Value()[0], shareRecrypt.Poly, ciphertext.Value()[0])
 
 	ciphertext.Value()[1] = crs.CopyNew()
 }
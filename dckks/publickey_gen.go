@@ -12,13 +12,33 @@ type CKGProtocol struct {
 }
 
 // CKGShare is a struct storing the CKG protocol's share.
-type CKGShare *ring.Poly
+type CKGShare struct {
+	*ring.Poly
+}
+
+// UnmarshalBinary decodes a marshaled CKG share on the target CKG share.
+func (share *CKGShare) UnmarshalBinary(data []byte) error {
+	if share.Poly == nil {
+		share.Poly = new(ring.Poly)
+	}
+	return share.Poly.UnmarshalBinary(data)
+}
+
+// TryNewCKGProtocol is the same as NewCKGProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewCKGProtocol(params *ckks.Parameters) (*CKGProtocol, error) {
+
+	if err := checkParameters("NewCKGProtocol", params); err != nil {
+		return nil, err
+	}
+
+	return NewCKGProtocol(params), nil
+}
 
 // NewCKGProtocol creates a new CKGProtocol instance
 func NewCKGProtocol(params *ckks.Parameters) *CKGProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewCKGProtocol : params not valid (check if they where generated properly)")
+	if err := checkParameters("NewCKGProtocol", params); err != nil {
+		panic(err)
 	}
 
 	ckg := new(CKGProtocol)
@@ -28,7 +48,7 @@ func NewCKGProtocol(params *ckks.Parameters) *CKGProtocol {
 
 // AllocateShares allocates the share of the CKG protocol.
 func (ckg *CKGProtocol) AllocateShares() CKGShare {
-	return ckg.dckksContext.contextQP.NewPoly()
+	return CKGShare{ckg.dckksContext.contextQP.NewPoly()}
 }
 
 // GenShare generates the party's public key share from its secret key as:
@@ -37,16 +57,16 @@ func (ckg *CKGProtocol) AllocateShares() CKGShare {
 //
 // for the receiver protocol. Has no effect is the share was already generated.
 func (ckg *CKGProtocol) GenShare(sk *ring.Poly, crs *ring.Poly, shareOut CKGShare) {
-	ckg.dckksContext.gaussianSampler.SampleNTT(shareOut)
-	ckg.dckksContext.contextQP.MulCoeffsMontgomeryAndSub(sk, crs, shareOut)
+	ckg.dckksContext.gaussianSampler.SampleNTT(shareOut.Poly)
+	ckg.dckksContext.contextQP.MulCoeffsMontgomeryAndSub(sk, crs, shareOut.Poly)
 }
 
 // AggregateShares aggregates a new share to the aggregate key
 func (ckg *CKGProtocol) AggregateShares(share1, share2, shareOut CKGShare) {
-	ckg.dckksContext.contextQP.Add(share1, share2, shareOut)
+	ckg.dckksContext.contextQP.Add(share1.Poly, share2.Poly, shareOut.Poly)
 }
 
 // GenPublicKey return the current aggregation of the received shares as a bfv.PublicKey.
 func (ckg *CKGProtocol) GenPublicKey(roundShare CKGShare, crs *ring.Poly, pubkey *ckks.PublicKey) {
-	pubkey.Set([2]*ring.Poly{roundShare, crs})
+	pubkey.Set([2]*ring.Poly{roundShare.Poly, crs})
 }
@@ -8,6 +8,7 @@ import (
 // RKGProtocol is a structure storing the parameters for the collective evaluation-key generation.
 type RKGProtocol struct {
 	dckksContext *dckksContext
+	gadget       *keySwitchingGadget
 	polypool     *ring.Poly
 }
 
@@ -20,33 +21,164 @@ type RKGShareRoundTwo [][2]*ring.Poly
 // RKGShareRoundThree is a struct storing the round three share of the RKG protocol.
 type RKGShareRoundThree []*ring.Poly
 
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *RKGShareRoundOne) MarshalBinary() ([]byte, error) {
+	rLength := (*share)[0].GetDataLen(true)
+	data := make([]byte, 1+rLength*uint64(len(*share)))
+	data[0] = uint8(len(*share))
+
+	pointer := uint64(1)
+	for _, s := range *share {
+		tmp, err := s.WriteTo(data[pointer : pointer+rLength])
+		if err != nil {
+			return []byte{}, err
+		}
+		pointer += tmp
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RKGShareRoundOne) UnmarshalBinary(data []byte) error {
+	//share.modulus = data[0]
+	lenShare := data[0]
+	rLength := len(data[1:]) / int(lenShare)
+	if *share == nil {
+		*share = make([]*ring.Poly, lenShare)
+	}
+	ptr := 1
+	for i := uint8(0); i < lenShare; i++ {
+		if (*share)[i] == nil {
+			(*share)[i] = new(ring.Poly)
+		}
+		err := (*share)[i].UnmarshalBinary(data[ptr : ptr+rLength])
+		if err != nil {
+			return err
+		}
+		ptr += rLength
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *RKGShareRoundTwo) MarshalBinary() ([]byte, error) {
+	return marshalPolyPairs(*share)
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RKGShareRoundTwo) UnmarshalBinary(data []byte) (err error) {
+	*share, err = unmarshalPolyPairs(data)
+	return err
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *RKGShareRoundThree) MarshalBinary() ([]byte, error) {
+	rLength := (*share)[0].GetDataLen(true)
+	data := make([]byte, 1+rLength*uint64(len(*share)))
+	data[0] = uint8(len(*share))
+
+	pointer := uint64(1)
+	for _, s := range *share {
+		tmp, err := s.WriteTo(data[pointer : pointer+rLength])
+		if err != nil {
+			return []byte{}, err
+		}
+		pointer += tmp
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RKGShareRoundThree) UnmarshalBinary(data []byte) error {
+	//share.modulus = data[0]
+	lenShare := data[0]
+	rLength := len(data[1:]) / int(lenShare)
+	if *share == nil {
+		*share = make([]*ring.Poly, lenShare)
+	}
+	ptr := 1
+	for i := uint8(0); i < lenShare; i++ {
+		if (*share)[i] == nil {
+			(*share)[i] = new(ring.Poly)
+		}
+		err := (*share)[i].UnmarshalBinary(data[ptr : ptr+rLength])
+		if err != nil {
+			return err
+		}
+		ptr += rLength
+	}
+
+	return nil
+}
+
 // AllocateShares allocates the shares of the RKG protocol.
 func (ekg *RKGProtocol) AllocateShares() (r1 RKGShareRoundOne, r2 RKGShareRoundTwo, r3 RKGShareRoundThree) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
-	r1 = make([]*ring.Poly, ekg.dckksContext.beta)
-	r2 = make([][2]*ring.Poly, ekg.dckksContext.beta)
-	r3 = make([]*ring.Poly, ekg.dckksContext.beta)
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
-		r1[i] = contextQP.NewPoly()
-		r2[i][0] = contextQP.NewPoly()
-		r2[i][1] = contextQP.NewPoly()
-		r3[i] = contextQP.NewPoly()
+	r1 = make([]*ring.Poly, ekg.gadget.size)
+	r2 = make([][2]*ring.Poly, ekg.gadget.size)
+	r3 = make([]*ring.Poly, ekg.gadget.size)
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		r1[i] = contextKeys.NewPoly()
+		r2[i][0] = contextKeys.NewPoly()
+		r2[i][1] = contextKeys.NewPoly()
+		r3[i] = contextKeys.NewPoly()
 	}
 	return
 }
 
+// TryNewEkgProtocol is the same as NewEkgProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewEkgProtocol(params *ckks.Parameters) (*RKGProtocol, error) {
+
+	if err := checkKeySwitching("NewEkgProtocol", params, 0); err != nil {
+		return nil, err
+	}
+
+	return NewEkgProtocol(params), nil
+}
+
 // NewEkgProtocol creates a new RKGProtocol object that will be used to generate a collective evaluation-key.
 func NewEkgProtocol(params *ckks.Parameters) *RKGProtocol {
 
-	if !params.IsValid() {
-		panic("cannot NewEkgProtocol : params not valid (check if they where generated properly)")
+	if err := checkKeySwitching("NewEkgProtocol", params, 0); err != nil {
+		panic(err)
+	}
+
+	return newEkgProtocol(params, 0)
+}
+
+// TryNewEkgProtocolGadget is the same as NewEkgProtocolGadget, but returns an error instead of panicking if the parameters are invalid.
+func TryNewEkgProtocolGadget(params *ckks.Parameters, logBase uint64) (*RKGProtocol, error) {
+
+	if err := checkKeySwitching("NewEkgProtocolGadget", params, logBase); err != nil {
+		return nil, err
 	}
 
+	return NewEkgProtocolGadget(params, logBase), nil
+}
+
+// NewEkgProtocolGadget creates a new RKGProtocol object that will be used to generate a collective evaluation-key using
+// the gadget decomposition of base 2^logBase (see ckks.GenRelinKeyGadget), which does not require the special primes Pi.
+// The protocol takes len(params.Qi)*params.GadgetDigits(logBase) CRPs, and its output must be allocated with ckks.NewRelinKeyGadget.
+func NewEkgProtocolGadget(params *ckks.Parameters, logBase uint64) *RKGProtocol {
+
+	if err := checkKeySwitching("NewEkgProtocolGadget", params, logBase); err != nil {
+		panic(err)
+	}
+
+	return newEkgProtocol(params, logBase)
+}
+
+func newEkgProtocol(params *ckks.Parameters, logBase uint64) *RKGProtocol {
+
 	ekg := new(RKGProtocol)
 	dckksContext := newDckksContext(params)
 	ekg.dckksContext = dckksContext
+	ekg.gadget = newKeySwitchingGadget(dckksContext, logBase)
 	ekg.polypool = ekg.dckksContext.contextQP.NewPoly()
 
 	return ekg
@@ -56,7 +188,7 @@ func NewEkgProtocol(params *ckks.Parameters) *RKGProtocol {
 // Each party is required to pre-compute a secret additional ephemeral key in addition to its share
 // of the collective secret-key.
 func (ekg *RKGProtocol) NewEphemeralKey(p float64) (ephemeralKey *ring.Poly) {
-	return ekg.dckksContext.contextQP.SampleTernaryMontgomeryNTTNew(p)
+	return ekg.gadget.contextKeys.SampleTernaryMontgomeryNTTNew(p)
 }
 
 // GenShareRoundOne is the first of three rounds of the RKGProtocol protocol. Each party generates a pseudo encryption of
@@ -64,46 +196,24 @@ func (ekg *RKGProtocol) NewEphemeralKey(p float64) (ephemeralKey *ring.Poly) {
 // j-1 parties.
 func (ekg *RKGProtocol) GenShareRoundOne(u, sk *ring.Poly, crp []*ring.Poly, shareOut RKGShareRoundOne) {
 
-	contextQP := ekg.dckksContext.contextQP
-
-	var index uint64
+	contextKeys := ekg.gadget.contextKeys
 
-	// Given a base decomposition w_i (here the CRT decomposition)
-	// computes [-u*a_i + P*s_i + e_i]
+	// Given a base decomposition w_i (the CRT decomposition, or the CRT decomposition combined with
+	// a decomposition in base 2^logBase) computes [-u*a_i + s*w_i + e_i]
 	// where a_i = crp_i
 
-	ekg.polypool.Copy(sk)
-
-	contextQP.MulScalarBigint(ekg.polypool, ekg.dckksContext.contextP.ModulusBigint, ekg.polypool)
+	ekg.gadget.scaleSecret(sk, ekg.polypool)
 
-	contextQP.InvMForm(ekg.polypool, ekg.polypool)
-
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// h = e
-		ekg.dckksContext.gaussianSampler.SampleNTT(shareOut[i])
-
-		// h = sk*CrtBaseDecompQi + e
-		for j := uint64(0); j < ekg.dckksContext.alpha; j++ {
-
-			index = i*ekg.dckksContext.alpha + j
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i])
 
-			qi := contextQP.Modulus[index]
-			tmp0 := ekg.polypool.Coeffs[index]
-			tmp1 := shareOut[i].Coeffs[index]
-
-			for w := uint64(0); w < contextQP.N; w++ {
-				tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
-			}
-
-			// Handles the case where nb pj does not divides nb qi
-			if index >= uint64(len(ekg.dckksContext.params.Qi)-1) {
-				break
-			}
-		}
+		// h = sk*w_i + e
+		ekg.gadget.addElement(i, ekg.polypool, shareOut[i])
 
-		// h = sk*CrtBaseDecompQi + -u*a + e
-		contextQP.MulCoeffsMontgomeryAndSub(u, crp[i], shareOut[i])
+		// h = sk*w_i + -u*a + e
+		contextKeys.MulCoeffsMontgomeryAndSub(u, crp[i], shareOut[i])
 	}
 
 	ekg.polypool.Zero()
@@ -114,10 +224,10 @@ func (ekg *RKGProtocol) GenShareRoundOne(u, sk *ring.Poly, crp []*ring.Poly, sha
 // AggregateShareRoundOne sums share1 with share2 on shareOut.
 func (ekg *RKGProtocol) AggregateShareRoundOne(share1, share2, shareOut RKGShareRoundOne) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
-		contextQP.Add(share1[i], share2[i], shareOut[i])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		contextKeys.Add(share1[i], share2[i], shareOut[i])
 	}
 
 }
@@ -131,26 +241,26 @@ func (ekg *RKGProtocol) AggregateShareRoundOne(share1, share2, shareOut RKGShare
 // and broadcasts both values to the other j-1 parties.
 func (ekg *RKGProtocol) GenShareRoundTwo(round1 RKGShareRoundOne, sk *ring.Poly, crp []*ring.Poly, shareOut RKGShareRoundTwo) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
 	// Each sample is of the form [-u*a_i + s*w_i + e_i]
 	// So for each element of the base decomposition w_i :
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// Computes [(sum samples)*sk + e_1i, sk*a + e_2i]
 
 		// (AggregateShareRoundTwo samples) * sk
-		contextQP.MulCoeffsMontgomery(round1[i], sk, shareOut[i][0])
+		contextKeys.MulCoeffsMontgomery(round1[i], sk, shareOut[i][0])
 
 		// (AggregateShareRoundTwo samples) * sk + e_1i
-		ekg.dckksContext.gaussianSampler.SampleNTT(ekg.polypool)
-		contextQP.Add(shareOut[i][0], ekg.polypool, shareOut[i][0])
+		ekg.gadget.gaussianSampler.SampleNTT(ekg.polypool)
+		contextKeys.Add(shareOut[i][0], ekg.polypool, shareOut[i][0])
 
 		// Second Element
 		// e_2i
-		ekg.dckksContext.gaussianSampler.SampleNTT(shareOut[i][1])
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i][1])
 		// s*a + e_2i
-		contextQP.MulCoeffsMontgomeryAndAdd(sk, crp[i], shareOut[i][1])
+		contextKeys.MulCoeffsMontgomeryAndAdd(sk, crp[i], shareOut[i][1])
 	}
 
 	ekg.polypool.Zero()
@@ -165,11 +275,11 @@ func (ekg *RKGProtocol) GenShareRoundTwo(round1 RKGShareRoundOne, sk *ring.Poly,
 // = [s * (-u*a + s*w + e) + e_1, s*a + e_2].
 func (ekg *RKGProtocol) AggregateShareRoundTwo(share1, share2, shareOut RKGShareRoundTwo) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
-		contextQP.Add(share1[i][0], share2[i][0], shareOut[i][0])
-		contextQP.Add(share1[i][1], share2[i][1], shareOut[i][1])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		contextKeys.Add(share1[i][0], share2[i][0], shareOut[i][0])
+		contextKeys.Add(share1[i][1], share2[i][1], shareOut[i][1])
 	}
 
 }
@@ -182,42 +292,42 @@ func (ekg *RKGProtocol) AggregateShareRoundTwo(share1, share2, shareOut RKGShare
 // and broadcasts the result to the other j-1 parties.
 func (ekg *RKGProtocol) GenShareRoundThree(round2 RKGShareRoundTwo, u, sk *ring.Poly, shareOut RKGShareRoundThree) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
 	// (u_i - s_i)
-	contextQP.Sub(u, sk, ekg.polypool)
+	contextKeys.Sub(u, sk, ekg.polypool)
 
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
 		// (u - s) * (sum [x][s*a_i + e_2i]) + e3i
-		ekg.dckksContext.gaussianSampler.SampleNTT(shareOut[i])
-		contextQP.MulCoeffsMontgomeryAndAdd(ekg.polypool, round2[i][1], shareOut[i])
+		ekg.gadget.gaussianSampler.SampleNTT(shareOut[i])
+		contextKeys.MulCoeffsMontgomeryAndAdd(ekg.polypool, round2[i][1], shareOut[i])
 	}
 }
 
 // AggregateShareRoundThree sums share1 with share2 on shareOut.
 func (ekg *RKGProtocol) AggregateShareRoundThree(share1, share2, shareOut RKGShareRoundThree) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
-		contextQP.Add(share1[i], share2[i], shareOut[i])
+	for i := uint64(0); i < ekg.gadget.size; i++ {
+		contextKeys.Add(share1[i], share2[i], shareOut[i])
 	}
 }
 
 // GenRelinearizationKey finalizes the protocol and returns the collective EvalutionKey.
 func (ekg *RKGProtocol) GenRelinearizationKey(round2 RKGShareRoundTwo, round3 RKGShareRoundThree, evalKeyOut *ckks.EvaluationKey) {
 
-	contextQP := ekg.dckksContext.contextQP
+	contextKeys := ekg.gadget.contextKeys
 
 	key := evalKeyOut.Get().Get()
-	for i := uint64(0); i < ekg.dckksContext.beta; i++ {
+	for i := uint64(0); i < ekg.gadget.size; i++ {
 
-		contextQP.Add(round2[i][0], round3[i], key[i][0])
+		contextKeys.Add(round2[i][0], round3[i], key[i][0])
 		key[i][1].Copy(round2[i][1])
 
-		contextQP.MForm(key[i][0], key[i][0])
-		contextQP.MForm(key[i][1], key[i][1])
+		contextKeys.MForm(key[i][0], key[i][0])
+		contextKeys.MForm(key[i][1], key[i][1])
 
 	}
 }
@@ -1,6 +1,9 @@
 package dckks
 
 import (
+	"encoding/binary"
+	"errors"
+
 	"github.com/ldsec/lattigo/ckks"
 	"github.com/ldsec/lattigo/ring"
 )
@@ -8,6 +11,7 @@ import (
 // RTGProtocol is the structure storing the parameters for the collective rotation-keys generation.
 type RTGProtocol struct {
 	dckksContext *dckksContext
+	gadget       *keySwitchingGadget
 
 	galElRotRow uint64
 	galElRotCol map[ckks.Rotation][]uint64
@@ -23,32 +27,114 @@ type RTGShare struct {
 	Value []*ring.Poly
 }
 
+// MarshalBinary encode the target element on a slice of byte.
+func (share *RTGShare) MarshalBinary() ([]byte, error) {
+	lenRing := share.Value[0].GetDataLen(true)
+	data := make([]byte, 3*8+lenRing*uint64(len(share.Value)))
+	binary.BigEndian.PutUint64(data[0:8], share.K)
+	binary.BigEndian.PutUint64(data[8:16], uint64(share.Type))
+	binary.BigEndian.PutUint64(data[16:24], lenRing)
+	ptr := uint64(24)
+	for _, val := range share.Value {
+		cnt, err := val.WriteTo(data[ptr : ptr+lenRing])
+		if err != nil {
+			return []byte{}, err
+		}
+		ptr += cnt
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *RTGShare) UnmarshalBinary(data []byte) error {
+	if len(data) <= 24 {
+		return errors.New("Unsufficient data length")
+	}
+	share.K = binary.BigEndian.Uint64(data[0:8])
+	share.Type = ckks.Rotation(binary.BigEndian.Uint64(data[8:16]))
+	lenRing := binary.BigEndian.Uint64(data[16:24])
+	valLength := uint64(len(data)-3*8) / lenRing
+
+	share.Value = make([]*ring.Poly, valLength)
+	ptr := uint64(24)
+	for i := range share.Value {
+		share.Value[i] = new(ring.Poly)
+		err := share.Value[i].UnmarshalBinary(data[ptr : ptr+lenRing])
+		if err != nil {
+			return err
+		}
+		ptr += lenRing
+
+	}
+
+	return nil
+}
+
 // AllocateShare allocates the share the the RTG protocol.
 func (rtg *RTGProtocol) AllocateShare() (rtgShare RTGShare) {
-	rtgShare.Value = make([]*ring.Poly, rtg.dckksContext.beta)
-	for i := uint64(0); i < rtg.dckksContext.beta; i++ {
-		rtgShare.Value[i] = rtg.dckksContext.contextQP.NewPoly()
+	rtgShare.Value = make([]*ring.Poly, rtg.gadget.size)
+	for i := uint64(0); i < rtg.gadget.size; i++ {
+		rtgShare.Value[i] = rtg.gadget.contextKeys.NewPoly()
 	}
 	return
 }
 
+// TryNewRotKGProtocol is the same as NewRotKGProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRotKGProtocol(params *ckks.Parameters) (*RTGProtocol, error) {
+
+	if err := checkKeySwitching("NewRotKGProtocol", params, 0); err != nil {
+		return nil, err
+	}
+
+	return NewRotKGProtocol(params), nil
+}
+
 // NewRotKGProtocol creates a new rotkg object and will be used to generate collective rotation-keys from a shared secret-key among j parties.
 func NewRotKGProtocol(params *ckks.Parameters) (rtg *RTGProtocol) {
 
-	if !params.IsValid() {
-		panic("cannot NewRotKGProtocol : params not valid (check if they where generated properly)")
+	if err := checkKeySwitching("NewRotKGProtocol", params, 0); err != nil {
+		panic(err)
+	}
+
+	return newRotKGProtocol(params, 0)
+}
+
+// TryNewRotKGProtocolGadget is the same as NewRotKGProtocolGadget, but returns an error instead of panicking if the parameters are invalid.
+func TryNewRotKGProtocolGadget(params *ckks.Parameters, logBase uint64) (*RTGProtocol, error) {
+
+	if err := checkKeySwitching("NewRotKGProtocolGadget", params, logBase); err != nil {
+		return nil, err
+	}
+
+	return NewRotKGProtocolGadget(params, logBase), nil
+}
+
+// NewRotKGProtocolGadget creates a new rotkg object that will be used to generate collective rotation-keys using the gadget
+// decomposition of base 2^logBase (see ckks.GenRotGadget), which does not require the special primes Pi.
+// The protocol takes len(params.Qi)*params.GadgetDigits(logBase) CRPs.
+func NewRotKGProtocolGadget(params *ckks.Parameters, logBase uint64) (rtg *RTGProtocol) {
+
+	if err := checkKeySwitching("NewRotKGProtocolGadget", params, logBase); err != nil {
+		panic(err)
 	}
 
+	return newRotKGProtocol(params, logBase)
+}
+
+func newRotKGProtocol(params *ckks.Parameters, logBase uint64) (rtg *RTGProtocol) {
+
 	rtg = new(RTGProtocol)
 
 	dckksContext := newDckksContext(params)
 
 	rtg.dckksContext = dckksContext
+	rtg.gadget = newKeySwitchingGadget(dckksContext, logBase)
 
-	rtg.tmpSwitchKey = make([][2]*ring.Poly, rtg.dckksContext.beta)
+	rtg.tmpSwitchKey = make([][2]*ring.Poly, rtg.gadget.size)
 	for i := range rtg.tmpSwitchKey {
-		rtg.tmpSwitchKey[i][0] = dckksContext.contextQP.NewPoly()
-		rtg.tmpSwitchKey[i][1] = dckksContext.contextQP.NewPoly()
+		rtg.tmpSwitchKey[i][0] = rtg.gadget.contextKeys.NewPoly()
+		rtg.tmpSwitchKey[i][1] = rtg.gadget.contextKeys.NewPoly()
 	}
 
 	rtg.tmpPoly = dckksContext.contextQP.NewPoly()
@@ -94,46 +180,26 @@ func (rtg *RTGProtocol) GenShare(rotType ckks.Rotation, k uint64, sk *ring.Poly,
 // genswitchkey is a generic method to generate the public-share of the collective rotation-key.
 func (rtg *RTGProtocol) genShare(sk *ring.Poly, galEl uint64, crp []*ring.Poly, evakey []*ring.Poly) {
 
-	contextQP := rtg.dckksContext.contextQP
+	contextKeys := rtg.gadget.contextKeys
 
 	ring.PermuteNTT(sk, galEl, rtg.tmpPoly)
 
-	contextQP.MulScalarBigint(rtg.tmpPoly, rtg.dckksContext.contextP.ModulusBigint, rtg.tmpPoly)
-
-	contextQP.InvMForm(rtg.tmpPoly, rtg.tmpPoly)
+	rtg.gadget.scaleSecret(rtg.tmpPoly, rtg.tmpPoly)
 
-	var index uint64
-
-	for i := uint64(0); i < rtg.dckksContext.beta; i++ {
+	for i := uint64(0); i < rtg.gadget.size; i++ {
 
 		// e
-		evakey[i] = rtg.dckksContext.gaussianSampler.SampleNTTNew()
+		evakey[i] = rtg.gadget.gaussianSampler.SampleNTTNew()
 
 		// a is the CRP
 
 		// e + sk_in * (qiBarre*qiStar) * 2^w
 		// (qiBarre*qiStar)%qi = 1, else 0
-		for j := uint64(0); j < rtg.dckksContext.alpha; j++ {
-
-			index = i*rtg.dckksContext.alpha + j
-
-			qi := contextQP.Modulus[index]
-			tmp0 := rtg.tmpPoly.Coeffs[index]
-			tmp1 := evakey[i].Coeffs[index]
-
-			for w := uint64(0); w < contextQP.N; w++ {
-				tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
-			}
-
-			// Handles the case where nb pj does not divides nb qi
-			if index >= uint64(len(rtg.dckksContext.params.Qi)-1) {
-				break
-			}
-		}
+		rtg.gadget.addElement(i, rtg.tmpPoly, evakey[i])
 
 		// sk_in * (qiBarre*qiStar) * 2^w - a*sk + e
-		contextQP.MulCoeffsMontgomeryAndSub(crp[i], sk, evakey[i])
-		contextQP.MForm(evakey[i], evakey[i])
+		contextKeys.MulCoeffsMontgomeryAndSub(crp[i], sk, evakey[i])
+		contextKeys.MForm(evakey[i], evakey[i])
 
 	}
 
@@ -147,7 +213,7 @@ func (rtg *RTGProtocol) genShare(sk *ring.Poly, galEl uint64, crp []*ring.Poly,
 //
 // [sum(a*a_j + pi(s_j) + e_j), a]
 func (rtg *RTGProtocol) Aggregate(share1, share2, shareOut RTGShare) {
-	contextQP := rtg.dckksContext.contextQP
+	contextKeys := rtg.gadget.contextKeys
 
 	if share1.Type != share2.Type || share1.K != share2.K {
 		panic("cannot aggregate shares of different types")
@@ -155,8 +221,8 @@ func (rtg *RTGProtocol) Aggregate(share1, share2, shareOut RTGShare) {
 
 	shareOut.Type = share1.Type
 	shareOut.K = share1.K
-	for i := uint64(0); i < rtg.dckksContext.beta; i++ {
-		contextQP.Add(share1.Value[i], share2.Value[i], shareOut.Value[i])
+	for i := uint64(0); i < rtg.gadget.size; i++ {
+		contextKeys.Add(share1.Value[i], share2.Value[i], shareOut.Value[i])
 	}
 }
 
@@ -165,10 +231,10 @@ func (rtg *RTGProtocol) Finalize(params *ckks.Parameters, share RTGShare, crp []
 
 	k := share.K & ((rtg.dckksContext.n >> 1) - 1)
 
-	for i := uint64(0); i < rtg.dckksContext.beta; i++ {
+	for i := uint64(0); i < rtg.gadget.size; i++ {
 		rtg.tmpSwitchKey[i][0].Copy(share.Value[i])
-		rtg.dckksContext.contextQP.MForm(crp[i], rtg.tmpSwitchKey[i][1])
+		rtg.gadget.contextKeys.MForm(crp[i], rtg.tmpSwitchKey[i][1])
 	}
 
-	rotKey.SetRotKey(params, rtg.tmpSwitchKey, share.Type, k)
+	rotKey.SetRotKeyGadget(params, rtg.tmpSwitchKey, share.Type, k, rtg.gadget.logBase)
 }
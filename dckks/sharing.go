@@ -0,0 +1,249 @@
+package dckks
+
+import (
+	"math/big"
+
+	"github.com/ldsec/lattigo/ckks"
+	"github.com/ldsec/lattigo/ring"
+)
+
+// AdditiveShare is a struct holding an additive secret-share of a plaintext at a given level, as a polynomial of
+// R_Q_level in the coefficient domain. The plaintext, scaled by the scale of its ciphertext, is the sum modulo
+// Q_level of the additive shares of all the parties.
+type AdditiveShare struct {
+	*ring.Poly
+}
+
+// Level returns the level of the target share.
+func (share AdditiveShare) Level() uint64 {
+	return uint64(len(share.Coeffs) - 1)
+}
+
+// MarshalBinary encodes the target share, with its level, on a slice of bytes.
+func (share AdditiveShare) MarshalBinary() ([]byte, error) {
+	return marshalPolysLvl(share.Level(), share.Poly)
+}
+
+// UnmarshalBinary decodes a marshaled additive share on the target additive share.
+func (share *AdditiveShare) UnmarshalBinary(data []byte) error {
+	_, polys, err := unmarshalPolysLvl(data, 1)
+	if err != nil {
+		return err
+	}
+	share.Poly = polys[0]
+	return nil
+}
+
+// E2SProtocol is the structure storing the parameters and temporary buffers for the encryption-to-shares protocol,
+// which turns a ciphertext encrypted under the collective secret-key into additive secret-shares of its plaintext.
+//
+// Each party i samples a uniform mask M_i in R_Q_level, which is its additive share, and computes the public share
+// h_i = s_i*ct[1] + e_i - M_i, where e_i is a flooding noise. The party receiving the aggregated public share
+// decrypts ct[0] + sum h_i = m - sum M_i + e and adds its own mask, so that the additive shares of the parties
+// sum to m + e.
+type E2SProtocol struct {
+	dckksContext *dckksContext
+
+	floodingBound *big.Int
+
+	tmp *ring.Poly
+}
+
+// TryNewE2SProtocol is the same as NewE2SProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewE2SProtocol(params *ckks.Parameters, noiseBound float64, lambda uint64) (*E2SProtocol, error) {
+
+	if err := checkFlooding("NewE2SProtocol", params, noiseBound, lambda); err != nil {
+		return nil, err
+	}
+
+	return NewE2SProtocol(params, noiseBound, lambda), nil
+}
+
+// NewE2SProtocol creates a new E2SProtocol instance. The public shares are flooded with a noise whose coefficients are
+// uniformly distributed in [-B, B], with B = 2^lambda * noiseBound, so that a decryption noise whose coefficients are
+// at most noiseBound is hidden with a statistical distance of at most 2^-lambda per coefficient (N * 2^-lambda for the
+// whole polynomial). The decryption noise of a ciphertext depends on the circuit that produced it, and thus noiseBound
+// must bound the noise of all the ciphertexts on which the protocol is run; it cannot be smaller than the standard
+// deviation of the encryption noise. The flooding noise is sampled as a big integer reduced modulo each Qi, so that B
+// is only limited by the modulus Q: B must be smaller than Q_level/2 at the level of the ciphertexts.
+//
+// The flooding noise is added to the shares of the plaintext, and thus reduces its precision by about
+// lambda + log2(noiseBound) bits with respect to the scale of the ciphertexts, which must be large enough to
+// preserve the required precision.
+func NewE2SProtocol(params *ckks.Parameters, noiseBound float64, lambda uint64) *E2SProtocol {
+
+	if err := checkFlooding("NewE2SProtocol", params, noiseBound, lambda); err != nil {
+		panic(err)
+	}
+
+	dckksContext := newDckksContext(params)
+
+	e2s := new(E2SProtocol)
+	e2s.dckksContext = dckksContext
+	e2s.floodingBound = floodingBound(noiseBound, lambda)
+	e2s.tmp = dckksContext.contextQ.NewPoly()
+
+	return e2s
+}
+
+// AllocateShares allocates the additive share and the public share of the E2S protocol at the given level.
+func (e2s *E2SProtocol) AllocateShares(level uint64) (AdditiveShare, CKSShare) {
+	return AdditiveShare{e2s.dckksContext.contextQ.NewPolyLvl(level)}, CKSShare{e2s.dckksContext.contextQ.NewPolyLvl(level)}
+}
+
+// GenShare generates the additive share of the party on secretShareOut, and its public share
+//
+// [s_i * ct[1] + e_i - M_i]
+//
+// on publicShareOut. The shares must be at the level of the ciphertext. The public shares of all the parties must
+// be aggregated and sent to the party receiving the last additive share. The flooding bound must be smaller than
+// Q_level/2 at the level of the ciphertext.
+func (e2s *E2SProtocol) GenShare(sk *ring.Poly, ct *ckks.Ciphertext, secretShareOut AdditiveShare, publicShareOut CKSShare) {
+	if err := e2s.TryGenShare(sk, ct, secretShareOut, publicShareOut); err != nil {
+		panic(err)
+	}
+}
+
+// TryGenShare is the same as GenShare, but returns an error instead of panicking if the ciphertext is nil, or if the
+// flooding bound is not smaller than Q_level/2 at the level of the ciphertext.
+func (e2s *E2SProtocol) TryGenShare(sk *ring.Poly, ct *ckks.Ciphertext, secretShareOut AdditiveShare, publicShareOut CKSShare) error {
+
+	if ct == nil {
+		return &ckks.Error{Op: "GenShare", Err: ckks.ErrNilOperand}
+	}
+
+	contextQ := e2s.dckksContext.contextQ
+
+	level := ct.Level()
+
+	if bound := new(big.Int).Lsh(e2s.floodingBound, 1); bound.Cmp(modulusBigint(contextQ.Modulus[:level+1])) >= 0 {
+		return &ckks.Error{Op: "GenShare", Err: ckks.ErrLevelMismatch, Reason: "the flooding bound exceeds Q_level/2 at the level of the ciphertext"}
+	}
+
+	// h = s*ct[1]
+	contextQ.MulCoeffsMontgomeryLvl(level, ct.Value()[1], sk, publicShareOut.Poly)
+
+	// M = uniform polynomial in R_Q_level
+	contextQ.UniformPoly(e2s.tmp)
+	contextQ.CopyLvl(level, e2s.tmp, secretShareOut.Poly)
+
+	// tmp = e - M
+	contextQ.NegLvl(level, e2s.tmp, e2s.tmp)
+	contextQ.SampleUniformBigAndAddLvl(level, e2s.tmp, e2s.floodingBound)
+
+	// h = s*ct[1] + e - M
+	contextQ.NTTLvl(level, e2s.tmp, e2s.tmp)
+	contextQ.AddLvl(level, publicShareOut.Poly, e2s.tmp, publicShareOut.Poly)
+
+	e2s.tmp.Zero()
+
+	return nil
+}
+
+// AggregateShares sums the public shares share1 and share2 on shareOut.
+func (e2s *E2SProtocol) AggregateShares(share1, share2, shareOut CKSShare) {
+	e2s.dckksContext.contextQ.AddLvl(share1.Level(), share1.Poly, share2.Poly, shareOut.Poly)
+}
+
+// GetShare decrypts the ciphertext with the aggregated public share of all the parties (including the target
+// party), and adds the result to the additive share secretShare of the target party, which gives its final
+// additive share on secretShareOut.
+func (e2s *E2SProtocol) GetShare(secretShare AdditiveShare, aggregatePublicShare CKSShare, ct *ckks.Ciphertext, secretShareOut AdditiveShare) {
+
+	contextQ := e2s.dckksContext.contextQ
+
+	level := ct.Level()
+
+	// m - sum M_i + e
+	contextQ.AddLvl(level, ct.Value()[0], aggregatePublicShare.Poly, e2s.tmp)
+	contextQ.InvNTTLvl(level, e2s.tmp, e2s.tmp)
+
+	contextQ.AddLvl(level, e2s.tmp, secretShare.Poly, secretShareOut.Poly)
+}
+
+// S2EProtocol is the structure storing the parameters and temporary buffers for the shares-to-encryption protocol,
+// which turns additive secret-shares of a plaintext into a ciphertext encrypting it under the collective
+// secret-key.
+//
+// Given a common reference polynomial a, each party i computes the share h_i = -s_i*a + M_i + e_i from its
+// additive share M_i, and the ciphertext (sum h_i, a) decrypts to m + e.
+type S2EProtocol struct {
+	dckksContext *dckksContext
+
+	sigmaSmudging float64
+
+	tmp *ring.Poly
+}
+
+// TryNewS2EProtocol is the same as NewS2EProtocol, but returns an error instead of panicking if the parameters are invalid.
+func TryNewS2EProtocol(params *ckks.Parameters, sigmaSmudging float64) (*S2EProtocol, error) {
+
+	if err := checkSmudging("NewS2EProtocol", params, sigmaSmudging); err != nil {
+		return nil, err
+	}
+
+	return NewS2EProtocol(params, sigmaSmudging), nil
+}
+
+// NewS2EProtocol creates a new S2EProtocol instance. The shares are smudged with a gaussian noise of standard
+// deviation sigmaSmudging, which must be at least the standard deviation of the encryption noise.
+func NewS2EProtocol(params *ckks.Parameters, sigmaSmudging float64) *S2EProtocol {
+
+	if err := checkSmudging("NewS2EProtocol", params, sigmaSmudging); err != nil {
+		panic(err)
+	}
+
+	dckksContext := newDckksContext(params)
+
+	s2e := new(S2EProtocol)
+	s2e.dckksContext = dckksContext
+	s2e.sigmaSmudging = sigmaSmudging
+	s2e.tmp = dckksContext.contextQ.NewPoly()
+
+	return s2e
+}
+
+// AllocateShare allocates the share of the S2E protocol at the given level.
+func (s2e *S2EProtocol) AllocateShare(level uint64) CKSShare {
+	return CKSShare{s2e.dckksContext.contextQ.NewPolyLvl(level)}
+}
+
+// GenShare generates the share
+//
+// [-s_i * crs + M_i + e_i]
+//
+// of the party from its additive share M_i, at the level of the additive share, where crs is a uniform polynomial
+// of R_Q (in the NTT domain) common to all the parties.
+func (s2e *S2EProtocol) GenShare(sk *ring.Poly, crs *ring.Poly, secretShare AdditiveShare, shareOut CKSShare) {
+
+	contextQ := s2e.dckksContext.contextQ
+
+	level := secretShare.Level()
+
+	// h = -s*crs
+	contextQ.MulCoeffsMontgomeryLvl(level, crs, sk, shareOut.Poly)
+	contextQ.NegLvl(level, shareOut.Poly, shareOut.Poly)
+
+	// tmp = M + e
+	contextQ.CopyLvl(level, secretShare.Poly, s2e.tmp)
+	contextQ.SampleGaussianAndAdd(s2e.tmp, s2e.sigmaSmudging, uint64(6*s2e.sigmaSmudging))
+
+	// h = -s*crs + M + e
+	contextQ.NTTLvl(level, s2e.tmp, s2e.tmp)
+	contextQ.AddLvl(level, shareOut.Poly, s2e.tmp, shareOut.Poly)
+
+	s2e.tmp.Zero()
+}
+
+// AggregateShares sums share1 and share2 on shareOut.
+func (s2e *S2EProtocol) AggregateShares(share1, share2, shareOut CKSShare) {
+	s2e.dckksContext.contextQ.AddLvl(share1.Level(), share1.Poly, share2.Poly, shareOut.Poly)
+}
+
+// GetEncryption sets ctOut to the ciphertext (sum h_i, crs) given the aggregated share of all the parties. The
+// ciphertext must be at the level of the shares, and its scale must be the scale of the plaintext that was shared.
+func (s2e *S2EProtocol) GetEncryption(aggregateShare CKSShare, crs *ring.Poly, ctOut *ckks.Ciphertext) {
+	level := aggregateShare.Level()
+	s2e.dckksContext.contextQ.CopyLvl(level, aggregateShare.Poly, ctOut.Value()[0])
+	s2e.dckksContext.contextQ.CopyLvl(level, crs, ctOut.Value()[1])
+}
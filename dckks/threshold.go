@@ -0,0 +1,202 @@
+package dckks
+
+import (
+	"math/big"
+
+	"github.com/ldsec/lattigo/ckks"
+	"github.com/ldsec/lattigo/ring"
+)
+
+// ShamirPublicKey is the public point at which the Shamir polynomials are evaluated to obtain the threshold
+// secret-share of a party. The ShamirPublicKeys of the parties must be distinct and non-zero modulo each Qi and Pi.
+type ShamirPublicKey uint64
+
+// ShamirPolynomial is the polynomial of degree threshold-1 (with coefficients in R_QP) generated by a party in the
+// thresholdizer protocol. Its constant coefficient is the additive secret-share of the party.
+type ShamirPolynomial struct {
+	coeffs []*ring.Poly
+}
+
+// ShamirShare is a struct holding a share of the thresholdizer protocol, or a threshold secret-share once the
+// shares received by a party are aggregated.
+type ShamirShare struct {
+	*ring.Poly
+}
+
+// UnmarshalBinary decodes a marshaled Shamir share on the target Shamir share.
+func (share *ShamirShare) UnmarshalBinary(data []byte) error {
+	if share.Poly == nil {
+		share.Poly = new(ring.Poly)
+	}
+	return share.Poly.UnmarshalBinary(data)
+}
+
+// ThresholdizerProtocol is the structure storing the parameters for a party in the thresholdizer protocol, which
+// turns the additive (N-out-of-N) secret-shares of the collective secret-key into Shamir (t-out-of-N) secret-shares.
+//
+// Each party i generates a ShamirPolynomial P_i with P_i(0) = s_i and sends P_i(pk_j) to each party j over a private
+// channel. Each party j then aggregates the shares it received into its threshold secret-share P(pk_j), where
+// P = sum P_i and P(0) = s is the collective secret-key.
+type ThresholdizerProtocol struct {
+	context   *ring.Context
+	threshold uint64
+}
+
+// TryNewThresholdizerProtocol is the same as NewThresholdizerProtocol, but returns an error instead of panicking if
+// the parameters or the threshold are invalid.
+func TryNewThresholdizerProtocol(params *ckks.Parameters, threshold uint64) (*ThresholdizerProtocol, error) {
+
+	if err := checkThreshold("NewThresholdizerProtocol", params, threshold); err != nil {
+		return nil, err
+	}
+
+	return NewThresholdizerProtocol(params, threshold), nil
+}
+
+// NewThresholdizerProtocol creates a new ThresholdizerProtocol instance, for which any threshold parties among the
+// N parties will be able to run the protocols on the collective secret-key.
+func NewThresholdizerProtocol(params *ckks.Parameters, threshold uint64) *ThresholdizerProtocol {
+
+	if err := checkThreshold("NewThresholdizerProtocol", params, threshold); err != nil {
+		panic(err)
+	}
+
+	thr := new(ThresholdizerProtocol)
+	thr.context = newDckksContext(params).contextQP
+	thr.threshold = threshold
+	return thr
+}
+
+// GenShamirPolynomial generates a new ShamirPolynomial whose constant coefficient is the secret-share sk of the
+// party, and whose threshold-1 other coefficients are uniformly random.
+func (thr *ThresholdizerProtocol) GenShamirPolynomial(sk *ring.Poly) *ShamirPolynomial {
+
+	polynomial := &ShamirPolynomial{coeffs: make([]*ring.Poly, thr.threshold)}
+
+	polynomial.coeffs[0] = sk.CopyNew()
+	for i := uint64(1); i < thr.threshold; i++ {
+		polynomial.coeffs[i] = thr.context.NewUniformPoly()
+	}
+
+	return polynomial
+}
+
+// AllocateShare allocates a Shamir share.
+func (thr *ThresholdizerProtocol) AllocateShare() ShamirShare {
+	return ShamirShare{thr.context.NewPoly()}
+}
+
+// GenShare generates the share of the party of ShamirPublicKey recipient as the evaluation of the ShamirPolynomial at
+// the point recipient. Panics if recipient is zero modulo one of the Qi or Pi.
+func (thr *ThresholdizerProtocol) GenShare(polynomial *ShamirPolynomial, recipient ShamirPublicKey, shareOut ShamirShare) {
+
+	for _, qi := range thr.context.Modulus {
+		if uint64(recipient)%qi == 0 {
+			panic("cannot GenShare: the ShamirPublicKey must be non-zero modulo each Qi and Pi")
+		}
+	}
+
+	// Horner evaluation of the polynomial at the point recipient
+	shareOut.Poly.Copy(polynomial.coeffs[len(polynomial.coeffs)-1])
+	for i := len(polynomial.coeffs) - 2; i >= 0; i-- {
+		thr.context.MulScalar(shareOut.Poly, uint64(recipient), shareOut.Poly)
+		thr.context.Add(shareOut.Poly, polynomial.coeffs[i], shareOut.Poly)
+	}
+}
+
+// AggregateShares aggregates two Shamir shares. The aggregation of the shares received from all the parties is the
+// threshold secret-share of the party.
+func (thr *ThresholdizerProtocol) AggregateShares(share1, share2, shareOut ShamirShare) {
+	thr.context.Add(share1.Poly, share2.Poly, shareOut.Poly)
+}
+
+// CombinerProtocol is the structure storing the parameters for a party to turn its threshold secret-share into an
+// additive secret-share among a set of at least threshold active parties. The additive secret-shares can then be used
+// as the secret-keys of the N-out-of-N protocols (e.g. the CKSProtocol and the PCKSProtocol) run among the active
+// parties only.
+type CombinerProtocol struct {
+	context   *ring.Context
+	threshold uint64
+	modulus   *big.Int
+}
+
+// TryNewCombinerProtocol is the same as NewCombinerProtocol, but returns an error instead of panicking if the
+// parameters or the threshold are invalid.
+func TryNewCombinerProtocol(params *ckks.Parameters, threshold uint64) (*CombinerProtocol, error) {
+
+	if err := checkThreshold("NewCombinerProtocol", params, threshold); err != nil {
+		return nil, err
+	}
+
+	return NewCombinerProtocol(params, threshold), nil
+}
+
+// NewCombinerProtocol creates a new CombinerProtocol instance.
+func NewCombinerProtocol(params *ckks.Parameters, threshold uint64) *CombinerProtocol {
+
+	if err := checkThreshold("NewCombinerProtocol", params, threshold); err != nil {
+		panic(err)
+	}
+
+	cmb := new(CombinerProtocol)
+	cmb.context = newDckksContext(params).contextQP
+	cmb.threshold = threshold
+	cmb.modulus = ring.NewUint(1)
+	for _, qi := range cmb.context.Modulus {
+		cmb.modulus.Mul(cmb.modulus, ring.NewUint(qi))
+	}
+	return cmb
+}
+
+// GenAdditiveShare generates the additive secret-share of the party of ShamirPublicKey own among the parties of
+// ShamirPublicKeys active, from its threshold secret-share, as:
+//
+// P(pk_own) * prod_{j != own} pk_j / (pk_j - pk_own)
+//
+// active must contain own and at least threshold distinct ShamirPublicKeys.
+func (cmb *CombinerProtocol) GenAdditiveShare(active []ShamirPublicKey, own ShamirPublicKey, thresholdShare ShamirShare, skOut *ring.Poly) {
+
+	if uint64(len(active)) < cmb.threshold {
+		panic("cannot GenAdditiveShare: there are fewer active parties than the threshold")
+	}
+
+	lambda := ring.NewUint(1)
+	den := ring.NewUint(1)
+	tmp := new(big.Int)
+	found := false
+	seen := make(map[ShamirPublicKey]bool, len(active))
+
+	for _, pk := range active {
+
+		if seen[pk] {
+			panic("cannot GenAdditiveShare: the active ShamirPublicKeys must be distinct")
+		}
+		seen[pk] = true
+
+		if pk == own {
+			found = true
+			continue
+		}
+
+		lambda.Mul(lambda, ring.NewUint(uint64(pk)))
+		lambda.Mod(lambda, cmb.modulus)
+
+		tmp.Sub(ring.NewUint(uint64(pk)), ring.NewUint(uint64(own)))
+		den.Mul(den, tmp)
+		den.Mod(den, cmb.modulus)
+	}
+
+	if !found {
+		panic("cannot GenAdditiveShare: the ShamirPublicKey of the party is not among the active ones")
+	}
+
+	if tmp.GCD(nil, nil, den, cmb.modulus).Cmp(ring.NewUint(1)) != 0 {
+		panic("cannot GenAdditiveShare: the active ShamirPublicKeys must be distinct modulo each Qi and Pi")
+	}
+
+	tmp.ModInverse(den, cmb.modulus)
+	lambda.Mul(lambda, tmp)
+	lambda.Mod(lambda, cmb.modulus)
+
+	cmb.context.MulScalarBigint(thresholdShare.Poly, lambda, skOut)
+}
@@ -1,6 +1,9 @@
 package dckks
 
 import (
+	"errors"
+	"math/big"
+
 	"github.com/ldsec/lattigo/ring"
 )
 
@@ -11,3 +14,136 @@ func randomFloat(max float64) float64 {
 func randomComplex(max float64) complex128 {
 	return complex(randomFloat(max), randomFloat(max))
 }
+
+// marshalPolysLvl encodes the level followed by the polynomials, which must all be at this level, on a slice of bytes.
+func marshalPolysLvl(level uint64, polys ...*ring.Poly) ([]byte, error) {
+
+	if level > 0xFF {
+		return nil, errors.New("cannot marshal share: uint8 overflow on level")
+	}
+
+	var dataLen uint64
+	for _, pol := range polys {
+		if uint64(len(pol.Coeffs)) != level+1 {
+			return nil, errors.New("cannot marshal share: the polynomials are not at the level of the share")
+		}
+		dataLen += pol.GetDataLen(true)
+	}
+
+	data := make([]byte, 1+dataLen)
+	data[0] = uint8(level)
+
+	ptr := uint64(1)
+	for _, pol := range polys {
+		cnt, err := pol.WriteTo(data[ptr:])
+		if err != nil {
+			return nil, err
+		}
+		ptr += cnt
+	}
+
+	return data, nil
+}
+
+// unmarshalPolysLvl decodes a slice of bytes generated by marshalPolysLvl with n polynomials, and returns the level
+// and the polynomials.
+func unmarshalPolysLvl(data []byte, n int) (level uint64, polys []*ring.Poly, err error) {
+
+	if len(data) < 1 || (len(data)-1)%n != 0 {
+		return 0, nil, errors.New("cannot unmarshal share: invalid encoding")
+	}
+
+	level = uint64(data[0])
+	polyLen := (len(data) - 1) / n
+
+	polys = make([]*ring.Poly, n)
+	for i := range polys {
+		ptr := 1 + i*polyLen
+		polys[i] = new(ring.Poly)
+		if err = polys[i].UnmarshalBinary(data[ptr : ptr+polyLen]); err != nil {
+			return 0, nil, err
+		}
+		if uint64(len(polys[i].Coeffs)) != level+1 {
+			return 0, nil, errors.New("cannot unmarshal share: the polynomials are not at the level of the share")
+		}
+	}
+
+	return level, polys, nil
+}
+
+// marshalPolyPairs encodes the number of pairs followed by the pairs of polynomials, which must all have the same
+// size, on a slice of bytes.
+func marshalPolyPairs(pairs [][2]*ring.Poly) ([]byte, error) {
+
+	if len(pairs) == 0 {
+		return nil, errors.New("cannot marshal share: the share is empty")
+	}
+
+	if len(pairs) > 0xFF {
+		return nil, errors.New("cannot marshal share: uint8 overflow on length")
+	}
+
+	polyLen := pairs[0][0].GetDataLen(true)
+
+	data := make([]byte, 1+2*polyLen*uint64(len(pairs)))
+	data[0] = uint8(len(pairs))
+
+	ptr := uint64(1)
+	for _, pair := range pairs {
+		for _, pol := range pair {
+			if pol.GetDataLen(true) != polyLen {
+				return nil, errors.New("cannot marshal share: the polynomials do not have the same size")
+			}
+			cnt, err := pol.WriteTo(data[ptr:])
+			if err != nil {
+				return nil, err
+			}
+			ptr += cnt
+		}
+	}
+
+	return data, nil
+}
+
+// unmarshalPolyPairs decodes a slice of bytes generated by marshalPolyPairs, and returns the pairs of polynomials.
+func unmarshalPolyPairs(data []byte) (pairs [][2]*ring.Poly, err error) {
+
+	if len(data) < 1 || data[0] == 0 || (len(data)-1)%(2*int(data[0])) != 0 {
+		return nil, errors.New("cannot unmarshal share: invalid encoding")
+	}
+
+	polyLen := (len(data) - 1) / (2 * int(data[0]))
+	if polyLen < 2 {
+		return nil, errors.New("cannot unmarshal share: invalid encoding")
+	}
+
+	pairs = make([][2]*ring.Poly, data[0])
+
+	ptr := 1
+	for i := range pairs {
+		for j := range pairs[i] {
+			pairs[i][j] = new(ring.Poly)
+			if err = pairs[i][j].UnmarshalBinary(data[ptr : ptr+polyLen]); err != nil {
+				return nil, err
+			}
+			ptr += polyLen
+		}
+	}
+
+	return pairs, nil
+}
+
+// floodingBound returns the bound 2^lambda * noiseBound, rounded down, of the coefficients of the flooding noise.
+func floodingBound(noiseBound float64, lambda uint64) *big.Int {
+	bound, _ := new(big.Float).SetMantExp(big.NewFloat(noiseBound), int(lambda)).Int(nil)
+	return bound
+}
+
+// modulusBigint returns the product of the given moduli.
+func modulusBigint(moduli []uint64) *big.Int {
+	modulus := ring.NewUint(1)
+	for _, qi := range moduli {
+		modulus.Mul(modulus, ring.NewUint(qi))
+	}
+	return modulus
+}
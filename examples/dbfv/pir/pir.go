@@ -289,20 +289,21 @@ func main() {
 		elapsedMaskTask time.Duration
 	}
 
-	// Splits the task among the Go routines
+	// Splits the task among the Go routines, each of which uses a shallow copy of the same evaluator
+	evaluator := bfv.NewEvaluator(params).WithKey(nil, rtk)
 	tasks := make(chan *MaskTask)
 	workers := &sync.WaitGroup{}
 	workers.Add(NGoRoutine)
 	for i := 1; i <= NGoRoutine; i++ {
 		go func(i int) {
-			evaluator := bfv.NewEvaluator(params)
+			evaluator := evaluator.ShallowCopy()
 			tmp := bfv.NewCiphertext(params, 1)
 			for task := range tasks {
 				task.elapsedMaskTask = runTimed(func() {
 					// 1) Multiplication of the query with the plaintext mask.
 					evaluator.Mul(task.query, task.mask, tmp)
 					// 2) Inner sum (populates all the slots with the sum of all the slots).
-					evaluator.InnerSum(tmp, rtk, tmp)
+					evaluator.InnerSum(tmp, nil, tmp)
 					// 3) Multiplication of 2) with the ith ciphertext stored in the cloud.
 					evaluator.Mul(tmp, task.row, task.res)
 				})
@@ -334,7 +335,6 @@ func main() {
 		elapsedRequestCloudCPU += t.elapsedMaskTask
 	}
 
-	evaluator := bfv.NewEvaluator(params)
 	resultDeg2 := bfv.NewCiphertext(params, 2)
 	result := bfv.NewCiphertext(params, 1)
 
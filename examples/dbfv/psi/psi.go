@@ -212,17 +212,19 @@ func main() {
 		elapsedMultTask time.Duration
 	}
 
+	// Each goroutine uses a shallow copy of the same evaluator, which stores the relinearization key
+	evaluator := bfv.NewEvaluator(params).WithKey(rlk, nil)
 	tasks := make(chan *MultTask)
 	workers := &sync.WaitGroup{}
 	workers.Add(NGoRoutine)
 	//l.Println("> Spawning", NGoRoutine, "evaluator goroutine")
 	for i := 1; i <= NGoRoutine; i++ {
 		go func(i int) {
-			evaluator := bfv.NewEvaluator(params)
+			evaluator := evaluator.ShallowCopy()
 			for task := range tasks {
 				task.elapsedMultTask = runTimed(func() {
 					evaluator.Mul(task.op1, task.op2, task.res)
-					evaluator.Relinearize(task.res, rlk, task.res)
+					evaluator.Relinearize(task.res, nil, task.res)
 				})
 				task.wg.Done()
 			}
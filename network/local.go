@@ -0,0 +1,127 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+)
+
+// localLinkCapacity is the number of messages that can be sent on a link between two local parties before Send blocks.
+const localLinkCapacity = 16
+
+// localNetwork stores the links between the parties of an in-process network.
+type localNetwork struct {
+	links [][]chan []byte // links[from][to]
+	done  []chan struct{} // done[id] is closed when the party id closes its Transport
+}
+
+// localTransport is a Transport between parties running in the same process, which exchange their messages over
+// buffered channels.
+type localTransport struct {
+	id      PartyID
+	network *localNetwork
+	once    sync.Once
+}
+
+// NewLocalTransports creates an in-process network of the given number of parties, and returns the Transport of
+// each party, indexed by its PartyID. It is meant for the tests and for the simulation of the protocols.
+func NewLocalTransports(parties uint64) []Transport {
+
+	network := &localNetwork{
+		links: make([][]chan []byte, parties),
+		done:  make([]chan struct{}, parties),
+	}
+
+	for i := range network.links {
+		network.links[i] = make([]chan []byte, parties)
+		for j := range network.links[i] {
+			if i != j {
+				network.links[i][j] = make(chan []byte, localLinkCapacity)
+			}
+		}
+		network.done[i] = make(chan struct{})
+	}
+
+	transports := make([]Transport, parties)
+	for i := range transports {
+		transports[i] = &localTransport{id: PartyID(i), network: network}
+	}
+
+	return transports
+}
+
+// ID returns the PartyID of the local party.
+func (t *localTransport) ID() PartyID {
+	return t.id
+}
+
+// Parties returns the number of parties of the network.
+func (t *localTransport) Parties() uint64 {
+	return uint64(len(t.network.links))
+}
+
+// Send sends a copy of msg to the party to. Blocks if the link to the party is full.
+func (t *localTransport) Send(to PartyID, msg []byte) error {
+
+	if err := t.checkParty(to); err != nil {
+		return fmt.Errorf("cannot Send: %w", err)
+	}
+
+	select {
+	case <-t.network.done[t.id]:
+		return fmt.Errorf("cannot Send: %w", ErrClosed)
+	default:
+	}
+
+	msgCopy := make([]byte, len(msg))
+	copy(msgCopy, msg)
+
+	select {
+	case <-t.network.done[t.id]:
+		return fmt.Errorf("cannot Send: %w", ErrClosed)
+	case <-t.network.done[to]:
+		return fmt.Errorf("cannot Send: %w", ErrClosed)
+	case t.network.links[t.id][to] <- msgCopy:
+		return nil
+	}
+}
+
+// Receive blocks until the next message from the party from is received and returns it. The messages sent by
+// the party before it closed its Transport can still be received.
+func (t *localTransport) Receive(from PartyID) ([]byte, error) {
+
+	if err := t.checkParty(from); err != nil {
+		return nil, fmt.Errorf("cannot Receive: %w", err)
+	}
+
+	link := t.network.links[from][t.id]
+
+	select {
+	case msg := <-link:
+		return msg, nil
+	case <-t.network.done[t.id]:
+		return nil, fmt.Errorf("cannot Receive: %w", ErrClosed)
+	case <-t.network.done[from]:
+		select {
+		case msg := <-link:
+			return msg, nil
+		default:
+			return nil, fmt.Errorf("cannot Receive: %w", ErrClosed)
+		}
+	}
+}
+
+// Close closes the Transport of the local party. The blocked calls to Send and Receive of the local party,
+// and the ones of the other parties to and from the local party, return ErrClosed.
+func (t *localTransport) Close() error {
+	t.once.Do(func() {
+		close(t.network.done[t.id])
+	})
+	return nil
+}
+
+func (t *localTransport) checkParty(id PartyID) error {
+	if uint64(id) >= t.Parties() || id == t.id {
+		return ErrUnknownParty
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package network implements the communication layer of the multiparty protocols of the dbfv and dckks packages.
+// The parties exchange their marshaled shares over a Transport, along the routes given by a Topology, and a Runner
+// carries the aggregation of the shares and the broadcast of the results for the local party.
+package network
+
+import (
+	"encoding"
+	"errors"
+)
+
+// PartyID identifies a party in a network of N parties, as an integer in [0, N-1].
+type PartyID uint64
+
+// Transport is the interface for the point-to-point communication between the parties of a network. The messages
+// sent from one party to another are received in the same order as they were sent. A Transport can be used
+// concurrently to send (or to receive) messages to (or from) different parties.
+type Transport interface {
+	// ID returns the PartyID of the local party.
+	ID() PartyID
+	// Parties returns the number of parties of the network.
+	Parties() uint64
+	// Send sends msg to the party to.
+	Send(to PartyID, msg []byte) error
+	// Receive blocks until the next message from the party from is received and returns it.
+	Receive(from PartyID) ([]byte, error)
+	// Close closes the connections of the local party to the other parties.
+	Close() error
+}
+
+// Message is the interface for the objects (such as the protocol shares) that can be sent over a Transport.
+type Message interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+var (
+	// ErrClosed is returned when a message is sent or received on a closed Transport, or received from a party
+	// which closed its connection.
+	ErrClosed = errors.New("transport is closed")
+	// ErrUnknownParty is returned when a PartyID is not one of the parties of the network, or is the local party.
+	ErrUnknownParty = errors.New("unknown party")
+	// ErrMessageTooLarge is returned when a message exceeds the maximum size accepted by a Transport.
+	ErrMessageTooLarge = errors.New("message is too large")
+)
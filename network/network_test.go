@@ -0,0 +1,381 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/ldsec/lattigo/bfv"
+	"github.com/ldsec/lattigo/dbfv"
+	"github.com/ldsec/lattigo/ring"
+)
+
+func check(t *testing.T, err error) {
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// testShare is a share whose aggregation is the sum of its values.
+type testShare struct {
+	values []uint64
+}
+
+func (share *testShare) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8*len(share.values))
+	for i, v := range share.values {
+		binary.BigEndian.PutUint64(data[8*i:], v)
+	}
+	return data, nil
+}
+
+func (share *testShare) UnmarshalBinary(data []byte) error {
+	if len(data) != 8*len(share.values) {
+		return errors.New("invalid testShare encoding")
+	}
+	for i := range share.values {
+		share.values[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	return nil
+}
+
+type testTransports struct {
+	name string
+	new  func(parties uint64) ([]Transport, error)
+}
+
+var testParties = uint64(7)
+
+var transports = []testTransports{
+	{"Local", func(parties uint64) ([]Transport, error) { return NewLocalTransports(parties), nil }},
+	{"TCP", func(parties uint64) ([]Transport, error) { return NewLoopbackTCPTransports(parties, 0) }},
+}
+
+// runParties calls f concurrently for each party and returns once all the calls are done.
+func runParties(transports []Transport, f func(transport Transport)) {
+	var wg sync.WaitGroup
+	for _, transport := range transports {
+		wg.Add(1)
+		go func(transport Transport) {
+			f(transport)
+			wg.Done()
+		}(transport)
+	}
+	wg.Wait()
+}
+
+func closeAll(t *testing.T, transports []Transport) {
+	for _, transport := range transports {
+		check(t, transport.Close())
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	t.Run("Transport", testTransport)
+	t.Run("TCP", testTCP)
+	t.Run("Topology", testTopology)
+	t.Run("Runner", testRunner)
+	t.Run("CKG", testCKG)
+}
+
+func testTransport(t *testing.T) {
+
+	for _, tt := range transports {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			parties, err := tt.new(testParties)
+			check(t, err)
+
+			// Each party sends two messages to all the others, which must be received in order
+			runParties(parties, func(transport Transport) {
+
+				id := transport.ID()
+
+				for j := PartyID(0); j < PartyID(testParties); j++ {
+					if j != id {
+						for k := 0; k < 2; k++ {
+							check(t, transport.Send(j, []byte(fmt.Sprintf("%d-%d-%d", id, j, k))))
+						}
+					}
+				}
+
+				for j := PartyID(0); j < PartyID(testParties); j++ {
+					if j != id {
+						for k := 0; k < 2; k++ {
+							msg, err := transport.Receive(j)
+							check(t, err)
+							if string(msg) != fmt.Sprintf("%d-%d-%d", j, id, k) {
+								t.Errorf("party %d received %q from party %d", id, msg, j)
+							}
+						}
+					}
+				}
+			})
+
+			if err := parties[0].Send(0, nil); !errors.Is(err, ErrUnknownParty) {
+				t.Errorf("invalid error: have %v, want %v", err, ErrUnknownParty)
+			}
+
+			if _, err := parties[0].Receive(PartyID(testParties)); !errors.Is(err, ErrUnknownParty) {
+				t.Errorf("invalid error: have %v, want %v", err, ErrUnknownParty)
+			}
+
+			// The messages sent before closing can still be received
+			check(t, parties[1].Send(2, []byte("last")))
+			check(t, parties[1].Close())
+
+			if msg, err := parties[2].Receive(1); err != nil || string(msg) != "last" {
+				t.Errorf("party 2 received %q, %v from party 1", msg, err)
+			}
+
+			if _, err := parties[2].Receive(1); !errors.Is(err, ErrClosed) {
+				t.Errorf("invalid error: have %v, want %v", err, ErrClosed)
+			}
+
+			if err := parties[1].Send(2, nil); !errors.Is(err, ErrClosed) {
+				t.Errorf("invalid error: have %v, want %v", err, ErrClosed)
+			}
+
+			closeAll(t, parties)
+		})
+	}
+}
+
+func testTCP(t *testing.T) {
+
+	t.Run("StrayConnections", func(t *testing.T) {
+
+		listeners := make([]net.Listener, 2)
+		addresses := make([]string, 2)
+		for i := range listeners {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+			listeners[i] = listener
+			addresses[i] = listener.Addr().String()
+		}
+
+		// Connections which close before the handshake, or which send an invalid PartyID, are ignored
+		for _, handshake := range [][]byte{nil, {0, 0, 0, 0, 0, 0, 0, 0}, {0, 0, 0, 0, 0, 0, 0, 7}} {
+			conn, err := net.Dial("tcp", addresses[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if handshake != nil {
+				_, err = conn.Write(handshake)
+				check(t, err)
+			}
+			conn.Close()
+		}
+
+		parties := make([]Transport, 2)
+		errs := make([]error, 2)
+
+		var wg sync.WaitGroup
+		for i := range parties {
+			wg.Add(1)
+			go func(i int) {
+				parties[i], errs[i] = NewTCPTransport(PartyID(i), listeners[i], addresses, 0)
+				wg.Done()
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		check(t, parties[1].Send(0, []byte("msg")))
+		if msg, err := parties[0].Receive(1); err != nil || string(msg) != "msg" {
+			t.Errorf("party 0 received %q, %v from party 1", msg, err)
+		}
+
+		closeAll(t, parties)
+	})
+
+	t.Run("MessageTooLarge", func(t *testing.T) {
+
+		maxMessageSize := uint64(1 << 10)
+
+		parties, err := NewLoopbackTCPTransports(2, maxMessageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := parties[0].Send(1, make([]byte, maxMessageSize+1)); !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("invalid error: have %v, want %v", err, ErrMessageTooLarge)
+		}
+
+		// Forged header announcing a message larger than the maximum size
+		header := make([]byte, 8)
+		binary.BigEndian.PutUint64(header, maxMessageSize+1)
+		_, err = parties[0].(*tcpTransport).conns[1].conn.Write(header)
+		check(t, err)
+
+		for i := 0; i < 2; i++ {
+			if _, err := parties[1].Receive(0); !errors.Is(err, ErrMessageTooLarge) {
+				t.Errorf("invalid error: have %v, want %v", err, ErrMessageTooLarge)
+			}
+		}
+
+		closeAll(t, parties)
+	})
+}
+
+func testTopology(t *testing.T) {
+
+	star := NewStarTopology(testParties, 2)
+	tree := NewTreeTopology(testParties, 2)
+
+	if parent, ok := star.Parent(5); !ok || parent != 2 {
+		t.Errorf("invalid parent of party 5 in the star: have %d, %v", parent, ok)
+	}
+
+	if _, ok := star.Parent(2); ok {
+		t.Error("the root of the star has a parent")
+	}
+
+	if len(star.Children(2)) != int(testParties)-1 || len(star.Children(0)) != 0 {
+		t.Error("invalid children in the star")
+	}
+
+	if parent, ok := tree.Parent(6); !ok || parent != 2 {
+		t.Errorf("invalid parent of party 6 in the tree: have %d, %v", parent, ok)
+	}
+
+	if children := tree.Children(1); len(children) != 2 || children[0] != 3 || children[1] != 4 {
+		t.Errorf("invalid children of party 1 in the tree: %v", children)
+	}
+
+	if _, err := TryNewRunner(NewLocalTransports(testParties)[0], NewStarTopology(testParties+1, 0)); err == nil {
+		t.Error("a Runner was created with a Topology of a different number of parties")
+	}
+}
+
+func testRunner(t *testing.T) {
+
+	topologies := []struct {
+		name     string
+		topology Topology
+	}{
+		{"Star", NewStarTopology(testParties, 2)},
+		{"Tree", NewTreeTopology(testParties, 2)},
+		{"Chain", NewTreeTopology(testParties, 1)},
+	}
+
+	for _, tt := range transports {
+		for _, topology := range topologies {
+
+			t.Run(tt.name+"/"+topology.name, func(t *testing.T) {
+
+				parties, err := tt.new(testParties)
+				check(t, err)
+
+				results := make([]*testShare, testParties)
+
+				runParties(parties, func(transport Transport) {
+
+					runner := NewRunner(transport, topology.topology)
+
+					id := uint64(runner.ID())
+
+					share := &testShare{values: []uint64{id, 1, id * id}}
+					tmp := &testShare{values: make([]uint64, 3)}
+
+					check(t, runner.Aggregate(share, tmp, func() {
+						for i := range share.values {
+							share.values[i] += tmp.values[i]
+						}
+					}))
+
+					check(t, runner.Broadcast(share))
+
+					results[id] = share
+				})
+
+				var want [3]uint64
+				for i := uint64(0); i < testParties; i++ {
+					want[0] += i
+					want[1]++
+					want[2] += i * i
+				}
+
+				for id, share := range results {
+					if share.values[0] != want[0] || share.values[1] != want[1] || share.values[2] != want[2] {
+						t.Errorf("party %d has %v, want %v", id, share.values, want)
+					}
+				}
+
+				closeAll(t, parties)
+			})
+		}
+	}
+}
+
+func testCKG(t *testing.T) {
+
+	params := bfv.DefaultParams[bfv.PN12QP109]
+
+	contextQP, err := ring.NewContextWithParams(1<<params.LogN, append(params.Qi, params.Pi...))
+	check(t, err)
+
+	crpGenerator := ring.NewCRPGenerator(nil, contextQP)
+	crpGenerator.Seed([]byte{})
+	crp := crpGenerator.ClockNew()
+
+	kgen := bfv.NewKeyGenerator(params)
+
+	// The shares are generated beforehand so that the result can be compared with the local aggregation
+	ckg := dbfv.NewCKGProtocol(params)
+	shares := make([]dbfv.CKGShare, testParties)
+	want := ckg.AllocateShares()
+	for i := range shares {
+		shares[i] = ckg.AllocateShares()
+		ckg.GenShare(kgen.GenSecretKey().Get(), crp, shares[i])
+		ckg.AggregateShares(shares[i], want, want)
+	}
+
+	for _, tt := range transports {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			parties, err := tt.new(testParties)
+			check(t, err)
+
+			pks := make([]*bfv.PublicKey, testParties)
+
+			runParties(parties, func(transport Transport) {
+
+				runner := NewRunner(transport, NewTreeTopology(testParties, 2))
+
+				ckg := dbfv.NewCKGProtocol(params)
+				share := ckg.AllocateShares()
+				tmp := ckg.AllocateShares()
+				share.Copy(shares[runner.ID()].Poly)
+
+				check(t, runner.Aggregate(&share, &tmp, func() { ckg.AggregateShares(share, tmp, share) }))
+				check(t, runner.Broadcast(&share))
+
+				pk := new(bfv.PublicKey)
+				ckg.GenPublicKey(share, crp, pk)
+				pks[runner.ID()] = pk
+			})
+
+			for id, pk := range pks {
+				if !contextQP.Equal(pk.Get()[0], want.Poly) || !contextQP.Equal(pk.Get()[1], crp) {
+					t.Errorf("party %d has an invalid public key", id)
+				}
+			}
+
+			closeAll(t, parties)
+		})
+	}
+}
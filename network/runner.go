@@ -0,0 +1,121 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Runner carries the communication steps of the multiparty protocols for the local party: the shares of the
+// parties are marshaled, sent along the Topology over the Transport, and aggregated with the aggregation
+// function of the protocol. All the parties must call the methods of their Runner in the same order.
+//
+// For example, the collective public key generation of the dbfv package is run by each party as:
+//
+//	ckg.GenShare(sk, crs, share)
+//	runner.Aggregate(&share, &tmp, func() { ckg.AggregateShares(share, tmp, share) })
+//	runner.Broadcast(&share)
+//	ckg.GenPublicKey(share, crs, pk)
+type Runner struct {
+	transport Transport
+	topology  Topology
+}
+
+// TryNewRunner is the same as NewRunner, but returns an error instead of panicking if the Topology does not
+// have the same number of parties as the Transport.
+func TryNewRunner(transport Transport, topology Topology) (*Runner, error) {
+
+	if transport.Parties() != topology.Parties() {
+		return nil, errors.New("cannot NewRunner: the Topology and the Transport have a different number of parties")
+	}
+
+	return &Runner{transport: transport, topology: topology}, nil
+}
+
+// NewRunner creates a new Runner for the local party of the Transport, which routes the shares along the Topology.
+func NewRunner(transport Transport, topology Topology) *Runner {
+
+	runner, err := TryNewRunner(transport, topology)
+	if err != nil {
+		panic(err)
+	}
+
+	return runner
+}
+
+// ID returns the PartyID of the local party.
+func (runner *Runner) ID() PartyID {
+	return runner.transport.ID()
+}
+
+// IsRoot returns true if the local party is the root of the Topology.
+func (runner *Runner) IsRoot() bool {
+	return runner.transport.ID() == runner.topology.Root()
+}
+
+// Aggregate aggregates the shares of all the parties at the root of the Topology. The local party receives the
+// aggregated share of each of its children on tmp and calls aggregate, which must add tmp to share, then sends
+// share to its parent. When Aggregate returns, share holds the aggregation of the shares of all the parties at
+// the root, and the aggregation of the shares of its subtree at the other parties.
+func (runner *Runner) Aggregate(share, tmp Message, aggregate func()) error {
+
+	id := runner.transport.ID()
+
+	for _, child := range runner.topology.Children(id) {
+
+		data, err := runner.transport.Receive(child)
+		if err != nil {
+			return fmt.Errorf("cannot Aggregate: %w", err)
+		}
+
+		if err = tmp.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("cannot Aggregate: %w", err)
+		}
+
+		aggregate()
+	}
+
+	if parent, ok := runner.topology.Parent(id); ok {
+
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("cannot Aggregate: %w", err)
+		}
+
+		if err = runner.transport.Send(parent, data); err != nil {
+			return fmt.Errorf("cannot Aggregate: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Broadcast sends the value of msg at the root of the Topology to all the parties, along the Topology. When
+// Broadcast returns, msg holds the value of the root at all the parties.
+func (runner *Runner) Broadcast(msg Message) (err error) {
+
+	id := runner.transport.ID()
+
+	var data []byte
+
+	if parent, ok := runner.topology.Parent(id); ok {
+
+		if data, err = runner.transport.Receive(parent); err != nil {
+			return fmt.Errorf("cannot Broadcast: %w", err)
+		}
+
+		if err = msg.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("cannot Broadcast: %w", err)
+		}
+
+	} else if data, err = msg.MarshalBinary(); err != nil {
+		return fmt.Errorf("cannot Broadcast: %w", err)
+	}
+
+	for _, child := range runner.topology.Children(id) {
+		if err = runner.transport.Send(child, data); err != nil {
+			return fmt.Errorf("cannot Broadcast: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,307 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxTCPMessageSize is the maximum size in bytes of the messages of the TCP Transports created with a
+	// maxMessageSize of 0. It fits the shares of the dbfv and dckks protocols on the default parameters up to PN14QP438,
+	// the largest being the dckks relinearization-key shares of about 15MB. The larger parameters require a larger
+	// maxMessageSize, e.g. about 63MB for the dckks relinearization-key shares of PN15QP880.
+	DefaultMaxTCPMessageSize = 32 << 20
+
+	// tcpHandshakeTimeout is the time given to an accepted connection to identify its party.
+	tcpHandshakeTimeout = 10 * time.Second
+)
+
+// tcpConn is a TCP connection to a party, with a lock for each direction so that one message is written
+// (or read) at a time. readErr is set once the received stream cannot be framed anymore.
+type tcpConn struct {
+	conn    net.Conn
+	read    sync.Mutex
+	write   sync.Mutex
+	readErr error
+}
+
+// tcpTransport is a Transport over one TCP connection between each pair of parties. Each message is framed
+// by its length, encoded on 8 bytes.
+type tcpTransport struct {
+	id             PartyID
+	conns          []*tcpConn // conns[id] is nil
+	maxMessageSize uint64
+	mu             sync.Mutex
+	closed         bool
+}
+
+// NewTCPTransport creates the Transport of the party id over TCP, where addresses[i] is the address on which the
+// party i listens. The local party accepts the connections of the parties of larger PartyID on the listener (which
+// must listen on addresses[id] and remains owned by the caller), and connects to the parties of smaller PartyID.
+// Blocks until the connections to all the other parties are established.
+//
+// The messages sent and received are limited to maxMessageSize bytes, which should be the size of the largest marshaled
+// share of the protocols run over the Transport (DefaultMaxTCPMessageSize if 0). The length of the received messages is
+// checked before allocating them, so that a peer cannot exhaust the memory of the local party with a forged length.
+//
+// Each connection starts with the PartyID of the connecting party. The accepted connections which do not send a valid
+// PartyID of a larger party (which is not already connected) within tcpHandshakeTimeout are closed and ignored, so
+// that a stray connection does not fail the setup. The PartyID is not authenticated: the network is assumed to be
+// authenticated at a lower layer (e.g. with TLS or a VPN).
+func NewTCPTransport(id PartyID, listener net.Listener, addresses []string, maxMessageSize uint64) (Transport, error) {
+
+	parties := uint64(len(addresses))
+
+	if uint64(id) >= parties {
+		return nil, fmt.Errorf("cannot NewTCPTransport: %w", ErrUnknownParty)
+	}
+
+	if maxMessageSize == 0 {
+		maxMessageSize = DefaultMaxTCPMessageSize
+	}
+
+	t := &tcpTransport{id: id, conns: make([]*tcpConn, parties), maxMessageSize: maxMessageSize}
+
+	handshake := make([]byte, 8)
+	binary.BigEndian.PutUint64(handshake, uint64(id))
+
+	for i := uint64(0); i < uint64(id); i++ {
+
+		conn, err := net.Dial("tcp", addresses[i])
+		if err != nil {
+			t.Close()
+			return nil, fmt.Errorf("cannot NewTCPTransport: %w", err)
+		}
+
+		t.conns[i] = &tcpConn{conn: conn}
+
+		if _, err = conn.Write(handshake); err != nil {
+			t.Close()
+			return nil, fmt.Errorf("cannot NewTCPTransport: %w", err)
+		}
+	}
+
+	for remaining := parties - uint64(id) - 1; remaining > 0; {
+
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Close()
+			return nil, fmt.Errorf("cannot NewTCPTransport: %w", err)
+		}
+
+		peer, err := readHandshake(conn)
+		if err != nil || peer <= uint64(id) || peer >= parties || t.conns[peer] != nil {
+			conn.Close()
+			continue
+		}
+
+		t.conns[peer] = &tcpConn{conn: conn}
+		remaining--
+	}
+
+	return t, nil
+}
+
+// readHandshake reads the PartyID sent by the party at the start of the connection.
+func readHandshake(conn net.Conn) (peer uint64, err error) {
+
+	if err = conn.SetReadDeadline(time.Now().Add(tcpHandshakeTimeout)); err != nil {
+		return
+	}
+
+	handshake := make([]byte, 8)
+	if _, err = io.ReadFull(conn, handshake); err != nil {
+		return
+	}
+
+	return binary.BigEndian.Uint64(handshake), conn.SetReadDeadline(time.Time{})
+}
+
+// NewLoopbackTCPTransports creates a network of the given number of parties connected over TCP on the loopback
+// interface, and returns the Transport of each party, indexed by its PartyID. The messages are limited to
+// maxMessageSize bytes (DefaultMaxTCPMessageSize if 0).
+func NewLoopbackTCPTransports(parties, maxMessageSize uint64) ([]Transport, error) {
+
+	listeners := make([]net.Listener, parties)
+	addresses := make([]string, parties)
+
+	defer func() {
+		for _, listener := range listeners {
+			if listener != nil {
+				listener.Close()
+			}
+		}
+	}()
+
+	for i := range listeners {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, fmt.Errorf("cannot NewLoopbackTCPTransports: %w", err)
+		}
+		listeners[i] = listener
+		addresses[i] = listener.Addr().String()
+	}
+
+	transports := make([]Transport, parties)
+	errs := make([]error, parties)
+
+	// If a party fails, the listeners are closed so that the other parties do not wait for its connections
+	var once sync.Once
+	var wg sync.WaitGroup
+	for i := range transports {
+		wg.Add(1)
+		go func(i int) {
+			if transports[i], errs[i] = NewTCPTransport(PartyID(i), listeners[i], addresses, maxMessageSize); errs[i] != nil {
+				once.Do(func() {
+					for _, listener := range listeners {
+						listener.Close()
+					}
+				})
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			for _, t := range transports {
+				if t != nil {
+					t.Close()
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return transports, nil
+}
+
+// ID returns the PartyID of the local party.
+func (t *tcpTransport) ID() PartyID {
+	return t.id
+}
+
+// Parties returns the number of parties of the network.
+func (t *tcpTransport) Parties() uint64 {
+	return uint64(len(t.conns))
+}
+
+// Send sends msg to the party to. Returns ErrMessageTooLarge if msg is larger than the maximum message size of the Transport.
+func (t *tcpTransport) Send(to PartyID, msg []byte) error {
+
+	c, err := t.conn(to)
+	if err != nil {
+		return fmt.Errorf("cannot Send: %w", err)
+	}
+
+	if uint64(len(msg)) > t.maxMessageSize {
+		return fmt.Errorf("cannot Send: %w", ErrMessageTooLarge)
+	}
+
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint64(header, uint64(len(msg)))
+
+	c.write.Lock()
+	defer c.write.Unlock()
+
+	buffers := net.Buffers{header, msg}
+	if _, err = buffers.WriteTo(c.conn); err != nil {
+		return fmt.Errorf("cannot Send: %w", t.wrap(err))
+	}
+
+	return nil
+}
+
+// Receive blocks until the next message from the party from is received and returns it. If the announced length
+// of the message is larger than the maximum message size of the Transport, returns ErrMessageTooLarge for this message and for all the next
+// ones from the party, since the remaining stream cannot be framed anymore.
+func (t *tcpTransport) Receive(from PartyID) ([]byte, error) {
+
+	c, err := t.conn(from)
+	if err != nil {
+		return nil, fmt.Errorf("cannot Receive: %w", err)
+	}
+
+	c.read.Lock()
+	defer c.read.Unlock()
+
+	if c.readErr != nil {
+		return nil, fmt.Errorf("cannot Receive: %w", c.readErr)
+	}
+
+	header := make([]byte, 8)
+	if _, err = io.ReadFull(c.conn, header); err != nil {
+		return nil, fmt.Errorf("cannot Receive: %w", t.wrap(err))
+	}
+
+	size := binary.BigEndian.Uint64(header)
+	if size > t.maxMessageSize {
+		c.readErr = ErrMessageTooLarge
+		return nil, fmt.Errorf("cannot Receive: %w", c.readErr)
+	}
+
+	msg := make([]byte, size)
+	if _, err = io.ReadFull(c.conn, msg); err != nil {
+		return nil, fmt.Errorf("cannot Receive: %w", t.wrap(err))
+	}
+
+	return msg, nil
+}
+
+// Close closes the connections of the local party to the other parties.
+func (t *tcpTransport) Close() (err error) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	for _, c := range t.conns {
+		if c != nil {
+			if errClose := c.conn.Close(); errClose != nil && err == nil {
+				err = errClose
+			}
+		}
+	}
+
+	return err
+}
+
+func (t *tcpTransport) conn(id PartyID) (*tcpConn, error) {
+
+	if uint64(id) >= t.Parties() || id == t.id {
+		return nil, ErrUnknownParty
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, ErrClosed
+	}
+
+	return t.conns[id], nil
+}
+
+// wrap returns ErrClosed if err was caused by the closing of the connection by either party.
+func (t *tcpTransport) wrap(err error) error {
+
+	t.mu.Lock()
+	closed := t.closed
+	t.mu.Unlock()
+
+	if closed || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrClosed
+	}
+
+	return err
+}
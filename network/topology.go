@@ -0,0 +1,95 @@
+package network
+
+// Topology is the interface for the routes along which the shares of the parties are aggregated and the results
+// are broadcast. The parties form a tree: each party aggregates the shares of its children with its own share and
+// sends the result to its parent, so that the root obtains the aggregation of the shares of all the parties.
+type Topology interface {
+	// Parties returns the number of parties of the Topology.
+	Parties() uint64
+	// Root returns the PartyID of the root of the Topology.
+	Root() PartyID
+	// Parent returns the PartyID of the parent of the party id, and false if the party is the root.
+	Parent(id PartyID) (PartyID, bool)
+	// Children returns the PartyIDs of the children of the party id.
+	Children(id PartyID) []PartyID
+}
+
+// tree is a Topology defined by the parent of each party.
+type tree struct {
+	root     PartyID
+	parents  []PartyID
+	children [][]PartyID
+}
+
+// NewStarTopology creates a new Topology in which all the parties send their share directly to the party root,
+// which aggregates all the shares.
+func NewStarTopology(parties uint64, root PartyID) Topology {
+
+	if uint64(root) >= parties {
+		panic("cannot NewStarTopology: the root is not one of the parties")
+	}
+
+	parents := make([]PartyID, parties)
+	for i := range parents {
+		parents[i] = root
+	}
+
+	return newTree(root, parents)
+}
+
+// NewTreeTopology creates a new Topology in which the parties form a complete tree of the given arity, rooted at
+// the party 0, and where the children of the party i are the parties arity*i+1, ..., arity*i+arity. The shares are
+// aggregated concurrently in each subtree, in a number of steps logarithmic in the number of parties.
+func NewTreeTopology(parties, arity uint64) Topology {
+
+	if parties == 0 {
+		panic("cannot NewTreeTopology: the number of parties must be at least 1")
+	}
+
+	if arity == 0 {
+		panic("cannot NewTreeTopology: the arity must be at least 1")
+	}
+
+	parents := make([]PartyID, parties)
+	for i := uint64(1); i < parties; i++ {
+		parents[i] = PartyID((i - 1) / arity)
+	}
+
+	return newTree(0, parents)
+}
+
+func newTree(root PartyID, parents []PartyID) *tree {
+
+	t := &tree{root: root, parents: parents, children: make([][]PartyID, len(parents))}
+
+	for i, parent := range parents {
+		if PartyID(i) != root {
+			t.children[parent] = append(t.children[parent], PartyID(i))
+		}
+	}
+
+	return t
+}
+
+// Parties returns the number of parties of the Topology.
+func (t *tree) Parties() uint64 {
+	return uint64(len(t.parents))
+}
+
+// Root returns the PartyID of the root of the Topology.
+func (t *tree) Root() PartyID {
+	return t.root
+}
+
+// Parent returns the PartyID of the parent of the party id, and false if the party is the root.
+func (t *tree) Parent(id PartyID) (PartyID, bool) {
+	if id == t.root {
+		return 0, false
+	}
+	return t.parents[id], true
+}
+
+// Children returns the PartyIDs of the children of the party id.
+func (t *tree) Children(id PartyID) []PartyID {
+	return t.children[id]
+}
@@ -2,30 +2,26 @@ package ring
 
 // NTT performs the NTT transformation on the CRT coefficients of a Polynomial, based on the target context.
 func (context *Context) NTT(p1, p2 *Poly) {
-	for x := range context.Modulus {
-		NTT(p1.Coeffs[x], p2.Coeffs[x], context.N, context.nttPsi[x], context.Modulus[x], context.mredParams[x], context.bredParams[x])
-	}
+	context.NTTLvl(uint64(len(context.Modulus)-1), p1, p2)
 }
 
 // NTTLvl performs the NTT transformation on the CRT coefficients of a Polynomial, based on the target context.
 func (context *Context) NTTLvl(level uint64, p1, p2 *Poly) {
-	for x := uint64(0); x < level+1; x++ {
+	context.forEachModulus(level, func(x uint64) {
 		NTT(p1.Coeffs[x], p2.Coeffs[x], context.N, context.nttPsi[x], context.Modulus[x], context.mredParams[x], context.bredParams[x])
-	}
+	})
 }
 
 // InvNTT performs the inverse NTT transformation on the CRT coefficients of of a Polynomial, based on the target context.
 func (context *Context) InvNTT(p1, p2 *Poly) {
-	for x := range context.Modulus {
-		InvNTT(p1.Coeffs[x], p2.Coeffs[x], context.N, context.nttPsiInv[x], context.nttNInv[x], context.Modulus[x], context.mredParams[x])
-	}
+	context.InvNTTLvl(uint64(len(context.Modulus)-1), p1, p2)
 }
 
 // InvNTTLvl performs the NTT transformation on the CRT coefficients of a Polynomial, based on the target context.
 func (context *Context) InvNTTLvl(level uint64, p1, p2 *Poly) {
-	for x := uint64(0); x < level+1; x++ {
+	context.forEachModulus(level, func(x uint64) {
 		InvNTT(p1.Coeffs[x], p2.Coeffs[x], context.N, context.nttPsiInv[x], context.nttNInv[x], context.Modulus[x], context.mredParams[x])
-	}
+	})
 }
 
 // Butterfly computes X, Y = U + V*Psi, U - V*Psi mod Q.
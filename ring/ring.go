@@ -219,79 +219,58 @@ func (context *Context) MulCoeffsAndAddNoMod(p1, p2, p3 *Poly) {
 // MulCoeffsMontgomery multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, returning the result on p3.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomery(p1, p2, p3 *Poly) {
-	for i, qi := range context.Modulus {
-		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
-		mredParams := context.mredParams[i]
-		for j := uint64(0); j < context.N; j++ {
-			p3tmp[j] = MRed(p1tmp[j], p2tmp[j], qi, mredParams)
-		}
-	}
+	context.MulCoeffsMontgomeryLvl(uint64(len(context.Modulus)-1), p1, p2, p3)
 }
 
 // MulCoeffsMontgomeryLvl multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, returning the result on p3.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomeryLvl(level uint64, p1, p2, p3 *Poly) {
-	var qi uint64
-	for i := uint64(0); i < level+1; i++ {
-		qi = context.Modulus[i]
+	context.forEachModulus(level, func(i uint64) {
+		qi := context.Modulus[i]
 		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
 		mredParams := context.mredParams[i]
 		for j := uint64(0); j < context.N; j++ {
 			p3tmp[j] = MRed(p1tmp[j], p2tmp[j], qi, mredParams)
 		}
-	}
+	})
 }
 
 // MulCoeffsMontgomeryAndAdd multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, adding the result to p3.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomeryAndAdd(p1, p2, p3 *Poly) {
-	for i, qi := range context.Modulus {
-		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
-		mredParams := context.mredParams[i]
-		for j := uint64(0); j < context.N; j++ {
-			p3tmp[j] = CRed(p3tmp[j]+MRed(p1tmp[j], p2tmp[j], qi, mredParams), qi)
-		}
-	}
+	context.MulCoeffsMontgomeryAndAddLvl(uint64(len(context.Modulus)-1), p1, p2, p3)
 }
 
 // MulCoeffsMontgomeryAndAddLvl multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, adding the result to p3.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomeryAndAddLvl(level uint64, p1, p2, p3 *Poly) {
-	var qi uint64
-	for i := uint64(0); i < level+1; i++ {
-		qi = context.Modulus[i]
+	context.forEachModulus(level, func(i uint64) {
+		qi := context.Modulus[i]
 		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
 		mredParams := context.mredParams[i]
 		for j := uint64(0); j < context.N; j++ {
 			p3tmp[j] = CRed(p3tmp[j]+MRed(p1tmp[j], p2tmp[j], qi, mredParams), qi)
 		}
-	}
+	})
 }
 
 // MulCoeffsMontgomeryAndAddNoMod multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, adding the result to p3 without modular reduction.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomeryAndAddNoMod(p1, p2, p3 *Poly) {
-	for i, qi := range context.Modulus {
-		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
-		mredParams := context.mredParams[i]
-		for j := uint64(0); j < context.N; j++ {
-			p3tmp[j] += MRed(p1tmp[j], p2tmp[j], qi, mredParams)
-		}
-	}
+	context.MulCoeffsMontgomeryAndAddNoModLvl(uint64(len(context.Modulus)-1), p1, p2, p3)
 }
 
 // MulCoeffsMontgomeryAndAddNoModLvl multiplies p1 by p2 coefficient wise with a Montgomery modular reduction, adding the result to p3 without modular reduction.
 // Expects p1 and/or p2 to be in Montgomery form for correctness (see MRed).
 func (context *Context) MulCoeffsMontgomeryAndAddNoModLvl(level uint64, p1, p2, p3 *Poly) {
-	var qi uint64
-	for i := uint64(0); i < level+1; i++ {
-		qi = context.Modulus[i]
+	context.forEachModulus(level, func(i uint64) {
+		qi := context.Modulus[i]
 		p1tmp, p2tmp, p3tmp := p1.Coeffs[i], p2.Coeffs[i], p3.Coeffs[i]
 		mredParams := context.mredParams[i]
 		for j := uint64(0); j < context.N; j++ {
 			p3tmp[j] += MRed(p1tmp[j], p2tmp[j], qi, mredParams)
 		}
-	}
+	})
 }
 
 // MulCoeffsMontgomeryConstantAndAddNoModLvl is like MulCoeffsMontgomeryAndAddNoModLvl but in constant time
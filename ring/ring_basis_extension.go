@@ -9,10 +9,10 @@ import (
 type FastBasisExtender struct {
 	contextQ        *Context
 	contextP        *Context
-	paramsQP        *modupParams
+	paramsQP        []*modupParams
 	paramsPQ        *modupParams
 	modDownParamsPQ []uint64
-	modDownParamsQP []uint64
+	modDownParamsQP [][]uint64
 	polypoolQ       *Poly
 	polypoolP       *Poly
 }
@@ -36,7 +36,7 @@ type modupParams struct {
 	mredParamsP []uint64
 }
 
-func genModDownParams(contextP, contextQ *Context) (params []uint64) {
+func genModDownParams(contextP *Context, Q *big.Int) (params []uint64) {
 
 	params = make([]uint64, len(contextP.Modulus))
 
@@ -44,7 +44,7 @@ func genModDownParams(contextP, contextQ *Context) (params []uint64) {
 	tmp := new(big.Int)
 	for i, Qi := range contextP.Modulus {
 
-		params[i] = tmp.Mod(contextQ.ModulusBigint, NewUint(Qi)).Uint64()
+		params[i] = tmp.Mod(Q, NewUint(Qi)).Uint64()
 		params[i] = ModExp(params[i], Qi-2, Qi)
 		params[i] = MForm(params[i], Qi, bredParams[i])
 	}
@@ -53,7 +53,8 @@ func genModDownParams(contextP, contextQ *Context) (params []uint64) {
 }
 
 // NewFastBasisExtender is a struct storing all the pre-computed parameters to apply modUp from a basis Q to P
-// and modDown from a basis QP to Q.
+// and modDown from a basis QP to Q. The parameters of the modUp from Q to P and of the modDown from QP to P
+// are pre-computed for each level of Q.
 func NewFastBasisExtender(contextQ, contextP *Context) *FastBasisExtender {
 
 	newParams := new(FastBasisExtender)
@@ -61,11 +62,18 @@ func NewFastBasisExtender(contextQ, contextP *Context) *FastBasisExtender {
 	newParams.contextQ = contextQ
 	newParams.contextP = contextP
 
-	newParams.paramsQP = basisextenderparameters(contextQ.Modulus, contextP.Modulus)
 	newParams.paramsPQ = basisextenderparameters(contextP.Modulus, contextQ.Modulus)
+	newParams.modDownParamsPQ = genModDownParams(contextQ, contextP.ModulusBigint)
 
-	newParams.modDownParamsPQ = genModDownParams(contextQ, contextP)
-	newParams.modDownParamsQP = genModDownParams(contextP, contextQ)
+	newParams.paramsQP = make([]*modupParams, len(contextQ.Modulus))
+	newParams.modDownParamsQP = make([][]uint64, len(contextQ.Modulus))
+
+	Q := NewUint(1)
+	for i, qi := range contextQ.Modulus {
+		Q.Mul(Q, NewUint(qi))
+		newParams.paramsQP[i] = basisextenderparameters(contextQ.Modulus[:i+1], contextP.Modulus)
+		newParams.modDownParamsQP[i] = genModDownParams(contextP, Q)
+	}
 
 	newParams.polypoolQ = contextQ.NewPoly()
 	newParams.polypoolP = contextP.NewPoly()
@@ -73,6 +81,21 @@ func NewFastBasisExtender(contextQ, contextP *Context) *FastBasisExtender {
 	return newParams
 }
 
+// ShallowCopy creates a shallow copy of the target FastBasisExtender, which shares its read-only precomputed
+// parameters but has its own memory pool. The copy and the target can then be used concurrently.
+func (basisextender *FastBasisExtender) ShallowCopy() *FastBasisExtender {
+	return &FastBasisExtender{
+		contextQ:        basisextender.contextQ,
+		contextP:        basisextender.contextP,
+		paramsQP:        basisextender.paramsQP,
+		paramsPQ:        basisextender.paramsPQ,
+		modDownParamsPQ: basisextender.modDownParamsPQ,
+		modDownParamsQP: basisextender.modDownParamsQP,
+		polypoolQ:       basisextender.contextQ.NewPoly(),
+		polypoolP:       basisextender.contextP.NewPoly(),
+	}
+}
+
 func basisextenderparameters(Q, P []uint64) (params *modupParams) {
 
 	params = new(modupParams)
@@ -145,14 +168,14 @@ func basisextenderparameters(Q, P []uint64) (params *modupParams) {
 // Given a polynomial with coefficients in basis {Q0,Q1....Qi}
 // Extends its basis from {Q0,Q1....Qi} to {Q0,Q1....Qi,P0,P1...Pj}
 func (basisextender *FastBasisExtender) ModUpSplitQP(level uint64, p1, p2 *Poly) {
-	modUpExact(p1.Coeffs[:level+1], p2.Coeffs[:uint64(len(basisextender.paramsQP.P))], basisextender.paramsQP)
+	modUpExact(basisextender.contextQ.pool, p1.Coeffs[:level+1], p2.Coeffs[:uint64(len(basisextender.contextP.Modulus))], basisextender.paramsQP[level])
 }
 
 // ModUpSplitPQ extends the basis of a polynomial
 // Given a polynomial with coefficients in basis {P0,P1....Pi}
 // Extends its basis from {P0,P1....Pi} to {Q0,Q1...Qj}
 func (basisextender *FastBasisExtender) ModUpSplitPQ(level uint64, p1, p2 *Poly) {
-	modUpExact(p1.Coeffs[:level+1], p2.Coeffs[:uint64(len(basisextender.paramsPQ.P))], basisextender.paramsPQ)
+	modUpExact(basisextender.contextQ.pool, p1.Coeffs[:level+1], p2.Coeffs[:uint64(len(basisextender.paramsPQ.P))], basisextender.paramsPQ)
 }
 
 // ModDownNTTPQ reduces the basis of a polynomial.
@@ -168,16 +191,16 @@ func (basisextender *FastBasisExtender) ModDownNTTPQ(level uint64, p1, p2 *Poly)
 	polypool := basisextender.polypoolQ
 
 	// First we get the P basis part of p1 out of the NTT domain
-	for j := 0; j < len(contextP.Modulus); j++ {
+	contextQ.pool.run(len(contextP.Modulus), func(j int) {
 		InvNTT(p1.Coeffs[len(contextQ.Modulus)+j], p1.Coeffs[len(contextQ.Modulus)+j], contextP.N, contextP.GetNttPsiInv()[j], contextP.GetNttNInv()[j], contextP.Modulus[j], contextP.GetMredParams()[j])
-	}
+	})
 
 	// Then we target this P basis of p1 and convert it to a Q basis (at the "level" of p1) and copy it on polypool
 	// polypool is now the representation of the P basis of p1 but in basis Q (at the "level" of p1)
-	modUpExact(p1.Coeffs[len(contextQ.Modulus):len(contextQ.Modulus)+len(contextP.Modulus)], polypool.Coeffs[:level+1], basisextender.paramsPQ)
+	modUpExact(basisextender.contextQ.pool, p1.Coeffs[len(contextQ.Modulus):len(contextQ.Modulus)+len(contextP.Modulus)], polypool.Coeffs[:level+1], basisextender.paramsPQ)
 
 	// Finaly, for each level of p1 (and polypool since they now share the same basis) we compute p2 = (P^-1) * (p1 - polypool) mod Q
-	for i := uint64(0); i < level+1; i++ {
+	contextQ.forEachModulus(level, func(i uint64) {
 
 		qi := contextQ.Modulus[i]
 		p1tmp := p1.Coeffs[i]
@@ -194,7 +217,7 @@ func (basisextender *FastBasisExtender) ModDownNTTPQ(level uint64, p1, p2 *Poly)
 		for j := uint64(0); j < contextQ.N; j++ {
 			p2tmp[j] = MRed(p1tmp[j]+(qi-p3tmp[j]), params, qi, mredParams)
 		}
-	}
+	})
 
 	// In total we do len(P) + len(Q) NTT, which is optimal (linear in the number of moduli of P and Q)
 }
@@ -216,10 +239,10 @@ func (basisextender *FastBasisExtender) ModDownSplitedNTTPQ(level uint64, p1Q, p
 
 	// Then we target this P basis of p1 and convert it to a Q basis (at the "level" of p1) and copy it on polypool
 	// polypool is now the representation of the P basis of p1 but in basis Q (at the "level" of p1)
-	modUpExact(p1P.Coeffs, polypool.Coeffs[:level+1], basisextender.paramsPQ)
+	modUpExact(basisextender.contextQ.pool, p1P.Coeffs, polypool.Coeffs[:level+1], basisextender.paramsPQ)
 
 	// Finaly, for each level of p1 (and polypool since they now share the same basis) we compute p2 = (P^-1) * (p1 - polypool) mod Q
-	for i := uint64(0); i < level+1; i++ {
+	contextQ.forEachModulus(level, func(i uint64) {
 
 		qi := contextQ.Modulus[i]
 		p1tmp := p1Q.Coeffs[i]
@@ -236,7 +259,7 @@ func (basisextender *FastBasisExtender) ModDownSplitedNTTPQ(level uint64, p1Q, p
 		for j := uint64(0); j < contextQ.N; j++ {
 			p2tmp[j] = MRed(p1tmp[j]+(qi-p3tmp[j]), params, qi, mredParams)
 		}
-	}
+	})
 
 	// In total we do len(P) + len(Q) NTT, which is optimal (linear in the number of moduli of P and Q)
 }
@@ -253,10 +276,10 @@ func (basisextender *FastBasisExtender) ModDownPQ(level uint64, p1, p2 *Poly) {
 
 	// Then we target this P basis of p1 and convert it to a Q basis (at the "level" of p1) and copy it on polypool
 	// polypool is now the representation of the P basis of p1 but in basis Q (at the "level" of p1)
-	modUpExact(p1.Coeffs[level+1:level+1+uint64(len(basisextender.paramsQP.P))], polypool.Coeffs[:level+1], basisextender.paramsPQ)
+	modUpExact(basisextender.contextQ.pool, p1.Coeffs[level+1:level+1+uint64(len(basisextender.contextP.Modulus))], polypool.Coeffs[:level+1], basisextender.paramsPQ)
 
 	// Finaly, for each level of p1 (and polypool since they now share the same basis) we compute p2 = (P^-1) * (p1 - polypool) mod Q
-	for i := uint64(0); i < level+1; i++ {
+	context.forEachModulus(level, func(i uint64) {
 
 		qi := context.Modulus[i]
 		p1tmp := p1.Coeffs[i]
@@ -269,7 +292,7 @@ func (basisextender *FastBasisExtender) ModDownPQ(level uint64, p1, p2 *Poly) {
 		for j := uint64(0); j < context.N; j++ {
 			p2tmp[j] = MRed(p1tmp[j]+(qi-p3tmp[j]), params, qi, mredParams)
 		}
-	}
+	})
 
 	// In total we do len(P) + len(Q) NTT, which is optimal (linear in the number of moduli of P and Q)
 }
@@ -286,10 +309,10 @@ func (basisextender *FastBasisExtender) ModDownSplitedPQ(level uint64, p1Q, p1P,
 
 	// Then we target this P basis of p1 and convert it to a Q basis (at the "level" of p1) and copy it on polypool
 	// polypool is now the representation of the P basis of p1 but in basis Q (at the "level" of p1)
-	modUpExact(p1P.Coeffs, polypool.Coeffs[:level+1], basisextender.paramsPQ)
+	modUpExact(basisextender.contextQ.pool, p1P.Coeffs, polypool.Coeffs[:level+1], basisextender.paramsPQ)
 
 	// Finaly, for each level of p1 (and polypool since they now share the same basis) we compute p2 = (P^-1) * (p1 - polypool) mod Q
-	for i := uint64(0); i < level+1; i++ {
+	contextQ.forEachModulus(level, func(i uint64) {
 
 		qi := contextQ.Modulus[i]
 		p1tmp := p1Q.Coeffs[i]
@@ -302,7 +325,7 @@ func (basisextender *FastBasisExtender) ModDownSplitedPQ(level uint64, p1Q, p1P,
 		for j := uint64(0); j < contextQ.N; j++ {
 			p2tmp[j] = MRed(p1tmp[j]+(qi-p3tmp[j]), params, qi, mredParams)
 		}
-	}
+	})
 
 	// In total we do len(P) + len(Q) NTT, which is optimal (linear in the number of moduli of P and Q)
 }
@@ -310,12 +333,12 @@ func (basisextender *FastBasisExtender) ModDownSplitedPQ(level uint64, p1Q, p1P,
 // ModDownSplitedQP reduces the basis of a polynomial.
 // Given a polynomial with coefficients in basis {Q0,Q1....Qi} and {P0,P1...Pj}
 // Reduces its basis from {Q0,Q1....Qi} and {P0,P1...Pj} to {P0,P1...Pj}
-// and does a runded integer division of the result by Q.
+// and does a runded integer division of the result by Q (the product of the moduli up to levelQ).
 func (basisextender *FastBasisExtender) ModDownSplitedQP(levelQ, levelP uint64, p1Q, p1P, p2 *Poly) {
 
 	contextP := basisextender.contextP
 	//contextQ := basisextender.contextQ
-	modDownParams := basisextender.modDownParamsQP
+	modDownParams := basisextender.modDownParamsQP[levelQ]
 	polypool := basisextender.polypoolP
 
 	// Then we target this P basis of p1 and convert it to a Q basis (at the "level" of p1) and copy it on polypool
@@ -331,8 +354,9 @@ func (basisextender *FastBasisExtender) ModDownSplitedQP(levelQ, levelP uint64,
 	//fmt.Println("P", bigint_coeffs[0])
 
 	// Finaly, for each level of p1 (and polypool since they now share the same basis) we compute p2 = (P^-1) * (p1 - polypool) mod Q
-	for i := uint64(0); i < levelP+1; i++ {
+	basisextender.contextQ.pool.run(int(levelP+1), func(x int) {
 
+		i := uint64(x)
 		qi := contextP.Modulus[i]
 		p1tmp := p1P.Coeffs[i]
 		p2tmp := p2.Coeffs[i]
@@ -344,12 +368,20 @@ func (basisextender *FastBasisExtender) ModDownSplitedQP(levelQ, levelP uint64,
 		for j := uint64(0); j < contextP.N; j++ {
 			p2tmp[j] = MRed(p1tmp[j]+(qi-p3tmp[j]), params, qi, mredParams)
 		}
-	}
+	})
 
 	// In total we do len(P) + len(Q) NTT, which is optimal (linear in the number of moduli of P and Q)
 }
 
-func modUpExact(p1, p2 [][]uint64, params *modupParams) {
+// modUpExact extends the basis of p1 to the basis of p2. Since the extension is independent for each coefficient,
+// the coefficients are split into chunks which are processed on the given pool (sequentially if the pool is nil).
+func modUpExact(pool *WorkerPool, p1, p2 [][]uint64, params *modupParams) {
+	pool.runChunks(len(p1[0]), func(start, end int) {
+		modUpExactRange(p1, p2, params, uint64(start), uint64(end))
+	})
+}
+
+func modUpExactRange(p1, p2 [][]uint64, params *modupParams, start, end uint64) {
 
 	var v uint64
 	var vi float64
@@ -358,7 +390,7 @@ func modUpExact(p1, p2 [][]uint64, params *modupParams) {
 	y := make([]uint64, len(p1))
 
 	//We loop over each coefficient and apply the basis extension
-	for x := uint64(0); x < uint64(len(p1[0])); x++ {
+	for x := start; x < end; x++ {
 
 		vi = 0
 
@@ -711,3 +743,24 @@ func (decomposer *Decomposer) DecomposeAndSplit(level, crtDecompLevel uint64, p0
 		}
 	}
 }
+
+// DecomposeDigitLvl takes a polynomial p1 (outside of the NTT domain), extracts the j-th digit in base 2^logBase of its
+// coefficients modulo the i-th modulus, and sets the result on each modulus of p2 up to the given level.
+// This is the digit decomposition used by the key-switching with a gadget decomposition. Since a digit is smaller than
+// 2^logBase, logBase must not be larger than the bit-size of the smallest modulus.
+func (context *Context) DecomposeDigitLvl(level, i, j, logBase uint64, p1, p2 *Poly) {
+
+	shift := j * logBase
+	mask := uint64(1<<logBase) - 1
+
+	coeffs := p1.Coeffs[i]
+	digits := p2.Coeffs[0]
+
+	for x := uint64(0); x < context.N; x++ {
+		digits[x] = (coeffs[x] >> shift) & mask
+	}
+
+	for k := uint64(1); k < level+1; k++ {
+		copy(p2.Coeffs[k], digits)
+	}
+}
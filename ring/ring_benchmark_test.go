@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/bits"
 	"math/rand"
+	"runtime"
 	"testing"
 )
 
@@ -23,6 +24,7 @@ func BenchmarkRing(b *testing.B) {
 	b.Run("MRed", benchMRed)
 	b.Run("BRed", benchBRed)
 	b.Run("BRedAdd", benchBRedAdd)
+	b.Run("WorkerPool", benchWorkerPool)
 
 }
 
@@ -457,3 +459,64 @@ func benchBRedAdd(b *testing.B) {
 		}
 	})
 }
+
+func benchWorkerPool(b *testing.B) {
+
+	pool := NewWorkerPool(runtime.NumCPU())
+	defer pool.Close()
+
+	for _, parameters := range testParams.polyParams {
+
+		contextQ := genPolyContext(parameters[0])
+		contextP := genPolyContext(parameters[1])
+
+		p0 := contextQ.NewUniformPoly()
+		p1 := contextQ.NewUniformPoly()
+		pP := contextP.NewUniformPoly()
+
+		level := uint64(len(contextQ.Modulus) - 1)
+
+		basisExtender := NewFastBasisExtender(contextQ, contextP)
+
+		for _, withPool := range []*WorkerPool{nil, pool} {
+
+			contextQ.SetWorkerPool(withPool)
+			contextP.SetWorkerPool(withPool)
+
+			mode := "Sequential"
+			if withPool != nil {
+				mode = fmt.Sprintf("Workers=%d", withPool.Workers())
+			}
+
+			b.Run(testString(fmt.Sprintf("NTT/%s/", mode), contextQ), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					contextQ.NTT(p0, p0)
+				}
+			})
+
+			b.Run(testString(fmt.Sprintf("InvNTT/%s/", mode), contextQ), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					contextQ.InvNTT(p0, p0)
+				}
+			})
+
+			b.Run(testString(fmt.Sprintf("MulCoeffsMontgomery/%s/", mode), contextQ), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					contextQ.MulCoeffsMontgomery(p0, p1, p0)
+				}
+			})
+
+			b.Run(fmt.Sprintf("ModUp/%s/N=%d/limbsQ=%d/limbsP=%d", mode, contextQ.N, len(contextQ.Modulus), len(contextP.Modulus)), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					basisExtender.ModUpSplitQP(level, p0, pP)
+				}
+			})
+
+			b.Run(fmt.Sprintf("ModDownNTT/%s/N=%d/limbsQ=%d/limbsP=%d", mode, contextQ.N, len(contextQ.Modulus), len(contextP.Modulus)), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					basisExtender.ModDownSplitedNTTPQ(level, p0, pP, p0)
+				}
+			})
+		}
+	}
+}
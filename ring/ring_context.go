@@ -48,6 +48,9 @@ type Context struct {
 	nttPsi    [][]uint64 //powers of the inverse of the 2nth primitive root in Montgomery form (in bitreversed order)
 	nttPsiInv [][]uint64 //powers of the inverse of the 2nth primitive root in Montgomery form (in bitreversed order)
 	nttNInv   []uint64   //[N^-1] mod Qi in Montgomery form
+
+	// Optional pool of goroutines for the per-modulus operations (nil for sequential execution)
+	pool *WorkerPool
 }
 
 // NewContext generates a new empty context.
@@ -104,6 +104,13 @@ func PermuteNTTWithIndex(polIn *Poly, index []uint64, polOut *Poly) {
 // It maps the coefficients x^i to x^(gen*i)
 // Careful, not inplace!
 func (context *Context) Permute(polIn *Poly, gen uint64, polOut *Poly) {
+	context.PermuteLvl(uint64(len(context.Modulus)-1), polIn, gen, polOut)
+}
+
+// PermuteLvl applies the galois transform on a polynonial outside of the NTT domain,
+// on the moduli from level 0 to the given level. It maps the coefficients x^i to x^(gen*i)
+// Careful, not inplace!
+func (context *Context) PermuteLvl(level uint64, polIn *Poly, gen uint64, polOut *Poly) {
 
 	var mask, index, indexRaw, logN, tmp uint64
 
@@ -119,7 +126,7 @@ func (context *Context) Permute(polIn *Poly, gen uint64, polOut *Poly) {
 
 		tmp = (indexRaw >> logN) & 1
 
-		for j, qi := range context.Modulus {
+		for j, qi := range context.Modulus[:level+1] {
 
 			polOut.Coeffs[j][index] = polIn.Coeffs[j][i]*(tmp^1) | (qi-polIn.Coeffs[j][i])*tmp
 		}
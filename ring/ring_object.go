@@ -5,6 +5,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"math/bits"
+
+	"golang.org/x/crypto/blake2b"
 )
 
 // Poly is the structure containing the coefficients of a polynomial.
@@ -175,6 +177,24 @@ func (pol *Poly) WriteTo(data []byte) (uint64, error) {
 	return cnt, err
 }
 
+// Digest returns a blake2b-256 digest of the coefficients of the target polynomial on its first level+1 moduli, which
+// can be compared to detect a modification of the polynomial.
+func (pol *Poly) Digest(level uint64) []byte {
+
+	hash, _ := blake2b.New256(nil)
+
+	buff := make([]byte, pol.GetDegree()<<3)
+
+	for _, coeffs := range pol.Coeffs[:level+1] {
+		for j, c := range coeffs {
+			binary.BigEndian.PutUint64(buff[j<<3:], c)
+		}
+		hash.Write(buff)
+	}
+
+	return hash.Sum(nil)
+}
+
 // WriteCoeffs write the coefficient to the given data array.
 // Fails if the data array is not big enough to contain the ring.Poly
 func (pol *Poly) WriteCoeffs(data []byte) (uint64, error) {
@@ -0,0 +1,162 @@
+package ring
+
+import (
+	"sync"
+)
+
+// WorkerPool is a pool of goroutines on which a Context can spread the work that is independent for each modulus
+// (such as the NTT or the coefficient-wise multiplications), instead of looping over the moduli on a single core.
+// A WorkerPool can be shared by several Contexts and can safely be used concurrently.
+type WorkerPool struct {
+	workers int
+	tasks   chan func()
+
+	mutex  sync.RWMutex // Guards the sends on tasks against its closing
+	closed bool
+}
+
+// NewWorkerPool creates a new WorkerPool with the given number of goroutines. Panics if workers is smaller than 1.
+func NewWorkerPool(workers int) *WorkerPool {
+
+	if workers < 1 {
+		panic("cannot NewWorkerPool: the number of workers must be at least 1")
+	}
+
+	pool := &WorkerPool{
+		workers: workers,
+		tasks:   make(chan func()),
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range pool.tasks {
+				task()
+			}
+		}()
+	}
+
+	return pool
+}
+
+// Workers returns the number of goroutines of the target WorkerPool.
+func (pool *WorkerPool) Workers() int {
+	return pool.workers
+}
+
+// Close stops the goroutines of the target WorkerPool. The Contexts still using a closed WorkerPool execute their
+// operations sequentially. Close can safely be called several times, and concurrently with operations using the pool.
+func (pool *WorkerPool) Close() {
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if !pool.closed {
+		pool.closed = true
+		close(pool.tasks)
+	}
+}
+
+// submit hands the task to an idle worker of the target WorkerPool, and returns false if no worker is available or
+// if the pool is closed.
+func (pool *WorkerPool) submit(task func()) bool {
+
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	if pool.closed {
+		return false
+	}
+
+	select {
+	case pool.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// run calls f(i) for each i in [0, n) and returns once all the calls are done. If the pool is nil or closed, the calls
+// are made sequentially. Otherwise, they are handed to the idle workers of the pool, and the calls for which no worker
+// is available are made by the caller itself, so that nested calls (e.g. from a task of the same pool) cannot deadlock.
+func (pool *WorkerPool) run(n int, f func(i int)) {
+
+	if pool == nil || n < 2 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < n-1; i++ {
+
+		i := i
+
+		wg.Add(1)
+
+		task := func() {
+			f(i)
+			wg.Done()
+		}
+
+		if !pool.submit(task) {
+			task()
+		}
+	}
+
+	f(n - 1)
+
+	wg.Wait()
+}
+
+// runChunks splits [0, n) into at most one chunk per worker (plus the caller) and calls f(start, end) for each of
+// them, with the same scheduling as run.
+func (pool *WorkerPool) runChunks(n int, f func(start, end int)) {
+
+	chunks := 1
+	if pool != nil {
+		chunks = pool.workers + 1
+	}
+
+	if chunks > n {
+		chunks = n
+	}
+
+	if chunks < 2 {
+		f(0, n)
+		return
+	}
+
+	size := (n + chunks - 1) / chunks
+
+	pool.run(chunks, func(i int) {
+		start := i * size
+		end := start + size
+		if end > n {
+			end = n
+		}
+		if start < end {
+			f(start, end)
+		}
+	})
+}
+
+// SetWorkerPool sets the WorkerPool on which the target Context spreads its per-modulus operations (NTT, InvNTT,
+// MulCoeffsMontgomery and the basis extensions of a FastBasisExtender built on this Context). A nil pool restores
+// the sequential execution.
+func (context *Context) SetWorkerPool(pool *WorkerPool) {
+	context.pool = pool
+}
+
+// WorkerPool returns the WorkerPool of the target Context, or nil if its operations are executed sequentially.
+func (context *Context) WorkerPool() *WorkerPool {
+	return context.pool
+}
+
+// forEachModulus calls f(i) for each i in [0, level], on the WorkerPool of the target Context if it has one.
+func (context *Context) forEachModulus(level uint64, f func(i uint64)) {
+	context.pool.run(int(level+1), func(i int) {
+		f(uint64(i))
+	})
+}
@@ -47,6 +47,7 @@ func TestRing(t *testing.T) {
 	t.Run("MarshalBinary", testMarshalBinary)
 	t.Run("GaussianSampler", testGaussianSampler)
 	t.Run("TernarySampler", testTernarySampler)
+	t.Run("UniformBigSampler", testUniformBigSampler)
 	t.Run("GaloisShift", testGaloisShift)
 	t.Run("BRed", testBRed)
 	t.Run("MRed", testMRed)
@@ -55,6 +56,7 @@ func TestRing(t *testing.T) {
 	t.Run("ExtendBasis", testExtendBasis)
 	t.Run("SimpleScaling", testSimpleScaling)
 	t.Run("MultByMonomial", testMultByMonomial)
+	t.Run("WorkerPool", testWorkerPool)
 }
 
 func genPolyContext(params *Parameters) (context *Context) {
@@ -303,6 +305,41 @@ func testGaussianSampler(t *testing.T) {
 	}
 }
 
+func testUniformBigSampler(t *testing.T) {
+
+	for _, parameters := range testParams.polyParams {
+
+		context := genPolyContext(parameters[0])
+
+		t.Run(testString("", context), func(t *testing.T) {
+
+			// The bound is larger than the first modulus, but smaller than Q/2
+			bound := new(big.Int).Rsh(context.ModulusBigint, 2)
+
+			pol := context.NewPoly()
+
+			context.SampleUniformBigAndAddLvl(uint64(len(context.Modulus)-1), pol, bound)
+
+			coeffs := make([]*big.Int, context.N)
+			context.PolyToBigint(pol, coeffs)
+
+			qHalf := new(big.Int).Rsh(context.ModulusBigint, 1)
+
+			for i := range coeffs {
+
+				if coeffs[i].Cmp(qHalf) > 0 {
+					coeffs[i].Sub(coeffs[i], context.ModulusBigint)
+				}
+
+				if coeffs[i].CmpAbs(bound) > 0 {
+					t.Errorf("UniformBigSampler")
+					break
+				}
+			}
+		})
+	}
+}
+
 func testTernarySampler(t *testing.T) {
 
 	for _, parameters := range testParams.polyParams {
@@ -580,6 +617,25 @@ func testExtendBasis(t *testing.T) {
 					}
 				}
 			}
+
+			// Basis extension from the first modulus of Q only
+			for i := uint64(0); i < contextQ.N; i++ {
+				coeffs[i] = RandInt(NewUint(contextQ.Modulus[0]))
+			}
+
+			contextQ.SetCoefficientsBigint(coeffs, Pol)
+			contextP.SetCoefficientsBigint(coeffs, PolWant)
+
+			basisextender.ModUpSplitQP(0, Pol, PolTest)
+
+			for i := range contextP.Modulus {
+				for j := uint64(0); j < contextQ.N; j++ {
+					if PolTest.Coeffs[i][j] != PolWant.Coeffs[i][j] {
+						t.Errorf("error extendBasis level 0, have %v - want %v", PolTest.Coeffs[i][j], PolWant.Coeffs[i][j])
+						break
+					}
+				}
+			}
 		})
 	}
 }
@@ -650,3 +706,99 @@ func testMultByMonomial(t *testing.T) {
 		})
 	}
 }
+
+func testWorkerPool(t *testing.T) {
+
+	pool := NewWorkerPool(4)
+	defer pool.Close()
+
+	for _, parameters := range testParams.polyParams {
+
+		contextQ := genPolyContext(parameters[0])
+		contextP := genPolyContext(parameters[1])
+
+		contextQPool := genPolyContext(parameters[0])
+		contextPPool := genPolyContext(parameters[1])
+		contextQPool.SetWorkerPool(pool)
+		contextPPool.SetWorkerPool(pool)
+
+		t.Run(testString("", contextQ), func(t *testing.T) {
+
+			if contextQPool.WorkerPool() != pool || contextQ.WorkerPool() != nil {
+				t.Errorf("error : WorkerPool does not return the pool of the context")
+			}
+
+			p0 := contextQ.NewUniformPoly()
+			p1 := contextQ.NewUniformPoly()
+
+			want := contextQ.NewPoly()
+			have := contextQ.NewPoly()
+
+			contextQ.NTT(p0, want)
+			contextQPool.NTT(p0, have)
+			if !contextQ.Equal(want, have) {
+				t.Errorf("error : parallel NTT")
+			}
+
+			contextQ.InvNTTLvl(0, p0, want)
+			contextQPool.InvNTTLvl(0, p0, have)
+			if !contextQ.EqualLvl(0, want, have) {
+				t.Errorf("error : parallel InvNTTLvl")
+			}
+
+			contextQ.MulCoeffsMontgomery(p0, p1, want)
+			contextQPool.MulCoeffsMontgomery(p0, p1, have)
+			if !contextQ.Equal(want, have) {
+				t.Errorf("error : parallel MulCoeffsMontgomery")
+			}
+
+			contextQ.MulCoeffsMontgomeryAndAdd(p0, p1, want)
+			contextQPool.MulCoeffsMontgomeryAndAdd(p0, p1, have)
+			if !contextQ.Equal(want, have) {
+				t.Errorf("error : parallel MulCoeffsMontgomeryAndAdd")
+			}
+
+			level := uint64(len(contextQ.Modulus) - 1)
+
+			extender := NewFastBasisExtender(contextQ, contextP)
+			extenderPool := NewFastBasisExtender(contextQPool, contextPPool)
+
+			wantP := contextP.NewPoly()
+			haveP := contextP.NewPoly()
+
+			extender.ModUpSplitQP(level, p0, wantP)
+			extenderPool.ModUpSplitQP(level, p0, haveP)
+			if !contextP.Equal(wantP, haveP) {
+				t.Errorf("error : parallel ModUpSplitQP")
+			}
+
+			extender.ModDownSplitedNTTPQ(level, p0, wantP.CopyNew(), want)
+			extenderPool.ModDownSplitedNTTPQ(level, p0, haveP.CopyNew(), have)
+			if !contextQ.Equal(want, have) {
+				t.Errorf("error : parallel ModDownSplitedNTTPQ")
+			}
+		})
+
+		t.Run(testString("Closed/", contextQ), func(t *testing.T) {
+
+			closedPool := NewWorkerPool(2)
+			contextQClosed := genPolyContext(parameters[0])
+			contextQClosed.SetWorkerPool(closedPool)
+
+			closedPool.Close()
+			closedPool.Close()
+
+			p0 := contextQ.NewUniformPoly()
+
+			want := contextQ.NewPoly()
+			have := contextQ.NewPoly()
+
+			// A Context attached to a closed pool falls back to the sequential execution
+			contextQ.NTT(p0, want)
+			contextQClosed.NTT(p0, have)
+			if !contextQ.Equal(want, have) {
+				t.Errorf("error : NTT on a closed WorkerPool")
+			}
+		})
+	}
+}
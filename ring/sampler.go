@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"math"
+	"math/big"
 	//"math/rand"
 )
 
@@ -83,6 +84,34 @@ func (context *Context) NewUniformPolyLvl(level uint64) (Pol *Poly) {
 	return
 }
 
+// SampleUniformBigAndAddLvl adds to the first level+1 moduli of pol (in the coefficient domain) a polynomial whose
+// coefficients are integers uniformly distributed in [-bound, bound]. Unlike the gaussian samplers, bound can be
+// larger than the moduli (e.g. to flood a decryption noise), since each coefficient is sampled as a big integer and
+// then reduced modulo each Qi.
+func (context *Context) SampleUniformBigAndAddLvl(level uint64, pol *Poly, bound *big.Int) {
+
+	width := new(big.Int).Lsh(bound, 1)
+	width.Add(width, NewInt(1))
+
+	moduli := make([]*big.Int, level+1)
+	for j := range moduli {
+		moduli[j] = NewUint(context.Modulus[j])
+	}
+
+	tmp := new(big.Int)
+
+	for i := uint64(0); i < context.N; i++ {
+
+		coeff := RandInt(width)
+		coeff.Sub(coeff, bound)
+
+		for j := range moduli {
+			tmp.Mod(coeff, moduli[j])
+			pol.Coeffs[j][i] = CRed(pol.Coeffs[j][i]+tmp.Uint64(), context.Modulus[j])
+		}
+	}
+}
+
 // RandUniform samples a uniform randomInt variable in the range [0, mask] until randomInt is in the range [0, v-1].
 // mask needs to be of the form 2^n -1.
 func RandUniform(v uint64, mask uint64) (randomInt uint64) {
@@ -0,0 +1,86 @@
+package ring
+
+import (
+	"fmt"
+	"math"
+)
+
+// SecretDistribution identifies the distribution of the secret-key of an RLWE instance.
+type SecretDistribution int
+
+const (
+	// SecretTernary is a secret-key with coefficients uniformly distributed in {-1, 0, 1}.
+	SecretTernary SecretDistribution = iota
+	// SecretGaussian is a secret-key with coefficients sampled from the (Gaussian) error distribution.
+	SecretGaussian
+)
+
+// Maximum bit-size of the modulus for each classical security level and log2 of the ring degree, as given by the
+// tables of the Homomorphic Encryption Security Standard (Albrecht et al., HomomorphicEncryption.org, 2018).
+var maxLogQTernary = map[uint64]map[uint64]uint64{
+	128: {10: 27, 11: 54, 12: 109, 13: 218, 14: 438, 15: 881},
+	192: {10: 19, 11: 37, 12: 75, 13: 152, 14: 305, 15: 611},
+	256: {10: 14, 11: 29, 12: 58, 13: 118, 14: 237, 15: 476},
+}
+
+var maxLogQGaussian = map[uint64]map[uint64]uint64{
+	128: {10: 29, 11: 56, 12: 111, 13: 220, 14: 440, 15: 883},
+	192: {10: 21, 11: 39, 12: 77, 13: 154, 14: 307, 15: 613},
+	256: {10: 16, 11: 31, 12: 60, 13: 120, 14: 239, 15: 478},
+}
+
+// MaxLogQ returns the largest bit-size of the modulus of an RLWE instance of degree 2^logN with the given secret distribution
+// that ensures the given classical security level (128, 192 or 256 bits), according to the Homomorphic Encryption Security Standard.
+// Returns an error if the security level or logN are not covered by the standard (which only provides 10 <= logN <= 15).
+func MaxLogQ(logN, securityLevel uint64, secret SecretDistribution) (uint64, error) {
+
+	var tables map[uint64]map[uint64]uint64
+
+	switch secret {
+	case SecretTernary:
+		tables = maxLogQTernary
+	case SecretGaussian:
+		tables = maxLogQGaussian
+	default:
+		return 0, fmt.Errorf("unknown secret distribution %d", secret)
+	}
+
+	table, ok := tables[securityLevel]
+	if !ok {
+		return 0, fmt.Errorf("security level %d is not supported (must be 128, 192 or 256)", securityLevel)
+	}
+
+	maxLogQ, ok := table[logN]
+	if !ok {
+		return 0, fmt.Errorf("LogN = %d is not covered by the security standard (must be between 10 and 15)", logN)
+	}
+
+	return maxLogQ, nil
+}
+
+// CheckSecurity returns an error if an RLWE instance of degree 2^logN with the given secret distribution and a modulus of
+// log2 logQ (which needs not be an integer) does not ensure the given classical security level (128, 192 or 256 bits),
+// according to the Homomorphic Encryption Security Standard.
+func CheckSecurity(logN uint64, logQ float64, securityLevel uint64, secret SecretDistribution) error {
+
+	maxLogQ, err := MaxLogQ(logN, securityLevel, secret)
+	if err != nil {
+		return err
+	}
+
+	if logQ > float64(maxLogQ) {
+		return fmt.Errorf("log2(QP) = %v is larger than %d, the maximum allowed for LogN = %d and %d-bit security", logQ, maxLogQ, logN, securityLevel)
+	}
+
+	return nil
+}
+
+// Log2Moduli returns the log2 of the product of the given moduli.
+func Log2Moduli(moduli ...[]uint64) (logQ float64) {
+	for _, m := range moduli {
+		for _, qi := range m {
+			logQ += math.Log2(float64(qi))
+		}
+	}
+	return
+}
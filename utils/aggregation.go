@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// AggregationTree aggregates shares concurrently along a complete tree of a given arity, whose leaves are the shares.
+// The aggregation order is fixed by the order of the shares and by the arity only, so that it can be verified with
+// the digest of the aggregation.
+type AggregationTree struct {
+	arity uint64
+}
+
+// aggregationNode is a node of an AggregationTree: a share and the digest of its subtree.
+type aggregationNode struct {
+	share  Share
+	digest []byte
+}
+
+const (
+	leafDigestPrefix = 0
+	nodeDigestPrefix = 1
+)
+
+// NewAggregationTree creates a new AggregationTree of the given arity. Panics if arity is smaller than 2.
+func NewAggregationTree(arity uint64) *AggregationTree {
+
+	if arity < 2 {
+		panic("cannot NewAggregationTree: the arity must be at least 2")
+	}
+
+	return &AggregationTree{arity: arity}
+}
+
+// Aggregate aggregates the shares on shareOut, which can be one of the shares. The shares are split in consecutive
+// groups of arity shares, each group being aggregated (in its order) on a new share, and so on until a single group
+// remains, whose aggregation is set on shareOut. The groups of a same level of the tree are aggregated concurrently,
+// so that the aggregation of N shares takes log_arity(N) sequential steps instead of N-1.
+func (tree *AggregationTree) Aggregate(shares []Share, shareOut Share) (err error) {
+	_, err = tree.aggregate(shares, shareOut, false)
+	return
+}
+
+// AggregateAndDigest is the same as Aggregate, but also returns the digest of the aggregation: the digest of a leaf
+// is the hash of the marshaled share, and the digest of a node is the hash of the digests of its children (in their
+// order) and of the marshaled aggregated share. Aggregating the same shares in the same order with the same arity
+// always gives the same digest, while any change in the shares, in their order or in an intermediate aggregation
+// changes it, so that the parties can verify the aggregation.
+func (tree *AggregationTree) AggregateAndDigest(shares []Share, shareOut Share) (digest []byte, err error) {
+	return tree.aggregate(shares, shareOut, true)
+}
+
+func (tree *AggregationTree) aggregate(shares []Share, shareOut Share, withDigest bool) (digest []byte, err error) {
+
+	if len(shares) == 0 {
+		return nil, errors.New("cannot Aggregate: there are no shares to aggregate")
+	}
+
+	level := make([]*aggregationNode, len(shares))
+	for i, share := range shares {
+		if level[i], err = newLeaf(share, withDigest); err != nil {
+			return nil, err
+		}
+	}
+
+	arity := int(tree.arity)
+
+	for {
+
+		groups := (len(level) + arity - 1) / arity
+		last := groups == 1
+
+		next := make([]*aggregationNode, groups)
+		errs := make([]error, groups)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < groups; i++ {
+
+			start, end := i*arity, (i+1)*arity
+			if end > len(level) {
+				end = len(level)
+			}
+
+			// A single share at an intermediate level is carried to the next level as is
+			if end-start == 1 && !last {
+				next[i] = level[start]
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, children []*aggregationNode) {
+
+				next[i], errs[i] = newNode(children[0].share.Allocate(), children, withDigest)
+
+				wg.Done()
+			}(i, level[start:end])
+		}
+
+		wg.Wait()
+
+		for _, err = range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The root is aggregated on a new share and then set on shareOut, since shareOut can be one of the shares
+		if last {
+			shareOut.Aggregate(next[0].share, next[0].share.Allocate())
+			return next[0].digest, nil
+		}
+
+		level = next
+	}
+}
+
+// newLeaf returns the leaf of the share.
+func newLeaf(share Share, withDigest bool) (*aggregationNode, error) {
+
+	if !withDigest {
+		return &aggregationNode{share: share}, nil
+	}
+
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, _ := blake2b.New256(nil)
+	hash.Write([]byte{leafDigestPrefix})
+	hash.Write(data)
+
+	return &aggregationNode{share: share, digest: hash.Sum(nil)}, nil
+}
+
+// newNode aggregates the shares of the children on share, and returns the node of share.
+func newNode(share Share, children []*aggregationNode, withDigest bool) (*aggregationNode, error) {
+
+	if len(children) == 1 {
+		share.Aggregate(children[0].share, children[0].share.Allocate())
+	} else {
+		share.Aggregate(children[0].share, children[1].share)
+		for _, child := range children[2:] {
+			share.Aggregate(share, child.share)
+		}
+	}
+
+	if !withDigest {
+		return &aggregationNode{share: share}, nil
+	}
+
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, _ := blake2b.New256(nil)
+	hash.Write([]byte{nodeDigestPrefix})
+	for _, child := range children {
+		hash.Write(child.digest)
+	}
+	hash.Write(data)
+
+	return &aggregationNode{share: share, digest: hash.Sum(nil)}, nil
+}
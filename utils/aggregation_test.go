@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+// testShare is a share whose aggregation is the sum of its values.
+type testShare struct {
+	values []uint64
+}
+
+func (share *testShare) Allocate() Share {
+	return &testShare{values: make([]uint64, len(share.values))}
+}
+
+func (share *testShare) Aggregate(share1, share2 Share) {
+	for i := range share.values {
+		share.values[i] = share1.(*testShare).values[i] + share2.(*testShare).values[i]
+	}
+}
+
+func (share *testShare) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8*len(share.values))
+	for i, v := range share.values {
+		binary.BigEndian.PutUint64(data[8*i:], v)
+	}
+	return data, nil
+}
+
+func (share *testShare) UnmarshalBinary(data []byte) error {
+	for i := range share.values {
+		share.values[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	return nil
+}
+
+func Test_AggregationTree(t *testing.T) {
+
+	for _, arity := range []uint64{2, 3, 8} {
+		for _, parties := range []uint64{1, 2, 7, 16, 17} {
+
+			t.Run(fmt.Sprintf("arity=%d/parties=%d", arity, parties), func(t *testing.T) {
+
+				tree := NewAggregationTree(arity)
+
+				shares := make([]Share, parties)
+				for i := range shares {
+					shares[i] = &testShare{values: []uint64{uint64(i), 1}}
+				}
+
+				shareOut := &testShare{values: make([]uint64, 2)}
+
+				if err := tree.Aggregate(shares, shareOut); err != nil {
+					t.Fatal(err)
+				}
+
+				if shareOut.values[0] != parties*(parties-1)/2 || shareOut.values[1] != parties {
+					t.Errorf("invalid aggregation: %v", shareOut.values)
+				}
+
+				digest, err := tree.AggregateAndDigest(shares, shareOut)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if shareOut.values[0] != parties*(parties-1)/2 || shareOut.values[1] != parties {
+					t.Errorf("invalid aggregation with digest: %v", shareOut.values)
+				}
+
+				// The same shares in the same order give the same digest
+				if digestAgain, _ := tree.AggregateAndDigest(shares, shareOut); !bytes.Equal(digest, digestAgain) {
+					t.Error("the digest is not deterministic")
+				}
+
+				// Swapping two shares gives the same aggregation but a different digest
+				if parties > 1 {
+					shares[0], shares[1] = shares[1], shares[0]
+					if digestSwapped, _ := tree.AggregateAndDigest(shares, shareOut); bytes.Equal(digest, digestSwapped) {
+						t.Error("the digest does not depend on the aggregation order")
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("ShareOutIsInput", func(t *testing.T) {
+
+		shares := make([]Share, 4)
+		for i := range shares {
+			shares[i] = &testShare{values: []uint64{uint64(i), 1}}
+		}
+
+		// shareOut is read after the aggregation of the first shares of its group
+		shareOut := shares[2].(*testShare)
+
+		if err := NewAggregationTree(8).Aggregate(shares, shareOut); err != nil {
+			t.Fatal(err)
+		}
+
+		if shareOut.values[0] != 6 || shareOut.values[1] != 4 {
+			t.Errorf("invalid aggregation on an input share: %v", shareOut.values)
+		}
+	})
+
+	t.Run("NoShares", func(t *testing.T) {
+		if err := NewAggregationTree(2).Aggregate(nil, &testShare{}); err == nil {
+			t.Error("the aggregation of no shares did not return an error")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"sort"
+)
+
+// FindBestBSGSSplit returns the power of two baby-step size, at most slots, that minimizes the number of rotations
+// required to evaluate a matrix with the given non-zero diagonal indexes with the baby-step giant-step algorithm.
+func FindBestBSGSSplit(diags []uint64, slots uint64) (n1 uint64) {
+
+	minRotations := -1
+
+	for n := uint64(1); n <= slots; n <<= 1 {
+		if nbRotations := len(BSGSRotations(diags, n)); minRotations == -1 || nbRotations < minRotations {
+			minRotations = nbRotations
+			n1 = n
+		}
+	}
+
+	return
+}
+
+// BSGSIndex returns the sorted baby-steps and giant-steps of the given non-zero diagonal indexes for the
+// baby-step size n1.
+func BSGSIndex(diags []uint64, n1 uint64) (babySteps, giantSteps []uint64) {
+
+	babyMap := make(map[uint64]bool)
+	giantMap := make(map[uint64]bool)
+
+	for _, k := range diags {
+		babyMap[k%n1] = true
+		giantMap[k-(k%n1)] = true
+	}
+
+	return sortedKeys(babyMap), sortedKeys(giantMap)
+}
+
+// NaiveRotations returns the sorted non-zero rotations required to evaluate a matrix with the given non-zero
+// diagonal indexes with one rotation per diagonal.
+func NaiveRotations(diags []uint64) (rotations []uint64) {
+
+	rotMap := make(map[uint64]bool)
+
+	for _, k := range diags {
+		if k != 0 {
+			rotMap[k] = true
+		}
+	}
+
+	return sortedKeys(rotMap)
+}
+
+// BSGSRotations returns the sorted non-zero rotations required to evaluate a matrix with the given non-zero
+// diagonal indexes with the baby-step giant-step algorithm and baby-step size n1.
+func BSGSRotations(diags []uint64, n1 uint64) (rotations []uint64) {
+
+	rotMap := make(map[uint64]bool)
+
+	for _, k := range diags {
+
+		if k%n1 != 0 {
+			rotMap[k%n1] = true
+		}
+
+		if k-(k%n1) != 0 {
+			rotMap[k-(k%n1)] = true
+		}
+	}
+
+	return sortedKeys(rotMap)
+}
+
+func sortedKeys(m map[uint64]bool) (keys []uint64) {
+
+	keys = make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBSGS(t *testing.T) {
+
+	diags := []uint64{7, 0, 1, 2, 3, 4, 5, 6}
+
+	assert.Equal(t, uint64(2), FindBestBSGSSplit(diags, 8))
+
+	babySteps, giantSteps := BSGSIndex(diags, 2)
+	assert.Equal(t, []uint64{0, 1}, babySteps)
+	assert.Equal(t, []uint64{0, 2, 4, 6}, giantSteps)
+
+	assert.Equal(t, []uint64{1, 2, 4, 6}, BSGSRotations(diags, 2))
+	assert.Equal(t, []uint64{1, 3}, NaiveRotations([]uint64{0, 3, 1, 3}))
+}
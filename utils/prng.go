@@ -1,11 +1,15 @@
 package utils
 
 import (
+	"crypto/rand"
 	"errors"
 	"golang.org/x/crypto/blake2b"
 	"hash"
 )
 
+// SeedSize is the size in bytes of the seeds returned by NewSeed.
+const SeedSize = 32
+
 // PRNG is a structure storing the parameters used to securely and deterministically generate shared
 // sequences of random bytes among different parties using the hash function blake2b. Backward sequence
 // security (given the digest i, compute the digest i-1) is ensured by default, however forward sequence
@@ -26,6 +30,16 @@ func NewPRNG(key []byte) (*PRNG, error) {
 	return prng, err
 }
 
+// NewSeed returns a new seed of SeedSize bytes sampled with crypto/rand, that can be used to seed a PRNG
+// (for example to generate uniform polynomials that can later be re-generated from the seed only).
+func NewSeed() []byte {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		panic("crypto rand error")
+	}
+	return seed
+}
+
 // GetClock returns the value of the clock cycle of the PRNG.
 func (prng *PRNG) GetClock() uint64 {
 	return prng.clock
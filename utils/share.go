@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"encoding"
+)
+
+// BinaryShare is the interface implemented by the shares of all the multiparty protocols of the dbfv and dckks
+// packages, so that they can be sent between the parties: a share encoded with MarshalBinary is decoded with
+// UnmarshalBinary on a share of the same type.
+type BinaryShare interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Share is the interface for the shares of the multiparty protocols that can be aggregated by an AggregationTree.
+// The shares of the dbfv and dckks protocols implement it once bound to their protocol (see their BindShare method).
+type Share interface {
+	BinaryShare
+	// Allocate returns a new (zero) share of the same type and dimensions as the target share.
+	Allocate() Share
+	// Aggregate sets the target share to the aggregation of share1 and share2.
+	Aggregate(share1, share2 Share)
+}